@@ -57,6 +57,7 @@ type GenericOptions func(i interface{})
 
 type GenericContext struct {
 	*http.Server
+	mux           *http.ServeMux
 	httpHandler   *process.HTTPHandler
 	healthChecker *HealthzChecker
 
@@ -184,6 +185,7 @@ func NewGenericContext(
 	}
 
 	c := &GenericContext{
+		mux:         mux,
 		httpHandler: httpHandler,
 		Server: &http.Server{
 			Handler: httpHandler.WithHandleChain(mux),
@@ -215,6 +217,16 @@ func (c *GenericContext) IsEnabled(name string, components []string) bool {
 	return general.IsNameEnabled(name, c.DisabledByDefault, components)
 }
 
+// RegisterHTTPHandler registers handler for the given path on generic endpoint,
+// and it should be called before the context starts to run.
+func (c *GenericContext) RegisterHTTPHandler(path string, handler http.Handler) {
+	if c.mux == nil {
+		general.Warningf("http mux is not initialized, skip registering handler for %v", path)
+		return
+	}
+	c.mux.Handle(path, handler)
+}
+
 // SetDefaultMetricsEmitter to set default metrics emitter by custom metric emitter
 func (c *GenericContext) SetDefaultMetricsEmitter(metricEmitter metrics.MetricEmitter) {
 	c.EmitterPool.SetDefaultMetricsEmitter(metricEmitter)
@@ -17,7 +17,10 @@ limitations under the License.
 package agent
 
 import (
+	"net/http"
+
 	"github.com/kubewharf/katalyst-core/pkg/agent/audit"
+	"github.com/kubewharf/katalyst-core/pkg/agent/audit/sink"
 	"github.com/kubewharf/katalyst-core/pkg/config"
 	"github.com/kubewharf/katalyst-core/pkg/util/eventbus"
 )
@@ -30,5 +33,12 @@ func InitAuditManager(agentCtx *GenericContext, conf *config.Configuration, _ in
 	eventbus.GetDefaultEventBus().SetEmitter(agentCtx.EmitterPool.GetDefaultMetricsEmitter())
 	auditManager := audit.NewAuditManager(conf.AuditConfiguration, eventbus.GetDefaultEventBus(), agentCtx.EmitterPool.GetDefaultMetricsEmitter())
 
+	// expose in-memory audit history on generic endpoint for debugging
+	if s, ok := auditManager.GetSink(sink.SinkNameHistory); ok {
+		if handler, ok := s.(http.Handler); ok {
+			agentCtx.RegisterHTTPHandler(sink.HistoryHTTPPath, handler)
+		}
+	}
+
 	return true, auditManager, nil
 }
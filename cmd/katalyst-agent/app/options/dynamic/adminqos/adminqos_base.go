@@ -22,6 +22,7 @@ import (
 
 	"github.com/kubewharf/katalyst-core/cmd/katalyst-agent/app/options/dynamic/adminqos/advisor"
 	"github.com/kubewharf/katalyst-core/cmd/katalyst-agent/app/options/dynamic/adminqos/eviction"
+	"github.com/kubewharf/katalyst-core/cmd/katalyst-agent/app/options/dynamic/adminqos/finegrainedresource"
 	"github.com/kubewharf/katalyst-core/cmd/katalyst-agent/app/options/dynamic/adminqos/qrm"
 	"github.com/kubewharf/katalyst-core/cmd/katalyst-agent/app/options/dynamic/adminqos/reclaimedresource"
 	"github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic/adminqos"
@@ -32,14 +33,16 @@ type AdminQoSOptions struct {
 	*qrm.QRMPluginOptions
 	*eviction.EvictionOptions
 	*advisor.AdvisorOptions
+	*finegrainedresource.FineGrainedResourceOptions
 }
 
 func NewAdminQoSOptions() *AdminQoSOptions {
 	return &AdminQoSOptions{
-		ReclaimedResourceOptions: reclaimedresource.NewReclaimedResourceOptions(),
-		QRMPluginOptions:         qrm.NewQRMPluginOptions(),
-		EvictionOptions:          eviction.NewEvictionOptions(),
-		AdvisorOptions:           advisor.NewAdvisorOptions(),
+		ReclaimedResourceOptions:   reclaimedresource.NewReclaimedResourceOptions(),
+		QRMPluginOptions:           qrm.NewQRMPluginOptions(),
+		EvictionOptions:            eviction.NewEvictionOptions(),
+		AdvisorOptions:             advisor.NewAdvisorOptions(),
+		FineGrainedResourceOptions: finegrainedresource.NewFineGrainedResourceOptions(),
 	}
 }
 
@@ -48,6 +51,7 @@ func (o *AdminQoSOptions) AddFlags(fss *cliflag.NamedFlagSets) {
 	o.QRMPluginOptions.AddFlags(fss)
 	o.EvictionOptions.AddFlags(fss)
 	o.AdvisorOptions.AddFlags(fss)
+	o.FineGrainedResourceOptions.AddFlags(fss)
 }
 
 func (o *AdminQoSOptions) ApplyTo(c *adminqos.AdminQoSConfiguration) error {
@@ -56,5 +60,6 @@ func (o *AdminQoSOptions) ApplyTo(c *adminqos.AdminQoSConfiguration) error {
 	errList = append(errList, o.QRMPluginOptions.ApplyTo(c.QRMPluginConfiguration))
 	errList = append(errList, o.EvictionOptions.ApplyTo(c.EvictionConfiguration))
 	errList = append(errList, o.AdvisorOptions.ApplyTo(c.AdvisorConfiguration))
+	errList = append(errList, o.FineGrainedResourceOptions.ApplyTo(c.FineGrainedResourceConfiguration))
 	return errors.NewAggregate(errList)
 }
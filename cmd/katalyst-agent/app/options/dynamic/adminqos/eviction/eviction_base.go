@@ -26,7 +26,11 @@ import (
 )
 
 type EvictionOptions struct {
-	DryRun []string
+	DryRun            []string
+	ShadowModePlugins []string
+
+	EvictionCandidateComparators []string
+	EvictionCandidateOwnerKinds  []string
 
 	*CPUPressureEvictionOptions
 	*MemoryPressureEvictionOptions
@@ -51,6 +55,14 @@ func (o *EvictionOptions) AddFlags(fss *cliflag.NamedFlagSets) {
 	fs := fss.FlagSet("eviction")
 	fs.StringSliceVar(&o.DryRun, "eviction-dry-run-plugins", o.DryRun, fmt.Sprintf(" A list of "+
 		"eviction plugins to dry run. If a plugin in this list, it will enter dry run mode"))
+	fs.StringSliceVar(&o.ShadowModePlugins, "eviction-shadow-mode-plugins", o.ShadowModePlugins, " A list of "+
+		"eviction plugins to run in shadow mode. If a plugin in this list, its candidates will be recorded by "+
+		"a dry-run killer after candidate selection and rule queueing instead of being killed")
+	fs.StringSliceVar(&o.EvictionCandidateComparators, "eviction-candidate-comparators", o.EvictionCandidateComparators,
+		"The ordered list of comparators to sort eviction candidates, e.g. katalyst-qos,priority,eviction-scope,pod-name,"+
+			"uptime,restarts,owner-kind. If it's empty, the default chain will be used")
+	fs.StringSliceVar(&o.EvictionCandidateOwnerKinds, "eviction-candidate-owner-kinds", o.EvictionCandidateOwnerKinds,
+		"The ordered list of controller kinds whose pods are preferred to be evicted by the owner-kind comparator")
 
 	o.CPUPressureEvictionOptions.AddFlags(fss)
 	o.MemoryPressureEvictionOptions.AddFlags(fss)
@@ -63,6 +75,9 @@ func (o *EvictionOptions) AddFlags(fss *cliflag.NamedFlagSets) {
 func (o *EvictionOptions) ApplyTo(c *eviction.EvictionConfiguration) error {
 	var errList []error
 	c.DryRun = o.DryRun
+	c.ShadowModePlugins = o.ShadowModePlugins
+	c.EvictionCandidateComparators = o.EvictionCandidateComparators
+	c.EvictionCandidateOwnerKinds = o.EvictionCandidateOwnerKinds
 	errList = append(errList, o.CPUPressureEvictionOptions.ApplyTo(c.CPUPressureEvictionConfiguration))
 	errList = append(errList, o.MemoryPressureEvictionOptions.ApplyTo(c.MemoryPressureEvictionConfiguration))
 	errList = append(errList, o.ReclaimedResourcesEvictionOptions.ApplyTo(c.ReclaimedResourcesEvictionConfiguration))
@@ -0,0 +1,89 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package finegrainedresource
+
+import (
+	"fmt"
+
+	cliflag "k8s.io/component-base/cli/flag"
+
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic/adminqos/finegrainedresource"
+)
+
+type CPUBurstOptions struct {
+	DynamicCPUBurstMinPercent              int64
+	DynamicCPUBurstMaxPercent              int64
+	DynamicCPUBurstStepPercent             int64
+	DynamicCPUBurstThrottledRatioThreshold float64
+	DynamicCPUBurstNodeUsageLowWatermark   float64
+	DynamicCPUBurstNodeUsageHighWatermark  float64
+	DynamicCPUBurstNodeBudgetRatio         float64
+}
+
+func NewCPUBurstOptions() *CPUBurstOptions {
+	return &CPUBurstOptions{
+		DynamicCPUBurstMinPercent:              0,
+		DynamicCPUBurstMaxPercent:              200,
+		DynamicCPUBurstStepPercent:             20,
+		DynamicCPUBurstThrottledRatioThreshold: 0.1,
+		DynamicCPUBurstNodeUsageLowWatermark:   0.6,
+		DynamicCPUBurstNodeUsageHighWatermark:  0.85,
+		DynamicCPUBurstNodeBudgetRatio:         0.2,
+	}
+}
+
+// AddFlags parses the flags to CPUBurstOptions
+func (o *CPUBurstOptions) AddFlags(fss *cliflag.NamedFlagSets) {
+	fs := fss.FlagSet("cpu-burst")
+
+	fs.Int64Var(&o.DynamicCPUBurstMinPercent, "dynamic-cpu-burst-min-percent", o.DynamicCPUBurstMinPercent,
+		"the minimum cpu burst percent for pods with dynamic cpu burst policy")
+	fs.Int64Var(&o.DynamicCPUBurstMaxPercent, "dynamic-cpu-burst-max-percent", o.DynamicCPUBurstMaxPercent,
+		"the maximum cpu burst percent for pods with dynamic cpu burst policy")
+	fs.Int64Var(&o.DynamicCPUBurstStepPercent, "dynamic-cpu-burst-step-percent", o.DynamicCPUBurstStepPercent,
+		"the cpu burst percent raised or lowered in each round for pods with dynamic cpu burst policy")
+	fs.Float64Var(&o.DynamicCPUBurstThrottledRatioThreshold, "dynamic-cpu-burst-throttled-ratio-threshold", o.DynamicCPUBurstThrottledRatioThreshold,
+		"the ratio of throttled periods above which cpu burst of the container will be raised")
+	fs.Float64Var(&o.DynamicCPUBurstNodeUsageLowWatermark, "dynamic-cpu-burst-node-usage-low-watermark", o.DynamicCPUBurstNodeUsageLowWatermark,
+		"the node cpu usage ratio below which cpu burst can be raised")
+	fs.Float64Var(&o.DynamicCPUBurstNodeUsageHighWatermark, "dynamic-cpu-burst-node-usage-high-watermark", o.DynamicCPUBurstNodeUsageHighWatermark,
+		"the node cpu usage ratio above which cpu burst will be lowered")
+	fs.Float64Var(&o.DynamicCPUBurstNodeBudgetRatio, "dynamic-cpu-burst-node-budget-ratio", o.DynamicCPUBurstNodeBudgetRatio,
+		"the ratio to node cpu capacity that caps the total cpu burst of pods with dynamic cpu burst policy")
+}
+
+func (o *CPUBurstOptions) ApplyTo(c *finegrainedresource.CPUBurstConfiguration) error {
+	if o.DynamicCPUBurstMinPercent < 0 || o.DynamicCPUBurstMinPercent > o.DynamicCPUBurstMaxPercent {
+		return fmt.Errorf("invalid dynamic cpu burst percent range [%v, %v]", o.DynamicCPUBurstMinPercent, o.DynamicCPUBurstMaxPercent)
+	}
+	if o.DynamicCPUBurstStepPercent <= 0 {
+		return fmt.Errorf("invalid dynamic cpu burst step percent %v", o.DynamicCPUBurstStepPercent)
+	}
+	if o.DynamicCPUBurstNodeUsageLowWatermark > o.DynamicCPUBurstNodeUsageHighWatermark {
+		return fmt.Errorf("dynamic cpu burst node usage low watermark %v is larger than high watermark %v",
+			o.DynamicCPUBurstNodeUsageLowWatermark, o.DynamicCPUBurstNodeUsageHighWatermark)
+	}
+
+	c.DynamicCPUBurstMinPercent = o.DynamicCPUBurstMinPercent
+	c.DynamicCPUBurstMaxPercent = o.DynamicCPUBurstMaxPercent
+	c.DynamicCPUBurstStepPercent = o.DynamicCPUBurstStepPercent
+	c.DynamicCPUBurstThrottledRatioThreshold = o.DynamicCPUBurstThrottledRatioThreshold
+	c.DynamicCPUBurstNodeUsageLowWatermark = o.DynamicCPUBurstNodeUsageLowWatermark
+	c.DynamicCPUBurstNodeUsageHighWatermark = o.DynamicCPUBurstNodeUsageHighWatermark
+	c.DynamicCPUBurstNodeBudgetRatio = o.DynamicCPUBurstNodeBudgetRatio
+	return nil
+}
@@ -44,12 +44,32 @@ type GenericEvictionOptions struct {
 	// EvictionBurst limit the burst eviction counts
 	EvictionBurst int
 
+	// EvictionBudgetWindow is the sliding window to count evictions for eviction budgets
+	EvictionBudgetWindow time.Duration
+	// NodeEvictionBudget limits the eviction counts of the whole node in each window
+	NodeEvictionBudget int
+	// QoSEvictionBudgets limits the eviction counts of each QoS level in each window
+	QoSEvictionBudgets map[string]int
+	// OwnerEvictionBudget limits the eviction counts of each workload owner in each window
+	OwnerEvictionBudget int
+
 	// PodKiller specify the pod killer implementation
 	PodKiller string
 
 	// QoSPodKillers specify the pod killer implementation for different QoS levels
 	QoSPodKillers map[string]string
 
+	// FreezeKillerMode specifies how freeze-killer suspends pods
+	FreezeKillerMode string
+	// FreezeKillerEscalationDuration is the duration a pod can be kept suspended by freeze-killer
+	FreezeKillerEscalationDuration time.Duration
+	// FreezeKillerReleaseTimeout is the duration after which a suspended pod will be resumed
+	FreezeKillerReleaseTimeout time.Duration
+	// FreezeKillerEscalationKiller specifies the pod killer used by freeze-killer to evict pods
+	FreezeKillerEscalationKiller string
+	// FreezeKillerCheckpointDir is the directory to checkpoint pods suspended by freeze-killer
+	FreezeKillerCheckpointDir string
+
 	// StrictAuthentication means whether to authenticate plugins strictly
 	StrictAuthentication bool
 
@@ -66,15 +86,21 @@ type GenericEvictionOptions struct {
 // NewGenericEvictionOptions creates a new Options with a default config.
 func NewGenericEvictionOptions() *GenericEvictionOptions {
 	return &GenericEvictionOptions{
-		InnerPlugins:                  []string{},
-		ConditionTransitionPeriod:     5 * time.Minute,
-		EvictionManagerSyncPeriod:     5 * time.Second,
-		EvictionSkippedAnnotationKeys: []string{},
-		EvictionSkippedLabelKeys:      []string{},
-		EvictionBurst:                 3,
-		HostPathNotifierRootPath:      "/opt/katalyst",
-		PodKiller:                     consts.KillerNameEvictionKiller,
-		StrictAuthentication:          false,
+		InnerPlugins:                   []string{},
+		ConditionTransitionPeriod:      5 * time.Minute,
+		EvictionManagerSyncPeriod:      5 * time.Second,
+		EvictionSkippedAnnotationKeys:  []string{},
+		EvictionSkippedLabelKeys:       []string{},
+		EvictionBurst:                  3,
+		QoSEvictionBudgets:             map[string]int{},
+		HostPathNotifierRootPath:       "/opt/katalyst",
+		PodKiller:                      consts.KillerNameEvictionKiller,
+		FreezeKillerMode:               consts.FreezeKillerModeFreeze,
+		FreezeKillerEscalationDuration: 5 * time.Minute,
+		FreezeKillerReleaseTimeout:     time.Minute,
+		FreezeKillerEscalationKiller:   consts.KillerNameEvictionKiller,
+		FreezeKillerCheckpointDir:      "/var/lib/katalyst/eviction/freeze_killer",
+		StrictAuthentication:           false,
 	}
 }
 
@@ -100,12 +126,33 @@ func (o *GenericEvictionOptions) AddFlags(fss *cliflag.NamedFlagSets) {
 	fs.IntVar(&o.EvictionBurst, "eviction-burst", o.EvictionBurst,
 		"The burst amount of pods to be evicted by edition manager")
 
+	fs.DurationVar(&o.EvictionBudgetWindow, "eviction-budget-window", o.EvictionBudgetWindow,
+		"the sliding window to count evictions for eviction budgets, non-positive value disables eviction budgets")
+	fs.IntVar(&o.NodeEvictionBudget, "eviction-node-budget", o.NodeEvictionBudget,
+		"the max amount of pods to be evicted on this node in each eviction budget window, non-positive means unlimited")
+	fs.StringToIntVar(&o.QoSEvictionBudgets, "eviction-qos-budgets", o.QoSEvictionBudgets,
+		"the max amount of pods to be evicted for each QoS level in each eviction budget window")
+	fs.IntVar(&o.OwnerEvictionBudget, "eviction-owner-budget", o.OwnerEvictionBudget,
+		"the max amount of pods to be evicted for each workload owner in each eviction budget window, non-positive means unlimited")
+
 	fs.StringVar(&o.PodKiller, "pod-killer", o.PodKiller,
 		"the pod killer used to evict pod")
 
 	fs.StringToStringVar(&o.QoSPodKillers, "qos-pod-killers", o.QoSPodKillers,
 		"the pod killer used to evict pod for different QoS levels")
 
+	fs.StringVar(&o.FreezeKillerMode, "freeze-killer-mode", o.FreezeKillerMode,
+		fmt.Sprintf("the way freeze-killer suspends pods, %q freezes pods by cgroup freezer and %q hard-throttles pods by cpu quota",
+			consts.FreezeKillerModeFreeze, consts.FreezeKillerModeThrottle))
+	fs.DurationVar(&o.FreezeKillerEscalationDuration, "freeze-killer-escalation-duration", o.FreezeKillerEscalationDuration,
+		"the duration a pod can be kept suspended by freeze-killer before it is evicted by the escalation killer")
+	fs.DurationVar(&o.FreezeKillerReleaseTimeout, "freeze-killer-release-timeout", o.FreezeKillerReleaseTimeout,
+		"the duration after which a suspended pod will be resumed by freeze-killer if no more eviction request comes")
+	fs.StringVar(&o.FreezeKillerEscalationKiller, "freeze-killer-escalation-killer", o.FreezeKillerEscalationKiller,
+		"the pod killer used by freeze-killer to evict pods if pressure persists")
+	fs.StringVar(&o.FreezeKillerCheckpointDir, "freeze-killer-checkpoint-dir", o.FreezeKillerCheckpointDir,
+		"the directory to checkpoint pods suspended by freeze-killer, so that they can be resumed after restart; empty disables it")
+
 	fs.BoolVar(&o.StrictAuthentication, "strict-authentication", o.StrictAuthentication,
 		"whether to authenticate plugins strictly, the out-of-tree plugins must use valid and authorized token "+
 			"to register if it set to true")
@@ -128,8 +175,17 @@ func (o *GenericEvictionOptions) ApplyTo(c *evictionconfig.GenericEvictionConfig
 	c.EvictionSkippedAnnotationKeys.Insert(o.EvictionSkippedAnnotationKeys...)
 	c.EvictionSkippedLabelKeys.Insert(o.EvictionSkippedLabelKeys...)
 	c.EvictionBurst = o.EvictionBurst
+	c.EvictionBudgetWindow = o.EvictionBudgetWindow
+	c.NodeEvictionBudget = o.NodeEvictionBudget
+	c.QoSEvictionBudgets = o.QoSEvictionBudgets
+	c.OwnerEvictionBudget = o.OwnerEvictionBudget
 	c.PodKiller = o.PodKiller
 	c.QoSPodKillers = o.QoSPodKillers
+	c.FreezeKillerMode = o.FreezeKillerMode
+	c.FreezeKillerEscalationDuration = o.FreezeKillerEscalationDuration
+	c.FreezeKillerReleaseTimeout = o.FreezeKillerReleaseTimeout
+	c.FreezeKillerEscalationKiller = o.FreezeKillerEscalationKiller
+	c.FreezeKillerCheckpointDir = o.FreezeKillerCheckpointDir
 	c.StrictAuthentication = o.StrictAuthentication
 	c.PodMetricLabels.Insert(o.PodMetricLabels...)
 	c.RecordManager = o.RecordManager
@@ -17,6 +17,9 @@ limitations under the License.
 package global
 
 import (
+	"fmt"
+	"time"
+
 	cliflag "k8s.io/component-base/cli/flag"
 
 	"github.com/kubewharf/katalyst-core/pkg/agent/audit/sink"
@@ -28,12 +31,38 @@ const DefaultBufferSize = 1000
 type AuditOptions struct {
 	Sinks      []string
 	BufferSize int
+
+	FileSinkPath       string
+	FileSinkMaxSizeMB  int
+	FileSinkMaxBackups int
+	FileSinkMaxAgeDays int
+
+	WebhookSinkURL           string
+	WebhookSinkBatchSize     int
+	WebhookSinkFlushInterval time.Duration
+	WebhookSinkTimeout       time.Duration
+	WebhookSinkMaxRetries    int
+	WebhookSinkRetryInterval time.Duration
+
+	HistorySinkCapacity int
+	HistorySinkMaxPods  int
 }
 
 func NewAuditOptions() *AuditOptions {
 	return &AuditOptions{
-		Sinks:      []string{sink.SinkNameLogBased},
-		BufferSize: DefaultBufferSize,
+		Sinks:                    []string{sink.SinkNameLogBased},
+		BufferSize:               DefaultBufferSize,
+		FileSinkPath:             "/var/log/katalyst/audit.log",
+		FileSinkMaxSizeMB:        100,
+		FileSinkMaxBackups:       5,
+		FileSinkMaxAgeDays:       7,
+		WebhookSinkBatchSize:     100,
+		WebhookSinkFlushInterval: 5 * time.Second,
+		WebhookSinkTimeout:       10 * time.Second,
+		WebhookSinkMaxRetries:    3,
+		WebhookSinkRetryInterval: time.Second,
+		HistorySinkCapacity:      100,
+		HistorySinkMaxPods:       1000,
 	}
 }
 
@@ -42,11 +71,56 @@ func (o *AuditOptions) AddFlags(fss *cliflag.NamedFlagSets) {
 	fs := fss.FlagSet("audit")
 	fs.StringSliceVar(&o.Sinks, "sinks", o.Sinks, "the sinks to send audit data")
 	fs.IntVar(&o.BufferSize, "buffer-size", o.BufferSize, "buffer size for write audit data")
+
+	fs.StringVar(&o.FileSinkPath, "audit-file-sink-path", o.FileSinkPath,
+		"the path of JSON-lines file for file sink")
+	fs.IntVar(&o.FileSinkMaxSizeMB, "audit-file-sink-max-size", o.FileSinkMaxSizeMB,
+		"the max size in megabytes of audit file before it gets rotated")
+	fs.IntVar(&o.FileSinkMaxBackups, "audit-file-sink-max-backups", o.FileSinkMaxBackups,
+		"the max number of rotated audit files to retain")
+	fs.IntVar(&o.FileSinkMaxAgeDays, "audit-file-sink-max-age", o.FileSinkMaxAgeDays,
+		"the max number of days to retain rotated audit files")
+
+	fs.StringVar(&o.WebhookSinkURL, "audit-webhook-sink-url", o.WebhookSinkURL,
+		"the HTTP endpoint that webhook sink sends audit data to")
+	fs.IntVar(&o.WebhookSinkBatchSize, "audit-webhook-sink-batch-size", o.WebhookSinkBatchSize,
+		"the max number of audit events sent in one webhook request")
+	fs.DurationVar(&o.WebhookSinkFlushInterval, "audit-webhook-sink-flush-interval", o.WebhookSinkFlushInterval,
+		"the interval to send audit events even if the batch is not full")
+	fs.DurationVar(&o.WebhookSinkTimeout, "audit-webhook-sink-timeout", o.WebhookSinkTimeout,
+		"the timeout of each webhook request")
+	fs.IntVar(&o.WebhookSinkMaxRetries, "audit-webhook-sink-max-retries", o.WebhookSinkMaxRetries,
+		"the max retries for a failed webhook request before the batch is dropped")
+	fs.DurationVar(&o.WebhookSinkRetryInterval, "audit-webhook-sink-retry-interval", o.WebhookSinkRetryInterval,
+		"the interval between retries of webhook requests")
+
+	fs.IntVar(&o.HistorySinkCapacity, "audit-history-sink-capacity", o.HistorySinkCapacity,
+		"the number of latest audit events kept in memory for each pod and each event kind")
+	fs.IntVar(&o.HistorySinkMaxPods, "audit-history-sink-max-pods", o.HistorySinkMaxPods,
+		"the max number of pods whose audit events are kept in memory, non-positive means unlimited")
 }
 
 // ApplyTo fills up config with options
 func (o *AuditOptions) ApplyTo(conf *global.AuditConfiguration) error {
+	for _, name := range o.Sinks {
+		if name == sink.SinkNameWebhook && o.WebhookSinkURL == "" {
+			return fmt.Errorf("audit-webhook-sink-url must be set if %v sink is enabled", sink.SinkNameWebhook)
+		}
+	}
+
 	conf.Sinks = o.Sinks
 	conf.BufferSize = o.BufferSize
+	conf.FileSinkPath = o.FileSinkPath
+	conf.FileSinkMaxSizeMB = o.FileSinkMaxSizeMB
+	conf.FileSinkMaxBackups = o.FileSinkMaxBackups
+	conf.FileSinkMaxAgeDays = o.FileSinkMaxAgeDays
+	conf.WebhookSinkURL = o.WebhookSinkURL
+	conf.WebhookSinkBatchSize = o.WebhookSinkBatchSize
+	conf.WebhookSinkFlushInterval = o.WebhookSinkFlushInterval
+	conf.WebhookSinkTimeout = o.WebhookSinkTimeout
+	conf.WebhookSinkMaxRetries = o.WebhookSinkMaxRetries
+	conf.WebhookSinkRetryInterval = o.WebhookSinkRetryInterval
+	conf.HistorySinkCapacity = o.HistorySinkCapacity
+	conf.HistorySinkMaxPods = o.HistorySinkMaxPods
 	return nil
 }
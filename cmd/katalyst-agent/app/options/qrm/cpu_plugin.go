@@ -25,6 +25,7 @@ import (
 	"github.com/kubewharf/katalyst-core/cmd/katalyst-agent/app/options/qrm/hintoptimizer"
 	"github.com/kubewharf/katalyst-core/cmd/katalyst-agent/app/options/qrm/irqtuner"
 	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/commonstate"
+	cpuconsts "github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/consts"
 	qrmconfig "github.com/kubewharf/katalyst-core/pkg/config/agent/qrm"
 )
 
@@ -54,6 +55,14 @@ type CPUDynamicPolicyOptions struct {
 	EnableCPUBurst                      bool
 	EnableDefaultDedicatedCoresCPUBurst bool
 	EnableDefaultSharedCoresCPUBurst    bool
+	EnableCoreScheduling                bool
+	CoreSchedulingGranularity           string
+	CoreSchedulingQoSLevels             []string
+	EnableLLCPartition                  bool
+	LLCPartitionMinReclaimedWays        int
+	LLCPartitionMaxReclaimedWays        int
+	LLCPartitionMPKILowThreshold        float64
+	LLCPartitionMPKIHighThreshold       float64
 	*irqtuner.IRQTunerOptions
 	*hintoptimizer.HintOptimizerOptions
 }
@@ -69,12 +78,20 @@ func NewCPUOptions() *CPUOptions {
 		ReservedCPUCores:       0,
 		SkipCPUStateCorruption: false,
 		CPUDynamicPolicyOptions: CPUDynamicPolicyOptions{
-			EnableCPUAdvisor:          false,
-			AdvisorGetAdviceInterval:  5 * time.Second,
-			EnableCPUPressureEviction: false,
-			EnableSyncingCPUIdle:      false,
-			EnableCPUIdle:             false,
-			EnableCPUBurst:            false,
+			EnableCPUAdvisor:              false,
+			AdvisorGetAdviceInterval:      5 * time.Second,
+			EnableCPUPressureEviction:     false,
+			EnableSyncingCPUIdle:          false,
+			EnableCPUIdle:                 false,
+			EnableCPUBurst:                false,
+			EnableCoreScheduling:          false,
+			CoreSchedulingGranularity:     cpuconsts.CoreSchedGranularityQoS,
+			CoreSchedulingQoSLevels:       []string{consts.PodAnnotationQoSLevelReclaimedCores},
+			EnableLLCPartition:            false,
+			LLCPartitionMinReclaimedWays:  1,
+			LLCPartitionMaxReclaimedWays:  4,
+			LLCPartitionMPKILowThreshold:  5,
+			LLCPartitionMPKIHighThreshold: 10,
 			LoadPressureEvictionSkipPools: []string{
 				commonstate.PoolNameReclaim,
 				commonstate.PoolNameDedicated,
@@ -140,6 +157,24 @@ func (o *CPUOptions) AddFlags(fss *cliflag.NamedFlagSets) {
 		o.EnableDefaultSharedCoresCPUBurst, "if set true, it will enable cpu burst for shared cores by default")
 	fs.BoolVar(&o.EnableDefaultDedicatedCoresCPUBurst, "enable-default-dedicated-cores-cpu-burst",
 		o.EnableDefaultDedicatedCoresCPUBurst, "if set true, it will enable cpu burst for dedicated cores by default")
+	fs.BoolVar(&o.EnableCoreScheduling, "enable-core-scheduling", o.EnableCoreScheduling,
+		"if set true, tasks will be assigned core scheduling cookies periodically, so that tasks "+
+			"in different groups never run on SMT siblings simultaneously")
+	fs.StringVar(&o.CoreSchedulingGranularity, "core-scheduling-granularity", o.CoreSchedulingGranularity,
+		"the granularity (qos/pod) of tasks sharing the same core scheduling cookie")
+	fs.StringSliceVar(&o.CoreSchedulingQoSLevels, "core-scheduling-qos-levels", o.CoreSchedulingQoSLevels,
+		"the qos levels whose tasks will be assigned core scheduling cookies, and tasks in other qos levels "+
+			"without cookies still can't run with them on SMT siblings")
+	fs.BoolVar(&o.EnableLLCPartition, "enable-llc-partition", o.EnableLLCPartition,
+		"if set true, L3 cache ways will be partitioned between online pools and the reclaimed pool through resctrl")
+	fs.IntVar(&o.LLCPartitionMinReclaimedWays, "llc-partition-min-reclaimed-ways", o.LLCPartitionMinReclaimedWays,
+		"the minimum number of L3 cache ways for the reclaimed pool")
+	fs.IntVar(&o.LLCPartitionMaxReclaimedWays, "llc-partition-max-reclaimed-ways", o.LLCPartitionMaxReclaimedWays,
+		"the maximum number of L3 cache ways for the reclaimed pool")
+	fs.Float64Var(&o.LLCPartitionMPKILowThreshold, "llc-partition-mpki-low-threshold", o.LLCPartitionMPKILowThreshold,
+		"the reclaimed pool gets one more L3 cache way if L3 misses per kilo instructions of online pods are below it")
+	fs.Float64Var(&o.LLCPartitionMPKIHighThreshold, "llc-partition-mpki-high-threshold", o.LLCPartitionMPKIHighThreshold,
+		"the reclaimed pool gives up one L3 cache way if L3 misses per kilo instructions of online pods reach it")
 	o.HintOptimizerOptions.AddFlags(fss)
 	o.IRQTunerOptions.AddFlags(fss)
 }
@@ -164,6 +199,14 @@ func (o *CPUOptions) ApplyTo(conf *qrmconfig.CPUQRMPluginConfig) error {
 	conf.EnableCPUBurst = o.EnableCPUBurst
 	conf.EnableDefaultDedicatedCoresCPUBurst = o.EnableDefaultDedicatedCoresCPUBurst
 	conf.EnableDefaultSharedCoresCPUBurst = o.EnableDefaultSharedCoresCPUBurst
+	conf.EnableCoreScheduling = o.EnableCoreScheduling
+	conf.CoreSchedulingGranularity = o.CoreSchedulingGranularity
+	conf.CoreSchedulingQoSLevels = o.CoreSchedulingQoSLevels
+	conf.EnableLLCPartition = o.EnableLLCPartition
+	conf.LLCPartitionMinReclaimedWays = o.LLCPartitionMinReclaimedWays
+	conf.LLCPartitionMaxReclaimedWays = o.LLCPartitionMaxReclaimedWays
+	conf.LLCPartitionMPKILowThreshold = o.LLCPartitionMPKILowThreshold
+	conf.LLCPartitionMPKIHighThreshold = o.LLCPartitionMPKIHighThreshold
 	if err := o.HintOptimizerOptions.ApplyTo(conf.HintOptimizerConfiguration); err != nil {
 		return err
 	}
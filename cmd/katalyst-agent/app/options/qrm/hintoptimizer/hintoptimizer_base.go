@@ -34,6 +34,7 @@ type HintOptimizerOptions struct {
 	*CanonicalHintOptimizerOptions
 	*MemoryBandwidthHintOptimizerOptions
 	*MetricBasedHintOptimizerOptions
+	*InterferenceHintOptimizerOptions
 }
 
 func NewHintOptimizerOptions() *HintOptimizerOptions {
@@ -42,6 +43,7 @@ func NewHintOptimizerOptions() *HintOptimizerOptions {
 		CanonicalHintOptimizerOptions:       NewCanonicalHintOptimizerOptions(),
 		MemoryBandwidthHintOptimizerOptions: NewMemoryBandwidthHintOptimizerOptions(),
 		MetricBasedHintOptimizerOptions:     NewMetricBasedHintOptimizerOptions(),
+		InterferenceHintOptimizerOptions:    NewInterferenceHintOptimizerOptions(),
 	}
 }
 
@@ -56,6 +58,7 @@ func (o *HintOptimizerOptions) AddFlags(fss *cliflag.NamedFlagSets) {
 	o.CanonicalHintOptimizerOptions.AddFlags(fss)
 	o.MemoryBandwidthHintOptimizerOptions.AddFlags(fss)
 	o.MetricBasedHintOptimizerOptions.AddFlags(fss)
+	o.InterferenceHintOptimizerOptions.AddFlags(fss)
 }
 
 func (o *HintOptimizerOptions) ApplyTo(conf *hintoptimizer.HintOptimizerConfiguration) error {
@@ -81,5 +84,8 @@ func (o *HintOptimizerOptions) ApplyTo(conf *hintoptimizer.HintOptimizerConfigur
 	if err := o.MetricBasedHintOptimizerOptions.ApplyTo(conf.MetricBasedHintOptimizerConfig); err != nil {
 		return err
 	}
+	if err := o.InterferenceHintOptimizerOptions.ApplyTo(conf.InterferenceHintOptimizerConfig); err != nil {
+		return err
+	}
 	return nil
 }
@@ -0,0 +1,96 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hintoptimizer
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	cliflag "k8s.io/component-base/cli/flag"
+
+	apiconsts "github.com/kubewharf/katalyst-api/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/qrm/hintoptimizer"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+)
+
+type InterferenceHintOptimizerOptions struct {
+	InterferenceLatencySensitiveQoSLevels []string
+	InterferenceCollectInterval           time.Duration
+	InterferenceHistoryDecayFactor        float64
+	InterferenceMinSampleCount            int
+	InterferenceNoisyThreshold            float64
+	InterferenceMetricWeights             map[string]string
+}
+
+func NewInterferenceHintOptimizerOptions() *InterferenceHintOptimizerOptions {
+	return &InterferenceHintOptimizerOptions{
+		InterferenceLatencySensitiveQoSLevels: []string{
+			apiconsts.PodAnnotationQoSLevelSharedCores,
+			apiconsts.PodAnnotationQoSLevelDedicatedCores,
+		},
+		InterferenceCollectInterval:    30 * time.Second,
+		InterferenceHistoryDecayFactor: 0.2,
+		InterferenceMinSampleCount:     10,
+		InterferenceNoisyThreshold:     1.2,
+		InterferenceMetricWeights: map[string]string{
+			consts.MetricCPUCPIContainer:             "1",
+			consts.MetricMemLatencyReadNuma:          "1",
+			consts.MetricCPUNrThrottledRateContainer: "0.5",
+		},
+	}
+}
+
+func (o *InterferenceHintOptimizerOptions) AddFlags(fss *cliflag.NamedFlagSets) {
+	fs := fss.FlagSet("interference_hint_optimizer")
+
+	fs.StringSliceVar(&o.InterferenceLatencySensitiveQoSLevels, "interference-latency-sensitive-qos-levels", o.InterferenceLatencySensitiveQoSLevels,
+		"the qos levels of pods whose hints will be optimized by numa interference history")
+	fs.DurationVar(&o.InterferenceCollectInterval, "interference-collect-interval", o.InterferenceCollectInterval,
+		"the interval to sample interference metrics for each numa")
+	fs.Float64Var(&o.InterferenceHistoryDecayFactor, "interference-history-decay-factor", o.InterferenceHistoryDecayFactor,
+		"the weight (0, 1] of the newest sample when updating the moving average of numa interference")
+	fs.IntVar(&o.InterferenceMinSampleCount, "interference-min-sample-count", o.InterferenceMinSampleCount,
+		"the minimum number of samples required before the interference history of a metric is trusted")
+	fs.Float64Var(&o.InterferenceNoisyThreshold, "interference-noisy-threshold", o.InterferenceNoisyThreshold,
+		"the ratio of a numa's interference score to the average of all numas, above which the numa is considered noisy")
+	fs.StringToStringVar(&o.InterferenceMetricWeights, "interference-metric-weights", o.InterferenceMetricWeights,
+		"the weight of each metric when calculating numa interference score, metrics not listed are ignored")
+}
+
+func (o *InterferenceHintOptimizerOptions) ApplyTo(conf *hintoptimizer.InterferenceHintOptimizerConfig) error {
+	if o.InterferenceHistoryDecayFactor <= 0 || o.InterferenceHistoryDecayFactor > 1 {
+		return fmt.Errorf("invalid interference history decay factor %v", o.InterferenceHistoryDecayFactor)
+	}
+
+	weights := make(map[string]float64, len(o.InterferenceMetricWeights))
+	for metricName, weightStr := range o.InterferenceMetricWeights {
+		weight, err := strconv.ParseFloat(weightStr, 64)
+		if err != nil {
+			return fmt.Errorf("invalid interference weight %q for metric %s: %v", weightStr, metricName, err)
+		}
+		weights[metricName] = weight
+	}
+
+	conf.InterferenceLatencySensitiveQoSLevels = o.InterferenceLatencySensitiveQoSLevels
+	conf.InterferenceCollectInterval = o.InterferenceCollectInterval
+	conf.InterferenceHistoryDecayFactor = o.InterferenceHistoryDecayFactor
+	conf.InterferenceMinSampleCount = o.InterferenceMinSampleCount
+	conf.InterferenceNoisyThreshold = o.InterferenceNoisyThreshold
+	conf.InterferenceMetricWeights = weights
+	return nil
+}
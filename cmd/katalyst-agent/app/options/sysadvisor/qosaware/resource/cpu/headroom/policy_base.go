@@ -22,15 +22,20 @@ import (
 	"github.com/kubewharf/katalyst-core/pkg/config/agent/sysadvisor/qosaware/resource/cpu/headroom"
 )
 
-type CPUHeadroomPolicyOptions struct{}
+type CPUHeadroomPolicyOptions struct {
+	CPUPolicyHistoricalOptions *CPUPolicyHistoricalOptions
+}
 
 func NewCPUHeadroomPolicyOptions() *CPUHeadroomPolicyOptions {
-	return &CPUHeadroomPolicyOptions{}
+	return &CPUHeadroomPolicyOptions{
+		CPUPolicyHistoricalOptions: NewCPUPolicyHistoricalOptions(),
+	}
 }
 
-func (o *CPUHeadroomPolicyOptions) AddFlags(_ *pflag.FlagSet) {
+func (o *CPUHeadroomPolicyOptions) AddFlags(fs *pflag.FlagSet) {
+	o.CPUPolicyHistoricalOptions.AddFlags(fs)
 }
 
-func (o *CPUHeadroomPolicyOptions) ApplyTo(_ *headroom.CPUHeadroomPolicyConfiguration) error {
-	return nil
+func (o *CPUHeadroomPolicyOptions) ApplyTo(c *headroom.CPUHeadroomPolicyConfiguration) error {
+	return o.CPUPolicyHistoricalOptions.ApplyTo(c.CPUPolicyHistorical)
 }
@@ -0,0 +1,66 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package headroom
+
+import (
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/sysadvisor/qosaware/resource/cpu/headroom"
+)
+
+type CPUPolicyHistoricalOptions struct {
+	Percentile          float64
+	Lookahead           time.Duration
+	MinSamplesPerBucket int
+	MaxSamplesPerBucket int
+	DownsamplePeriod    time.Duration
+}
+
+func NewCPUPolicyHistoricalOptions() *CPUPolicyHistoricalOptions {
+	return &CPUPolicyHistoricalOptions{
+		Percentile:          0.95,
+		Lookahead:           time.Hour,
+		MinSamplesPerBucket: 6,
+		MaxSamplesPerBucket: 48,
+		DownsamplePeriod:    10 * time.Minute,
+	}
+}
+
+func (o *CPUPolicyHistoricalOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.Float64Var(&o.Percentile, "cpu-headroom-historical-percentile", o.Percentile,
+		"the percentile of usage samples in each hour-of-week referred to by historical cpu headroom policy")
+	fs.DurationVar(&o.Lookahead, "cpu-headroom-historical-lookahead", o.Lookahead,
+		"the duration ahead for historical cpu headroom policy to prepare for the known usage peaks")
+	fs.IntVar(&o.MinSamplesPerBucket, "cpu-headroom-historical-min-samples", o.MinSamplesPerBucket,
+		"the min number of usage samples for an hour-of-week to be referred to by historical cpu headroom policy")
+	fs.IntVar(&o.MaxSamplesPerBucket, "cpu-headroom-historical-max-samples", o.MaxSamplesPerBucket,
+		"the max number of latest downsampled usage samples kept for each hour-of-week by historical cpu headroom policy")
+	fs.DurationVar(&o.DownsamplePeriod, "cpu-headroom-historical-downsample-period", o.DownsamplePeriod,
+		"the period in which usage samples are merged into their max by historical cpu headroom policy, "+
+			"so that the profile covers several weeks with a few samples")
+}
+
+func (o *CPUPolicyHistoricalOptions) ApplyTo(c *headroom.CPUPolicyHistoricalConfiguration) error {
+	c.Percentile = o.Percentile
+	c.Lookahead = o.Lookahead
+	c.MinSamplesPerBucket = o.MinSamplesPerBucket
+	c.MaxSamplesPerBucket = o.MaxSamplesPerBucket
+	c.DownsamplePeriod = o.DownsamplePeriod
+	return nil
+}
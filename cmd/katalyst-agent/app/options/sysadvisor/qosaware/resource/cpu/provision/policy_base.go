@@ -26,9 +26,13 @@ import (
 )
 
 type CPURegulatorOptions struct {
-	MaxRampUpStep     int
-	MaxRampDownStep   int
-	MinRampDownPeriod time.Duration
+	MaxRampUpStep        int
+	MaxRampDownStep      int
+	MinRampDownPeriod    time.Duration
+	OscillationWindow    time.Duration
+	OscillationThreshold int
+	DampingDeadband      int
+	DampingDuration      time.Duration
 }
 
 type CPUProvisionPolicyOptions struct {
@@ -38,6 +42,9 @@ type CPUProvisionPolicyOptions struct {
 	// PolicyRamaOptions is the options for policy rama
 	PolicyRama *PolicyRamaOptions
 
+	// PolicyMPCOptions is the options for policy mpc
+	PolicyMPC *PolicyMPCOptions
+
 	// enable to use control knob cpu quota when cgroup2 available
 	EnableControlKnobCPUQuota bool
 }
@@ -45,11 +52,16 @@ type CPUProvisionPolicyOptions struct {
 func NewCPUProvisionPolicyOptions() *CPUProvisionPolicyOptions {
 	return &CPUProvisionPolicyOptions{
 		CPURegulatorOptions: CPURegulatorOptions{
-			MaxRampUpStep:     10,
-			MaxRampDownStep:   2,
-			MinRampDownPeriod: 30 * time.Second,
+			MaxRampUpStep:        10,
+			MaxRampDownStep:      2,
+			MinRampDownPeriod:    30 * time.Second,
+			OscillationWindow:    10 * time.Minute,
+			OscillationThreshold: 0,
+			DampingDeadband:      2,
+			DampingDuration:      5 * time.Minute,
 		},
 		PolicyRama: NewPolicyRamaOptions(),
+		PolicyMPC:  NewPolicyMPCOptions(),
 	}
 }
 
@@ -58,11 +70,16 @@ func (o *CPUProvisionPolicyOptions) ApplyTo(c *provisionconfig.CPUProvisionPolic
 	c.MaxRampUpStep = o.MaxRampUpStep
 	c.MaxRampDownStep = o.MaxRampDownStep
 	c.MinRampDownPeriod = o.MinRampDownPeriod
+	c.OscillationWindow = o.OscillationWindow
+	c.OscillationThreshold = o.OscillationThreshold
+	c.DampingDeadband = o.DampingDeadband
+	c.DampingDuration = o.DampingDuration
 
 	c.EnableControlKnobCPUQuota = o.EnableControlKnobCPUQuota
 
 	var errList []error
 	errList = append(errList, o.PolicyRama.ApplyTo(c.PolicyRama))
+	errList = append(errList, o.PolicyMPC.ApplyTo(c.PolicyMPC))
 
 	return errors.NewAggregate(errList)
 }
@@ -72,6 +89,16 @@ func (o *CPUProvisionPolicyOptions) AddFlags(fs *pflag.FlagSet) {
 	fs.IntVar(&o.MaxRampUpStep, "cpu-regulator-max-ramp-up-step", o.MaxRampUpStep, "max ramp up step for cpu provision policy")
 	fs.IntVar(&o.MaxRampDownStep, "cpu-regulator-max-ramp-down-step", o.MaxRampDownStep, "max ramp down step for cpu provision policy")
 	fs.DurationVar(&o.MinRampDownPeriod, "cpu-regulator-min-ramp-down-period", o.MinRampDownPeriod, "min ramp down period for cpu provision policy")
+	fs.DurationVar(&o.OscillationWindow, "cpu-regulator-oscillation-window", o.OscillationWindow,
+		"time window to detect oscillation of cpu requirement in cpu regulator")
+	fs.IntVar(&o.OscillationThreshold, "cpu-regulator-oscillation-threshold", o.OscillationThreshold,
+		"min direction reversals of cpu requirement in window regarded as oscillation, damping is disabled by default "+
+			"and it's enabled only if this value is positive")
+	fs.IntVar(&o.DampingDeadband, "cpu-regulator-damping-deadband", o.DampingDeadband,
+		"max cpu cores of requirement changes to be ignored during damping")
+	fs.DurationVar(&o.DampingDuration, "cpu-regulator-damping-duration", o.DampingDuration,
+		"time to keep damping after oscillation of cpu requirement is detected")
 	o.PolicyRama.AddFlags(fs)
+	o.PolicyMPC.AddFlags(fs)
 	fs.BoolVar(&o.EnableControlKnobCPUQuota, "cpu-provision-enable-control-knob-cpu-quota", o.EnableControlKnobCPUQuota, "enable control knob cpu quota for cpu provision policy")
 }
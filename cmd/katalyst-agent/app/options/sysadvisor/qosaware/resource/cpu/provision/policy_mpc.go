@@ -0,0 +1,80 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provision
+
+import (
+	"github.com/spf13/pflag"
+
+	provisionconfig "github.com/kubewharf/katalyst-core/pkg/config/agent/sysadvisor/qosaware/resource/cpu/provision"
+)
+
+type PolicyMPCOptions struct {
+	HistorySize         int
+	Horizon             int
+	SmoothingAlpha      float64
+	TrendBeta           float64
+	TargetUtilization   float64
+	ShortageWeight      float64
+	OverProvisionWeight float64
+	MovementWeight      float64
+}
+
+func NewPolicyMPCOptions() *PolicyMPCOptions {
+	return &PolicyMPCOptions{
+		HistorySize:         20,
+		Horizon:             5,
+		SmoothingAlpha:      0.5,
+		TrendBeta:           0.3,
+		TargetUtilization:   1.0,
+		ShortageWeight:      10,
+		OverProvisionWeight: 1,
+		MovementWeight:      0.5,
+	}
+}
+
+// AddFlags adds flags to the specified FlagSet.
+func (o *PolicyMPCOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.IntVar(&o.HistorySize, "mpc-history-size", o.HistorySize,
+		"the number of cpu usage samples used to forecast load in mpc policy")
+	fs.IntVar(&o.Horizon, "mpc-horizon", o.Horizon,
+		"the number of control intervals to optimize over in mpc policy")
+	fs.Float64Var(&o.SmoothingAlpha, "mpc-smoothing-alpha", o.SmoothingAlpha,
+		"the level smoothing factor of load forecasting in mpc policy")
+	fs.Float64Var(&o.TrendBeta, "mpc-trend-beta", o.TrendBeta,
+		"the trend smoothing factor of load forecasting in mpc policy")
+	fs.Float64Var(&o.TargetUtilization, "mpc-target-utilization", o.TargetUtilization,
+		"the expected ratio of cpu usage to cpu requirement in mpc policy")
+	fs.Float64Var(&o.ShortageWeight, "mpc-shortage-weight", o.ShortageWeight,
+		"the weight of cost for lacking cpu in mpc policy")
+	fs.Float64Var(&o.OverProvisionWeight, "mpc-over-provision-weight", o.OverProvisionWeight,
+		"the weight of cost for wasting cpu in mpc policy")
+	fs.Float64Var(&o.MovementWeight, "mpc-movement-weight", o.MovementWeight,
+		"the weight of cost for changing cpu requirement in mpc policy")
+}
+
+// ApplyTo fills up config with options
+func (o *PolicyMPCOptions) ApplyTo(c *provisionconfig.PolicyMPCConfiguration) error {
+	c.HistorySize = o.HistorySize
+	c.Horizon = o.Horizon
+	c.SmoothingAlpha = o.SmoothingAlpha
+	c.TrendBeta = o.TrendBeta
+	c.TargetUtilization = o.TargetUtilization
+	c.ShortageWeight = o.ShortageWeight
+	c.OverProvisionWeight = o.OverProvisionWeight
+	c.MovementWeight = o.MovementWeight
+	return nil
+}
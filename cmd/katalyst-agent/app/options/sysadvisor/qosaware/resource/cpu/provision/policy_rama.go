@@ -19,16 +19,34 @@ package provision
 import (
 	"github.com/spf13/pflag"
 
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
 	provisionconfig "github.com/kubewharf/katalyst-core/pkg/config/agent/sysadvisor/qosaware/resource/cpu/provision"
 )
 
 type PolicyRamaOptions struct {
 	EnableBorwein                   bool
 	EnableBorweinModelResultFetcher bool
+
+	EnablePIDAutoTuning               bool
+	PIDAutoTuningWindow               int
+	PIDAutoTuningOscillationThreshold int
+	PIDAutoTuningSluggishSteps        int
+	PIDAutoTuningCooldownSteps        int
+	PIDAutoTuningGainAdjustRatio      float64
+	PIDAutoTuningMinGainScale         float64
+	PIDAutoTuningMaxGainScale         float64
 }
 
 func NewPolicyRamaOptions() *PolicyRamaOptions {
-	return &PolicyRamaOptions{}
+	return &PolicyRamaOptions{
+		PIDAutoTuningWindow:               10,
+		PIDAutoTuningOscillationThreshold: 4,
+		PIDAutoTuningSluggishSteps:        10,
+		PIDAutoTuningCooldownSteps:        5,
+		PIDAutoTuningGainAdjustRatio:      0.1,
+		PIDAutoTuningMinGainScale:         0.2,
+		PIDAutoTuningMaxGainScale:         5,
+	}
 }
 
 // AddFlags adds flags to the specified FlagSet.
@@ -37,11 +55,37 @@ func (o *PolicyRamaOptions) AddFlags(fs *pflag.FlagSet) {
 		"if set as true, enable borwein model to adjust target indicator offset in rama policy")
 	fs.BoolVar(&o.EnableBorweinModelResultFetcher, "enable-borwein-model-result-fetcher", o.EnableBorweinModelResultFetcher,
 		"if set as true, enable borwein model result fetcher to call borwein-inference-server and get results")
+	fs.BoolVar(&o.EnablePIDAutoTuning, "enable-pid-auto-tuning-in-rama", o.EnablePIDAutoTuning,
+		"if set as true, pid gains in rama policy will be tuned online according to the observed indicator response")
+	fs.IntVar(&o.PIDAutoTuningWindow, "pid-auto-tuning-window", o.PIDAutoTuningWindow,
+		"the number of latest control steps used to detect oscillation in pid auto-tuning")
+	fs.IntVar(&o.PIDAutoTuningOscillationThreshold, "pid-auto-tuning-oscillation-threshold", o.PIDAutoTuningOscillationThreshold,
+		"the number of indicator error sign flips in window regarded as oscillation in pid auto-tuning")
+	fs.IntVar(&o.PIDAutoTuningSluggishSteps, "pid-auto-tuning-sluggish-steps", o.PIDAutoTuningSluggishSteps,
+		"the number of consecutive control steps without indicator error decrease regarded as sluggish in pid auto-tuning")
+	fs.IntVar(&o.PIDAutoTuningCooldownSteps, "pid-auto-tuning-cooldown-steps", o.PIDAutoTuningCooldownSteps,
+		"the number of control steps to wait before pid gains can be tuned again")
+	fs.Float64Var(&o.PIDAutoTuningGainAdjustRatio, "pid-auto-tuning-gain-adjust-ratio", o.PIDAutoTuningGainAdjustRatio,
+		"the relative change of pid gains in each tuning")
+	fs.Float64Var(&o.PIDAutoTuningMinGainScale, "pid-auto-tuning-min-gain-scale", o.PIDAutoTuningMinGainScale,
+		"the minimum scale of tuned pid gains relative to the configured ones")
+	fs.Float64Var(&o.PIDAutoTuningMaxGainScale, "pid-auto-tuning-max-gain-scale", o.PIDAutoTuningMaxGainScale,
+		"the maximum scale of tuned pid gains relative to the configured ones")
 }
 
 // ApplyTo fills up config with options
 func (o *PolicyRamaOptions) ApplyTo(c *provisionconfig.PolicyRamaConfiguration) error {
 	c.EnableBorwein = o.EnableBorwein
 	c.EnableBorweinModelResultFetcher = o.EnableBorweinModelResultFetcher
+	c.EnablePIDAutoTuning = o.EnablePIDAutoTuning
+	c.PIDAutoTuningParams = types.PIDAutoTuningParams{
+		Window:               o.PIDAutoTuningWindow,
+		OscillationThreshold: o.PIDAutoTuningOscillationThreshold,
+		SluggishSteps:        o.PIDAutoTuningSluggishSteps,
+		CooldownSteps:        o.PIDAutoTuningCooldownSteps,
+		GainAdjustRatio:      o.PIDAutoTuningGainAdjustRatio,
+		MinGainScale:         o.PIDAutoTuningMinGainScale,
+		MaxGainScale:         o.PIDAutoTuningMaxGainScale,
+	}
 	return nil
 }
@@ -24,21 +24,25 @@ import (
 )
 
 type MemoryHeadroomPolicyOptions struct {
-	MemoryPolicyCanonicalOptions *MemoryPolicyCanonicalOptions
+	MemoryPolicyCanonicalOptions  *MemoryPolicyCanonicalOptions
+	MemoryPolicyHistoricalOptions *MemoryPolicyHistoricalOptions
 }
 
 func NewMemoryHeadroomPolicyOptions() *MemoryHeadroomPolicyOptions {
 	return &MemoryHeadroomPolicyOptions{
-		MemoryPolicyCanonicalOptions: NewMemoryPolicyCanonicalOptions(),
+		MemoryPolicyCanonicalOptions:  NewMemoryPolicyCanonicalOptions(),
+		MemoryPolicyHistoricalOptions: NewMemoryPolicyHistoricalOptions(),
 	}
 }
 
 func (o *MemoryHeadroomPolicyOptions) AddFlags(fs *pflag.FlagSet) {
 	o.MemoryPolicyCanonicalOptions.AddFlags(fs)
+	o.MemoryPolicyHistoricalOptions.AddFlags(fs)
 }
 
 func (o *MemoryHeadroomPolicyOptions) ApplyTo(c *headroom.MemoryHeadroomPolicyConfiguration) error {
 	var errList []error
 	errList = append(errList, o.MemoryPolicyCanonicalOptions.ApplyTo(c.MemoryPolicyCanonicalConfiguration))
+	errList = append(errList, o.MemoryPolicyHistoricalOptions.ApplyTo(c.MemoryPolicyHistorical))
 	return errors.NewAggregate(errList)
 }
@@ -17,6 +17,8 @@ limitations under the License.
 package options
 
 import (
+	"time"
+
 	cliflag "k8s.io/component-base/cli/flag"
 
 	"github.com/kubewharf/katalyst-core/pkg/config/controller"
@@ -26,6 +28,8 @@ type NPDOptions struct {
 	NPDMetricsPlugins     []string
 	EnableScopeDuplicated bool
 	SyncWorkers           int
+	UsageSyncPeriod       time.Duration
+	UsageWindows          []time.Duration
 }
 
 func NewNPDOptions() *NPDOptions {
@@ -33,6 +37,8 @@ func NewNPDOptions() *NPDOptions {
 		NPDMetricsPlugins:     []string{},
 		EnableScopeDuplicated: false,
 		SyncWorkers:           1,
+		UsageSyncPeriod:       time.Minute,
+		UsageWindows:          []time.Duration{5 * time.Minute, time.Hour, 24 * time.Hour},
 	}
 }
 
@@ -45,12 +51,18 @@ func (o *NPDOptions) AddFlags(fss *cliflag.NamedFlagSets) {
 		"Whether metrics with the same scope can be updated by multiple plugins")
 	fs.IntVar(&o.SyncWorkers, "npd-sync-workers", o.SyncWorkers,
 		"Number of workers to sync npd status")
+	fs.DurationVar(&o.UsageSyncPeriod, "npd-usage-sync-period", o.UsageSyncPeriod,
+		"The interval for usage metrics plugins to fetch node and pod usage from katalyst-metric")
+	fs.DurationSliceVar(&o.UsageWindows, "npd-usage-windows", o.UsageWindows,
+		"The windows over which node and pod usage statistics are aggregated")
 }
 
 func (o *NPDOptions) ApplyTo(c *controller.NPDConfig) error {
 	c.NPDMetricsPlugins = o.NPDMetricsPlugins
 	c.EnableScopeDuplicated = o.EnableScopeDuplicated
 	c.SyncWorkers = o.SyncWorkers
+	c.UsageSyncPeriod = o.UsageSyncPeriod
+	c.UsageWindows = o.UsageWindows
 	return nil
 }
 
@@ -28,6 +28,15 @@ import (
 const (
 	defaultRecSyncWorkers                = 1
 	defaultResourceRecommendReSyncPeriod = 24 * time.Hour
+
+	defaultOOMForecastLookback   = 6 * time.Hour
+	defaultOOMForecastHorizon    = 24 * time.Hour
+	defaultOOMForecastStep       = 5 * time.Minute
+	defaultOOMForecastMinSamples = 12
+
+	defaultDecayHalfLife               = 24 * time.Hour
+	defaultDecayingPercentile          = 0.9
+	defaultHistogramCheckpointInterval = 10 * time.Minute
 )
 
 type ResourceRecommenderOptions struct {
@@ -36,10 +45,16 @@ type ResourceRecommenderOptions struct {
 	HealthProbeBindPort string `desc:"The port the health probe binds to."`
 	MetricsBindPort     string `desc:"The port the metric endpoint binds to."`
 
-	// available datasource: prom
+	// available datasource: prom, katalyst-metric, offline
 	DataSource []string
 	// DataSourcePromConfig is the prometheus datasource config
 	DataSourcePromConfig prometheus.PromConfig
+	// DefaultDataSource is the datasource used for recommendation, and it must be one of DataSource
+	DefaultDataSource string
+	// DataSourceOfflinePath is the snapshot file or directory for offline datasource
+	DataSourceOfflinePath string
+	// DataSourceOfflineReplayAnchor is the time in snapshots to be replayed as the controller start time
+	DataSourceOfflineReplayAnchor string
 
 	// LogVerbosityLevel to specify log verbosity level. (The default level is 4)
 	// Set it to something larger than 4 if more detailed logs are needed.
@@ -47,6 +62,16 @@ type ResourceRecommenderOptions struct {
 
 	RecSyncWorkers int
 	RecSyncPeriod  time.Duration
+
+	OOMForecastEnabled    bool
+	OOMForecastLookback   time.Duration
+	OOMForecastHorizon    time.Duration
+	OOMForecastStep       time.Duration
+	OOMForecastMinSamples int
+
+	DecayHalfLife               time.Duration
+	DecayingPercentile          float64
+	HistogramCheckpointInterval time.Duration
 }
 
 // NewResourceRecommenderOptions creates a new Options with a default config.
@@ -62,7 +87,15 @@ func NewResourceRecommenderOptions() *ResourceRecommenderOptions {
 			BRateLimit:                  false,
 			MaxPointsLimitPerTimeSeries: 11000,
 		},
-		LogVerbosityLevel: "4",
+		LogVerbosityLevel:     "4",
+		OOMForecastLookback:   defaultOOMForecastLookback,
+		OOMForecastHorizon:    defaultOOMForecastHorizon,
+		OOMForecastStep:       defaultOOMForecastStep,
+		OOMForecastMinSamples: defaultOOMForecastMinSamples,
+
+		DecayHalfLife:               defaultDecayHalfLife,
+		DecayingPercentile:          defaultDecayingPercentile,
+		HistogramCheckpointInterval: defaultHistogramCheckpointInterval,
 	}
 }
 
@@ -74,7 +107,9 @@ func (o *ResourceRecommenderOptions) AddFlags(fss *cliflag.NamedFlagSets) {
 	fs.StringVar(&o.HealthProbeBindPort, "resourcerecommend-health-probe-bind-port", "8080", "The port the health probe binds to.")
 	fs.StringVar(&o.MetricsBindPort, "resourcerecommend-metrics-bind-port", "8081", "The port the metric endpoint binds to.")
 
-	fs.StringSliceVar(&o.DataSource, "resourcerecommend-datasource", []string{"prom"}, "available datasource: prom")
+	fs.StringSliceVar(&o.DataSource, "resourcerecommend-datasource", []string{"prom"}, "available datasource: prom, katalyst-metric, offline")
+	fs.StringVar(&o.DefaultDataSource, "resourcerecommend-default-datasource", "prom",
+		"the datasource used for recommendation, and it must be one of resourcerecommend-datasource")
 	fs.StringVar(&o.DataSourcePromConfig.Address, "resourcerecommend-prometheus-address", "", "prometheus address")
 	fs.StringVar(&o.DataSourcePromConfig.Auth.Type, "resourcerecommend-prometheus-auth-type", "", "prometheus auth type")
 	fs.StringVar(&o.DataSourcePromConfig.Auth.Username, "resourcerecommend-prometheus-auth-username", "", "prometheus auth username")
@@ -84,11 +119,34 @@ func (o *ResourceRecommenderOptions) AddFlags(fss *cliflag.NamedFlagSets) {
 	fs.DurationVar(&o.DataSourcePromConfig.Timeout, "resourcerecommend-prometheus-timeout", 3*time.Minute, "prometheus timeout")
 	fs.BoolVar(&o.DataSourcePromConfig.BRateLimit, "resourcerecommend-prometheus-bratelimit", false, "prometheus bratelimit")
 	fs.IntVar(&o.DataSourcePromConfig.MaxPointsLimitPerTimeSeries, "resourcerecommend-prometheus-maxpoints", 11000, "prometheus max points limit per time series")
+	fs.StringVar(&o.DataSourceOfflinePath, "resourcerecommend-offline-snapshot-path", "",
+		"snapshot file or directory of csv or OpenMetrics text format for offline datasource")
+	fs.StringVar(&o.DataSourceOfflineReplayAnchor, "resourcerecommend-offline-replay-anchor", "",
+		"the time in snapshots replayed as the controller start time, either in RFC3339 format or 'latest' for the latest sample; "+
+			"snapshots are replayed at their original time if empty")
 	fs.StringVar(&o.DataSourcePromConfig.BaseFilter, "resourcerecommend-prometheus-promql-base-filter", "", ""+
 		"Get basic filters in promql for historical usage data. This filter is added to all promql statements. "+
 		"Supports filters format of promql, e.g: group=\\\"Katalyst\\\",cluster=\\\"cfeaf782fasdfe\\\"")
 	fs.IntVar(&o.RecSyncWorkers, "res-sync-workers", defaultRecSyncWorkers, "num of goroutine to sync recs")
 	fs.DurationVar(&o.RecSyncPeriod, "resource-recommend-resync-period", defaultResourceRecommendReSyncPeriod, "period for recommend controller to sync resource recommend")
+
+	fs.BoolVar(&o.OOMForecastEnabled, "resourcerecommend-oom-forecast-enabled", false,
+		"whether to raise memory recommendation for containers predicted to oom by oom history and memory growth trends")
+	fs.DurationVar(&o.OOMForecastLookback, "resourcerecommend-oom-forecast-lookback", defaultOOMForecastLookback,
+		"length of memory usage history used to fit the memory growth trend")
+	fs.DurationVar(&o.OOMForecastHorizon, "resourcerecommend-oom-forecast-horizon", defaultOOMForecastHorizon,
+		"how far into the future the memory growth trend is extrapolated to predict oom")
+	fs.DurationVar(&o.OOMForecastStep, "resourcerecommend-oom-forecast-step", defaultOOMForecastStep,
+		"resolution of memory usage samples used to fit the memory growth trend")
+	fs.IntVar(&o.OOMForecastMinSamples, "resourcerecommend-oom-forecast-min-samples", defaultOOMForecastMinSamples,
+		"minimal number of memory usage samples required to predict oom")
+
+	fs.DurationVar(&o.DecayHalfLife, "resourcerecommend-decay-half-life", defaultDecayHalfLife,
+		"default half-life of sample weights for decaying algorithm, it can be overridden by decayHalfLife (in hours) in algorithm extensions")
+	fs.Float64Var(&o.DecayingPercentile, "resourcerecommend-decaying-percentile", defaultDecayingPercentile,
+		"percentile of decaying histograms used as recommendation for decaying algorithm")
+	fs.DurationVar(&o.HistogramCheckpointInterval, "resourcerecommend-histogram-checkpoint-interval", defaultHistogramCheckpointInterval,
+		"interval to checkpoint decaying histograms into configmaps")
 }
 
 func (o *ResourceRecommenderOptions) ApplyTo(c *controller.ResourceRecommenderConfig) error {
@@ -97,9 +155,20 @@ func (o *ResourceRecommenderOptions) ApplyTo(c *controller.ResourceRecommenderCo
 	c.MetricsBindPort = o.MetricsBindPort
 	c.DataSource = o.DataSource
 	c.DataSourcePromConfig = o.DataSourcePromConfig
+	c.DefaultDataSource = o.DefaultDataSource
+	c.DataSourceOfflinePath = o.DataSourceOfflinePath
+	c.DataSourceOfflineReplayAnchor = o.DataSourceOfflineReplayAnchor
 	c.LogVerbosityLevel = o.LogVerbosityLevel
 	c.RecSyncWorkers = o.RecSyncWorkers
 	c.RecSyncPeriod = o.RecSyncPeriod
+	c.OOMForecastEnabled = o.OOMForecastEnabled
+	c.OOMForecastLookback = o.OOMForecastLookback
+	c.OOMForecastHorizon = o.OOMForecastHorizon
+	c.OOMForecastStep = o.OOMForecastStep
+	c.OOMForecastMinSamples = o.OOMForecastMinSamples
+	c.DecayHalfLife = o.DecayHalfLife
+	c.DecayingPercentile = o.DecayingPercentile
+	c.HistogramCheckpointInterval = o.HistogramCheckpointInterval
 	return nil
 }
 
@@ -38,6 +38,8 @@ type VPARecommendationOptions struct{}
 type ResourceRecommendOptions struct {
 	// time interval of resync VPA
 	VPAResyncPeriod time.Duration
+	// maps extended resources to names of their usage metrics in custom metrics API
+	ExtendedResourceMetrics map[string]string
 }
 
 // VPAOptions holds the configurations for vertical pod auto-scaler.
@@ -71,6 +73,9 @@ func (o *VPAOptions) AddFlags(fss *cliflag.NamedFlagSets) {
 	fs.IntVar(&o.VPARecSyncWorkers, "vparec-sync-workers", defaultVpaRecSyncWorkers, "num of goroutines to sync vparecs")
 	fs.DurationVar(&o.ResourceRecommendOptions.VPAResyncPeriod, "resource-recommend-resync-vpa-period",
 		defaultResourceRecommendResyncVPAPeriod, "Period for recommend controller to sync vpa")
+	fs.StringToStringVar(&o.ResourceRecommendOptions.ExtendedResourceMetrics, "resource-recommend-extended-resource-metrics",
+		o.ResourceRecommendOptions.ExtendedResourceMetrics, "A set of extended resources and names of their usage metrics "+
+			"in custom metrics API, e.g. nvidia.com/gpu=container_gpu_usage; extended resources are recommended only if it is set")
 }
 
 // ApplyTo fills up config with options
@@ -80,6 +85,7 @@ func (o *VPAOptions) ApplyTo(c *controller.VPAConfig) error {
 	c.VPASyncWorkers = o.VPASyncWorkers
 	c.VPARecSyncWorkers = o.VPARecSyncWorkers
 	c.ResourceRecommendConfig.VPAReSyncPeriod = o.ResourceRecommendOptions.VPAResyncPeriod
+	c.ResourceRecommendConfig.ExtendedResourceMetrics = o.ResourceRecommendOptions.ExtendedResourceMetrics
 	return nil
 }
 
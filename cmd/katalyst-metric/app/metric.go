@@ -151,6 +151,8 @@ func initStore(ctx context.Context, baseCtx *katalystbase.GenericContext, conf *
 	switch conf.CustomMetricConfiguration.StoreConfiguration.StoreName {
 	case local.MetricStoreNameLocalMemory:
 		return local.NewLocalMemoryMetricStore(ctx, baseCtx, conf.GenericMetricConfiguration, conf.StoreConfiguration)
+	case local.MetricStoreNameMultiTier:
+		return local.NewMultiTierMetricStore(ctx, baseCtx, conf.GenericMetricConfiguration, conf.StoreConfiguration)
 	case mock.MetricStoreNameMockLocalMemory:
 		// beware of using this store implementation. It's for pressure test only, never use it in any product environment
 		// just replace the MetaInformer filled by mock data
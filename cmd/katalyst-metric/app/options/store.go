@@ -37,6 +37,13 @@ type StoreOptions struct {
 	StoreServerShardCount   int
 	StoreServerReplicaTotal int
 
+	MultiTierDataDir              string
+	MultiTierSegmentDuration      time.Duration
+	MultiTierDownsampleAfter      time.Duration
+	MultiTierDownsampleResolution time.Duration
+	MultiTierRetention            time.Duration
+	MultiTierMaxQueryRange        time.Duration
+
 	ServiceDiscoveryName string
 	SDPodSelector        string
 	SDServiceNamespace   string
@@ -54,6 +61,13 @@ func NewStoreOptions() *StoreOptions {
 		StoreServerShardCount:   1,
 		StoreServerReplicaTotal: 3,
 
+		MultiTierDataDir:              "/var/lib/katalyst-metric/store",
+		MultiTierSegmentDuration:      time.Hour,
+		MultiTierDownsampleAfter:      6 * time.Hour,
+		MultiTierDownsampleResolution: 5 * time.Minute,
+		MultiTierRetention:            7 * 24 * time.Hour,
+		MultiTierMaxQueryRange:        24 * time.Hour,
+
 		SDPodSelector: "katalyst-custom-metric=store-server",
 	}
 }
@@ -76,6 +90,19 @@ func (o *StoreOptions) AddFlags(fss *cliflag.NamedFlagSets) {
 	fs.IntVar(&o.StoreServerReplicaTotal, "store-server-replica-total", o.StoreServerReplicaTotal,
 		"the amount of duplicated replicas this store will use, only valid in store-server mode")
 
+	fs.StringVar(&o.MultiTierDataDir, "store-multi-tier-data-dir", o.MultiTierDataDir,
+		"the directory to persist metrics for multi-tier store")
+	fs.DurationVar(&o.MultiTierSegmentDuration, "store-multi-tier-segment-duration", o.MultiTierSegmentDuration,
+		"the max time range each append-only segment covers for multi-tier store")
+	fs.DurationVar(&o.MultiTierDownsampleAfter, "store-multi-tier-downsample-after", o.MultiTierDownsampleAfter,
+		"segments older than this will be downsampled for multi-tier store")
+	fs.DurationVar(&o.MultiTierDownsampleResolution, "store-multi-tier-downsample-resolution", o.MultiTierDownsampleResolution,
+		"the resolution that raw metrics will be downsampled into for multi-tier store")
+	fs.DurationVar(&o.MultiTierRetention, "store-multi-tier-retention", o.MultiTierRetention,
+		"the max time range that on-disk metrics will be kept for multi-tier store")
+	fs.DurationVar(&o.MultiTierMaxQueryRange, "store-multi-tier-max-query-range", o.MultiTierMaxQueryRange,
+		"the max time range of each query that reads on-disk metrics for multi-tier store")
+
 	fs.StringVar(&o.ServiceDiscoveryName, "store-server-sd-name", o.ServiceDiscoveryName,
 		"defines which service-discovery manager will be used")
 	fs.StringVar(&o.SDServiceNamespace, "store-server-service-ns", o.SDServiceNamespace,
@@ -96,6 +123,13 @@ func (o *StoreOptions) ApplyTo(c *metric.StoreConfiguration) error {
 	c.StoreServerShardCount = o.StoreServerShardCount
 	c.StoreServerReplicaTotal = o.StoreServerReplicaTotal
 
+	c.MultiTierDataDir = o.MultiTierDataDir
+	c.MultiTierSegmentDuration = o.MultiTierSegmentDuration
+	c.MultiTierDownsampleAfter = o.MultiTierDownsampleAfter
+	c.MultiTierDownsampleResolution = o.MultiTierDownsampleResolution
+	c.MultiTierRetention = o.MultiTierRetention
+	c.MultiTierMaxQueryRange = o.MultiTierMaxQueryRange
+
 	c.ServiceDiscoveryConf.Name = o.ServiceDiscoveryName
 
 	c.ServiceDiscoveryConf.PodSinglePortSDConf.PortName = native.ContainerMetricStorePortName
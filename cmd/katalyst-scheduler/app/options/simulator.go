@@ -0,0 +1,92 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/pflag"
+	kubeschedulerconfig "k8s.io/kubernetes/pkg/scheduler/apis/config"
+	"k8s.io/kubernetes/pkg/scheduler/apis/config/latest"
+	kubeschedulerscheme "k8s.io/kubernetes/pkg/scheduler/apis/config/scheme"
+
+	"github.com/kubewharf/katalyst-core/cmd/base/options"
+	"github.com/kubewharf/katalyst-core/pkg/config/generic"
+	"github.com/kubewharf/katalyst-core/pkg/scheduler/simulator"
+)
+
+// SimulatorOptions has all the params needed to run a scheduling simulation
+type SimulatorOptions struct {
+	ConfigFile    string
+	SnapshotPaths []string
+	Output        string
+
+	*options.QoSOptions
+}
+
+// NewSimulatorOptions returns default simulator options.
+func NewSimulatorOptions() *SimulatorOptions {
+	return &SimulatorOptions{
+		Output:     simulator.OutputFormatTable,
+		QoSOptions: options.NewQoSOptions(),
+	}
+}
+
+// AddFlags adds flags to the specified FlagSet.
+func (o *SimulatorOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.ConfigFile, "config", o.ConfigFile,
+		"the path to the scheduler configuration file, and the default configuration is used if not set")
+	fs.StringSliceVar(&o.SnapshotPaths, "snapshot", o.SnapshotPaths,
+		"files or directories containing Nodes, CNRs and Pods, and pods without node name are scheduled in the simulation")
+	fs.StringVarP(&o.Output, "output", "o", o.Output,
+		fmt.Sprintf("output format, one of %v and %v", simulator.OutputFormatTable, simulator.OutputFormatJSON))
+	o.QoSOptions.AddFlags(fs)
+}
+
+// Config returns the scheduler configuration and qos configuration for the simulation.
+func (o *SimulatorOptions) Config() (*kubeschedulerconfig.KubeSchedulerConfiguration, *generic.QoSConfiguration, error) {
+	if len(o.SnapshotPaths) == 0 {
+		return nil, nil, fmt.Errorf("at least one snapshot is required")
+	}
+
+	qosConfig := generic.NewQoSConfiguration()
+	if err := o.QoSOptions.ApplyTo(qosConfig); err != nil {
+		return nil, nil, err
+	}
+
+	if o.ConfigFile == "" {
+		cfg, err := latest.Default()
+		return cfg, qosConfig, err
+	}
+
+	data, err := os.ReadFile(o.ConfigFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// the UniversalDecoder runs defaulting and returns the internal type by default.
+	obj, gvk, err := kubeschedulerscheme.Codecs.UniversalDecoder().Decode(data, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	cfg, ok := obj.(*kubeschedulerconfig.KubeSchedulerConfiguration)
+	if !ok {
+		return nil, nil, fmt.Errorf("couldn't decode as KubeSchedulerConfiguration, got %s", gvk)
+	}
+	return cfg, qosConfig, nil
+}
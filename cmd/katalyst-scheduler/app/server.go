@@ -114,6 +114,7 @@ kube-scheduler is the reference implementation.
 		klog.ErrorS(err, "Failed to mark flag filename")
 	}
 
+	cmd.AddCommand(NewSimulatorCommand(registryOptions...))
 	return cmd
 }
 
@@ -0,0 +1,92 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"io"
+
+	"github.com/spf13/cobra"
+	frameworkplugins "k8s.io/kubernetes/pkg/scheduler/framework/plugins"
+	"k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+
+	"github.com/kubewharf/katalyst-core/cmd/katalyst-scheduler/app/options"
+	"github.com/kubewharf/katalyst-core/pkg/scheduler/simulator"
+	"github.com/kubewharf/katalyst-core/pkg/scheduler/util"
+)
+
+// NewSimulatorCommand creates a *cobra.Command that runs scheduler plugins offline
+// against snapshots of the cluster, so that capacity planning and plugin tuning
+// don't require a live cluster.
+func NewSimulatorCommand(registryOptions ...Option) *cobra.Command {
+	opts := options.NewSimulatorOptions()
+
+	cmd := &cobra.Command{
+		Use:   "simulate",
+		Short: "Simulate scheduling of pending pods against snapshots of the cluster",
+		Long: `Simulate loads Nodes, CNRs and Pods from snapshot files, schedules pods without
+node name one by one with the profiles in the scheduler configuration, and prints
+placement decisions, NUMA assignments and scores of each plugin. Scheduled pods
+are assumed to be running, so later pods see the resources they consume.
+`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSimulation(cmd.OutOrStdout(), opts, registryOptions...)
+		},
+	}
+	opts.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+func runSimulation(out io.Writer, opts *options.SimulatorOptions, registryOptions ...Option) error {
+	cfg, qosConfig, err := opts.Config()
+	if err != nil {
+		return err
+	}
+	util.SetQoSConfig(qosConfig)
+
+	registry := frameworkplugins.NewInTreeRegistry()
+	outOfTreeRegistry := make(runtime.Registry)
+	for _, option := range registryOptions {
+		if err := option(outOfTreeRegistry); err != nil {
+			return err
+		}
+	}
+	if err := registry.Merge(outOfTreeRegistry); err != nil {
+		return err
+	}
+
+	snapshot, err := simulator.LoadSnapshot(opts.SnapshotPaths...)
+	if err != nil {
+		return err
+	}
+
+	sim, err := simulator.NewSimulator(cfg, registry, snapshot)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	results, err := sim.Run(ctx)
+	if err != nil {
+		return err
+	}
+	return simulator.PrintResults(out, results, opts.Output)
+}
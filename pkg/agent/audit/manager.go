@@ -38,6 +38,9 @@ func GetSinkInitializers() map[string]sink.Initializer {
 
 func init() {
 	RegisterSink(sink.SinkNameLogBased, sink.NewLogBasedAuditSink)
+	RegisterSink(sink.SinkNameFileBased, sink.NewFileBasedAuditSink)
+	RegisterSink(sink.SinkNameWebhook, sink.NewWebhookAuditSink)
+	RegisterSink(sink.SinkNameHistory, sink.NewHistoryAuditSink)
 }
 
 type AuditManager struct {
@@ -60,6 +63,12 @@ func NewAuditManager(conf *global.AuditConfiguration, eventbus eventbus.EventBus
 	return &AuditManager{sinks: sinks, eventBus: eventbus}
 }
 
+// GetSink returns the initialized sink by name
+func (m *AuditManager) GetSink(name string) (sink.Interface, bool) {
+	s, ok := m.sinks[name]
+	return s, ok
+}
+
 func (m *AuditManager) Run(ctx context.Context) {
 	for n, i := range m.sinks {
 		general.Infof("start sink: %v", n)
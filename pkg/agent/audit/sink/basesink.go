@@ -49,5 +49,15 @@ func (b *BaseAuditSink) Run(ctx context.Context, bus eventbus.EventBus) {
 	if err != nil {
 		general.Errorf("subscribe %v failed", consts.TopicNameSyscall)
 	}
+
+	err = bus.Subscribe(consts.TopicNameEviction, b.GetName(), b.GetBufferSize(), b.GetHandler())
+	if err != nil {
+		general.Errorf("subscribe %v failed", consts.TopicNameEviction)
+	}
+
+	err = bus.Subscribe(consts.TopicNameAllocation, b.GetName(), b.GetBufferSize(), b.GetHandler())
+	if err != nil {
+		general.Errorf("subscribe %v failed", consts.TopicNameAllocation)
+	}
 	<-ctx.Done()
 }
@@ -100,6 +100,7 @@ func TestBaseAuditSink_Run(t *testing.T) {
 			mockBus.On("Subscribe", consts.TopicNameApplyProcFS, mockSink.GetName(), mockSink.GetBufferSize(), mock.AnythingOfType("eventbus.ConsumeFunc")).Return(nil)
 			mockBus.On("Subscribe", consts.TopicNameApplySysFS, mockSink.GetName(), mockSink.GetBufferSize(), mock.AnythingOfType("eventbus.ConsumeFunc")).Return(nil)
 			mockBus.On("Subscribe", consts.TopicNameSyscall, mockSink.GetName(), mockSink.GetBufferSize(), mock.AnythingOfType("eventbus.ConsumeFunc")).Return(nil)
+			mockBus.On("Subscribe", consts.TopicNameEviction, mockSink.GetName(), mockSink.GetBufferSize(), mock.AnythingOfType("eventbus.ConsumeFunc")).Return(nil)
 
 			// Create a context that can be canceled
 			ctx, cancel := context.WithCancel(context.Background())
@@ -127,6 +128,7 @@ func TestBaseAuditSink_Run(t *testing.T) {
 			mockBus.On("Subscribe", consts.TopicNameApplyProcFS, mockSink.GetName(), mockSink.GetBufferSize(), mock.AnythingOfType("eventbus.ConsumeFunc")).Return(errors.New("subscribe failed"))
 			mockBus.On("Subscribe", consts.TopicNameApplySysFS, mockSink.GetName(), mockSink.GetBufferSize(), mock.AnythingOfType("eventbus.ConsumeFunc")).Return(nil)
 			mockBus.On("Subscribe", consts.TopicNameSyscall, mockSink.GetName(), mockSink.GetBufferSize(), mock.AnythingOfType("eventbus.ConsumeFunc")).Return(errors.New("subscribe failed"))
+			mockBus.On("Subscribe", consts.TopicNameEviction, mockSink.GetName(), mockSink.GetBufferSize(), mock.AnythingOfType("eventbus.ConsumeFunc")).Return(nil)
 
 			// Create a context that can be canceled
 			ctx, cancel := context.WithCancel(context.Background())
@@ -158,6 +160,7 @@ func TestBaseAuditSink_Run(t *testing.T) {
 			mockBus.On("Subscribe", consts.TopicNameApplyProcFS, mockSink.GetName(), mockSink.GetBufferSize(), mock.AnythingOfType("eventbus.ConsumeFunc")).Return(context.Canceled)
 			mockBus.On("Subscribe", consts.TopicNameApplySysFS, mockSink.GetName(), mockSink.GetBufferSize(), mock.AnythingOfType("eventbus.ConsumeFunc")).Return(context.Canceled)
 			mockBus.On("Subscribe", consts.TopicNameSyscall, mockSink.GetName(), mockSink.GetBufferSize(), mock.AnythingOfType("eventbus.ConsumeFunc")).Return(context.Canceled)
+			mockBus.On("Subscribe", consts.TopicNameEviction, mockSink.GetName(), mockSink.GetBufferSize(), mock.AnythingOfType("eventbus.ConsumeFunc")).Return(context.Canceled)
 
 			// Run the base sink
 			baseSink.Run(ctx, mockBus)
@@ -167,6 +170,7 @@ func TestBaseAuditSink_Run(t *testing.T) {
 			mockBus.AssertCalled(t, "Subscribe", consts.TopicNameApplyProcFS, mockSink.GetName(), mockSink.GetBufferSize(), mock.AnythingOfType("eventbus.ConsumeFunc"))
 			mockBus.AssertCalled(t, "Subscribe", consts.TopicNameApplySysFS, mockSink.GetName(), mockSink.GetBufferSize(), mock.AnythingOfType("eventbus.ConsumeFunc"))
 			mockBus.AssertCalled(t, "Subscribe", consts.TopicNameSyscall, mockSink.GetName(), mockSink.GetBufferSize(), mock.AnythingOfType("eventbus.ConsumeFunc"))
+			mockBus.AssertCalled(t, "Subscribe", consts.TopicNameEviction, mockSink.GetName(), mockSink.GetBufferSize(), mock.AnythingOfType("eventbus.ConsumeFunc"))
 		})
 	})
 }
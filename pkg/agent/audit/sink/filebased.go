@@ -0,0 +1,101 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/global"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/eventbus"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+)
+
+const (
+	SinkNameFileBased = "file"
+)
+
+// FileBasedAuditSink writes audit events as JSON lines into a local file,
+// and the file is rotated according to its size.
+type FileBasedAuditSink struct {
+	BaseAuditSink
+	bufferSize int
+
+	mutex  sync.Mutex
+	writer io.WriteCloser
+}
+
+func NewFileBasedAuditSink(c *global.AuditConfiguration, _ metrics.MetricEmitter) Interface {
+	sink := &FileBasedAuditSink{
+		bufferSize: c.BufferSize,
+		writer: &lumberjack.Logger{
+			Filename:   c.FileSinkPath,
+			MaxSize:    c.FileSinkMaxSizeMB,
+			MaxBackups: c.FileSinkMaxBackups,
+			MaxAge:     c.FileSinkMaxAgeDays,
+		},
+	}
+	sink.Interface = sink
+	return sink
+}
+
+func (f *FileBasedAuditSink) Run(ctx context.Context, bus eventbus.EventBus) {
+	f.BaseAuditSink.Run(ctx, bus)
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if err := f.writer.Close(); err != nil {
+		general.Errorf("close audit file failed: %v", err)
+	}
+}
+
+func (f *FileBasedAuditSink) GetHandler() eventbus.ConsumeFunc {
+	return func(event interface{}) error {
+		if event == nil {
+			general.Warningf("ignore nil event")
+			return nil
+		}
+
+		record, err := NewAuditRecord(event)
+		if err != nil {
+			return err
+		}
+
+		data, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+
+		f.mutex.Lock()
+		defer f.mutex.Unlock()
+		_, err = f.writer.Write(append(data, '\n'))
+		return err
+	}
+}
+
+func (f *FileBasedAuditSink) GetName() string {
+	return SinkNameFileBased
+}
+
+func (f *FileBasedAuditSink) GetBufferSize() int {
+	return f.bufferSize
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/global"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/eventbus"
+)
+
+func TestFileBasedAuditSink(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "audit.log")
+	s := NewFileBasedAuditSink(&global.AuditConfiguration{
+		BufferSize:        10,
+		FileSinkPath:      path,
+		FileSinkMaxSizeMB: 1,
+	}, metrics.DummyMetrics{})
+	assert.Equal(t, SinkNameFileBased, s.GetName())
+	assert.Equal(t, 10, s.GetBufferSize())
+
+	handler := s.GetHandler()
+	now := time.Now()
+	require.NoError(t, handler(eventbus.RawCGroupEvent{BaseEventImpl: eventbus.BaseEventImpl{Time: now}, CGroupFile: "cpu.max"}))
+	require.NoError(t, handler(eventbus.EvictionEvent{BaseEventImpl: eventbus.BaseEventImpl{Time: now}, PodName: "pod-1"}))
+	require.NoError(t, handler(nil))
+	require.Error(t, handler("unknown"))
+	require.NoError(t, s.(*FileBasedAuditSink).writer.Close())
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var types []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		record := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		types = append(types, record["type"].(string))
+	}
+	assert.Equal(t, []string{AuditRecordTypeCGroup, AuditRecordTypeEviction}, types)
+}
@@ -0,0 +1,281 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/global"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/eventbus"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+)
+
+const (
+	SinkNameHistory = "history"
+
+	// HistoryHTTPPath is the path on agent generic endpoint to query audit history
+	HistoryHTTPPath = "/audit/history"
+)
+
+// HistoryFilter is used to filter audit records in history, and empty fields match all.
+type HistoryFilter struct {
+	PodUID       string
+	PodNamespace string
+	Type         string
+	Since        time.Time
+	Until        time.Time
+	// Limit keeps the latest records only if it's positive
+	Limit int
+}
+
+func (f *HistoryFilter) match(r *AuditRecord) bool {
+	if f.PodUID != "" && r.PodUID != f.PodUID {
+		return false
+	} else if f.PodNamespace != "" && r.PodNamespace != f.PodNamespace {
+		return false
+	} else if f.Type != "" && r.Type != f.Type {
+		return false
+	} else if !f.Since.IsZero() && r.Time.Before(f.Since) {
+		return false
+	} else if !f.Until.IsZero() && r.Time.After(f.Until) {
+		return false
+	}
+	return true
+}
+
+// recordRing is a fixed-size ring buffer that keeps the latest audit records.
+type recordRing struct {
+	records []*AuditRecord
+	next    int
+}
+
+func newRecordRing(capacity int) *recordRing {
+	return &recordRing{records: make([]*AuditRecord, 0, capacity)}
+}
+
+// add appends the record, and the oldest one is overwritten if the ring is full.
+func (r *recordRing) add(record *AuditRecord) {
+	if len(r.records) < cap(r.records) {
+		r.records = append(r.records, record)
+	} else {
+		r.records[r.next] = record
+		r.next = (r.next + 1) % len(r.records)
+	}
+}
+
+// list returns records from the oldest to the latest
+func (r *recordRing) list() []*AuditRecord {
+	records := make([]*AuditRecord, 0, len(r.records))
+	records = append(records, r.records[r.next:]...)
+	return append(records, r.records[:r.next]...)
+}
+
+// podHistory keeps the latest records of a pod in one ring per event type.
+type podHistory struct {
+	typeRecords map[string]*recordRing
+	lastUpdated time.Time
+}
+
+// HistoryAuditSink keeps the latest audit records per pod and per event type in
+// memory, and records not belonging to any pod are kept per event type for the
+// node. Memory is bounded by the ring capacity and the max number of pods. It
+// serves queries over HTTP for debugging purposes.
+type HistoryAuditSink struct {
+	BaseAuditSink
+	bufferSize int
+	capacity   int
+	maxPods    int
+
+	mutex sync.RWMutex
+	// nodeRecords keeps records without pod uid by event type
+	nodeRecords map[string]*recordRing
+	podRecords  map[string]*podHistory
+}
+
+func NewHistoryAuditSink(c *global.AuditConfiguration, _ metrics.MetricEmitter) Interface {
+	capacity := c.HistorySinkCapacity
+	if capacity <= 0 {
+		capacity = 1
+	}
+
+	sink := &HistoryAuditSink{
+		bufferSize:  c.BufferSize,
+		capacity:    capacity,
+		maxPods:     c.HistorySinkMaxPods,
+		nodeRecords: make(map[string]*recordRing),
+		podRecords:  make(map[string]*podHistory),
+	}
+	sink.Interface = sink
+	return sink
+}
+
+func (h *HistoryAuditSink) GetHandler() eventbus.ConsumeFunc {
+	return func(event interface{}) error {
+		if event == nil {
+			general.Warningf("ignore nil event")
+			return nil
+		}
+
+		record, err := NewAuditRecord(event)
+		if err != nil {
+			return err
+		}
+		if record.Time.IsZero() {
+			record.Time = time.Now()
+		}
+
+		h.mutex.Lock()
+		defer h.mutex.Unlock()
+
+		typeRecords := h.nodeRecords
+		if record.PodUID != "" {
+			history, ok := h.podRecords[record.PodUID]
+			if !ok {
+				h.evictStalePodLocked()
+				history = &podHistory{typeRecords: make(map[string]*recordRing)}
+				h.podRecords[record.PodUID] = history
+			}
+			if record.Time.After(history.lastUpdated) {
+				history.lastUpdated = record.Time
+			}
+			typeRecords = history.typeRecords
+		}
+
+		if _, ok := typeRecords[record.Type]; !ok {
+			typeRecords[record.Type] = newRecordRing(h.capacity)
+		}
+		typeRecords[record.Type].add(record)
+		return nil
+	}
+}
+
+func (h *HistoryAuditSink) GetName() string {
+	return SinkNameHistory
+}
+
+func (h *HistoryAuditSink) GetBufferSize() int {
+	return h.bufferSize
+}
+
+// evictStalePodLocked makes room for a new pod by dropping the records of
+// the pod that has been updated least recently.
+func (h *HistoryAuditSink) evictStalePodLocked() {
+	if h.maxPods <= 0 || len(h.podRecords) < h.maxPods {
+		return
+	}
+
+	var stalePodUID string
+	var staleTime time.Time
+	for podUID, history := range h.podRecords {
+		if stalePodUID == "" || history.lastUpdated.Before(staleTime) {
+			stalePodUID, staleTime = podUID, history.lastUpdated
+		}
+	}
+	delete(h.podRecords, stalePodUID)
+}
+
+// Query returns records matching the given filter ordered by time.
+func (h *HistoryAuditSink) Query(filter HistoryFilter) []*AuditRecord {
+	h.mutex.RLock()
+	var candidates []*AuditRecord
+	appendRings := func(typeRecords map[string]*recordRing) {
+		for _, ring := range typeRecords {
+			candidates = append(candidates, ring.list()...)
+		}
+	}
+	if filter.PodUID != "" {
+		if history, ok := h.podRecords[filter.PodUID]; ok {
+			appendRings(history.typeRecords)
+		}
+	} else {
+		appendRings(h.nodeRecords)
+		for _, history := range h.podRecords {
+			appendRings(history.typeRecords)
+		}
+	}
+
+	var records []*AuditRecord
+	for _, record := range candidates {
+		if filter.match(record) {
+			records = append(records, record)
+		}
+	}
+	h.mutex.RUnlock()
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Time.Before(records[j].Time)
+	})
+	if filter.Limit > 0 && len(records) > filter.Limit {
+		records = records[len(records)-filter.Limit:]
+	}
+	return records
+}
+
+// ServeHTTP serves queries for audit history, and the supported query
+// parameters are podUID, namespace, kind, since, until and limit.
+func (h *HistoryAuditSink) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseHistoryFilter(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(err.Error()))
+		return
+	}
+
+	data, err := json.Marshal(h.Query(filter))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(err.Error()))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
+
+func parseHistoryFilter(r *http.Request) (HistoryFilter, error) {
+	query := r.URL.Query()
+	filter := HistoryFilter{
+		PodUID:       query.Get("podUID"),
+		PodNamespace: query.Get("namespace"),
+		Type:         query.Get("kind"),
+	}
+
+	var err error
+	if since := query.Get("since"); since != "" {
+		if filter.Since, err = time.Parse(time.RFC3339, since); err != nil {
+			return filter, fmt.Errorf("invalid since %v: %v", since, err)
+		}
+	}
+	if until := query.Get("until"); until != "" {
+		if filter.Until, err = time.Parse(time.RFC3339, until); err != nil {
+			return filter, fmt.Errorf("invalid until %v: %v", until, err)
+		}
+	}
+	if limit := query.Get("limit"); limit != "" {
+		if filter.Limit, err = strconv.Atoi(limit); err != nil {
+			return filter, fmt.Errorf("invalid limit %v: %v", limit, err)
+		}
+	}
+	return filter, nil
+}
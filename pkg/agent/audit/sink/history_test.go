@@ -0,0 +1,142 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/global"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/eventbus"
+)
+
+func newEvictionEventForHistory(podUID, namespace string, t time.Time) eventbus.EvictionEvent {
+	return eventbus.EvictionEvent{
+		BaseEventImpl: eventbus.BaseEventImpl{Time: t},
+		PodUID:        podUID,
+		PodNamespace:  namespace,
+		PodName:       podUID,
+	}
+}
+
+func TestHistoryAuditSink_Query(t *testing.T) {
+	t.Parallel()
+
+	s := NewHistoryAuditSink(&global.AuditConfiguration{
+		BufferSize:          10,
+		HistorySinkCapacity: 2,
+		HistorySinkMaxPods:  2,
+	}, metrics.DummyMetrics{}).(*HistoryAuditSink)
+	assert.Equal(t, SinkNameHistory, s.GetName())
+	assert.Equal(t, 10, s.GetBufferSize())
+
+	now := time.Now()
+	handler := s.GetHandler()
+	require.NoError(t, handler(newEvictionEventForHistory("uid-1", "ns-1", now)))
+	require.NoError(t, handler(eventbus.SyscallEvent{BaseEventImpl: eventbus.BaseEventImpl{Time: now.Add(time.Second)}, PodUID: "uid-1"}))
+	require.NoError(t, handler(eventbus.RawCGroupEvent{BaseEventImpl: eventbus.BaseEventImpl{Time: now.Add(2 * time.Second)}}))
+	require.NoError(t, handler(newEvictionEventForHistory("uid-2", "ns-2", now.Add(3*time.Second))))
+	require.NoError(t, handler(newEvictionEventForHistory("uid-3", "ns-2", now.Add(4*time.Second))))
+
+	getPodUIDs := func(records []*AuditRecord) []string {
+		var uids []string
+		for _, r := range records {
+			uids = append(uids, r.PodUID)
+		}
+		return uids
+	}
+
+	// records of uid-1 are dropped to make room for uid-3
+	assert.Equal(t, []string{"", "uid-2", "uid-3"}, getPodUIDs(s.Query(HistoryFilter{})))
+	assert.Empty(t, s.Query(HistoryFilter{PodUID: "uid-1"}))
+	assert.Equal(t, []string{"uid-2", "uid-3"}, getPodUIDs(s.Query(HistoryFilter{PodNamespace: "ns-2"})))
+	assert.Equal(t, []string{"uid-3"}, getPodUIDs(s.Query(HistoryFilter{Type: AuditRecordTypeEviction, Limit: 1})))
+	assert.Equal(t, []string{"", "uid-2"}, getPodUIDs(s.Query(HistoryFilter{
+		Since: now.Add(2 * time.Second), Until: now.Add(3 * time.Second),
+	})))
+}
+
+func TestHistoryAuditSink_PodRings(t *testing.T) {
+	t.Parallel()
+
+	s := NewHistoryAuditSink(&global.AuditConfiguration{HistorySinkCapacity: 2}, metrics.DummyMetrics{}).(*HistoryAuditSink)
+	now := time.Now()
+	newAllocationEvent := func(podUID string, t time.Time) eventbus.AllocationEvent {
+		return eventbus.AllocationEvent{
+			BaseEventImpl: eventbus.BaseEventImpl{Time: t},
+			PodUID:        podUID,
+			PodNamespace:  "ns-1",
+			Results:       []eventbus.AllocationResult{{ResourceName: "cpu", AllocatedQuantity: 2, AllocationResult: "0-1"}},
+		}
+	}
+
+	handler := s.GetHandler()
+	require.NoError(t, handler(newEvictionEventForHistory("uid-1", "ns-1", now)))
+	require.NoError(t, handler(newAllocationEvent("uid-1", now.Add(time.Second))))
+	assert.Len(t, s.Query(HistoryFilter{PodUID: "uid-1"}), 2)
+	assert.Equal(t, AuditRecordTypeAllocation, s.Query(HistoryFilter{PodUID: "uid-1", Limit: 1})[0].Type)
+
+	// records of a busy pod never push out those of other pods
+	for i := 0; i < 5; i++ {
+		require.NoError(t, handler(newEvictionEventForHistory("uid-2", "ns-1", now.Add(time.Duration(i+2)*time.Second))))
+	}
+	assert.Len(t, s.Query(HistoryFilter{PodUID: "uid-1"}), 2)
+	assert.Len(t, s.Query(HistoryFilter{PodUID: "uid-2"}), 2)
+
+	// only the latest records of each type are kept for a pod
+	require.NoError(t, handler(newEvictionEventForHistory("uid-1", "ns-1", now.Add(10*time.Second))))
+	require.NoError(t, handler(newEvictionEventForHistory("uid-1", "ns-1", now.Add(11*time.Second))))
+	records := s.Query(HistoryFilter{PodUID: "uid-1"})
+	require.Len(t, records, 3)
+	assert.Equal(t, AuditRecordTypeAllocation, records[0].Type)
+	assert.Equal(t, now.Add(10*time.Second), records[1].Time)
+	assert.Equal(t, now.Add(11*time.Second), records[2].Time)
+}
+
+func TestHistoryAuditSink_ServeHTTP(t *testing.T) {
+	t.Parallel()
+
+	s := NewHistoryAuditSink(&global.AuditConfiguration{HistorySinkCapacity: 10}, metrics.DummyMetrics{}).(*HistoryAuditSink)
+	now := time.Now().Truncate(time.Second)
+	require.NoError(t, s.GetHandler()(newEvictionEventForHistory("uid-1", "ns-1", now)))
+	require.NoError(t, s.GetHandler()(newEvictionEventForHistory("uid-2", "ns-1", now.Add(time.Minute))))
+
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + HistoryHTTPPath + "?namespace=ns-1&kind=eviction&since=" + now.Add(time.Second).Format(time.RFC3339))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var records []AuditRecord
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&records))
+	require.Len(t, records, 1)
+	assert.Equal(t, "uid-2", records[0].PodUID)
+
+	badResp, err := http.Get(server.URL + HistoryHTTPPath + "?limit=abc")
+	require.NoError(t, err)
+	defer badResp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, badResp.StatusCode)
+}
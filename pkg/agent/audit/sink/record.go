@@ -0,0 +1,70 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/kubewharf/katalyst-core/pkg/util/eventbus"
+)
+
+const (
+	AuditRecordTypeCGroup     = "cgroup"
+	AuditRecordTypeProcFS     = "procfs"
+	AuditRecordTypeSysFS      = "sysfs"
+	AuditRecordTypeSyscall    = "syscall"
+	AuditRecordTypeEviction   = "eviction"
+	AuditRecordTypeAllocation = "allocation"
+)
+
+// AuditRecord is the serializable form of audit events, it's shared by all
+// sinks that ship audit events out of the agent.
+type AuditRecord struct {
+	Type         string      `json:"type"`
+	Time         time.Time   `json:"time"`
+	PodUID       string      `json:"podUID,omitempty"`
+	PodNamespace string      `json:"podNamespace,omitempty"`
+	Event        interface{} `json:"event"`
+}
+
+// NewAuditRecord wraps the given event with its type and timestamp.
+func NewAuditRecord(event interface{}) (*AuditRecord, error) {
+	switch e := event.(type) {
+	case eventbus.RawCGroupEvent:
+		return &AuditRecord{Type: AuditRecordTypeCGroup, Time: e.Time, Event: e}, nil
+	case eventbus.RawProcfsEvent:
+		return &AuditRecord{Type: AuditRecordTypeProcFS, Time: e.Time, Event: e}, nil
+	case eventbus.RawSysfsEvent:
+		return &AuditRecord{Type: AuditRecordTypeSysFS, Time: e.Time, Event: e}, nil
+	case eventbus.SyscallEvent:
+		return &AuditRecord{Type: AuditRecordTypeSyscall, Time: e.Time, PodUID: e.PodUID, Event: e}, nil
+	case eventbus.EvictionEvent:
+		return &AuditRecord{
+			Type: AuditRecordTypeEviction, Time: e.Time,
+			PodUID: e.PodUID, PodNamespace: e.PodNamespace, Event: e,
+		}, nil
+	case eventbus.AllocationEvent:
+		return &AuditRecord{
+			Type: AuditRecordTypeAllocation, Time: e.Time,
+			PodUID: e.PodUID, PodNamespace: e.PodNamespace, Event: e,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported event type:%v", reflect.TypeOf(event))
+	}
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/global"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/eventbus"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+)
+
+const (
+	SinkNameWebhook = "webhook"
+
+	MetricsNameWebhookSinkSend = "audit_webhook_sink_send"
+)
+
+// WebhookAuditSink sends audit events in batches as JSON arrays to a remote
+// HTTP endpoint. A batch is sent either when it's full or when flush interval
+// elapses, and it will be dropped after all retries fail. Batches are sent one
+// by one outside the batch lock, so that a slow endpoint only blocks the sender
+// and the events beyond GetBufferSize will be dropped by event bus instead of
+// piling up in memory.
+type WebhookAuditSink struct {
+	BaseAuditSink
+	bufferSize    int
+	url           string
+	batchSize     int
+	flushInterval time.Duration
+	maxRetries    int
+	retryInterval time.Duration
+	client        *http.Client
+	emitter       metrics.MetricEmitter
+
+	mutex sync.Mutex
+	batch []*AuditRecord
+	// sendMutex serializes sending batches to keep them in order
+	sendMutex sync.Mutex
+}
+
+func NewWebhookAuditSink(c *global.AuditConfiguration, emitter metrics.MetricEmitter) Interface {
+	batchSize := c.WebhookSinkBatchSize
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+
+	sink := &WebhookAuditSink{
+		bufferSize:    c.BufferSize,
+		url:           c.WebhookSinkURL,
+		batchSize:     batchSize,
+		flushInterval: c.WebhookSinkFlushInterval,
+		maxRetries:    c.WebhookSinkMaxRetries,
+		retryInterval: c.WebhookSinkRetryInterval,
+		client:        &http.Client{Timeout: c.WebhookSinkTimeout},
+		emitter:       emitter,
+		batch:         make([]*AuditRecord, 0, batchSize),
+	}
+	sink.Interface = sink
+	return sink
+}
+
+func (w *WebhookAuditSink) Run(ctx context.Context, bus eventbus.EventBus) {
+	if w.flushInterval > 0 {
+		go wait.UntilWithContext(ctx, func(_ context.Context) {
+			if err := w.flush(); err != nil {
+				general.Errorf("flush audit events to webhook failed: %v", err)
+			}
+		}, w.flushInterval)
+	}
+
+	w.BaseAuditSink.Run(ctx, bus)
+
+	if err := w.flush(); err != nil {
+		general.Errorf("flush audit events to webhook failed: %v", err)
+	}
+}
+
+func (w *WebhookAuditSink) GetHandler() eventbus.ConsumeFunc {
+	return func(event interface{}) error {
+		if event == nil {
+			general.Warningf("ignore nil event")
+			return nil
+		}
+
+		record, err := NewAuditRecord(event)
+		if err != nil {
+			return err
+		}
+
+		w.mutex.Lock()
+		w.batch = append(w.batch, record)
+		if len(w.batch) < w.batchSize {
+			w.mutex.Unlock()
+			return nil
+		}
+		records := w.swapBatchLocked()
+		w.mutex.Unlock()
+
+		return w.sendBatch(records)
+	}
+}
+
+func (w *WebhookAuditSink) GetName() string {
+	return SinkNameWebhook
+}
+
+func (w *WebhookAuditSink) GetBufferSize() int {
+	return w.bufferSize
+}
+
+func (w *WebhookAuditSink) flush() error {
+	w.mutex.Lock()
+	records := w.swapBatchLocked()
+	w.mutex.Unlock()
+
+	return w.sendBatch(records)
+}
+
+// swapBatchLocked takes current batch away and replaces it with an empty one,
+// and the caller must hold the mutex.
+func (w *WebhookAuditSink) swapBatchLocked() []*AuditRecord {
+	records := w.batch
+	w.batch = make([]*AuditRecord, 0, w.batchSize)
+	return records
+}
+
+// sendBatch sends the given records without holding the batch lock.
+func (w *WebhookAuditSink) sendBatch(records []*AuditRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	w.sendMutex.Lock()
+	defer w.sendMutex.Unlock()
+
+	err := w.send(records)
+	state := "succeeded"
+	if err != nil {
+		state = "failed"
+	}
+	_ = w.emitter.StoreInt64(MetricsNameWebhookSinkSend, int64(len(records)), metrics.MetricTypeNameCount,
+		metrics.MetricTag{Key: "state", Val: state})
+	return err
+}
+
+func (w *WebhookAuditSink) send(records []*AuditRecord) error {
+	body, err := json.Marshal(records)
+	if err != nil {
+		return fmt.Errorf("marshal audit records failed: %v", err)
+	}
+
+	for i := 0; ; i++ {
+		err = w.post(body)
+		if err == nil {
+			return nil
+		} else if i >= w.maxRetries {
+			return fmt.Errorf("send %v audit records failed after %v retries: %v", len(records), i, err)
+		}
+
+		general.Warningf("send audit records failed: %v, retry after %v", err, w.retryInterval)
+		time.Sleep(w.retryInterval)
+	}
+}
+
+func (w *WebhookAuditSink) post(body []byte) error {
+	resp, err := w.client.Post(w.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unexpected status code %v", resp.StatusCode)
+	}
+	return nil
+}
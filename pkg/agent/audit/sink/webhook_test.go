@@ -0,0 +1,146 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/global"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/eventbus"
+)
+
+type fakeWebhookServer struct {
+	mutex    sync.Mutex
+	failures int
+	batches  [][]AuditRecord
+}
+
+func (f *fakeWebhookServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.failures > 0 {
+		f.failures--
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	var batch []AuditRecord
+	if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	f.batches = append(f.batches, batch)
+}
+
+func (f *fakeWebhookServer) getBatchSizes() []int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	sizes := make([]int, 0, len(f.batches))
+	for _, batch := range f.batches {
+		sizes = append(sizes, len(batch))
+	}
+	return sizes
+}
+
+func TestWebhookAuditSink(t *testing.T) {
+	t.Parallel()
+
+	fakeServer := &fakeWebhookServer{failures: 1}
+	server := httptest.NewServer(fakeServer)
+	defer server.Close()
+
+	s := NewWebhookAuditSink(&global.AuditConfiguration{
+		BufferSize:               10,
+		WebhookSinkURL:           server.URL,
+		WebhookSinkBatchSize:     2,
+		WebhookSinkFlushInterval: time.Hour,
+		WebhookSinkTimeout:       time.Second,
+		WebhookSinkMaxRetries:    1,
+		WebhookSinkRetryInterval: time.Millisecond,
+	}, metrics.DummyMetrics{})
+	assert.Equal(t, SinkNameWebhook, s.GetName())
+	assert.Equal(t, 10, s.GetBufferSize())
+
+	// the first request fails and the batch is sent by retry
+	handler := s.GetHandler()
+	require.NoError(t, handler(eventbus.EvictionEvent{PodName: "pod-1"}))
+	assert.Empty(t, fakeServer.getBatchSizes())
+	require.NoError(t, handler(eventbus.EvictionEvent{PodName: "pod-2"}))
+	assert.Equal(t, []int{2}, fakeServer.getBatchSizes())
+
+	// the remaining events are flushed when sink stops
+	require.NoError(t, handler(eventbus.SyscallEvent{Syscall: "test"}))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.Run(ctx, eventbus.NewEventBus(10))
+	assert.Equal(t, []int{2, 1}, fakeServer.getBatchSizes())
+
+	// the batch is dropped if all retries fail
+	fakeServer.failures = 2
+	require.NoError(t, handler(eventbus.EvictionEvent{PodName: "pod-3"}))
+	require.Error(t, handler(eventbus.EvictionEvent{PodName: "pod-4"}))
+	assert.Empty(t, s.(*WebhookAuditSink).batch)
+}
+
+func TestWebhookAuditSink_SlowEndpoint(t *testing.T) {
+	t.Parallel()
+
+	received := make(chan struct{}, 1)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- struct{}{}
+		<-release
+	}))
+	defer server.Close()
+
+	s := NewWebhookAuditSink(&global.AuditConfiguration{
+		BufferSize:           10,
+		WebhookSinkURL:       server.URL,
+		WebhookSinkBatchSize: 2,
+		WebhookSinkTimeout:   time.Minute,
+	}, metrics.DummyMetrics{})
+	handler := s.GetHandler()
+
+	sent := make(chan error)
+	go func() {
+		_ = handler(eventbus.EvictionEvent{PodName: "pod-1"})
+		sent <- handler(eventbus.EvictionEvent{PodName: "pod-2"})
+	}()
+	<-received
+
+	// the batch being sent doesn't block new events from being buffered
+	require.NoError(t, handler(eventbus.EvictionEvent{PodName: "pod-3"}))
+	w := s.(*WebhookAuditSink)
+	w.mutex.Lock()
+	assert.Len(t, w.batch, 1)
+	w.mutex.Unlock()
+
+	close(release)
+	require.NoError(t, <-sent)
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evictionmanager
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	clocks "k8s.io/utils/clock"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/evictionmanager/podkiller"
+	"github.com/kubewharf/katalyst-core/pkg/agent/evictionmanager/rule"
+)
+
+// budgetChargingKiller wraps a Killer to charge eviction budgets only after pods are
+// evicted successfully, and it gives back the budgets reserved for failed or skipped evictions.
+// Pods only suspended by the killer are not charged either; they are treated as in flight
+// by the manager until they are resumed or escalated to eviction.
+type budgetChargingKiller struct {
+	podkiller.Killer
+
+	budget rule.EvictionBudget
+	clock  clocks.PassiveClock
+}
+
+func newBudgetChargingKiller(killer podkiller.Killer, budget rule.EvictionBudget, clock clocks.PassiveClock) podkiller.Killer {
+	return &budgetChargingKiller{
+		Killer: killer,
+		budget: budget,
+		clock:  clock,
+	}
+}
+
+func (b *budgetChargingKiller) Run(ctx context.Context) {
+	podkiller.RunKiller(ctx, b.Killer)
+}
+
+func (b *budgetChargingKiller) Evict(ctx context.Context, pod *v1.Pod, gracePeriodSeconds int64, reason, plugin string) error {
+	if err := b.Killer.Evict(ctx, pod, gracePeriodSeconds, reason, plugin); err != nil {
+		b.budget.Release(pod)
+		return err
+	}
+
+	if podkiller.IsPodSuspended(b.Killer, pod) {
+		b.budget.Release(pod)
+		return nil
+	}
+
+	b.budget.Commit(pod, b.clock.Now())
+	return nil
+}
+
+func (b *budgetChargingKiller) IsSuspended(pod *v1.Pod) bool {
+	return podkiller.IsPodSuspended(b.Killer, pod)
+}
+
+// Skip gives back the budget reserved for the pod, since it has been deleted by others
+func (b *budgetChargingKiller) Skip(pod *v1.Pod) {
+	b.budget.Release(pod)
+}
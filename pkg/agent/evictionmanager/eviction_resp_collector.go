@@ -47,11 +47,17 @@ type evictionRespCollector struct {
 	softEvictPods  map[string]*rule.RuledEvictPod
 	forceEvictPods map[string]*rule.RuledEvictPod
 
+	// shadowModePlugins are plugins running in shadow mode, and their soft and force
+	// evict pods are collected separately to be handled by a dry-run killer
+	shadowModePlugins    []string
+	shadowSoftEvictPods  map[string]*rule.RuledEvictPod
+	shadowForceEvictPods map[string]*rule.RuledEvictPod
+
 	// emitter is used to emit metrics.
 	emitter metrics.MetricEmitter
 }
 
-func newEvictionRespCollector(dryRun []string, conf *pkgconfig.Configuration, emitter metrics.MetricEmitter) *evictionRespCollector {
+func newEvictionRespCollector(dryRun, shadowModePlugins []string, conf *pkgconfig.Configuration, emitter metrics.MetricEmitter) *evictionRespCollector {
 	collector := &evictionRespCollector{
 		conf:                 conf,
 		currentMetThresholds: make(map[string]*pluginapi.ThresholdMetResponse),
@@ -61,9 +67,13 @@ func newEvictionRespCollector(dryRun []string, conf *pkgconfig.Configuration, em
 		softEvictPods:  make(map[string]*rule.RuledEvictPod),
 		forceEvictPods: make(map[string]*rule.RuledEvictPod),
 
+		shadowModePlugins:    shadowModePlugins,
+		shadowSoftEvictPods:  make(map[string]*rule.RuledEvictPod),
+		shadowForceEvictPods: make(map[string]*rule.RuledEvictPod),
+
 		emitter: emitter,
 	}
-	general.Infof("dry run plugins is %v", dryRun)
+	general.Infof("dry run plugins is %v, shadow mode plugins is %v", dryRun, shadowModePlugins)
 	return collector
 }
 
@@ -75,26 +85,46 @@ func (e *evictionRespCollector) isDryRun(dryRunPlugins []string, pluginName stri
 	return general.IsNameEnabled(pluginName, nil, dryRunPlugins)
 }
 
-func (e *evictionRespCollector) getLogPrefix(dryRun bool) string {
+func (e *evictionRespCollector) isShadowMode(pluginName string) bool {
+	if len(e.shadowModePlugins) == 0 {
+		return false
+	}
+
+	return general.IsNameEnabled(pluginName, nil, e.shadowModePlugins)
+}
+
+func (e *evictionRespCollector) getLogPrefix(dryRun, shadow bool) string {
 	if dryRun {
 		return "[DryRun]"
+	} else if shadow {
+		return "[Shadow]"
 	}
 
 	return ""
 }
 
+// getEvictPodsFor returns the soft and force evict pods that the given plugin should be collected into
+func (e *evictionRespCollector) getEvictPodsFor(shadow bool) (map[string]*rule.RuledEvictPod, map[string]*rule.RuledEvictPod) {
+	if shadow {
+		return e.getShadowSoftEvictPods(), e.getShadowForceEvictPods()
+	}
+
+	return e.getSoftEvictPods(), e.getForceEvictPods()
+}
+
 func (e *evictionRespCollector) collectEvictPods(dryRunPlugins []string, pluginName string, resp *pluginapi.GetEvictPodsResponse) {
 	dryRun := e.isDryRun(dryRunPlugins, pluginName)
+	shadow := e.isShadowMode(pluginName)
 
 	evictPods := make([]*pluginapi.EvictPod, 0, len(resp.EvictPods))
 	for i, evictPod := range resp.EvictPods {
 		if evictPod == nil || evictPod.Pod == nil {
-			general.Errorf("%v skip nil evict pod of plugin: %s", e.getLogPrefix(dryRun), pluginName)
+			general.Errorf("%v skip nil evict pod of plugin: %s", e.getLogPrefix(dryRun, shadow), pluginName)
 			continue
 		}
 
 		general.Infof("%v plugin: %s requests to evict pod: %s/%s with reason: %s, forceEvict: %v",
-			e.getLogPrefix(dryRun), pluginName, evictPod.Pod.Namespace, evictPod.Pod.Name, evictPod.Reason, evictPod.ForceEvict)
+			e.getLogPrefix(dryRun, shadow), pluginName, evictPod.Pod.Namespace, evictPod.Pod.Name, evictPod.Reason, evictPod.ForceEvict)
 
 		if dryRun {
 			metricsPodToEvict(e.emitter, e.conf.GenericConfiguration.QoSConfiguration, pluginName, evictPod.Pod, dryRun, e.conf.GenericEvictionConfiguration.PodMetricLabels)
@@ -103,18 +133,19 @@ func (e *evictionRespCollector) collectEvictPods(dryRunPlugins []string, pluginN
 		}
 	}
 
+	softEvictPods, forceEvictPods := e.getEvictPodsFor(shadow)
 	for _, evictPod := range evictPods {
 
 		// to avoid plugins forget to set EvictionPluginName property
 		evictPod.EvictionPluginName = pluginName
 
 		if evictPod.ForceEvict {
-			e.getForceEvictPods()[string(evictPod.Pod.UID)] = &rule.RuledEvictPod{
+			forceEvictPods[string(evictPod.Pod.UID)] = &rule.RuledEvictPod{
 				EvictPod: proto.Clone(evictPod).(*pluginapi.EvictPod),
 				Scope:    rule.EvictionScopeForce,
 			}
 		} else {
-			e.getSoftEvictPods()[string(evictPod.Pod.UID)] = &rule.RuledEvictPod{
+			softEvictPods[string(evictPod.Pod.UID)] = &rule.RuledEvictPod{
 				EvictPod: proto.Clone(evictPod).(*pluginapi.EvictPod),
 				Scope:    rule.EvictionScopeSoft,
 			}
@@ -123,9 +154,9 @@ func (e *evictionRespCollector) collectEvictPods(dryRunPlugins []string, pluginN
 
 	if resp.Condition != nil && resp.Condition.MetCondition {
 		general.Infof("%v plugin: %s requests set condition: %s of type: %s",
-			e.getLogPrefix(dryRun), pluginName, resp.Condition.ConditionName, resp.Condition.ConditionType.String())
+			e.getLogPrefix(dryRun, shadow), pluginName, resp.Condition.ConditionName, resp.Condition.ConditionType.String())
 
-		if !dryRun {
+		if !dryRun && !shadow {
 			e.getCurrentConditions()[resp.Condition.ConditionName] = proto.Clone(resp.Condition).(*pluginapi.Condition)
 		}
 	}
@@ -133,9 +164,10 @@ func (e *evictionRespCollector) collectEvictPods(dryRunPlugins []string, pluginN
 
 func (e *evictionRespCollector) collectMetThreshold(dryRunPlugins []string, pluginName string, resp *pluginapi.ThresholdMetResponse) {
 	dryRun := e.isDryRun(dryRunPlugins, pluginName)
+	shadow := e.isShadowMode(pluginName)
 
 	if resp.MetType == pluginapi.ThresholdMetType_NOT_MET {
-		general.InfofV(6, "%v plugin: %s threshold isn't met", e.getLogPrefix(dryRun), pluginName)
+		general.InfofV(6, "%v plugin: %s threshold isn't met", e.getLogPrefix(dryRun, shadow), pluginName)
 		return
 	}
 
@@ -151,10 +183,10 @@ func (e *evictionRespCollector) collectMetThreshold(dryRunPlugins []string, plug
 		e.getCurrentCandidatePods()[string(pod.UID)] = pod
 	}
 
-	general.Infof("%v plugin: %s met threshold: %s", e.getLogPrefix(dryRun), pluginName, resp.String())
+	general.Infof("%v plugin: %s met threshold: %s", e.getLogPrefix(dryRun, shadow), pluginName, resp.String())
 	if resp.Condition != nil && resp.Condition.MetCondition {
 		general.Infof("%v plugin: %s requests to set condition: %s of type: %s",
-			e.getLogPrefix(dryRun), pluginName, resp.Condition.ConditionName, resp.Condition.ConditionType.String())
+			e.getLogPrefix(dryRun, shadow), pluginName, resp.Condition.ConditionName, resp.Condition.ConditionType.String())
 		_ = e.emitter.StoreInt64(MetricsNameRequestConditionCNT, 1, metrics.MetricTypeNameRaw,
 			metrics.MetricTag{Key: "name", Val: pluginName},
 			metrics.MetricTag{Key: "condition_name", Val: resp.Condition.ConditionName},
@@ -163,7 +195,7 @@ func (e *evictionRespCollector) collectMetThreshold(dryRunPlugins []string, plug
 			metrics.MetricTag{Key: "dryrun", Val: strconv.FormatBool(dryRun)},
 		)
 
-		if !dryRun {
+		if !dryRun && !shadow {
 			e.getCurrentConditions()[resp.Condition.ConditionName] = proto.Clone(resp.Condition).(*pluginapi.Condition)
 		}
 	}
@@ -173,6 +205,7 @@ func (e *evictionRespCollector) collectTopSoftEvictionPods(dryRunPlugins []strin
 	threshold *pluginapi.ThresholdMetResponse, resp *pluginapi.GetTopEvictionPodsResponse,
 ) {
 	dryRun := e.isDryRun(dryRunPlugins, pluginName)
+	shadow := e.isShadowMode(pluginName)
 
 	targetPods := make([]*v1.Pod, 0, len(resp.TargetPods))
 	for i, pod := range resp.TargetPods {
@@ -181,7 +214,7 @@ func (e *evictionRespCollector) collectTopSoftEvictionPods(dryRunPlugins []strin
 		}
 
 		general.Infof("%v plugin %v request to notify topN pod %v/%v, reason: met threshold in scope [%v]",
-			e.getLogPrefix(dryRun), pluginName, pod.Namespace, pod.Name, threshold.EvictionScope)
+			e.getLogPrefix(dryRun, shadow), pluginName, pod.Namespace, pod.Name, threshold.EvictionScope)
 		if dryRun {
 			metricsPodToEvict(e.emitter, e.conf.GenericConfiguration.QoSConfiguration, pluginName, pod, dryRun, e.conf.GenericEvictionConfiguration.PodMetricLabels)
 		} else {
@@ -189,11 +222,12 @@ func (e *evictionRespCollector) collectTopSoftEvictionPods(dryRunPlugins []strin
 		}
 	}
 
+	softEvictPods, _ := e.getEvictPodsFor(shadow)
 	for _, pod := range targetPods {
 		reason := fmt.Sprintf("plugin %s met threshold in scope %s, target %v, observed %v",
 			pluginName, threshold.EvictionScope, threshold.ThresholdValue, threshold.ObservedValue)
 
-		e.getSoftEvictPods()[string(pod.UID)] = &rule.RuledEvictPod{
+		softEvictPods[string(pod.UID)] = &rule.RuledEvictPod{
 			EvictPod: &pluginapi.EvictPod{
 				Pod:                pod.DeepCopy(),
 				Reason:             reason,
@@ -209,6 +243,7 @@ func (e *evictionRespCollector) collectTopEvictionPods(dryRunPlugins []string, p
 	threshold *pluginapi.ThresholdMetResponse, resp *pluginapi.GetTopEvictionPodsResponse,
 ) {
 	dryRun := e.isDryRun(dryRunPlugins, pluginName)
+	shadow := e.isShadowMode(pluginName)
 
 	targetPods := make([]*v1.Pod, 0, len(resp.TargetPods))
 	for i, pod := range resp.TargetPods {
@@ -217,7 +252,7 @@ func (e *evictionRespCollector) collectTopEvictionPods(dryRunPlugins []string, p
 		}
 
 		general.Infof("%v plugin %v request to evict topN pod %v/%v, reason: met threshold in scope [%v]",
-			e.getLogPrefix(dryRun), pluginName, pod.Namespace, pod.Name, threshold.EvictionScope)
+			e.getLogPrefix(dryRun, shadow), pluginName, pod.Namespace, pod.Name, threshold.EvictionScope)
 		if dryRun {
 			metricsPodToEvict(e.emitter, e.conf.GenericConfiguration.QoSConfiguration, pluginName, pod, dryRun, e.conf.GenericEvictionConfiguration.PodMetricLabels)
 		} else {
@@ -225,12 +260,13 @@ func (e *evictionRespCollector) collectTopEvictionPods(dryRunPlugins []string, p
 		}
 	}
 
+	_, forceEvictPods := e.getEvictPodsFor(shadow)
 	for _, pod := range targetPods {
 		deletionOptions := resp.DeletionOptions
 		reason := fmt.Sprintf("plugin %s met threshold in scope %s, target %v, observed %v",
 			pluginName, threshold.EvictionScope, threshold.ThresholdValue, threshold.ObservedValue)
 
-		forceEvictPod := e.getForceEvictPods()[string(pod.UID)]
+		forceEvictPod := forceEvictPods[string(pod.UID)]
 		if forceEvictPod != nil && forceEvictPod.EvictPod != nil {
 			if forceEvictPod.EvictPod.DeletionOptions != nil {
 				if deletionOptions == nil {
@@ -242,7 +278,7 @@ func (e *evictionRespCollector) collectTopEvictionPods(dryRunPlugins []string, p
 			reason = fmt.Sprintf("%s; %s", reason, forceEvictPod.EvictPod.Reason)
 		}
 
-		e.getForceEvictPods()[string(pod.UID)] = &rule.RuledEvictPod{
+		forceEvictPods[string(pod.UID)] = &rule.RuledEvictPod{
 			EvictPod: &pluginapi.EvictPod{
 				Pod:                pod.DeepCopy(),
 				Reason:             reason,
@@ -274,3 +310,11 @@ func (e *evictionRespCollector) getSoftEvictPods() map[string]*rule.RuledEvictPo
 func (e *evictionRespCollector) getForceEvictPods() map[string]*rule.RuledEvictPod {
 	return e.forceEvictPods
 }
+
+func (e *evictionRespCollector) getShadowSoftEvictPods() map[string]*rule.RuledEvictPod {
+	return e.shadowSoftEvictPods
+}
+
+func (e *evictionRespCollector) getShadowForceEvictPods() map[string]*rule.RuledEvictPod {
+	return e.shadowForceEvictPods
+}
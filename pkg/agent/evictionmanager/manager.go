@@ -21,6 +21,7 @@ package evictionmanager // import "github.com/kubewharf/katalyst-core/pkg/evicti
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -29,6 +30,10 @@ import (
 	//nolint
 	"github.com/golang/protobuf/proto"
 	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -71,6 +76,8 @@ const (
 	MetricsNameRequestConditionCNT    = "request_condition_cnt"
 	MetricsNameEvictionPluginCalled   = "eviction_plugin_called"
 	MetricsNameEvictionPluginValidate = "eviction_plugin_validate"
+	MetricsNameCandidateChosen        = "eviction_candidate_chosen"
+	MetricsNameEvictionThrottled      = "eviction_throttled"
 
 	MetricsNameGetEvictionRecordCost   = "get_eviction_record_cost"
 	MetricsNameGetEvictionRecordFailed = "get_eviction_record_failed"
@@ -83,6 +90,8 @@ const (
 
 	MetricsPodLabelPrefix = "pod"
 
+	evictionThrottledScopePDB = "pdb"
+
 	evictionManagerHealthCheckName = "eviction_manager_sync"
 	reportTaintHealthCheckName     = "eviction_manager_report_taint"
 	reportCNRTaintHealthCheckName  = "eviction_manager_report_cnr_taint"
@@ -92,6 +101,11 @@ const (
 	cnrTaintReporterPluginName = "cnr-taint-reporter"
 )
 
+type pendingEvictPod struct {
+	rp          *rule.RuledEvictPod
+	throttledAt time.Time
+}
+
 // LatestCNRGetter returns the latest CNR resources.
 type LatestCNRGetter func() *v1alpha1.CustomNodeResource
 
@@ -116,11 +130,25 @@ type EvictionManger struct {
 
 	killQueue    rule.EvictionQueue
 	killStrategy rule.EvictionStrategy
+	// killBudget is shared among all plugins to limit evictions in a period of time
+	killBudget rule.EvictionBudget
+	// killer is the one used by podKiller, and it's kept to find pods suspended by it
+	killer podkiller.Killer
+	// pendingEvictPods keeps pods throttled by budgets, and they survive withdrawing of
+	// killQueue to be retried in later rounds as long as plugins still request them
+	pendingEvictPods map[types.UID]*pendingEvictPod
+
+	// shadowPodKiller and shadowKillQueue work for plugins in shadow mode, the
+	// shadowPodKiller only records what would be evicted by the real podKiller.
+	shadowPodKiller podkiller.PodKiller
+	shadowKillQueue rule.EvictionQueue
 
 	// metaGetter is used to collect metadata universal metaServer.
 	metaGetter *metaserver.MetaServer
 	// emitter is used to emit metrics.
 	emitter metrics.MetricEmitter
+	// recorder is used to record events.
+	recorder events.EventRecorder
 
 	// endpoints cache registered eviction plugin endpoints.
 	endpoints map[string]endpointpkg.Endpoint
@@ -161,9 +189,19 @@ func NewPodKillerInitializers() map[string]podkiller.InitFunc {
 	podKillerInitializers[consts.KillerNameEvictionKiller] = podkiller.NewEvictionAPIKiller
 	podKillerInitializers[consts.KillerNameDeletionKiller] = podkiller.NewDeletionAPIKiller
 	podKillerInitializers[consts.KillerNameContainerKiller] = podkiller.NewContainerKiller
+	podKillerInitializers[consts.KillerNameFreezeKiller] = podkiller.NewFreezeKillerInitFunc(podKillerInitializers)
 	return podKillerInitializers
 }
 
+// newDryRunKillerInitializers replaces each killer with a dry-run killer that acts on behalf of it
+func newDryRunKillerInitializers(initializers map[string]podkiller.InitFunc) map[string]podkiller.InitFunc {
+	dryRunKillerInitializers := make(map[string]podkiller.InitFunc, len(initializers))
+	for killerName := range initializers {
+		dryRunKillerInitializers[killerName] = podkiller.NewDryRunKillerInitFunc(killerName)
+	}
+	return dryRunKillerInitializers
+}
+
 // initializeKiller initializes a single killer instance
 func initializeKiller(killerType string, initializer podkiller.InitFunc, conf *pkgconfig.Configuration,
 	kubeClient kubernetes.Interface, recorder events.EventRecorder, emitter metrics.MetricEmitter,
@@ -231,13 +269,22 @@ func NewEvictionManager(genericClient *client.GenericClientSet, recorder events.
 	metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter, conf *pkgconfig.Configuration,
 ) (*EvictionManger, error) {
 	queue := rule.NewFIFOEvictionQueue(conf.EvictionBurst)
+	killBudget := rule.NewSlidingWindowEvictionBudget(conf.EvictionBudgetWindow, conf.NodeEvictionBudget,
+		conf.QoSEvictionBudgets, conf.OwnerEvictionBudget, conf.QoSConfiguration)
 
 	killer, err := initializeQoSAwareKiller(NewPodKillerInitializers(), conf, genericClient.KubeClient, recorder, emitter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to init QoS killer: %v", err)
 	}
 
-	podKiller := podkiller.NewAsynchronizedPodKiller(killer, metaServer.PodFetcher, genericClient.KubeClient)
+	budgetKiller := newBudgetChargingKiller(killer, killBudget, clocks.RealClock{})
+	podKiller := podkiller.NewAsynchronizedPodKiller(budgetKiller, metaServer.PodFetcher, genericClient.KubeClient)
+
+	shadowKiller, err := initializeQoSAwareKiller(newDryRunKillerInitializers(NewPodKillerInitializers()), conf, genericClient.KubeClient, recorder, emitter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init shadow QoS killer: %v", err)
+	}
+	shadowPodKiller := podkiller.NewSynchronizedPodKiller(shadowKiller)
 
 	notifier, err := podnotifier.NewHostPathPodNotifier(conf, genericClient.KubeClient, metaServer, recorder, emitter)
 	if err != nil {
@@ -267,11 +314,18 @@ func NewEvictionManager(genericClient *client.GenericClientSet, recorder events.
 	}
 
 	e := &EvictionManger{
-		killQueue:    queue,
-		killStrategy: rule.NewEvictionStrategyImpl(conf),
+		killQueue:        queue,
+		killStrategy:     rule.NewEvictionStrategyImpl(conf),
+		killBudget:       killBudget,
+		killer:           budgetKiller,
+		pendingEvictPods: make(map[types.UID]*pendingEvictPod),
+
+		shadowPodKiller: shadowPodKiller,
+		shadowKillQueue: rule.NewFIFOEvictionQueue(conf.EvictionBurst),
 
 		metaGetter:                metaServer,
 		emitter:                   emitter,
+		recorder:                  recorder,
 		podKiller:                 podKiller,
 		podNotifier:               podNotifier,
 		cnrTaintReporter:          cnrTaintReporter,
@@ -382,6 +436,11 @@ func (m *EvictionManger) sync(ctx context.Context) {
 	if evictErr != nil {
 		errList = append(errList, evictErr)
 	}
+
+	shadowEvictErr := m.doShadowEvict(collector.getShadowSoftEvictPods(), collector.getShadowForceEvictPods())
+	if shadowEvictErr != nil {
+		errList = append(errList, shadowEvictErr)
+	}
 	if len(errList) > 0 {
 		err = errors.NewAggregate(errList)
 	}
@@ -389,7 +448,7 @@ func (m *EvictionManger) sync(ctx context.Context) {
 
 func (m *EvictionManger) collectEvictionResult(ctx context.Context, pods []*v1.Pod) (*evictionRespCollector, error) {
 	dynamicConfig := m.conf.GetDynamicConfiguration()
-	collector := newEvictionRespCollector(dynamicConfig.DryRun, m.conf, m.emitter)
+	collector := newEvictionRespCollector(dynamicConfig.DryRun, dynamicConfig.ShadowModePlugins, m.conf, m.emitter)
 	var errList []error
 
 	m.endpointLock.RLock()
@@ -532,7 +591,7 @@ func (m *EvictionManger) doNotify(softEvictPods map[string]*rule.RuledEvictPod)
 
 func (m *EvictionManger) doEvict(softEvictPods, forceEvictPods map[string]*rule.RuledEvictPod) error {
 	softEvictPods = filterOutCandidatePodsWithForcePods(softEvictPods, forceEvictPods)
-	bestSuitedCandidate := m.getEvictPodFromCandidates(softEvictPods)
+	bestSuitedCandidate := m.getEvictPodFromCandidates(softEvictPods, false)
 	if bestSuitedCandidate != nil && bestSuitedCandidate.Pod != nil {
 		general.Infof(" choose best suited pod: %s/%s", bestSuitedCandidate.Pod.Namespace, bestSuitedCandidate.Pod.Name)
 		forceEvictPods[string(bestSuitedCandidate.Pod.UID)] = bestSuitedCandidate
@@ -561,6 +620,41 @@ func (m *EvictionManger) doEvict(softEvictPods, forceEvictPods map[string]*rule.
 	return nil
 }
 
+// doShadowEvict walks through the same candidate selection and rule queueing as doEvict
+// for pods from plugins in shadow mode, but those pods are only recorded by shadowPodKiller.
+func (m *EvictionManger) doShadowEvict(softEvictPods, forceEvictPods map[string]*rule.RuledEvictPod) error {
+	if len(softEvictPods) == 0 && len(forceEvictPods) == 0 {
+		return nil
+	}
+
+	softEvictPods = filterOutCandidatePodsWithForcePods(softEvictPods, forceEvictPods)
+	bestSuitedCandidate := m.getEvictPodFromCandidates(softEvictPods, true)
+	if bestSuitedCandidate != nil && bestSuitedCandidate.Pod != nil {
+		general.Infof(" [Shadow] choose best suited pod: %s/%s", bestSuitedCandidate.Pod.Namespace, bestSuitedCandidate.Pod.Name)
+		forceEvictPods[string(bestSuitedCandidate.Pod.UID)] = bestSuitedCandidate
+	}
+
+	rpList := rule.RuledEvictPodList{}
+	for _, rp := range forceEvictPods {
+		if rp != nil && rp.EvictPod.Pod != nil && m.killStrategy.CandidateValidate(rp) {
+			general.Infof(" [Shadow] ready to evict %s/%s, reason: %s", rp.Pod.Namespace, rp.Pod.Name, rp.Reason)
+			rpList = append(rpList, rp)
+		}
+	}
+
+	m.shadowKillQueue.Add(rpList, true)
+	victims := m.shadowKillQueue.Pop()
+	if err := m.shadowPodKiller.EvictPods(victims); err != nil {
+		general.Errorf(" [Shadow] got err: %v in EvictPods", err)
+		return err
+	}
+
+	general.Infof(" [Shadow] evict %d pods in evictionmanager", len(victims))
+	_ = m.emitter.StoreInt64(MetricsNameDryRunVictimPodCNT, int64(len(victims)), metrics.MetricTypeNameRaw,
+		metrics.MetricTag{Key: "type", Val: "shadow"})
+	return nil
+}
+
 // ValidatePlugin validates a plugin if the version is correct and the name has the format of an extended resource
 func (m *EvictionManger) ValidatePlugin(pluginName string, endpoint string, versions []string) error {
 	general.Infof(" got plugin %s at endpoint %s with versions %v", pluginName, endpoint, versions)
@@ -677,13 +771,169 @@ func (m *EvictionManger) isVersionCompatibleWithPlugin(versions []string) bool {
 // killWithRules send killing requests according to pre-defined rules
 // currently, we will use FIFO (with rate limiting) to
 func (m *EvictionManger) killWithRules(rpList rule.RuledEvictPodList) error {
-	// withdraw previous candidate killing pods by set override params as true
-	m.killQueue.Add(rpList, true)
-	return m.podKiller.EvictPods(m.killQueue.Pop())
+	// withdraw previous candidate killing pods by set override params as true,
+	// while pods throttled by budgets are kept to be retried before new ones
+	m.killQueue.Add(m.getPendingEvictPods(rpList), true)
+	m.killQueue.Add(rpList, false)
+	return m.podKiller.EvictPods(m.filterWithBudget(m.killQueue.Pop()))
+}
+
+// getPendingEvictPods returns pods throttled in previous rounds ordered by the time they
+// were throttled, refreshed with the latest requests. Pods not requested again in this round
+// are dropped, since the pressure they were requested for may have gone.
+func (m *EvictionManger) getPendingEvictPods(rpList rule.RuledEvictPodList) rule.RuledEvictPodList {
+	requested := make(map[types.UID]*rule.RuledEvictPod, len(rpList))
+	for _, rp := range rpList {
+		requested[rp.Pod.UID] = rp
+	}
+
+	pendingList := make([]*pendingEvictPod, 0, len(m.pendingEvictPods))
+	for uid, pending := range m.pendingEvictPods {
+		rp, ok := requested[uid]
+		if !ok {
+			general.Infof(" drop pending pod %s/%s since it's not requested any more",
+				pending.rp.Pod.Namespace, pending.rp.Pod.Name)
+			delete(m.pendingEvictPods, uid)
+			continue
+		}
+		pending.rp = rp
+		pendingList = append(pendingList, pending)
+	}
+	sort.SliceStable(pendingList, func(i, j int) bool {
+		if !pendingList[i].throttledAt.Equal(pendingList[j].throttledAt) {
+			return pendingList[i].throttledAt.Before(pendingList[j].throttledAt)
+		}
+		return pendingList[i].rp.Pod.Name < pendingList[j].rp.Pod.Name
+	})
+
+	result := make(rule.RuledEvictPodList, 0, len(pendingList))
+	for _, pending := range pendingList {
+		result = append(result, pending.rp)
+	}
+	return result
+}
+
+// filterWithBudget filters out pods that are throttled by eviction budgets or PodDisruptionBudgets,
+// and those throttled pods will be kept as pending to wait for the next round. Budgets are only
+// reserved here, and they are charged by the killer after pods are evicted successfully.
+func (m *EvictionManger) filterWithBudget(rpList rule.RuledEvictPodList) rule.RuledEvictPodList {
+	if len(rpList) == 0 || !m.killBudget.Enabled() {
+		return rpList
+	}
+
+	now := m.clock.Now()
+	pdbCache := make(map[string][]policyv1.PodDisruptionBudget)
+	disruptions := make(map[string]int32)
+	admitted := make(rule.RuledEvictPodList, 0, len(rpList))
+	throttledCount := 0
+	for _, rp := range rpList {
+		// pods in flight have already passed budgets, so they are handed over to the killer
+		// again without being charged, and the killer will deduplicate them; suspended pods
+		// are also in flight, and they must be requested again to keep being suspended
+		if m.killBudget.InFlight(rp.Pod, now) || podkiller.IsPodSuspended(m.killer, rp.Pod) {
+			delete(m.pendingEvictPods, rp.Pod.UID)
+			admitted = append(admitted, rp)
+			continue
+		}
+
+		var pdbs []*policyv1.PodDisruptionBudget
+		if m.getKillerNameForPod(rp.Pod) == consts.KillerNameEvictionKiller {
+			pdbs = m.getPodDisruptionBudgets(rp.Pod, pdbCache)
+		}
+
+		throttledScope := ""
+		for _, pdb := range pdbs {
+			if pdb.Status.DisruptionsAllowed-disruptions[native.GenerateUniqObjectNameKey(pdb)] <= 0 {
+				throttledScope = evictionThrottledScopePDB
+				break
+			}
+		}
+		if throttledScope == "" {
+			if ok, scope := m.killBudget.TryReserve(rp.Pod, now); !ok {
+				throttledScope = scope
+			}
+		}
+
+		if throttledScope != "" {
+			m.reportEvictionThrottled(rp, throttledScope)
+			if _, ok := m.pendingEvictPods[rp.Pod.UID]; !ok {
+				m.pendingEvictPods[rp.Pod.UID] = &pendingEvictPod{rp: rp, throttledAt: now}
+			}
+			throttledCount++
+			continue
+		}
+
+		for _, pdb := range pdbs {
+			disruptions[native.GenerateUniqObjectNameKey(pdb)]++
+		}
+		delete(m.pendingEvictPods, rp.Pod.UID)
+		admitted = append(admitted, rp)
+	}
+
+	if throttledCount > 0 {
+		general.Infof(" %d pods are throttled by eviction budgets, %d pods are pending totally",
+			throttledCount, len(m.pendingEvictPods))
+	}
+	return admitted
+}
+
+// getPodDisruptionBudgets returns PodDisruptionBudgets matching the given pod, and
+// PodDisruptionBudgets are listed at most once for each namespace in a round. If listing fails,
+// the pod is considered as not covered by any PodDisruptionBudget since eviction api still
+// respects PodDisruptionBudgets.
+func (m *EvictionManger) getPodDisruptionBudgets(pod *v1.Pod, pdbCache map[string][]policyv1.PodDisruptionBudget) []*policyv1.PodDisruptionBudget {
+	pdbList, ok := pdbCache[pod.Namespace]
+	if !ok {
+		pdbs, err := m.genericClient.KubeClient.PolicyV1().PodDisruptionBudgets(pod.Namespace).List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			general.Warningf(" failed to list pdb in namespace %s: %v", pod.Namespace, err)
+		} else {
+			pdbList = pdbs.Items
+		}
+		pdbCache[pod.Namespace] = pdbList
+	}
+
+	var pdbs []*policyv1.PodDisruptionBudget
+	for i := range pdbList {
+		// a nil selector matches nothing, and an empty selector matches all pods in the namespace
+		selector, err := metav1.LabelSelectorAsSelector(pdbList[i].Spec.Selector)
+		if err != nil {
+			general.Warningf(" invalid selector of pdb %s: %v", native.GenerateUniqObjectNameKey(&pdbList[i]), err)
+			continue
+		}
+		if selector.Matches(labels.Set(pod.Labels)) {
+			pdbs = append(pdbs, &pdbList[i])
+		}
+	}
+	return pdbs
+}
+
+func (m *EvictionManger) reportEvictionThrottled(rp *rule.RuledEvictPod, scope string) {
+	general.Infof(" eviction for pod %s/%s is throttled by %s budget", rp.Pod.Namespace, rp.Pod.Name, scope)
+	_ = m.emitter.StoreInt64(MetricsNameEvictionThrottled, 1, metrics.MetricTypeNameCount,
+		metrics.MetricTag{Key: "scope", Val: scope},
+		metrics.MetricTag{Key: "pod_ns", Val: rp.Pod.Namespace},
+		metrics.MetricTag{Key: "pod_name", Val: rp.Pod.Name},
+		metrics.MetricTag{Key: "plugin_name", Val: rp.EvictionPluginName})
+	if m.recorder != nil {
+		m.recorder.Eventf(rp.Pod, nil, v1.EventTypeWarning, consts.EventReasonEvictThrottled, consts.EventActionEvicting,
+			"Eviction is throttled by %s budget; reason: %s", scope, rp.Reason)
+	}
+}
+
+// getKillerNameForPod returns name of the killer that will be used to evict the given pod
+func (m *EvictionManger) getKillerNameForPod(pod *v1.Pod) string {
+	if qosLevel, err := m.conf.QoSConfiguration.GetQoSLevelForPod(pod); err == nil {
+		if killerName, ok := m.conf.QoSPodKillers[qosLevel]; ok {
+			return killerName
+		}
+	}
+	return m.conf.PodKiller
 }
 
-// getEvictPodFromCandidates returns the most critical pod to be evicted
-func (m *EvictionManger) getEvictPodFromCandidates(candidateEvictPods map[string]*rule.RuledEvictPod) *rule.RuledEvictPod {
+// getEvictPodFromCandidates returns the most critical pod to be evicted, and shadow indicates
+// whether the candidates come from plugins in shadow mode
+func (m *EvictionManger) getEvictPodFromCandidates(candidateEvictPods map[string]*rule.RuledEvictPod, shadow bool) *rule.RuledEvictPod {
 	rpList := rule.RuledEvictPodList{}
 	for _, rp := range candidateEvictPods {
 		// only killing pods that pass candidate validation
@@ -700,7 +950,16 @@ func (m *EvictionManger) getEvictPodFromCandidates(candidateEvictPods map[string
 	}
 
 	m.killStrategy.CandidateSort(rpList)
-	return rpList[len(rpList)-1]
+	rp := rpList[len(rpList)-1]
+
+	// report the comparator chain in eviction events by the way of eviction reason
+	comparators := strings.Join(m.killStrategy.CandidateComparators(), ",")
+	rp.Reason = fmt.Sprintf("%s; chosen among %d candidates by comparators [%s]", rp.Reason, len(rpList), comparators)
+	_ = m.emitter.StoreInt64(MetricsNameCandidateChosen, 1, metrics.MetricTypeNameCount,
+		metrics.MetricTag{Key: "comparators", Val: comparators},
+		metrics.MetricTag{Key: "plugin_name", Val: rp.EvictionPluginName},
+		metrics.MetricTag{Key: "shadow", Val: strconv.FormatBool(shadow)})
+	return rp
 }
 
 func (m *EvictionManger) getEvictionRecords(ctx context.Context, pods map[string]*v1.Pod) map[string]*pluginapi.EvictionRecord {
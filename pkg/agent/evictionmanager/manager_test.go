@@ -19,16 +19,20 @@ package evictionmanager
 import (
 	"context"
 	"fmt"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/tools/events"
+	testingclock "k8s.io/utils/clock/testing"
 
 	"github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
 	apiconsts "github.com/kubewharf/katalyst-api/pkg/consts"
@@ -36,6 +40,7 @@ import (
 	endpointpkg "github.com/kubewharf/katalyst-core/pkg/agent/evictionmanager/endpoint"
 	"github.com/kubewharf/katalyst-core/pkg/agent/evictionmanager/podkiller"
 	"github.com/kubewharf/katalyst-core/pkg/agent/evictionmanager/record"
+	"github.com/kubewharf/katalyst-core/pkg/agent/evictionmanager/rule"
 	"github.com/kubewharf/katalyst-core/pkg/client"
 	"github.com/kubewharf/katalyst-core/pkg/config"
 	"github.com/kubewharf/katalyst-core/pkg/config/agent"
@@ -380,6 +385,47 @@ func TestEvictionManger_collectEvictionResult(t *testing.T) {
 	}
 }
 
+type recordingPodKiller struct {
+	podkiller.DummyPodKiller
+	evicted sets.String
+}
+
+func (r *recordingPodKiller) EvictPods(rpList rule.RuledEvictPodList) error {
+	for _, rp := range rpList {
+		r.evicted.Insert(rp.Pod.Name)
+	}
+	return nil
+}
+
+func TestEvictionManger_shadowMode(t *testing.T) {
+	t.Parallel()
+
+	mgr := makeEvictionManager(t)
+	mgr.conf.GetDynamicConfiguration().ShadowModePlugins = []string{"plugin1"}
+
+	collector, _ := mgr.collectEvictionResult(context.Background(), pods)
+	getNames := func(rpMap map[string]*rule.RuledEvictPod) sets.String {
+		names := sets.String{}
+		for _, rp := range rpMap {
+			names.Insert(rp.Pod.Name)
+		}
+		return names
+	}
+	assert.Equal(t, sets.NewString("pod-3"), getNames(collector.getSoftEvictPods()))
+	assert.Equal(t, sets.NewString("pod-3"), getNames(collector.getForceEvictPods()))
+	assert.Equal(t, sets.NewString("pod-1", "pod-5"), getNames(collector.getShadowSoftEvictPods()))
+	assert.Equal(t, sets.NewString("pod-2"), getNames(collector.getShadowForceEvictPods()))
+
+	shadowKiller := &recordingPodKiller{evicted: sets.NewString()}
+	mgr.shadowPodKiller = shadowKiller
+	mgr.shadowKillQueue = rule.NewFIFOEvictionQueue(-1)
+	assert.NoError(t, mgr.doShadowEvict(collector.getShadowSoftEvictPods(), collector.getShadowForceEvictPods()))
+
+	// one candidate is chosen from soft evict pods, and all force evict pods are recorded
+	assert.Equal(t, 2, shadowKiller.evicted.Len())
+	assert.True(t, shadowKiller.evicted.Has("pod-2"))
+}
+
 // Test_initializeQoSAwareKiller tests the initialization of QoSAwareKiller
 func Test_initializeQoSAwareKiller(t *testing.T) {
 	t.Parallel()
@@ -736,3 +782,171 @@ func TestEvictionManager_Run(t *testing.T) {
 	assert.Contains(t, res, general.HealthzCheckName(reportTaintHealthCheckName))
 	assert.Contains(t, res, general.HealthzCheckName(evictionManagerHealthCheckName))
 }
+
+type fakeBudgetKiller struct {
+	podkiller.DummyKiller
+
+	mutex     sync.Mutex
+	failed    sets.String
+	suspended sets.String
+	evicted   sets.String
+}
+
+func (f *fakeBudgetKiller) Evict(_ context.Context, pod *v1.Pod, _ int64, _, _ string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.failed.Has(pod.Name) {
+		return fmt.Errorf("failed to evict %v", pod.Name)
+	} else if f.suspended.Has(pod.Name) {
+		return nil
+	}
+	f.evicted.Insert(pod.Name)
+	return nil
+}
+
+func (f *fakeBudgetKiller) IsSuspended(pod *v1.Pod) bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return f.suspended.Has(pod.Name)
+}
+
+func (f *fakeBudgetKiller) popEvicted() sets.String {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	evicted := f.evicted
+	f.evicted = sets.NewString()
+	return evicted
+}
+
+func makeRuledEvictPodForBudget(name, app string) *rule.RuledEvictPod {
+	return &rule.RuledEvictPod{
+		EvictPod: &pluginapi.EvictPod{
+			Pod: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: "default",
+					UID:       types.UID(name),
+					Labels:    map[string]string{"app": app},
+				},
+			},
+			Reason:             "test",
+			EvictionPluginName: "plugin1",
+		},
+	}
+}
+
+func TestEvictionManager_killWithBudget(t *testing.T) {
+	t.Parallel()
+
+	mgr := makeEvictionManager(t)
+	now := time.Now()
+	fakeClock := testingclock.NewFakeClock(now)
+	mgr.clock = fakeClock
+	mgr.killQueue = rule.NewFIFOEvictionQueue(-1)
+	mgr.killBudget = rule.NewSlidingWindowEvictionBudget(time.Minute, 2, nil, 0, mgr.conf.QoSConfiguration)
+	killer := &fakeBudgetKiller{failed: sets.NewString("p-2"), suspended: sets.NewString(), evicted: sets.NewString()}
+	mgr.killer = newBudgetChargingKiller(killer, mgr.killBudget, fakeClock)
+	mgr.podKiller = podkiller.NewSynchronizedPodKiller(mgr.killer)
+	mgr.genericClient = &client.GenericClientSet{KubeClient: fake.NewSimpleClientset(&policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Name: "pdb", Namespace: "default"},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "pdb"}},
+		},
+		Status: policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: 1},
+	})}
+
+	getPendingNames := func() sets.String {
+		names := sets.NewString()
+		for _, pending := range mgr.pendingEvictPods {
+			names.Insert(pending.rp.Pod.Name)
+		}
+		return names
+	}
+
+	// p-3 is throttled by node budget, and the failed eviction of p-2 gives back its budget
+	_ = mgr.killWithRules(rule.RuledEvictPodList{
+		makeRuledEvictPodForBudget("p-1", ""), makeRuledEvictPodForBudget("p-2", ""), makeRuledEvictPodForBudget("p-3", ""),
+	})
+	assert.Equal(t, sets.NewString("p-1"), killer.popEvicted())
+	assert.Equal(t, sets.NewString("p-3"), getPendingNames())
+
+	// pending p-3 requested again survives withdrawing of the kill queue, and it's retried before new pods
+	fakeClock.SetTime(now.Add(10 * time.Second))
+	_ = mgr.killWithRules(rule.RuledEvictPodList{makeRuledEvictPodForBudget("p-4", ""), makeRuledEvictPodForBudget("p-3", "")})
+	assert.Equal(t, sets.NewString("p-3"), killer.popEvicted())
+	assert.Equal(t, sets.NewString("p-4"), getPendingNames())
+
+	fakeClock.SetTime(now.Add(20 * time.Second))
+	_ = mgr.killWithRules(rule.RuledEvictPodList{makeRuledEvictPodForBudget("p-4", "")})
+	assert.Equal(t, 0, killer.popEvicted().Len())
+	assert.Equal(t, sets.NewString("p-4"), getPendingNames())
+
+	// budget of p-1 is refilled after the window
+	fakeClock.SetTime(now.Add(61 * time.Second))
+	_ = mgr.killWithRules(rule.RuledEvictPodList{makeRuledEvictPodForBudget("p-4", "")})
+	assert.Equal(t, sets.NewString("p-4"), killer.popEvicted())
+	assert.Equal(t, 0, getPendingNames().Len())
+
+	// p-6 is throttled by pdb, and in-flight p-7 is handed over to killer without being charged again
+	fakeClock.SetTime(now.Add(3 * time.Minute))
+	p7 := makeRuledEvictPodForBudget("p-7", "")
+	ok, _ := mgr.killBudget.TryReserve(p7.Pod, fakeClock.Now())
+	assert.True(t, ok)
+	_ = mgr.killWithRules(rule.RuledEvictPodList{
+		makeRuledEvictPodForBudget("p-5", "pdb"), makeRuledEvictPodForBudget("p-6", "pdb"), p7,
+	})
+	assert.Equal(t, sets.NewString("p-5", "p-7"), killer.popEvicted())
+	assert.Equal(t, sets.NewString("p-6"), getPendingNames())
+
+	// pending pods are dropped once they are not requested any more
+	fakeClock.SetTime(now.Add(3*time.Minute + 10*time.Second))
+	_ = mgr.killWithRules(nil)
+	assert.Equal(t, 0, killer.popEvicted().Len())
+	assert.Equal(t, 0, getPendingNames().Len())
+
+	// the budget reserved for a skipped eviction is given back
+	p8 := makeRuledEvictPodForBudget("p-8", "")
+	ok, _ = mgr.killBudget.TryReserve(p8.Pod, fakeClock.Now())
+	assert.True(t, ok)
+	mgr.killer.(podkiller.SkipAwareKiller).Skip(p8.Pod)
+	assert.False(t, mgr.killBudget.InFlight(p8.Pod, fakeClock.Now()))
+
+	// suspended p-9 is handed over to killer even if budgets run out, and it's never charged
+	killer.suspended.Insert("p-9")
+	_ = mgr.killWithRules(rule.RuledEvictPodList{makeRuledEvictPodForBudget("p-9", "")})
+	assert.Equal(t, 0, killer.popEvicted().Len())
+	assert.Equal(t, 0, getPendingNames().Len())
+	assert.False(t, mgr.killBudget.InFlight(makeRuledEvictPodForBudget("p-9", "").Pod, fakeClock.Now()))
+
+	fakeClock.SetTime(now.Add(4*time.Minute + time.Second))
+	_ = mgr.killWithRules(rule.RuledEvictPodList{
+		makeRuledEvictPodForBudget("p-9", ""), makeRuledEvictPodForBudget("p-10", ""), makeRuledEvictPodForBudget("p-11", ""),
+	})
+	assert.Equal(t, sets.NewString("p-10", "p-11"), killer.popEvicted())
+	assert.Equal(t, 0, getPendingNames().Len())
+}
+
+func TestEvictionManager_killWithBudgetDisabled(t *testing.T) {
+	t.Parallel()
+
+	mgr := makeEvictionManager(t)
+	mgr.killQueue = rule.NewFIFOEvictionQueue(-1)
+	mgr.killBudget = rule.NewSlidingWindowEvictionBudget(0, 1, nil, 0, mgr.conf.QoSConfiguration)
+	killer := &fakeBudgetKiller{failed: sets.NewString(), suspended: sets.NewString(), evicted: sets.NewString()}
+	mgr.killer = newBudgetChargingKiller(killer, mgr.killBudget, mgr.clock)
+	mgr.podKiller = podkiller.NewSynchronizedPodKiller(mgr.killer)
+
+	// pdbs are never listed if budgets are disabled
+	kubeClient := fake.NewSimpleClientset()
+	mgr.genericClient = &client.GenericClientSet{KubeClient: kubeClient}
+
+	_ = mgr.killWithRules(rule.RuledEvictPodList{
+		makeRuledEvictPodForBudget("p-1", "pdb"), makeRuledEvictPodForBudget("p-2", "pdb"),
+	})
+	assert.Equal(t, sets.NewString("p-1", "p-2"), killer.popEvicted())
+	assert.Equal(t, 0, len(mgr.pendingEvictPods))
+	assert.Equal(t, 0, len(kubeClient.Actions()))
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podkiller
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/klog/v2"
+
+	"github.com/kubewharf/katalyst-core/pkg/config"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+)
+
+const MetricsNameDryRunKillPod = "dryrun_kill_pod"
+
+// DryRunKiller implements Killer interface, it never kills any pod; instead,
+// it records which killer would have been used to evict the given pod
+// through metrics, events and audit records tagged with dry-run.
+type DryRunKiller struct {
+	killerName string
+	emitter    metrics.MetricEmitter
+	recorder   events.EventRecorder
+}
+
+// NewDryRunKillerInitFunc returns an InitFunc that builds a DryRunKiller
+// acting on behalf of the killer with the given name.
+func NewDryRunKillerInitFunc(killerName string) InitFunc {
+	return func(_ *config.Configuration, _ kubernetes.Interface, recorder events.EventRecorder, emitter metrics.MetricEmitter) (Killer, error) {
+		return &DryRunKiller{
+			killerName: killerName,
+			emitter:    emitter,
+			recorder:   recorder,
+		}, nil
+	}
+}
+
+func (d *DryRunKiller) Name() string { return consts.KillerNameDryRunKiller }
+
+func (d *DryRunKiller) Evict(_ context.Context, pod *v1.Pod, gracePeriodSeconds int64, reason, plugin string) error {
+	if pod == nil {
+		return fmt.Errorf("pod is nil")
+	}
+
+	klog.Infof("[dryrun-killer] pod %v/%v would be evicted by %v with graceful seconds %v, reason: %s",
+		pod.Namespace, pod.Name, d.killerName, gracePeriodSeconds, reason)
+
+	if d.recorder != nil {
+		d.recorder.Eventf(pod, nil, v1.EventTypeNormal, consts.EventReasonEvictDryRun, consts.EventActionEvicting,
+			"[DryRun] Pod would be evicted by %s; reason: %s", d.killerName, reason)
+	}
+	_ = d.emitter.StoreInt64(MetricsNameDryRunKillPod, 1, metrics.MetricTypeNameRaw,
+		metrics.MetricTag{Key: "killer", Val: d.killerName},
+		metrics.MetricTag{Key: "pod_ns", Val: pod.Namespace},
+		metrics.MetricTag{Key: "pod_name", Val: pod.Name},
+		metrics.MetricTag{Key: "plugin_name", Val: plugin})
+	publishEvictionEvent(pod, d.killerName, gracePeriodSeconds, reason, plugin, true)
+	return nil
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podkiller
+
+import (
+	"encoding/json"
+
+	"k8s.io/kubernetes/pkg/kubelet/checkpointmanager"
+	"k8s.io/kubernetes/pkg/kubelet/checkpointmanager/checksum"
+)
+
+const freezeKillerCheckpoint = "freeze_killer_checkpoint"
+
+// suspendedState is the original state of a pod before it's suspended, and
+// it's needed to resume the pod.
+type suspendedState struct {
+	CPUPeriod uint64 `json:"cpuPeriod,omitempty"`
+	CPUQuota  int64  `json:"cpuQuota,omitempty"`
+}
+
+// freezeKillerCheckpointEntry records a suspended pod, and timestamps are kept
+// in unix nanoseconds to make checksum stable across marshalling.
+type freezeKillerCheckpointEntry struct {
+	Namespace       string          `json:"namespace"`
+	Name            string          `json:"name"`
+	UID             string          `json:"uid"`
+	FirstSuspended  int64           `json:"firstSuspended"`
+	LastRequestedAt int64           `json:"lastRequestedAt"`
+	State           *suspendedState `json:"state,omitempty"`
+}
+
+// freezeKillerCheckpointData holds suspended pods of freeze-killer and its checksum
+type freezeKillerCheckpointData struct {
+	Mode     string                        `json:"mode"`
+	Entries  []freezeKillerCheckpointEntry `json:"entries"`
+	Checksum checksum.Checksum             `json:"checksum"`
+}
+
+var _ checkpointmanager.Checkpoint = &freezeKillerCheckpointData{}
+
+func (d *freezeKillerCheckpointData) MarshalCheckpoint() ([]byte, error) {
+	d.Checksum = checksum.New(d.Entries)
+	return json.Marshal(*d)
+}
+
+func (d *freezeKillerCheckpointData) UnmarshalCheckpoint(blob []byte) error {
+	return json.Unmarshal(blob, d)
+}
+
+func (d *freezeKillerCheckpointData) VerifyChecksum() error {
+	return d.Checksum.Verify(d.Entries)
+}
@@ -0,0 +1,467 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podkiller
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/kubelet/checkpointmanager"
+	cpmerrors "k8s.io/kubernetes/pkg/kubelet/checkpointmanager/errors"
+
+	"github.com/kubewharf/katalyst-core/pkg/config"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/cgroup/common"
+	cgroupmgr "github.com/kubewharf/katalyst-core/pkg/util/cgroup/manager"
+)
+
+const (
+	MetricsNameFreezePod = "freeze_pod"
+
+	freezeKillerReleasePeriod = 10 * time.Second
+	// freezeKillerThrottledCPUQuota is the cpu quota in each period for hard-throttled pods
+	freezeKillerThrottledCPUQuota = 1000
+)
+
+// podSuspender suspends and resumes all processes of a pod.
+type podSuspender interface {
+	// Suspend returns the original state of the pod, which will be used to resume it.
+	Suspend(pod *v1.Pod) (*suspendedState, error)
+	Resume(pod *v1.Pod, state *suspendedState) error
+}
+
+func newPodSuspender(mode string) (podSuspender, error) {
+	switch mode {
+	case consts.FreezeKillerModeFreeze:
+		return &cgroupFreezer{}, nil
+	case consts.FreezeKillerModeThrottle:
+		return &cpuThrottler{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported freeze killer mode %v", mode)
+	}
+}
+
+// cgroupFreezer suspends pods by cgroup.freeze for cgroupv2 or freezer for cgroupv1.
+type cgroupFreezer struct{}
+
+func (c *cgroupFreezer) Suspend(pod *v1.Pod) (*suspendedState, error) {
+	return nil, cgroupmgr.ApplyFreezerForPod(string(pod.UID), true)
+}
+
+func (c *cgroupFreezer) Resume(pod *v1.Pod, _ *suspendedState) error {
+	return cgroupmgr.ApplyFreezerForPod(string(pod.UID), false)
+}
+
+// cpuThrottler suspends pods by setting a tiny cpu quota, and restores the
+// original quota when resuming.
+type cpuThrottler struct{}
+
+func (c *cpuThrottler) Suspend(pod *v1.Pod) (*suspendedState, error) {
+	absCgroupPath, err := common.GetPodAbsCgroupPath(common.CgroupSubsysCPU, string(pod.UID))
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := cgroupmgr.GetCPUWithAbsolutePath(absCgroupPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cgroupmgr.ApplyCPUWithAbsolutePath(absCgroupPath, &common.CPUData{
+		CpuPeriod: stats.CpuPeriod,
+		CpuQuota:  freezeKillerThrottledCPUQuota,
+	}); err != nil {
+		return nil, err
+	}
+
+	return &suspendedState{CPUPeriod: stats.CpuPeriod, CPUQuota: stats.CpuQuota}, nil
+}
+
+func (c *cpuThrottler) Resume(pod *v1.Pod, state *suspendedState) error {
+	if state == nil {
+		return nil
+	}
+
+	absCgroupPath, err := common.GetPodAbsCgroupPath(common.CgroupSubsysCPU, string(pod.UID))
+	if err != nil {
+		return err
+	}
+
+	// unlimited quota is represented by -1 in cgroupv1 and max in cgroupv2
+	quota := state.CPUQuota
+	if quota <= 0 || quota == math.MaxInt64 {
+		quota = -1
+	}
+	return cgroupmgr.ApplyCPUWithAbsolutePath(absCgroupPath, &common.CPUData{
+		CpuPeriod: state.CPUPeriod,
+		CpuQuota:  quota,
+	})
+}
+
+type suspendedPodInfo struct {
+	pod             *v1.Pod
+	state           *suspendedState
+	firstSuspended  time.Time
+	lastRequestedAt time.Time
+}
+
+// releasedPodInfo remembers when a released pod was first suspended, so that
+// escalation can't be bypassed by pressure which clears and comes back quickly.
+type releasedPodInfo struct {
+	firstSuspended time.Time
+	releasedAt     time.Time
+}
+
+// FreezeKiller implements Killer interface, it suspends pods (either by freezing or
+// hard-throttling) instead of killing them, so that batch pods (i.e. reclaimed_cores)
+// can survive short pressure spikes. If eviction requests for a pod persist for
+// longer than the escalation duration, the pod will be resumed and then evicted by
+// the escalation killer; otherwise the pod will be resumed once no more eviction
+// requests come within the release timeout.
+//
+// Suspended pods are checkpointed, so that they can still be resumed or escalated
+// after the agent restarts.
+type FreezeKiller struct {
+	mode               string
+	escalationDuration time.Duration
+	releaseTimeout     time.Duration
+	escalationKiller   Killer
+	suspender          podSuspender
+
+	emitter  metrics.MetricEmitter
+	recorder events.EventRecorder
+
+	// checkpointManager is nil if checkpoint is disabled
+	checkpointManager checkpointmanager.CheckpointManager
+	runOnce           sync.Once
+
+	mutex         sync.Mutex
+	suspendedPods map[string]*suspendedPodInfo
+	releasedPods  map[string]*releasedPodInfo
+}
+
+var (
+	_ RunnableKiller   = &FreezeKiller{}
+	_ SuspendingKiller = &FreezeKiller{}
+)
+
+// NewFreezeKillerInitFunc returns an InitFunc that builds a FreezeKiller, and the
+// escalation killer is built from the given initializers. Since suspended pods are
+// node-level states, the returned InitFunc always returns the same FreezeKiller,
+// even if it's used for different QoS levels.
+func NewFreezeKillerInitFunc(initializers map[string]InitFunc) InitFunc {
+	var (
+		mutex  sync.Mutex
+		killer *FreezeKiller
+	)
+
+	return func(conf *config.Configuration, client kubernetes.Interface, recorder events.EventRecorder, emitter metrics.MetricEmitter) (Killer, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if killer != nil {
+			return killer, nil
+		}
+
+		escalationKillerName := conf.FreezeKillerEscalationKiller
+		if escalationKillerName == consts.KillerNameFreezeKiller {
+			return nil, fmt.Errorf("freeze killer can't be escalated to itself")
+		}
+
+		initializer, ok := initializers[escalationKillerName]
+		if !ok {
+			return nil, fmt.Errorf("unsupported escalation killer %v", escalationKillerName)
+		}
+
+		escalationKiller, err := initializer(conf, client, recorder, emitter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to init escalation killer %v: %v", escalationKillerName, err)
+		}
+
+		suspender, err := newPodSuspender(conf.FreezeKillerMode)
+		if err != nil {
+			return nil, err
+		}
+
+		f := newFreezeKiller(conf.FreezeKillerMode, conf.FreezeKillerEscalationDuration, conf.FreezeKillerReleaseTimeout,
+			escalationKiller, suspender, recorder, emitter)
+		if conf.FreezeKillerCheckpointDir != "" {
+			f.checkpointManager, err = checkpointmanager.NewCheckpointManager(conf.FreezeKillerCheckpointDir)
+			if err != nil {
+				return nil, fmt.Errorf("failed to initialize checkpoint manager: %v", err)
+			}
+
+			if err := f.restoreCheckpoint(); err != nil {
+				// suspended pods in a broken checkpoint can't be resumed by us anymore
+				klog.Errorf("[freeze-killer] failed to restore checkpoint: %v", err)
+			}
+		}
+
+		killer = f
+		return f, nil
+	}
+}
+
+func newFreezeKiller(mode string, escalationDuration, releaseTimeout time.Duration, escalationKiller Killer,
+	suspender podSuspender, recorder events.EventRecorder, emitter metrics.MetricEmitter,
+) *FreezeKiller {
+	return &FreezeKiller{
+		mode:               mode,
+		escalationDuration: escalationDuration,
+		releaseTimeout:     releaseTimeout,
+		escalationKiller:   escalationKiller,
+		suspender:          suspender,
+		emitter:            emitter,
+		recorder:           recorder,
+		suspendedPods:      make(map[string]*suspendedPodInfo),
+		releasedPods:       make(map[string]*releasedPodInfo),
+	}
+}
+
+func (f *FreezeKiller) Name() string { return consts.KillerNameFreezeKiller }
+
+// Run releases idle pods periodically until the context is done.
+func (f *FreezeKiller) Run(ctx context.Context) {
+	f.runOnce.Do(func() {
+		RunKiller(ctx, f.escalationKiller)
+		go wait.Until(f.releaseIdlePods, freezeKillerReleasePeriod, ctx.Done())
+	})
+}
+
+func (f *FreezeKiller) Evict(ctx context.Context, pod *v1.Pod, gracePeriodSeconds int64, reason, plugin string) error {
+	if pod == nil {
+		return fmt.Errorf("pod is nil")
+	}
+
+	now := time.Now()
+	podUID := string(pod.UID)
+
+	f.mutex.Lock()
+	info, ok := f.suspendedPods[podUID]
+	if !ok {
+		firstSuspended := now
+		if released, ok := f.releasedPods[podUID]; ok {
+			firstSuspended = released.firstSuspended
+			delete(f.releasedPods, podUID)
+		}
+
+		if now.Sub(firstSuspended) >= f.escalationDuration {
+			f.mutex.Unlock()
+			return f.escalate(ctx, pod, gracePeriodSeconds, reason, plugin)
+		}
+
+		state, err := f.suspender.Suspend(pod)
+		if err != nil {
+			f.mutex.Unlock()
+			klog.Errorf("[freeze-killer] failed to %v pod %v/%v: %v, escalate to %v",
+				f.mode, pod.Namespace, pod.Name, err, f.escalationKiller.Name())
+			f.emitFreezeMetric(pod, "failed", plugin)
+			return f.escalationKiller.Evict(ctx, pod, gracePeriodSeconds, reason, plugin)
+		}
+
+		f.suspendedPods[podUID] = &suspendedPodInfo{pod: pod, state: state, firstSuspended: firstSuspended, lastRequestedAt: now}
+		f.writeCheckpointLocked()
+		f.mutex.Unlock()
+
+		klog.Infof("[freeze-killer] %v pod %v/%v, reason: %s", f.mode, pod.Namespace, pod.Name, reason)
+		if f.recorder != nil {
+			f.recorder.Eventf(pod, nil, v1.EventTypeNormal, consts.EventReasonPodFrozen, consts.EventActionFreezing,
+				"Pod is suspended by %s for at most %v; reason: %s", f.mode, f.escalationDuration-now.Sub(firstSuspended), reason)
+		}
+		f.emitFreezeMetric(pod, "frozen", plugin)
+		return nil
+	}
+
+	info.lastRequestedAt = now
+	if now.Sub(info.firstSuspended) < f.escalationDuration {
+		f.mutex.Unlock()
+		return nil
+	}
+	delete(f.suspendedPods, podUID)
+	f.writeCheckpointLocked()
+	f.mutex.Unlock()
+
+	// processes must be resumed to handle termination signals gracefully
+	if err := f.suspender.Resume(pod, info.state); err != nil {
+		klog.Warningf("[freeze-killer] failed to resume pod %v/%v before escalation: %v", pod.Namespace, pod.Name, err)
+	}
+	return f.escalate(ctx, pod, gracePeriodSeconds, reason, plugin)
+}
+
+// IsSuspended returns whether the given pod is still suspended, i.e. it hasn't been escalated
+// or resumed yet.
+func (f *FreezeKiller) IsSuspended(pod *v1.Pod) bool {
+	if pod == nil {
+		return false
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	_, ok := f.suspendedPods[string(pod.UID)]
+	return ok
+}
+
+func (f *FreezeKiller) escalate(ctx context.Context, pod *v1.Pod, gracePeriodSeconds int64, reason, plugin string) error {
+	klog.Infof("[freeze-killer] pressure persists for pod %v/%v over %v, escalate to %v",
+		pod.Namespace, pod.Name, f.escalationDuration, f.escalationKiller.Name())
+	f.emitFreezeMetric(pod, "escalated", plugin)
+	return f.escalationKiller.Evict(ctx, pod, gracePeriodSeconds, reason, plugin)
+}
+
+// releaseIdlePods resumes those pods without eviction requests within release timeout,
+// which means that the pressure has already been cleared.
+func (f *FreezeKiller) releaseIdlePods() {
+	now := time.Now()
+
+	f.mutex.Lock()
+	for podUID, released := range f.releasedPods {
+		if now.Sub(released.releasedAt) >= f.escalationDuration {
+			delete(f.releasedPods, podUID)
+		}
+	}
+
+	var idlePods []*suspendedPodInfo
+	for podUID, info := range f.suspendedPods {
+		if now.Sub(info.lastRequestedAt) >= f.releaseTimeout {
+			idlePods = append(idlePods, info)
+			delete(f.suspendedPods, podUID)
+			f.releasedPods[podUID] = &releasedPodInfo{firstSuspended: info.firstSuspended, releasedAt: now}
+		}
+	}
+	if len(idlePods) > 0 {
+		f.writeCheckpointLocked()
+	}
+	f.mutex.Unlock()
+
+	for _, info := range idlePods {
+		pod := info.pod
+		// pods may have been deleted already, so we just log the errors
+		if err := f.suspender.Resume(pod, info.state); err != nil {
+			klog.Warningf("[freeze-killer] failed to resume pod %v/%v: %v", pod.Namespace, pod.Name, err)
+			continue
+		}
+
+		klog.Infof("[freeze-killer] resume pod %v/%v since pressure has cleared", pod.Namespace, pod.Name)
+		if f.recorder != nil {
+			f.recorder.Eventf(pod, nil, v1.EventTypeNormal, consts.EventReasonPodThawed, consts.EventActionFreezing,
+				"Pod is resumed since pressure has cleared")
+		}
+		f.emitFreezeMetric(pod, "thawed", "")
+	}
+}
+
+// writeCheckpointLocked persists suspended pods, and it must be called with mutex held.
+func (f *FreezeKiller) writeCheckpointLocked() {
+	if f.checkpointManager == nil {
+		return
+	}
+
+	data := &freezeKillerCheckpointData{
+		Mode:    f.mode,
+		Entries: make([]freezeKillerCheckpointEntry, 0, len(f.suspendedPods)),
+	}
+	for podUID, info := range f.suspendedPods {
+		data.Entries = append(data.Entries, freezeKillerCheckpointEntry{
+			Namespace:       info.pod.Namespace,
+			Name:            info.pod.Name,
+			UID:             podUID,
+			FirstSuspended:  info.firstSuspended.UnixNano(),
+			LastRequestedAt: info.lastRequestedAt.UnixNano(),
+			State:           info.state,
+		})
+	}
+	sort.Slice(data.Entries, func(i, j int) bool { return data.Entries[i].UID < data.Entries[j].UID })
+
+	if err := f.checkpointManager.CreateCheckpoint(freezeKillerCheckpoint, data); err != nil {
+		klog.Errorf("[freeze-killer] failed to write checkpoint file %q: %v", freezeKillerCheckpoint, err)
+	}
+}
+
+// restoreCheckpoint loads suspended pods from checkpoint, and those pods will be
+// resumed or escalated as usual. If freeze-killer mode has changed, they are resumed
+// by the suspender of the previous mode directly.
+func (f *FreezeKiller) restoreCheckpoint() error {
+	data := &freezeKillerCheckpointData{}
+	if err := f.checkpointManager.GetCheckpoint(freezeKillerCheckpoint, data); err != nil {
+		if err == cpmerrors.ErrCheckpointNotFound {
+			return nil
+		}
+		return err
+	}
+
+	var previousSuspender podSuspender
+	if data.Mode != f.mode {
+		var err error
+		previousSuspender, err = newPodSuspender(data.Mode)
+		if err != nil {
+			return err
+		}
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	for _, entry := range data.Entries {
+		pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Namespace: entry.Namespace,
+			Name:      entry.Name,
+			UID:       types.UID(entry.UID),
+		}}
+
+		if previousSuspender != nil {
+			if err := previousSuspender.Resume(pod, entry.State); err != nil {
+				klog.Warningf("[freeze-killer] failed to resume pod %v/%v suspended by %v: %v",
+					pod.Namespace, pod.Name, data.Mode, err)
+			}
+			continue
+		}
+
+		klog.Infof("[freeze-killer] restore suspended pod %v/%v", pod.Namespace, pod.Name)
+		f.suspendedPods[entry.UID] = &suspendedPodInfo{
+			pod:             pod,
+			state:           entry.State,
+			firstSuspended:  time.Unix(0, entry.FirstSuspended),
+			lastRequestedAt: time.Unix(0, entry.LastRequestedAt),
+		}
+	}
+
+	if previousSuspender != nil {
+		f.writeCheckpointLocked()
+	}
+	return nil
+}
+
+func (f *FreezeKiller) emitFreezeMetric(pod *v1.Pod, state, plugin string) {
+	_ = f.emitter.StoreInt64(MetricsNameFreezePod, 1, metrics.MetricTypeNameRaw,
+		metrics.MetricTag{Key: "state", Val: state},
+		metrics.MetricTag{Key: "mode", Val: f.mode},
+		metrics.MetricTag{Key: "pod_ns", Val: pod.Namespace},
+		metrics.MetricTag{Key: "pod_name", Val: pod.Name},
+		metrics.MetricTag{Key: "plugin_name", Val: plugin})
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podkiller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/kubernetes/pkg/kubelet/checkpointmanager"
+
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+)
+
+type fakeSuspender struct {
+	suspendErr error
+	suspended  map[string]bool
+}
+
+func (f *fakeSuspender) Suspend(pod *v1.Pod) (*suspendedState, error) {
+	if f.suspendErr != nil {
+		return nil, f.suspendErr
+	}
+	f.suspended[string(pod.UID)] = true
+	return &suspendedState{CPUPeriod: 100000, CPUQuota: -1}, nil
+}
+
+func (f *fakeSuspender) Resume(pod *v1.Pod, _ *suspendedState) error {
+	delete(f.suspended, string(pod.UID))
+	return nil
+}
+
+type countingKiller struct {
+	evicted []string
+}
+
+func (c *countingKiller) Name() string { return consts.KillerNameEvictionKiller }
+
+func (c *countingKiller) Evict(_ context.Context, pod *v1.Pod, _ int64, _, _ string) error {
+	c.evicted = append(c.evicted, pod.Name)
+	return nil
+}
+
+func TestFreezeKiller_Evict(t *testing.T) {
+	t.Parallel()
+
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod-1", Namespace: "default", UID: "uid-1"}}
+
+	suspender := &fakeSuspender{suspended: map[string]bool{}}
+	escalation := &countingKiller{}
+	f := newFreezeKiller(consts.FreezeKillerModeFreeze, time.Hour, time.Hour, escalation,
+		suspender, events.NewFakeRecorder(10), metrics.DummyMetrics{})
+	assert.Equal(t, consts.KillerNameFreezeKiller, f.Name())
+
+	// the first request suspends the pod, and the following ones keep it suspended
+	assert.NoError(t, f.Evict(context.Background(), pod, 0, "test", "plugin"))
+	f.suspendedPods["uid-1"].lastRequestedAt = time.Now().Add(-time.Minute)
+	assert.NoError(t, f.Evict(context.Background(), pod, 0, "test", "plugin"))
+	assert.True(t, suspender.suspended["uid-1"])
+	assert.True(t, f.IsSuspended(pod))
+	assert.WithinDuration(t, time.Now(), f.suspendedPods["uid-1"].lastRequestedAt, time.Second)
+	assert.Empty(t, escalation.evicted)
+
+	// pressure persists over escalation duration
+	f.suspendedPods["uid-1"].firstSuspended = time.Now().Add(-2 * time.Hour)
+	assert.NoError(t, f.Evict(context.Background(), pod, 0, "test", "plugin"))
+	assert.False(t, suspender.suspended["uid-1"])
+	assert.Equal(t, []string{"pod-1"}, escalation.evicted)
+	assert.Empty(t, f.suspendedPods)
+	assert.False(t, f.IsSuspended(pod))
+
+	// pressure clears
+	assert.NoError(t, f.Evict(context.Background(), pod, 0, "test", "plugin"))
+	f.releaseIdlePods()
+	assert.True(t, suspender.suspended["uid-1"])
+	f.suspendedPods["uid-1"].lastRequestedAt = time.Now().Add(-2 * time.Hour)
+	f.releaseIdlePods()
+	assert.False(t, suspender.suspended["uid-1"])
+	assert.Empty(t, f.suspendedPods)
+
+	// pressure comes back soon, and the pod is suspended since the first time
+	firstSuspended := f.releasedPods["uid-1"].firstSuspended
+	assert.NoError(t, f.Evict(context.Background(), pod, 0, "test", "plugin"))
+	assert.True(t, suspender.suspended["uid-1"])
+	assert.Equal(t, firstSuspended, f.suspendedPods["uid-1"].firstSuspended)
+	assert.Empty(t, f.releasedPods)
+
+	// pods suspended for too long are escalated directly once pressure comes back
+	f.suspendedPods["uid-1"].lastRequestedAt = time.Now().Add(-2 * time.Hour)
+	f.releaseIdlePods()
+	f.releasedPods["uid-1"].firstSuspended = time.Now().Add(-2 * time.Hour)
+	assert.NoError(t, f.Evict(context.Background(), pod, 0, "test", "plugin"))
+	assert.False(t, suspender.suspended["uid-1"])
+	assert.Equal(t, []string{"pod-1", "pod-1"}, escalation.evicted)
+	assert.Empty(t, f.suspendedPods)
+
+	// failing to suspend escalates directly
+	suspender.suspendErr = fmt.Errorf("test")
+	assert.NoError(t, f.Evict(context.Background(), pod, 0, "test", "plugin"))
+	assert.Equal(t, []string{"pod-1", "pod-1", "pod-1"}, escalation.evicted)
+}
+
+func TestFreezeKiller_Checkpoint(t *testing.T) {
+	t.Parallel()
+
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod-1", Namespace: "default", UID: "uid-1"}}
+	checkpointDir := t.TempDir()
+
+	newKiller := func(mode string, suspender podSuspender) *FreezeKiller {
+		f := newFreezeKiller(mode, time.Hour, time.Hour, &countingKiller{},
+			suspender, events.NewFakeRecorder(10), metrics.DummyMetrics{})
+		var err error
+		f.checkpointManager, err = checkpointmanager.NewCheckpointManager(checkpointDir)
+		assert.NoError(t, err)
+		assert.NoError(t, f.restoreCheckpoint())
+		return f
+	}
+
+	suspender := &fakeSuspender{suspended: map[string]bool{}}
+	f := newKiller(consts.FreezeKillerModeThrottle, suspender)
+	assert.NoError(t, f.Evict(context.Background(), pod, 0, "test", "plugin"))
+	firstSuspended := f.suspendedPods["uid-1"].firstSuspended
+
+	// suspended pods are restored after restart
+	restored := newKiller(consts.FreezeKillerModeThrottle, suspender)
+	assert.Len(t, restored.suspendedPods, 1)
+	info := restored.suspendedPods["uid-1"]
+	assert.Equal(t, "pod-1", info.pod.Name)
+	assert.Equal(t, "default", info.pod.Namespace)
+	assert.True(t, firstSuspended.Equal(info.firstSuspended))
+	assert.Equal(t, &suspendedState{CPUPeriod: 100000, CPUQuota: -1}, info.state)
+
+	// and they are resumed once pressure has cleared
+	info.lastRequestedAt = time.Now().Add(-2 * time.Hour)
+	restored.releaseIdlePods()
+	assert.False(t, suspender.suspended["uid-1"])
+	assert.Empty(t, newKiller(consts.FreezeKillerModeThrottle, suspender).suspendedPods)
+}
@@ -39,6 +39,7 @@ import (
 	"github.com/kubewharf/katalyst-core/pkg/config"
 	"github.com/kubewharf/katalyst-core/pkg/consts"
 	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/eventbus"
 )
 
 const (
@@ -57,6 +58,47 @@ type Killer interface {
 	Evict(ctx context.Context, pod *v1.Pod, gracePeriodSeconds int64, reason, plugin string) error
 }
 
+// RunnableKiller is a Killer with background routines, which are started along
+// with the pod killer and stopped once the given context is done.
+type RunnableKiller interface {
+	Killer
+
+	// Run starts background routines without blocking.
+	Run(ctx context.Context)
+}
+
+// SkipAwareKiller is a Killer that needs to know the evictions skipped by the pod killer,
+// e.g. to give back resources reserved for those evictions.
+type SkipAwareKiller interface {
+	Killer
+
+	// Skip is called instead of Evict if the given pod has already been deleted.
+	Skip(pod *v1.Pod)
+}
+
+// SuspendingKiller is a Killer that may suspend pods instead of evicting them.
+type SuspendingKiller interface {
+	Killer
+
+	// IsSuspended returns whether the given pod is suspended rather than evicted by the killer.
+	IsSuspended(pod *v1.Pod) bool
+}
+
+// IsPodSuspended returns whether the given pod is suspended by the given killer.
+func IsPodSuspended(killer Killer, pod *v1.Pod) bool {
+	if s, ok := killer.(SuspendingKiller); ok {
+		return s.IsSuspended(pod)
+	}
+	return false
+}
+
+// RunKiller starts background routines of the given killer if it has any.
+func RunKiller(ctx context.Context, killer Killer) {
+	if r, ok := killer.(RunnableKiller); ok {
+		r.Run(ctx)
+	}
+}
+
 // DummyKiller is a stub implementation for Killer interface.
 type DummyKiller struct{}
 
@@ -108,7 +150,7 @@ func (e *EvictionAPIKiller) Evict(_ context.Context, pod *v1.Pod, gracePeriodSec
 		return e.client.PolicyV1beta1().Evictions(eviction.Namespace).Evict(context.Background(), eviction)
 	}
 
-	return evict(e.client, e.recorder, e.emitter, pod, gracePeriodSeconds, reason, plugin, evictPod)
+	return evict(e.client, e.recorder, e.emitter, e.Name(), pod, gracePeriodSeconds, reason, plugin, evictPod)
 }
 
 // DeletionAPIKiller implements Killer interface it evict those
@@ -137,7 +179,7 @@ func (d *DeletionAPIKiller) Evict(ctx context.Context, pod *v1.Pod, gracePeriodS
 		return d.client.CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, deleteOptions)
 	}
 
-	return evict(d.client, d.recorder, d.emitter, pod, gracePeriodSeconds, reason, plugin, evictPod)
+	return evict(d.client, d.recorder, d.emitter, d.Name(), pod, gracePeriodSeconds, reason, plugin, evictPod)
 }
 
 // getWaitingPeriod get waiting period from graceful period.
@@ -217,8 +259,8 @@ func deleteWithRetry(pod *v1.Pod, gracePeriod int64, timeoutDuration time.Durati
 }
 
 // evict all killer implementations will perform evict actions.
-func evict(client kubernetes.Interface, recorder events.EventRecorder, emitter metrics.MetricEmitter, pod *v1.Pod,
-	gracePeriodSeconds int64, reason, plugin string, evictPod func(_ *v1.Pod, gracePeriod int64) error,
+func evict(client kubernetes.Interface, recorder events.EventRecorder, emitter metrics.MetricEmitter, killerName string,
+	pod *v1.Pod, gracePeriodSeconds int64, reason, plugin string, evictPod func(_ *v1.Pod, gracePeriod int64) error,
 ) error {
 	timeoutDuration := getWaitingPeriod(gracePeriodSeconds)
 	klog.Infof("[killer] evict pod %v/%v with graceful seconds %v", pod.Namespace, pod.Name, gracePeriodSeconds)
@@ -243,6 +285,7 @@ func evict(client kubernetes.Interface, recorder events.EventRecorder, emitter m
 		metrics.MetricTag{Key: "pod_name", Val: pod.Name},
 		metrics.MetricTag{Key: "plugin_name", Val: plugin})
 	klog.Infof("[killer] successfully create eviction for pod %v/%v", pod.Namespace, pod.Name)
+	publishEvictionEvent(pod, killerName, gracePeriodSeconds, reason, plugin, false)
 
 	podArray := []*v1.Pod{pod}
 	_, err := waitForDeleted(client, podArray, timeoutDuration)
@@ -260,6 +303,23 @@ func evict(client kubernetes.Interface, recorder events.EventRecorder, emitter m
 	return nil
 }
 
+// publishEvictionEvent publishes eviction actions to event bus for auditing.
+func publishEvictionEvent(pod *v1.Pod, killerName string, gracePeriodSeconds int64, reason, plugin string, dryRun bool) {
+	_ = eventbus.GetDefaultEventBus().Publish(consts.TopicNameEviction, eventbus.EvictionEvent{
+		BaseEventImpl: eventbus.BaseEventImpl{
+			Time: time.Now(),
+		},
+		PodUID:             string(pod.UID),
+		PodNamespace:       pod.Namespace,
+		PodName:            pod.Name,
+		Plugin:             plugin,
+		Killer:             killerName,
+		Reason:             reason,
+		GracePeriodSeconds: gracePeriodSeconds,
+		DryRun:             dryRun,
+	})
+}
+
 // ContainerKiller implements Killer interface it actually does not evict pod but
 // stop containers in given pod directly.
 type ContainerKiller struct {
@@ -313,6 +373,7 @@ func (c *ContainerKiller) Evict(_ context.Context, pod *v1.Pod, gracePeriodSecon
 			metrics.MetricTag{Key: "plugin_name", Val: plugin})
 		klog.Infof("[killer] successfully kill container %v/%v for pod %v/%v", containerStatus.Name, containerStatus.ContainerID, pod.Namespace, pod.Name)
 	}
+	publishEvictionEvent(pod, c.Name(), gracePeriodSeconds, reason, plugin, false)
 	// TODO: do we have to wait for container being completely killed?
 
 	return nil
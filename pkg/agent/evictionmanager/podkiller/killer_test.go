@@ -64,7 +64,7 @@ func TestEvictionQueue(t *testing.T) {
 	err = deleteKiller.Evict(context.Background(), pods[1], 0, "test-api", "test")
 	require.NoError(t, err)
 
-	err = evict(ctx.Client.KubeClient, &events.FakeRecorder{}, metrics.DummyMetrics{}, pods[2],
+	err = evict(ctx.Client.KubeClient, &events.FakeRecorder{}, metrics.DummyMetrics{}, consts.KillerNameDeletionKiller, pods[2],
 		0, "test-api", "test", func(_ *v1.Pod, gracePeriod int64) error {
 			return fmt.Errorf("test")
 		})
@@ -176,3 +176,20 @@ func TestContainerKiller_Evict(t *testing.T) {
 		}
 	}
 }
+
+func TestDryRunKiller_Evict(t *testing.T) {
+	t.Parallel()
+
+	recorder := events.NewFakeRecorder(10)
+	killer, err := NewDryRunKillerInitFunc(consts.KillerNameEvictionKiller)(nil, nil, recorder, metrics.DummyMetrics{})
+	require.NoError(t, err)
+	assert.Equal(t, consts.KillerNameDryRunKiller, killer.Name())
+
+	err = killer.Evict(context.Background(), nil, 0, "test", "test")
+	require.Error(t, err)
+
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod-1", Namespace: "default"}}
+	err = killer.Evict(context.Background(), pod, 0, "test", "test")
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(recorder.Events))
+}
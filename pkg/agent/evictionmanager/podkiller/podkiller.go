@@ -81,9 +81,11 @@ func NewSynchronizedPodKiller(killer Killer) PodKiller {
 
 func (s *SynchronizedPodKiller) Name() string { return "synchronized-pod-killer" }
 
-func (s *SynchronizedPodKiller) Start(_ context.Context) {
+func (s *SynchronizedPodKiller) Start(ctx context.Context) {
 	klog.Infof("[synchronized] pod-killer run with killer %v", s.killer.Name())
 	defer klog.Infof("[synchronized] pod-killer started")
+
+	RunKiller(ctx, s.killer)
 }
 
 func (s *SynchronizedPodKiller) EvictPod(rp *rule.RuledEvictPod) error {
@@ -174,6 +176,7 @@ func (a *AsynchronizedPodKiller) Start(ctx context.Context) {
 	klog.Infof("[asynchronous] pod-killer run with killer %v", a.killer.Name())
 	defer klog.Infof("[asynchronous] pod-killer started")
 
+	RunKiller(ctx, a.killer)
 	for i := 0; i < 10; i++ {
 		go wait.Until(a.run, time.Second, ctx.Done())
 	}
@@ -329,6 +332,7 @@ func (a *AsynchronizedPodKiller) sync(key string) (retError error, requeue bool)
 			if apierrors.IsNotFound(err) {
 				// Now, we can be sure that the pod has already been deleted, so we do not have to attempt to evict it.
 				klog.Infof("[asynchronous] pod %s/%s has already been deleted, skip", namespace, name)
+				a.skip(podKey, gracePeriodSeconds)
 				return nil, false
 			}
 			return err, true
@@ -340,6 +344,7 @@ func (a *AsynchronizedPodKiller) sync(key string) (retError error, requeue bool)
 		foundUid := pod.UID
 		if string(foundUid) != uid {
 			klog.Infof("[asynchronous] pod %s/%s has already been deleted, skip", namespace, name)
+			a.skip(podKey, gracePeriodSeconds)
 			return nil, false
 		}
 	}
@@ -362,6 +367,21 @@ func (a *AsynchronizedPodKiller) sync(key string) (retError error, requeue bool)
 	}
 }
 
+// skip notifies the killer that the eviction is skipped since the pod has already been deleted
+func (a *AsynchronizedPodKiller) skip(podKey string, gracePeriodSeconds int64) {
+	s, ok := a.killer.(SkipAwareKiller)
+	if !ok {
+		return
+	}
+
+	a.RLock()
+	info := a.processingPods[podKey][gracePeriodSeconds]
+	a.RUnlock()
+	if info != nil {
+		s.Skip(info.Pod)
+	}
+}
+
 func podKeyFunc(podNamespace, podName string, uid string) string {
 	return strings.Join([]string{podNamespace, podName, uid}, consts.KeySeparator)
 }
@@ -84,6 +84,7 @@ func TestSplitEvictionKey(t *testing.T) {
 
 type mockKiller struct {
 	EvictFunc func(ctx context.Context, pod *v1.Pod, gracePeriodSeconds int64, reason, plugin string) error
+	SkipFunc  func(pod *v1.Pod)
 }
 
 func (m *mockKiller) Name() string {
@@ -97,6 +98,12 @@ func (m *mockKiller) Evict(ctx context.Context, pod *v1.Pod, gracePeriodSeconds
 	return nil
 }
 
+func (m *mockKiller) Skip(pod *v1.Pod) {
+	if m.SkipFunc != nil {
+		m.SkipFunc(pod)
+	}
+}
+
 // TestAsynchronizedPodKiller_sync tests the sync method of AsynchronizedPodKiller
 func TestAsynchronizedPodKiller_sync(t *testing.T) {
 	t.Parallel()
@@ -163,7 +170,12 @@ func TestAsynchronizedPodKiller_sync(t *testing.T) {
 
 	mockey.PatchConvey("When pod is not found", t, func() {
 		// Arrange
-		mockKiller := &mockKiller{}
+		var skippedPod *v1.Pod
+		mockKiller := &mockKiller{
+			SkipFunc: func(pod *v1.Pod) {
+				skippedPod = pod
+			},
+		}
 
 		// Prepare test data
 		namespace := "default"
@@ -206,6 +218,9 @@ func TestAsynchronizedPodKiller_sync(t *testing.T) {
 		So(err, ShouldBeNil)
 		So(requeue, ShouldBeFalse)
 		// Check that the pod is removed from processingPods
+		// Check that the killer is notified of the skipped eviction
+		So(skippedPod, ShouldNotBeNil)
+		So(skippedPod.UID, ShouldEqual, uid)
 		So(killer.processingPods[podKey], ShouldBeNil)
 	})
 
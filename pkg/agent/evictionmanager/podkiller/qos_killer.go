@@ -53,6 +53,19 @@ func (q *qosAwareKiller) Evict(ctx context.Context, pod *v1.Pod, gracePeriodSeco
 	return killer.Evict(ctx, pod, gracePeriodSeconds, reason, plugin)
 }
 
+// Run starts background routines of all the underlying killers
+func (q *qosAwareKiller) Run(ctx context.Context) {
+	RunKiller(ctx, q.defaultKiller)
+	for _, killer := range q.killerMap {
+		RunKiller(ctx, killer)
+	}
+}
+
+// IsSuspended returns whether the given pod is suspended by the killer for its QoS level
+func (q *qosAwareKiller) IsSuspended(pod *v1.Pod) bool {
+	return IsPodSuspended(q.getKillerForPod(pod), pod)
+}
+
 // Name returns the name of this pod killer
 func (q *qosAwareKiller) Name() string {
 	return "qos-aware-killer"
@@ -0,0 +1,236 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rule
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubewharf/katalyst-core/pkg/config/generic"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+)
+
+const (
+	EvictionBudgetScopeNode  = "node"
+	EvictionBudgetScopeQoS   = "qos"
+	EvictionBudgetScopeOwner = "owner"
+)
+
+// EvictionBudget limits the amount of evictions in a period of time, and
+// it is shared among all eviction plugins to avoid burst evictions.
+type EvictionBudget interface {
+	// Enabled returns whether evictions are limited by budgets at all.
+	Enabled() bool
+	// TryReserve reserves budget for the given pod if all budgets it belongs to are
+	// available, otherwise it returns the scope whose budget runs out. The reserved
+	// budget is only charged by Commit after the eviction succeeds.
+	TryReserve(pod *v1.Pod, now time.Time) (bool, string)
+	// InFlight returns whether the given pod holds a reservation, i.e. its eviction is
+	// still in process, so that it won't be reserved repeatedly.
+	InFlight(pod *v1.Pod, now time.Time) bool
+	// Commit charges budget for the given pod once it has been evicted successfully.
+	Commit(pod *v1.Pod, now time.Time)
+	// Release gives back the budget reserved for the given pod if the eviction fails.
+	Release(pod *v1.Pod)
+}
+
+type budgetKey struct {
+	scope  string
+	key    string
+	budget int
+}
+
+type budgetReservation struct {
+	keys       []budgetKey
+	reservedAt time.Time
+}
+
+// SlidingWindowEvictionBudget is the default implementation for EvictionBudget;
+// it counts evictions in a sliding window for node, each QoS level and each
+// workload owner separately, and non-positive budget means unlimited.
+type SlidingWindowEvictionBudget struct {
+	window      time.Duration
+	nodeBudget  int
+	qosBudgets  map[string]int
+	ownerBudget int
+	qosConf     *generic.QoSConfiguration
+
+	mutex sync.Mutex
+	// records maps budget key to timestamps of successful evictions in the window
+	records map[string][]time.Time
+	// reservations maps pod uid to budgets reserved for in-flight evictions, and
+	// reservations never committed or released expire after the window
+	reservations map[string]*budgetReservation
+}
+
+func NewSlidingWindowEvictionBudget(window time.Duration, nodeBudget int, qosBudgets map[string]int,
+	ownerBudget int, qosConf *generic.QoSConfiguration,
+) EvictionBudget {
+	return &SlidingWindowEvictionBudget{
+		window:       window,
+		nodeBudget:   nodeBudget,
+		qosBudgets:   qosBudgets,
+		ownerBudget:  ownerBudget,
+		qosConf:      qosConf,
+		records:      make(map[string][]time.Time),
+		reservations: make(map[string]*budgetReservation),
+	}
+}
+
+func (s *SlidingWindowEvictionBudget) Enabled() bool {
+	return s.window > 0
+}
+
+func (s *SlidingWindowEvictionBudget) TryReserve(pod *v1.Pod, now time.Time) (bool, string) {
+	if s.window <= 0 || pod == nil {
+		return true, ""
+	}
+
+	keys := s.getBudgetKeys(pod)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.pruneReservations(now)
+	if _, ok := s.reservations[string(pod.UID)]; ok {
+		return true, ""
+	}
+
+	for _, k := range keys {
+		s.records[k.key] = s.pruneExpired(s.records[k.key], now)
+		if len(s.records[k.key])+s.countReservations(k.key) >= k.budget {
+			return false, k.scope
+		}
+	}
+
+	s.reservations[string(pod.UID)] = &budgetReservation{keys: keys, reservedAt: now}
+	return true, ""
+}
+
+func (s *SlidingWindowEvictionBudget) InFlight(pod *v1.Pod, now time.Time) bool {
+	if s.window <= 0 || pod == nil {
+		return false
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.pruneReservations(now)
+	_, ok := s.reservations[string(pod.UID)]
+	return ok
+}
+
+func (s *SlidingWindowEvictionBudget) Commit(pod *v1.Pod, now time.Time) {
+	if s.window <= 0 || pod == nil {
+		return
+	}
+
+	// the reservation may have expired if the eviction takes too long
+	keys := s.getBudgetKeys(pod)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if r, ok := s.reservations[string(pod.UID)]; ok {
+		keys = r.keys
+		delete(s.reservations, string(pod.UID))
+	}
+
+	for _, k := range keys {
+		s.records[k.key] = append(s.pruneExpired(s.records[k.key], now), now)
+	}
+}
+
+func (s *SlidingWindowEvictionBudget) Release(pod *v1.Pod) {
+	if s.window <= 0 || pod == nil {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.reservations, string(pod.UID))
+}
+
+// getBudgetKeys returns all limited budgets the given pod belongs to
+func (s *SlidingWindowEvictionBudget) getBudgetKeys(pod *v1.Pod) []budgetKey {
+	keys := []budgetKey{{scope: EvictionBudgetScopeNode, key: EvictionBudgetScopeNode, budget: s.nodeBudget}}
+
+	if qosLevel, err := s.qosConf.GetQoSLevelForPod(pod); err != nil {
+		general.Warningf("failed to get qos level for pod %v/%v: %v", pod.Namespace, pod.Name, err)
+	} else if budget, ok := s.qosBudgets[qosLevel]; ok {
+		keys = append(keys, budgetKey{scope: EvictionBudgetScopeQoS, key: fmt.Sprintf("%s/%s", EvictionBudgetScopeQoS, qosLevel), budget: budget})
+	}
+
+	if ownerKey := GetPodOwnerKey(pod); ownerKey != "" {
+		keys = append(keys, budgetKey{scope: EvictionBudgetScopeOwner, key: fmt.Sprintf("%s/%s", EvictionBudgetScopeOwner, ownerKey), budget: s.ownerBudget})
+	}
+
+	limited := keys[:0]
+	for _, k := range keys {
+		if k.budget > 0 {
+			limited = append(limited, k)
+		}
+	}
+	return limited
+}
+
+// pruneExpired drops timestamps that have already been out of the sliding window
+func (s *SlidingWindowEvictionBudget) pruneExpired(timestamps []time.Time, now time.Time) []time.Time {
+	idx := 0
+	for idx < len(timestamps) && now.Sub(timestamps[idx]) >= s.window {
+		idx++
+	}
+	return timestamps[idx:]
+}
+
+// pruneReservations drops reservations that have been neither committed nor released
+// within the window, e.g. pods deleted by others before being evicted
+func (s *SlidingWindowEvictionBudget) pruneReservations(now time.Time) {
+	for podUID, r := range s.reservations {
+		if now.Sub(r.reservedAt) >= s.window {
+			delete(s.reservations, podUID)
+		}
+	}
+}
+
+func (s *SlidingWindowEvictionBudget) countReservations(key string) int {
+	count := 0
+	for _, r := range s.reservations {
+		for _, k := range r.keys {
+			if k.key == key {
+				count++
+				break
+			}
+		}
+	}
+	return count
+}
+
+// GetPodOwnerKey returns the key of the workload controller for the given pod,
+// and empty string is returned if the pod doesn't have any controller.
+func GetPodOwnerKey(pod *v1.Pod) string {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s/%s", pod.Namespace, owner.Kind, owner.Name)
+}
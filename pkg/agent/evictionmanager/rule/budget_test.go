@@ -0,0 +1,150 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiconsts "github.com/kubewharf/katalyst-api/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/config/generic"
+)
+
+func makeRuledEvictPodForBudget(name, qosLevel, owner string) *RuledEvictPod {
+	ep := makeRuledEvictPodWithAnnotation(name, "", map[string]string{
+		apiconsts.PodAnnotationQoSLevelKey: qosLevel,
+	})
+	if owner != "" {
+		controller := true
+		ep.Pod.OwnerReferences = []metav1.OwnerReference{{Kind: "ReplicaSet", Name: owner, Controller: &controller}}
+	}
+	return ep
+}
+
+func TestSlidingWindowEvictionBudget(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	type acquire struct {
+		rp            *RuledEvictPod
+		at            time.Time
+		expectedOK    bool
+		expectedScope string
+	}
+
+	for _, tc := range []struct {
+		comment     string
+		window      time.Duration
+		nodeBudget  int
+		qosBudgets  map[string]int
+		ownerBudget int
+		acquires    []acquire
+	}{
+		{
+			comment:    "disabled budget should always be acquired",
+			window:     0,
+			nodeBudget: 1,
+			acquires: []acquire{
+				{rp: makeRuledEvictPodForBudget("p1", apiconsts.PodAnnotationQoSLevelSharedCores, ""), at: now, expectedOK: true},
+				{rp: makeRuledEvictPodForBudget("p2", apiconsts.PodAnnotationQoSLevelSharedCores, ""), at: now, expectedOK: true},
+			},
+		},
+		{
+			comment:    "node budget should be refilled after window",
+			window:     time.Minute,
+			nodeBudget: 1,
+			acquires: []acquire{
+				{rp: makeRuledEvictPodForBudget("p1", apiconsts.PodAnnotationQoSLevelSharedCores, ""), at: now, expectedOK: true},
+				{rp: makeRuledEvictPodForBudget("p2", apiconsts.PodAnnotationQoSLevelSharedCores, ""), at: now.Add(time.Second), expectedScope: EvictionBudgetScopeNode},
+				{rp: makeRuledEvictPodForBudget("p2", apiconsts.PodAnnotationQoSLevelSharedCores, ""), at: now.Add(time.Minute), expectedOK: true},
+			},
+		},
+		{
+			comment:    "qos budget should only limit the given qos level",
+			window:     time.Minute,
+			qosBudgets: map[string]int{apiconsts.PodAnnotationQoSLevelSharedCores: 1},
+			acquires: []acquire{
+				{rp: makeRuledEvictPodForBudget("p1", apiconsts.PodAnnotationQoSLevelSharedCores, ""), at: now, expectedOK: true},
+				{rp: makeRuledEvictPodForBudget("p2", apiconsts.PodAnnotationQoSLevelSharedCores, ""), at: now, expectedScope: EvictionBudgetScopeQoS},
+				{rp: makeRuledEvictPodForBudget("p3", apiconsts.PodAnnotationQoSLevelReclaimedCores, ""), at: now, expectedOK: true},
+			},
+		},
+		{
+			comment:     "owner budget should only limit pods of the same owner",
+			window:      time.Minute,
+			nodeBudget:  3,
+			ownerBudget: 1,
+			acquires: []acquire{
+				{rp: makeRuledEvictPodForBudget("p1", apiconsts.PodAnnotationQoSLevelSharedCores, "rs-1"), at: now, expectedOK: true},
+				{rp: makeRuledEvictPodForBudget("p2", apiconsts.PodAnnotationQoSLevelSharedCores, "rs-1"), at: now, expectedScope: EvictionBudgetScopeOwner},
+				{rp: makeRuledEvictPodForBudget("p3", apiconsts.PodAnnotationQoSLevelSharedCores, "rs-2"), at: now, expectedOK: true},
+				{rp: makeRuledEvictPodForBudget("p4", apiconsts.PodAnnotationQoSLevelSharedCores, ""), at: now, expectedOK: true},
+				{rp: makeRuledEvictPodForBudget("p5", apiconsts.PodAnnotationQoSLevelSharedCores, "rs-3"), at: now, expectedScope: EvictionBudgetScopeNode},
+			},
+		},
+	} {
+		budget := NewSlidingWindowEvictionBudget(tc.window, tc.nodeBudget, tc.qosBudgets, tc.ownerBudget, generic.NewQoSConfiguration())
+		for i, a := range tc.acquires {
+			ok, scope := budget.TryReserve(a.rp.Pod, a.at)
+			if ok {
+				budget.Commit(a.rp.Pod, a.at)
+			}
+			assert.Equal(t, a.expectedOK, ok, "%v: acquire %v", tc.comment, i)
+			assert.Equal(t, a.expectedScope, scope, "%v: acquire %v", tc.comment, i)
+		}
+	}
+}
+
+func TestSlidingWindowEvictionBudget_Reservation(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	p1 := makeRuledEvictPodForBudget("p1", apiconsts.PodAnnotationQoSLevelSharedCores, "").Pod
+	p2 := makeRuledEvictPodForBudget("p2", apiconsts.PodAnnotationQoSLevelSharedCores, "").Pod
+	budget := NewSlidingWindowEvictionBudget(time.Minute, 1, nil, 0, generic.NewQoSConfiguration())
+
+	// in-flight pods hold the budget, and reserving them again won't charge twice
+	ok, _ := budget.TryReserve(p1, now)
+	assert.True(t, ok)
+	assert.True(t, budget.InFlight(p1, now))
+	ok, _ = budget.TryReserve(p1, now)
+	assert.True(t, ok)
+	ok, scope := budget.TryReserve(p2, now)
+	assert.False(t, ok)
+	assert.Equal(t, EvictionBudgetScopeNode, scope)
+
+	// failed evictions give back the budget
+	budget.Release(p1)
+	assert.False(t, budget.InFlight(p1, now))
+	ok, _ = budget.TryReserve(p2, now)
+	assert.True(t, ok)
+
+	// reservations never finished expire after the window
+	assert.False(t, budget.InFlight(p2, now.Add(time.Minute)))
+	ok, _ = budget.TryReserve(p1, now.Add(time.Minute))
+	assert.True(t, ok)
+
+	// successful evictions are charged for the whole window
+	budget.Commit(p1, now.Add(time.Minute))
+	ok, _ = budget.TryReserve(p2, now.Add(time.Minute+time.Second))
+	assert.False(t, ok)
+	ok, _ = budget.TryReserve(p2, now.Add(2*time.Minute))
+	assert.True(t, ok)
+}
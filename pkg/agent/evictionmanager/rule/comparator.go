@@ -0,0 +1,191 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rule
+
+import (
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	pkgconfig "github.com/kubewharf/katalyst-core/pkg/config"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+)
+
+const (
+	CandidateComparatorNameKatalystQoS   = "katalyst-qos"
+	CandidateComparatorNamePriority      = "priority"
+	CandidateComparatorNameEvictionScope = "eviction-scope"
+	CandidateComparatorNamePodName       = "pod-name"
+	CandidateComparatorNameUptime        = "uptime"
+	CandidateComparatorNameRestarts      = "restarts"
+	CandidateComparatorNameOwnerKind     = "owner-kind"
+)
+
+// DefaultCandidateComparators is the comparator chain used when no chain
+// is specified by dynamic configuration.
+var DefaultCandidateComparators = []string{
+	CandidateComparatorNameKatalystQoS,
+	CandidateComparatorNamePriority,
+	CandidateComparatorNameEvictionScope,
+	CandidateComparatorNamePodName,
+}
+
+// CandidateComparatorInitFunc builds a comparator for RuledEvictPods. CandidateSort
+// sorts candidates in ascending order, and those at the tail of the list will be
+// evicted first, so a comparator should return -1 if s1 is less preferred to be
+// evicted than s2.
+type CandidateComparatorInitFunc func(conf *pkgconfig.Configuration) general.CmpFunc
+
+var candidateComparatorInitializers sync.Map
+
+// RegisterCandidateComparator registers a named comparator, and it can be
+// referred by name in the comparator chain of dynamic configuration.
+func RegisterCandidateComparator(name string, initFunc CandidateComparatorInitFunc) {
+	candidateComparatorInitializers.Store(name, initFunc)
+}
+
+// GetCandidateComparatorInitializer returns the registered comparator initializer by name.
+func GetCandidateComparatorInitializer(name string) (CandidateComparatorInitFunc, bool) {
+	initializer, ok := candidateComparatorInitializers.Load(name)
+	if !ok {
+		return nil, false
+	}
+	return initializer.(CandidateComparatorInitFunc), true
+}
+
+func init() {
+	RegisterCandidateComparator(CandidateComparatorNameKatalystQoS, func(conf *pkgconfig.Configuration) general.CmpFunc {
+		return (&EvictionStrategyImpl{conf: conf.GenericConfiguration}).CompareKatalystQoS
+	})
+	RegisterCandidateComparator(CandidateComparatorNamePriority, func(conf *pkgconfig.Configuration) general.CmpFunc {
+		return (&EvictionStrategyImpl{conf: conf.GenericConfiguration}).ComparePriority
+	})
+	RegisterCandidateComparator(CandidateComparatorNameEvictionScope, func(conf *pkgconfig.Configuration) general.CmpFunc {
+		return (&EvictionStrategyImpl{conf: conf.GenericConfiguration}).CompareEvictionResource
+	})
+	RegisterCandidateComparator(CandidateComparatorNamePodName, func(conf *pkgconfig.Configuration) general.CmpFunc {
+		return (&EvictionStrategyImpl{conf: conf.GenericConfiguration}).ComparePodName
+	})
+	RegisterCandidateComparator(CandidateComparatorNameUptime, NewUptimeComparator)
+	RegisterCandidateComparator(CandidateComparatorNameRestarts, NewRestartsComparator)
+	RegisterCandidateComparator(CandidateComparatorNameOwnerKind, NewOwnerKindComparator)
+}
+
+// NewUptimeComparator prefers to evict pods with the shortest uptime, and pods
+// that haven't been started yet are considered as the shortest ones.
+func NewUptimeComparator(_ *pkgconfig.Configuration) general.CmpFunc {
+	return func(s1, s2 interface{}) int {
+		return covertBoolSortCompareToIntSort(func(s1, s2 interface{}) bool {
+			c1, c2 := s1.(*RuledEvictPod), s2.(*RuledEvictPod)
+
+			t1, t2 := c1.Pod.Status.StartTime, c2.Pod.Status.StartTime
+			if t1 == nil {
+				return false
+			}
+			return t2 == nil || t1.Before(t2)
+		}, s1, s2)
+	}
+}
+
+// NewRestartsComparator prefers to evict pods with the most container restarts.
+func NewRestartsComparator(_ *pkgconfig.Configuration) general.CmpFunc {
+	return func(s1, s2 interface{}) int {
+		return covertBoolSortCompareToIntSort(func(s1, s2 interface{}) bool {
+			c1, c2 := s1.(*RuledEvictPod), s2.(*RuledEvictPod)
+
+			return getPodRestartCount(c1.Pod) < getPodRestartCount(c2.Pod)
+		}, s1, s2)
+	}
+}
+
+// NewOwnerKindComparator prefers to evict pods whose controller kind is listed in
+// EvictionCandidateOwnerKinds of dynamic configuration; the kinds listed earlier
+// are preferred to those listed later, and an empty kind matches pods without controller.
+func NewOwnerKindComparator(conf *pkgconfig.Configuration) general.CmpFunc {
+	ownerKinds := conf.GetDynamicConfiguration().EvictionCandidateOwnerKinds
+	kindRanks := make(map[string]int, len(ownerKinds))
+	for i, kind := range ownerKinds {
+		if _, ok := kindRanks[kind]; !ok {
+			kindRanks[kind] = i
+		}
+	}
+
+	// pods with kinds not listed are the least preferred to be evicted
+	getRank := func(pod *v1.Pod) int {
+		if rank, ok := kindRanks[getPodOwnerKind(pod)]; ok {
+			return len(ownerKinds) - rank
+		}
+		return 0
+	}
+
+	return func(s1, s2 interface{}) int {
+		return covertBoolSortCompareToIntSort(func(s1, s2 interface{}) bool {
+			c1, c2 := s1.(*RuledEvictPod), s2.(*RuledEvictPod)
+
+			return getRank(c1.Pod) < getRank(c2.Pod)
+		}, s1, s2)
+	}
+}
+
+// newCandidateComparators builds comparators according to the given chain, and
+// returns names of those comparators that are actually used. if none of the names
+// can be recognized, the default chain will be used instead.
+func newCandidateComparators(conf *pkgconfig.Configuration, names []string) ([]general.CmpFunc, []string) {
+	compares := make([]general.CmpFunc, 0, len(names))
+	chain := make([]string, 0, len(names))
+	visited := sets.NewString()
+	for _, name := range names {
+		if visited.Has(name) {
+			continue
+		}
+		visited.Insert(name)
+
+		initializer, ok := GetCandidateComparatorInitializer(name)
+		if !ok {
+			general.Warningf("unknown eviction candidate comparator %v, skip it", name)
+			continue
+		}
+		compares = append(compares, initializer(conf))
+		chain = append(chain, name)
+	}
+
+	if len(compares) == 0 && len(names) > 0 {
+		general.Warningf("no valid eviction candidate comparator in %v, use default chain", names)
+		return newCandidateComparators(conf, DefaultCandidateComparators)
+	}
+	return compares, chain
+}
+
+func getPodRestartCount(pod *v1.Pod) int32 {
+	var count int32
+	for _, status := range pod.Status.InitContainerStatuses {
+		count += status.RestartCount
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		count += status.RestartCount
+	}
+	return count
+}
+
+func getPodOwnerKind(pod *v1.Pod) string {
+	if owner := metav1.GetControllerOf(pod); owner != nil {
+		return owner.Kind
+	}
+	return ""
+}
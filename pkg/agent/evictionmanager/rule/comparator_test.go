@@ -0,0 +1,174 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubewharf/katalyst-core/cmd/katalyst-agent/app/options"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+)
+
+func makeRuledEvictPodWithStartTime(name string, startTime *time.Time) *RuledEvictPod {
+	ep := makeRuledEvictPod(name, "")
+	if startTime != nil {
+		ep.Pod.Status.StartTime = &metav1.Time{Time: *startTime}
+	}
+	return ep
+}
+
+func makeRuledEvictPodWithRestarts(name string, restarts ...int32) *RuledEvictPod {
+	ep := makeRuledEvictPod(name, "")
+	for _, r := range restarts {
+		ep.Pod.Status.ContainerStatuses = append(ep.Pod.Status.ContainerStatuses, v1.ContainerStatus{RestartCount: r})
+	}
+	return ep
+}
+
+func makeRuledEvictPodWithOwnerKind(name, kind string) *RuledEvictPod {
+	ep := makeRuledEvictPod(name, "")
+	if kind != "" {
+		controller := true
+		ep.Pod.OwnerReferences = []metav1.OwnerReference{{Kind: kind, Name: name, Controller: &controller}}
+	}
+	return ep
+}
+
+func TestUptimeComparator(t *testing.T) {
+	t.Parallel()
+
+	testConf, _ := options.NewOptions().Config()
+	now := time.Now()
+	earlier, later := now.Add(-time.Hour), now.Add(-time.Minute)
+
+	rpList := RuledEvictPodList{
+		makeRuledEvictPodWithStartTime("p-later", &later),
+		makeRuledEvictPodWithStartTime("p-not-started", nil),
+		makeRuledEvictPodWithStartTime("p-earlier", &earlier),
+	}
+	general.NewMultiSorter(NewUptimeComparator(testConf)).Sort(rpList)
+	assert.Equal(t, []string{"p-earlier", "p-later", "p-not-started"}, rpList.getPodNames())
+}
+
+func TestRestartsComparator(t *testing.T) {
+	t.Parallel()
+
+	testConf, _ := options.NewOptions().Config()
+	rpList := RuledEvictPodList{
+		makeRuledEvictPodWithRestarts("p-5", 2, 3),
+		makeRuledEvictPodWithRestarts("p-0"),
+		makeRuledEvictPodWithRestarts("p-1", 1),
+	}
+	general.NewMultiSorter(NewRestartsComparator(testConf)).Sort(rpList)
+	assert.Equal(t, []string{"p-0", "p-1", "p-5"}, rpList.getPodNames())
+}
+
+func TestOwnerKindComparator(t *testing.T) {
+	t.Parallel()
+
+	testConf, _ := options.NewOptions().Config()
+	testConf.GetDynamicConfiguration().EvictionCandidateOwnerKinds = []string{"Job", "ReplicaSet"}
+
+	rpList := RuledEvictPodList{
+		makeRuledEvictPodWithOwnerKind("p-job", "Job"),
+		makeRuledEvictPodWithOwnerKind("p-ds", "DaemonSet"),
+		makeRuledEvictPodWithOwnerKind("p-rs", "ReplicaSet"),
+		makeRuledEvictPodWithOwnerKind("p-none", ""),
+	}
+	general.NewMultiSorter(NewOwnerKindComparator(testConf), (&EvictionStrategyImpl{}).ComparePodName).Sort(rpList)
+	assert.Equal(t, []string{"p-none", "p-ds", "p-rs", "p-job"}, rpList.getPodNames())
+}
+
+func TestCandidateComparators(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		comment     string
+		comparators []string
+		expected    []string
+	}{
+		{
+			comment:     "empty chain should use default",
+			comparators: nil,
+			expected:    DefaultCandidateComparators,
+		},
+		{
+			comment:     "unknown and duplicated comparators should be skipped",
+			comparators: []string{CandidateComparatorNameRestarts, "unknown", CandidateComparatorNameRestarts, CandidateComparatorNamePodName},
+			expected:    []string{CandidateComparatorNameRestarts, CandidateComparatorNamePodName},
+		},
+		{
+			comment:     "chain without any valid comparator should use default",
+			comparators: []string{"unknown"},
+			expected:    DefaultCandidateComparators,
+		},
+	} {
+		testConf, _ := options.NewOptions().Config()
+		testConf.GetDynamicConfiguration().EvictionCandidateComparators = tc.comparators
+
+		s := NewEvictionStrategyImpl(testConf)
+		assert.Equal(t, tc.expected, s.CandidateComparators(), tc.comment)
+	}
+
+	testConf, _ := options.NewOptions().Config()
+	testConf.GetDynamicConfiguration().EvictionCandidateComparators = []string{
+		CandidateComparatorNameRestarts, CandidateComparatorNamePodName,
+	}
+	rpList := RuledEvictPodList{
+		makeRuledEvictPodWithRestarts("p-b", 1),
+		makeRuledEvictPodWithRestarts("p-c", 3),
+		makeRuledEvictPodWithRestarts("p-a", 1),
+	}
+	NewEvictionStrategyImpl(testConf).CandidateSort(rpList)
+	assert.Equal(t, []string{"p-b", "p-a", "p-c"}, rpList.getPodNames())
+}
+
+func TestCandidateComparatorsCache(t *testing.T) {
+	t.Parallel()
+
+	testConf, _ := options.NewOptions().Config()
+	dynamicConf := testConf.GetDynamicConfiguration()
+	dynamicConf.EvictionCandidateComparators = []string{CandidateComparatorNameOwnerKind, CandidateComparatorNamePodName}
+	dynamicConf.EvictionCandidateOwnerKinds = []string{"Job"}
+
+	s := NewEvictionStrategyImpl(testConf).(*EvictionStrategyImpl)
+	rpList := RuledEvictPodList{
+		makeRuledEvictPodWithOwnerKind("p-job", "Job"),
+		makeRuledEvictPodWithOwnerKind("p-rs", "ReplicaSet"),
+	}
+	s.CandidateSort(rpList)
+	assert.Equal(t, []string{"p-rs", "p-job"}, rpList.getPodNames())
+
+	// the chain should be reused as long as the configuration is unchanged
+	compares, _ := s.getCandidateComparators()
+	cachedCompares, _ := s.getCandidateComparators()
+	assert.True(t, &compares[0] == &cachedCompares[0])
+
+	// changes of owner kinds should rebuild the chain
+	dynamicConf.EvictionCandidateOwnerKinds = []string{"ReplicaSet"}
+	s.CandidateSort(rpList)
+	assert.Equal(t, []string{"p-job", "p-rs"}, rpList.getPodNames())
+
+	// changes of comparator names should rebuild the chain
+	dynamicConf.EvictionCandidateComparators = []string{CandidateComparatorNameRestarts}
+	assert.Equal(t, []string{CandidateComparatorNameRestarts}, s.CandidateComparators())
+}
@@ -17,6 +17,9 @@ limitations under the License.
 package rule
 
 import (
+	"reflect"
+	"sync"
+
 	"k8s.io/klog/v2"
 	kubelettypes "k8s.io/kubernetes/pkg/kubelet/types"
 
@@ -35,6 +38,9 @@ type EvictionStrategy interface {
 	// CandidateSort defines the eviction priority among different EvictPods
 	CandidateSort(rpList RuledEvictPodList)
 
+	// CandidateComparators returns names of the comparator chain used by CandidateSort.
+	CandidateComparators() []string
+
 	// CandidateValidate defines whether the given pod is permitted for
 	// eviction since some specific EvictPods should always keep running.
 	CandidateValidate(rp *RuledEvictPod) bool
@@ -42,31 +48,59 @@ type EvictionStrategy interface {
 
 type EvictionStrategyImpl struct {
 	conf     *generic.GenericConfiguration
-	compares []general.CmpFunc
+	fullConf *pkgconfig.Configuration
+
+	// the comparator chain is cached, and it's rebuilt only when the
+	// dynamic configuration it's built from changes
+	mutex           sync.Mutex
+	compares        []general.CmpFunc
+	chain           []string
+	chainNames      []string
+	chainOwnerKinds []string
 }
 
 func NewEvictionStrategyImpl(conf *pkgconfig.Configuration) EvictionStrategy {
-	e := &EvictionStrategyImpl{
-		conf: conf.GenericConfiguration,
+	return &EvictionStrategyImpl{
+		conf:     conf.GenericConfiguration,
+		fullConf: conf,
 	}
-
-	// if any compare function reach out with a result, returns immediately
-	e.compares = []general.CmpFunc{
-		e.CompareKatalystQoS,
-		e.ComparePriority,
-		e.CompareEvictionResource,
-		e.ComparePodName,
-	}
-	return e
 }
 
-// CandidateSort defines the sorting rules will be as below
+// CandidateSort sorts EvictPods with the comparator chain in dynamic configuration,
+// and the default sorting rules will be as below
 // - katalyst QoS: none-reclaimed > reclaimed
 // - pod priority
 // - predefined resource priority: e.g. memory > cpu > ...
 // - pod names
 func (e *EvictionStrategyImpl) CandidateSort(rpList RuledEvictPodList) {
-	general.NewMultiSorter(e.compares...).Sort(rpList)
+	// if any compare function reach out with a result, returns immediately
+	compares, _ := e.getCandidateComparators()
+	general.NewMultiSorter(compares...).Sort(rpList)
+}
+
+func (e *EvictionStrategyImpl) CandidateComparators() []string {
+	_, chain := e.getCandidateComparators()
+	return chain
+}
+
+// getCandidateComparators returns the cached comparator chain, and rebuilds it if
+// comparator names or owner kinds in dynamic configuration have been changed.
+func (e *EvictionStrategyImpl) getCandidateComparators() ([]general.CmpFunc, []string) {
+	dynamicConf := e.fullConf.GetDynamicConfiguration()
+	names := dynamicConf.EvictionCandidateComparators
+	if len(names) == 0 {
+		names = DefaultCandidateComparators
+	}
+	ownerKinds := dynamicConf.EvictionCandidateOwnerKinds
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.compares == nil || !reflect.DeepEqual(e.chainNames, names) || !reflect.DeepEqual(e.chainOwnerKinds, ownerKinds) {
+		e.compares, e.chain = newCandidateComparators(e.fullConf, names)
+		e.chainNames, e.chainOwnerKinds = names, ownerKinds
+	}
+	return e.compares, e.chain
 }
 
 // CandidateValidate will try to filter out EvictPods from eviction
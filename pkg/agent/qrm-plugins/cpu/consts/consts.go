@@ -34,6 +34,17 @@ const (
 	IRQTuning                  = CPUPluginDynamicPolicyName + "_irq_tuning"
 	CommunicateWithAdvisor     = CPUPluginDynamicPolicyName + "_communicate_with_advisor"
 	SyncCPUBurst               = CPUPluginDynamicPolicyName + "_sync_cpu_burst"
+	SyncCoreSched              = CPUPluginDynamicPolicyName + "_sync_core_sched"
+	SyncLLCPartition           = CPUPluginDynamicPolicyName + "_sync_llc_partition"
+)
+
+const (
+	// CoreSchedGranularityQoS makes all tasks of the same QoS level share one core scheduling cookie,
+	// so that tasks in different QoS levels never co-run on SMT siblings.
+	CoreSchedGranularityQoS = "qos"
+	// CoreSchedGranularityPod assigns each pod a separate core scheduling cookie,
+	// so that tasks in different pods never co-run on SMT siblings.
+	CoreSchedGranularityPod = "pod"
 )
 
 const (
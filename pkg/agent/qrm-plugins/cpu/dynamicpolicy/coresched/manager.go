@@ -0,0 +1,251 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coresched
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	cpuconsts "github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/consts"
+	"github.com/kubewharf/katalyst-core/pkg/config/generic"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver"
+	cgroupmgr "github.com/kubewharf/katalyst-core/pkg/util/cgroup/manager"
+	"github.com/kubewharf/katalyst-core/pkg/util/coresched"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+	"github.com/kubewharf/katalyst-core/pkg/util/native"
+)
+
+// Manager keeps core scheduling cookies of tasks consistent with their groups;
+// the cookies are kept by kernel, so the group-to-cookie mapping can be recovered
+// from running tasks after the agent restarts.
+type Manager interface {
+	SyncCookies() error
+}
+
+// listTasksFunc returns thread ids of the given container
+type listTasksFunc func(pod *v1.Pod, containerName string) ([]int, error)
+
+type managerImpl struct {
+	mutex sync.Mutex
+
+	metaServer  *metaserver.MetaServer
+	qosConf     *generic.QoSConfiguration
+	operator    coresched.CookieOperator
+	listTasks   listTasksFunc
+	granularity string
+	qosLevels   sets.String
+
+	// groupLeaders maps group key to the task whose cookie is shared to the whole group
+	groupLeaders map[string]int
+}
+
+func NewManager(metaServer *metaserver.MetaServer, qosConf *generic.QoSConfiguration,
+	granularity string, qosLevels []string,
+) (Manager, error) {
+	if granularity != cpuconsts.CoreSchedGranularityQoS && granularity != cpuconsts.CoreSchedGranularityPod {
+		return nil, fmt.Errorf("unsupported core scheduling granularity: %s", granularity)
+	}
+
+	m := newManager(metaServer, qosConf, coresched.NewCookieOperator(), granularity, qosLevels)
+	m.listTasks = m.listContainerTasks
+	return m, nil
+}
+
+func newManager(metaServer *metaserver.MetaServer, qosConf *generic.QoSConfiguration,
+	operator coresched.CookieOperator, granularity string, qosLevels []string,
+) *managerImpl {
+	return &managerImpl{
+		metaServer:   metaServer,
+		qosConf:      qosConf,
+		operator:     operator,
+		granularity:  granularity,
+		qosLevels:    sets.NewString(qosLevels...),
+		groupLeaders: make(map[string]int),
+	}
+}
+
+// SyncCookies makes sure that all the tasks in the same group share the same cookie,
+// and new tasks (e.g. threads or processes created after the last round) will be
+// covered in the next round if they don't inherit the cookie from their parents.
+func (m *managerImpl) SyncCookies() error {
+	if m.metaServer == nil {
+		return fmt.Errorf("nil metaServer")
+	} else if !m.operator.Supported() {
+		return fmt.Errorf("core scheduling isn't supported by kernel")
+	}
+
+	podList, err := m.metaServer.GetPodList(context.Background(), native.PodIsActive)
+	if err != nil {
+		return fmt.Errorf("error getting pod list: %v", err)
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var errList []error
+	groupTasks := make(map[string][]int)
+	for _, pod := range podList {
+		qosLevel, err := m.qosConf.GetQoSLevelForPod(pod)
+		if err != nil {
+			errList = append(errList, fmt.Errorf("get qos level for pod %s/%s failed: %v", pod.Namespace, pod.Name, err))
+			continue
+		} else if !m.qosLevels.Has(qosLevel) {
+			continue
+		}
+
+		groupKey := m.getGroupKey(qosLevel, pod)
+		for _, container := range pod.Spec.Containers {
+			tasks, err := m.listTasks(pod, container.Name)
+			if err != nil {
+				general.Warningf("list tasks of pod %s/%s container %s failed: %v", pod.Namespace, pod.Name, container.Name, err)
+				continue
+			}
+			groupTasks[groupKey] = append(groupTasks[groupKey], tasks...)
+		}
+	}
+
+	groupKeys := make([]string, 0, len(groupTasks))
+	for groupKey := range groupTasks {
+		groupKeys = append(groupKeys, groupKey)
+	}
+	sort.Strings(groupKeys)
+
+	// cookies claimed by groups in this round, to avoid recovering the same cookie for different groups
+	claimedCookies := make(map[uint64]string)
+	for _, groupKey := range groupKeys {
+		if err := m.syncGroup(groupKey, groupTasks[groupKey], claimedCookies); err != nil {
+			errList = append(errList, fmt.Errorf("sync cookie for group %s failed: %v", groupKey, err))
+		}
+	}
+
+	for groupKey := range m.groupLeaders {
+		if _, ok := groupTasks[groupKey]; !ok {
+			delete(m.groupLeaders, groupKey)
+		}
+	}
+
+	return utilerrors.NewAggregate(errList)
+}
+
+func (m *managerImpl) getGroupKey(qosLevel string, pod *v1.Pod) string {
+	if m.granularity == cpuconsts.CoreSchedGranularityPod {
+		return fmt.Sprintf("%s/%s", qosLevel, pod.UID)
+	}
+	return qosLevel
+}
+
+// syncGroup shares the cookie of the group leader to all tasks in the group; if the
+// leader has gone (e.g. after restarting), we recover the leader from the cookie held
+// by most tasks in the group, and create a new cookie only if no task holds a cookie.
+func (m *managerImpl) syncGroup(groupKey string, tasks []int, claimedCookies map[uint64]string) error {
+	taskCookies := make(map[int]uint64, len(tasks))
+	for _, task := range tasks {
+		cookie, err := m.operator.Get(task)
+		if err != nil {
+			// the task may have exited
+			continue
+		}
+		taskCookies[task] = cookie
+	}
+	if len(taskCookies) == 0 {
+		return nil
+	}
+
+	leader, ok := m.groupLeaders[groupKey]
+	if cookie, found := taskCookies[leader]; !ok || !found || cookie == 0 || claimedCookies[cookie] != "" {
+		leader, ok = recoverLeader(taskCookies, claimedCookies)
+		if !ok {
+			leader = minTask(taskCookies)
+			if err := m.operator.Create(leader); err != nil {
+				return err
+			}
+
+			cookie, err := m.operator.Get(leader)
+			if err != nil {
+				return err
+			}
+			taskCookies[leader] = cookie
+			general.Infof("create core sched cookie for group %s with leader task %d", groupKey, leader)
+		} else {
+			general.Infof("recover core sched cookie for group %s with leader task %d", groupKey, leader)
+		}
+		m.groupLeaders[groupKey] = leader
+	}
+
+	cookie := taskCookies[leader]
+	claimedCookies[cookie] = groupKey
+
+	var targets []int
+	for task, taskCookie := range taskCookies {
+		if taskCookie != cookie {
+			targets = append(targets, task)
+		}
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+	sort.Ints(targets)
+
+	general.Infof("share core sched cookie of group %s from task %d to %d tasks", groupKey, leader, len(targets))
+	return m.operator.ShareTo(leader, targets)
+}
+
+// recoverLeader returns a task holding the most common cookie that hasn't been claimed
+func recoverLeader(taskCookies map[int]uint64, claimedCookies map[uint64]string) (int, bool) {
+	cookieTasks := make(map[uint64][]int)
+	for task, cookie := range taskCookies {
+		if cookie == 0 || claimedCookies[cookie] != "" {
+			continue
+		}
+		cookieTasks[cookie] = append(cookieTasks[cookie], task)
+	}
+
+	leader, maxCount := 0, 0
+	for _, tasks := range cookieTasks {
+		sort.Ints(tasks)
+		if len(tasks) > maxCount || (len(tasks) == maxCount && tasks[0] < leader) {
+			leader, maxCount = tasks[0], len(tasks)
+		}
+	}
+	return leader, maxCount > 0
+}
+
+func minTask(taskCookies map[int]uint64) int {
+	first := true
+	var result int
+	for task := range taskCookies {
+		if first || task < result {
+			result, first = task, false
+		}
+	}
+	return result
+}
+
+func (m *managerImpl) listContainerTasks(pod *v1.Pod, containerName string) ([]int, error) {
+	podUID := string(pod.UID)
+	containerID, err := m.metaServer.GetContainerID(podUID, containerName)
+	if err != nil {
+		return nil, err
+	}
+	return cgroupmgr.GetTasksForContainer(podUID, containerID)
+}
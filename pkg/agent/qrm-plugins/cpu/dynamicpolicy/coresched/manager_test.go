@@ -0,0 +1,183 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coresched
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/kubewharf/katalyst-api/pkg/consts"
+	cpuconsts "github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/consts"
+	"github.com/kubewharf/katalyst-core/pkg/config/generic"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent/pod"
+)
+
+type fakeCookieOperator struct {
+	nextCookie uint64
+	cookies    map[int]uint64
+	creates    int
+}
+
+func newFakeCookieOperator(cookies map[int]uint64) *fakeCookieOperator {
+	return &fakeCookieOperator{nextCookie: 100, cookies: cookies}
+}
+
+func (f *fakeCookieOperator) Supported() bool { return true }
+
+func (f *fakeCookieOperator) Get(pid int) (uint64, error) {
+	cookie, ok := f.cookies[pid]
+	if !ok {
+		return 0, fmt.Errorf("no such process")
+	}
+	return cookie, nil
+}
+
+func (f *fakeCookieOperator) Create(pid int) error {
+	f.nextCookie++
+	f.creates++
+	f.cookies[pid] = f.nextCookie
+	return nil
+}
+
+func (f *fakeCookieOperator) ShareTo(from int, to []int) error {
+	for _, pid := range to {
+		f.cookies[pid] = f.cookies[from]
+	}
+	return nil
+}
+
+func makePod(uid, qosLevel string, containers ...string) *v1.Pod {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        uid,
+			UID:         types.UID(uid),
+			Annotations: map[string]string{consts.PodAnnotationQoSLevelKey: qosLevel},
+		},
+	}
+	for _, container := range containers {
+		pod.Spec.Containers = append(pod.Spec.Containers, v1.Container{Name: container})
+	}
+	return pod
+}
+
+func newTestManager(pods []*v1.Pod, operator *fakeCookieOperator, granularity string, tasks map[string][]int) *managerImpl {
+	metaServer := &metaserver.MetaServer{
+		MetaAgent: &agent.MetaAgent{
+			PodFetcher: &pod.PodFetcherStub{PodList: pods},
+		},
+	}
+
+	m := newManager(metaServer, generic.NewQoSConfiguration(), operator, granularity,
+		[]string{consts.PodAnnotationQoSLevelReclaimedCores, consts.PodAnnotationQoSLevelSharedCores})
+	m.listTasks = func(pod *v1.Pod, containerName string) ([]int, error) {
+		return tasks[fmt.Sprintf("%s/%s", pod.UID, containerName)], nil
+	}
+	return m
+}
+
+func TestManagerImpl_SyncCookies(t *testing.T) {
+	t.Parallel()
+
+	pods := []*v1.Pod{
+		makePod("pod-1", consts.PodAnnotationQoSLevelReclaimedCores, "c-1", "c-2"),
+		makePod("pod-2", consts.PodAnnotationQoSLevelReclaimedCores, "c-1"),
+		makePod("pod-3", consts.PodAnnotationQoSLevelSharedCores, "c-1"),
+		makePod("pod-4", consts.PodAnnotationQoSLevelDedicatedCores, "c-1"),
+	}
+	tasks := map[string][]int{
+		"pod-1/c-1": {10, 11},
+		"pod-1/c-2": {20},
+		"pod-2/c-1": {30},
+		"pod-3/c-1": {40, 41},
+		"pod-4/c-1": {50},
+	}
+	newCookies := func() map[int]uint64 {
+		return map[int]uint64{10: 0, 11: 0, 20: 0, 30: 0, 40: 0, 41: 0, 50: 0}
+	}
+
+	t.Run("qos granularity", func(t *testing.T) {
+		t.Parallel()
+
+		operator := newFakeCookieOperator(newCookies())
+		m := newTestManager(pods, operator, cpuconsts.CoreSchedGranularityQoS, tasks)
+		assert.NoError(t, m.SyncCookies())
+
+		reclaimedCookie := operator.cookies[10]
+		assert.NotZero(t, reclaimedCookie)
+		for _, task := range []int{11, 20, 30} {
+			assert.Equal(t, reclaimedCookie, operator.cookies[task])
+		}
+		assert.NotZero(t, operator.cookies[40])
+		assert.NotEqual(t, reclaimedCookie, operator.cookies[40])
+		assert.Equal(t, operator.cookies[40], operator.cookies[41])
+		assert.Zero(t, operator.cookies[50])
+		assert.Equal(t, 2, operator.creates)
+
+		// new tasks should join the cookie of their group without creating new cookies
+		operator.cookies[31] = 0
+		newTasks := map[string][]int{"pod-2/c-1": {30, 31}}
+		for key, value := range tasks {
+			if _, ok := newTasks[key]; !ok {
+				newTasks[key] = value
+			}
+		}
+		m.listTasks = func(pod *v1.Pod, containerName string) ([]int, error) {
+			return newTasks[fmt.Sprintf("%s/%s", pod.UID, containerName)], nil
+		}
+		assert.NoError(t, m.SyncCookies())
+		assert.Equal(t, reclaimedCookie, operator.cookies[31])
+		assert.Equal(t, 2, operator.creates)
+	})
+
+	t.Run("pod granularity", func(t *testing.T) {
+		t.Parallel()
+
+		operator := newFakeCookieOperator(newCookies())
+		m := newTestManager(pods, operator, cpuconsts.CoreSchedGranularityPod, tasks)
+		assert.NoError(t, m.SyncCookies())
+
+		assert.Equal(t, operator.cookies[10], operator.cookies[20])
+		assert.NotEqual(t, operator.cookies[10], operator.cookies[30])
+		assert.NotEqual(t, operator.cookies[30], operator.cookies[40])
+		assert.Equal(t, 3, operator.creates)
+	})
+
+	t.Run("recover after restart", func(t *testing.T) {
+		t.Parallel()
+
+		// tasks 10, 11 and 30 hold the cookie assigned before restarting, while task 20 is newly created
+		cookies := newCookies()
+		cookies[10], cookies[11], cookies[30] = 7, 7, 7
+		operator := newFakeCookieOperator(cookies)
+		m := newTestManager(pods, operator, cpuconsts.CoreSchedGranularityQoS, tasks)
+		assert.NoError(t, m.SyncCookies())
+
+		for _, task := range []int{10, 11, 20, 30} {
+			assert.Equal(t, uint64(7), operator.cookies[task])
+		}
+		assert.Equal(t, 10, m.groupLeaders[consts.PodAnnotationQoSLevelReclaimedCores])
+		// only shared_cores needs a new cookie
+		assert.Equal(t, 1, operator.creates)
+	})
+}
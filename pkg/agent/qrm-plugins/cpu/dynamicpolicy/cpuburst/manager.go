@@ -19,6 +19,7 @@ package cpuburst
 import (
 	"context"
 	"fmt"
+	"math"
 	"sync"
 
 	v1 "k8s.io/api/core/v1"
@@ -26,9 +27,13 @@ import (
 
 	"github.com/kubewharf/katalyst-api/pkg/consts"
 	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/util"
+	qrmutil "github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/util"
 	"github.com/kubewharf/katalyst-core/pkg/config"
 	"github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic"
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic/adminqos/finegrainedresource"
+	coreconsts "github.com/kubewharf/katalyst-core/pkg/consts"
 	"github.com/kubewharf/katalyst-core/pkg/metaserver"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
 	"github.com/kubewharf/katalyst-core/pkg/util/cgroup/common"
 	"github.com/kubewharf/katalyst-core/pkg/util/cgroup/manager"
 	"github.com/kubewharf/katalyst-core/pkg/util/general"
@@ -41,6 +46,22 @@ type Manager interface {
 
 type managerImpl struct {
 	metaServer *metaserver.MetaServer
+	emitter    metrics.MetricEmitter
+
+	mutex sync.Mutex
+	// dynamicBurstPercents maps container key to the current cpu burst percent of
+	// containers with dynamic policy, and it is tuned step by step in each round.
+	// The percents are kept before being scaled by the node budget, so that the
+	// scaling in one round doesn't drag down the tuning in the following rounds.
+	dynamicBurstPercents map[string]float64
+}
+
+// containerCPUCgroup is the cpu cgroup of a container whose cpu burst can be set.
+type containerCPUCgroup struct {
+	containerName string
+	containerID   string
+	absPath       string
+	stats         *common.CPUStats
 }
 
 var (
@@ -49,16 +70,18 @@ var (
 )
 
 // GetManager returns a single global instance of the cpu burst manager
-func GetManager(metaServer *metaserver.MetaServer) Manager {
+func GetManager(metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter) Manager {
 	once.Do(func() {
-		instance = newManager(metaServer)
+		instance = newManager(metaServer, emitter)
 	})
 	return instance
 }
 
-func newManager(metaServer *metaserver.MetaServer) *managerImpl {
+func newManager(metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter) *managerImpl {
 	return &managerImpl{
-		metaServer: metaServer,
+		metaServer:           metaServer,
+		emitter:              emitter,
+		dynamicBurstPercents: make(map[string]float64),
 	}
 }
 
@@ -83,6 +106,8 @@ func (m *managerImpl) UpdateCPUBurst(conf *config.Configuration, dynamicConfig *
 
 	isSoleSharedCoresPod := util.IsSoleSharedCoresPod(conf, podList, dynamicConfig)
 
+	var dynamicPods []*v1.Pod
+
 	for _, pod := range podList {
 		cpuBurstPolicy, err := util.GetPodCPUBurstPolicy(conf, pod, dynamicConfig, isSoleSharedCoresPod)
 		if err != nil {
@@ -112,19 +137,187 @@ func (m *managerImpl) UpdateCPUBurst(conf *config.Configuration, dynamicConfig *
 					consts.PodAnnotationCPUEnhancementCPUBurstPolicyStatic, pod.Name, err))
 			}
 		case consts.PodAnnotationCPUEnhancementCPUBurstPolicyDynamic:
-			errList = append(errList, fmt.Errorf("dynamic cpu burst policy is not supported yet"))
+			// For dynamic policy, cpu burst is tuned for all pods together to respect the node budget.
+			dynamicPods = append(dynamicPods, pod)
 		default:
 			errList = append(errList, fmt.Errorf("cpu burst policy %s is not supported", cpuBurstPolicy))
 		}
 	}
 
+	if err = m.updateCPUBurstDynamically(dynamicPods, dynamicConfig); err != nil {
+		errList = append(errList, fmt.Errorf("error setting cpu burst for policy %s: %v",
+			consts.PodAnnotationCPUEnhancementCPUBurstPolicyDynamic, err))
+	}
+
 	return utilerrors.NewAggregate(errList)
 }
 
 // updateCPUBurstByPercent updates the value of cpu burst for static policy by taking the
 // cpu quota from cgroup and calculating the cpu burst value by taking cpu quota * percent / 100.
 func (m *managerImpl) updateCPUBurstByPercent(percent float64, pod *v1.Pod) error {
+	cgroups, errList := m.listContainerCPUCgroups(pod)
+	for _, c := range cgroups {
+		if err := m.applyCPUBurst(pod, c, percent); err != nil {
+			errList = append(errList, err)
+		}
+	}
+
+	return utilerrors.NewAggregate(errList)
+}
+
+// updateCPUBurstDynamically tunes the cpu burst percent of containers with dynamic policy in a
+// closed loop: it is raised for containers that are heavily throttled when the node is idle enough,
+// and it is lowered for all containers when the node is saturated. Besides, the total cpu burst
+// of those containers is scaled down to fit in the node budget.
+func (m *managerImpl) updateCPUBurstDynamically(pods []*v1.Pod, dynamicConfig *dynamic.DynamicAgentConfiguration) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if len(pods) == 0 {
+		m.dynamicBurstPercents = make(map[string]float64)
+		return nil
+	}
+
+	if dynamicConfig == nil || dynamicConfig.GetDynamicConfiguration().DynamicCPUBurstConfiguration == nil {
+		return fmt.Errorf("nil dynamic cpu burst configuration")
+	}
+	burstConf := dynamicConfig.GetDynamicConfiguration().DynamicCPUBurstConfiguration
+	if err := validateDynamicCPUBurstConfiguration(burstConf); err != nil {
+		return err
+	}
+
+	nodeUsage, err := m.metaServer.GetNodeMetric(coreconsts.MetricCPUUsageRatioSystem)
+	if err != nil {
+		return fmt.Errorf("get node cpu usage ratio failed: %v", err)
+	}
+
+	type dynamicContainer struct {
+		pod    *v1.Pod
+		cgroup *containerCPUCgroup
+		key    string
+	}
+
+	var errList []error
+	var containers []dynamicContainer
+	percents := make(map[string]float64)
+	totalBurstCores := 0.0
+	for _, pod := range pods {
+		cgroups, errs := m.listContainerCPUCgroups(pod)
+		errList = append(errList, errs...)
+
+		for _, c := range cgroups {
+			// cpu burst makes no sense for containers without cpu limit
+			if c.stats.CpuQuota <= 0 || c.stats.CpuQuota == math.MaxInt64 || c.stats.CpuPeriod == 0 {
+				continue
+			}
+
+			key := fmt.Sprintf("%s/%s", pod.UID, c.containerName)
+			lastPercent, ok := m.dynamicBurstPercents[key]
+			if !ok {
+				// the cpu burst on disk may have been scaled by the node budget, so
+				// start from the minimum and step up instead of seeding from it
+				lastPercent = float64(burstConf.DynamicCPUBurstMinPercent)
+			}
+
+			throttledRatio, err := m.getContainerThrottledRatio(string(pod.UID), c.containerName)
+			if err != nil {
+				general.Warningf("get throttled ratio failed, pod: %s, container: %s, err: %v", pod.Name, c.containerName, err)
+			}
+
+			percent := tuneDynamicCPUBurstPercent(lastPercent, throttledRatio, nodeUsage.Value, burstConf)
+			percents[key] = percent
+			totalBurstCores += float64(c.stats.CpuQuota) * percent / 100 / float64(c.stats.CpuPeriod)
+			containers = append(containers, dynamicContainer{pod: pod, cgroup: c, key: key})
+		}
+	}
+
+	scale := 1.0
+	budget := burstConf.DynamicCPUBurstNodeBudgetRatio * float64(m.getNodeCPUCapacity())
+	if totalBurstCores > budget {
+		scale = 0.0
+		if totalBurstCores > 0 {
+			scale = budget / totalBurstCores
+		}
+		general.Infof("total dynamic cpu burst %.2f cores exceeds node budget %.2f cores, scale by %.2f", totalBurstCores, budget, scale)
+		_ = m.emitter.StoreFloat64(qrmutil.MetricNameCPUBurstBudgetExceeded, totalBurstCores-budget, metrics.MetricTypeNameRaw)
+	}
+
+	for _, c := range containers {
+		if err := m.applyCPUBurst(c.pod, c.cgroup, percents[c.key]*scale); err != nil {
+			errList = append(errList, err)
+		}
+	}
+
+	m.dynamicBurstPercents = percents
+	return utilerrors.NewAggregate(errList)
+}
+
+// validateDynamicCPUBurstConfiguration checks the configuration, since it may be
+// set from command line flags with invalid values.
+func validateDynamicCPUBurstConfiguration(burstConf *finegrainedresource.DynamicCPUBurstConfiguration) error {
+	if burstConf.DynamicCPUBurstStepPercent <= 0 {
+		return fmt.Errorf("invalid dynamic cpu burst step percent %v", burstConf.DynamicCPUBurstStepPercent)
+	}
+	if burstConf.DynamicCPUBurstMinPercent < 0 || burstConf.DynamicCPUBurstMinPercent > burstConf.DynamicCPUBurstMaxPercent {
+		return fmt.Errorf("invalid dynamic cpu burst percent range [%v, %v]",
+			burstConf.DynamicCPUBurstMinPercent, burstConf.DynamicCPUBurstMaxPercent)
+	}
+	if burstConf.DynamicCPUBurstNodeUsageLowWatermark > burstConf.DynamicCPUBurstNodeUsageHighWatermark {
+		return fmt.Errorf("dynamic cpu burst node usage low watermark %v is larger than high watermark %v",
+			burstConf.DynamicCPUBurstNodeUsageLowWatermark, burstConf.DynamicCPUBurstNodeUsageHighWatermark)
+	}
+	return nil
+}
+
+// tuneDynamicCPUBurstPercent returns the cpu burst percent for the next round according to the
+// throttling of the container and the pressure of the node.
+func tuneDynamicCPUBurstPercent(lastPercent, throttledRatio, nodeUsage float64,
+	burstConf *finegrainedresource.DynamicCPUBurstConfiguration,
+) float64 {
+	percent := lastPercent
+	switch {
+	case nodeUsage >= burstConf.DynamicCPUBurstNodeUsageHighWatermark:
+		percent -= float64(burstConf.DynamicCPUBurstStepPercent)
+	case nodeUsage < burstConf.DynamicCPUBurstNodeUsageLowWatermark &&
+		throttledRatio >= burstConf.DynamicCPUBurstThrottledRatioThreshold:
+		percent += float64(burstConf.DynamicCPUBurstStepPercent)
+	}
+
+	percent = general.MaxFloat64(percent, float64(burstConf.DynamicCPUBurstMinPercent))
+	percent = general.MinFloat64(percent, float64(burstConf.DynamicCPUBurstMaxPercent))
+	return percent
+}
+
+// getContainerThrottledRatio returns the ratio of throttled periods to all the periods of the container.
+func (m *managerImpl) getContainerThrottledRatio(podUID, containerName string) (float64, error) {
+	nrThrottled, err := m.metaServer.GetContainerMetric(podUID, containerName, coreconsts.MetricCPUNrThrottledRateContainer)
+	if err != nil {
+		return 0, err
+	}
+
+	nrPeriod, err := m.metaServer.GetContainerMetric(podUID, containerName, coreconsts.MetricCPUNrPeriodRateContainer)
+	if err != nil {
+		return 0, err
+	}
+
+	if nrPeriod.Value <= 0 {
+		return 0, nil
+	}
+	return nrThrottled.Value / nrPeriod.Value, nil
+}
+
+func (m *managerImpl) getNodeCPUCapacity() int {
+	if m.metaServer.KatalystMachineInfo == nil || m.metaServer.CPUTopology == nil {
+		return 0
+	}
+	return m.metaServer.NumCPUs
+}
+
+// listContainerCPUCgroups returns the cpu cgroups of all containers of the pod, and containers
+// whose cgroups are not ready yet are skipped.
+func (m *managerImpl) listContainerCPUCgroups(pod *v1.Pod) ([]*containerCPUCgroup, []error) {
 	var errList []error
+	var cgroups []*containerCPUCgroup
 	podUID := string(pod.GetUID())
 	podName := pod.Name
 
@@ -159,15 +352,30 @@ func (m *managerImpl) updateCPUBurstByPercent(percent float64, pod *v1.Pod) erro
 			continue
 		}
 
-		cpuBurstValue := util.CalculateCPUBurstFromPercent(percent, cpuStats.CpuQuota)
-		if err = manager.ApplyCPUWithAbsolutePath(containerAbsoluteCgroupPath, &common.CPUData{CpuBurst: &cpuBurstValue}); err != nil {
-			general.Errorf("apply container cpu burst failed, pod: %s, podName: %s, container: %s(%s), err: %v", podUID, podName, containerName, containerID, err)
-			errList = append(errList, err)
-			continue
-		}
+		cgroups = append(cgroups, &containerCPUCgroup{
+			containerName: containerName,
+			containerID:   containerID,
+			absPath:       containerAbsoluteCgroupPath,
+			stats:         cpuStats,
+		})
+	}
 
-		general.Infof("apply container cpu burst successfully, pod: %s, podName: %s, container: %s(%s), cpu burst: %d", podUID, podName, containerName, containerID, cpuBurstValue)
+	return cgroups, errList
+}
+
+// applyCPUBurst sets the cpu burst of the container to cpu quota * percent / 100.
+func (m *managerImpl) applyCPUBurst(pod *v1.Pod, c *containerCPUCgroup, percent float64) error {
+	podUID := string(pod.GetUID())
+	cpuBurstValue := util.CalculateCPUBurstFromPercent(percent, c.stats.CpuQuota)
+	if err := manager.ApplyCPUWithAbsolutePath(c.absPath, &common.CPUData{CpuBurst: &cpuBurstValue}); err != nil {
+		general.Errorf("apply container cpu burst failed, pod: %s, podName: %s, container: %s(%s), err: %v", podUID, pod.Name, c.containerName, c.containerID, err)
+		return err
 	}
 
-	return utilerrors.NewAggregate(errList)
+	general.Infof("apply container cpu burst successfully, pod: %s, podName: %s, container: %s(%s), cpu burst: %d", podUID, pod.Name, c.containerName, c.containerID, cpuBurstValue)
+	_ = m.emitter.StoreFloat64(qrmutil.MetricNameCPUBurstPercent, percent, metrics.MetricTypeNameRaw,
+		metrics.MetricTag{Key: "pod_ns", Val: pod.Namespace},
+		metrics.MetricTag{Key: "pod_name", Val: pod.Name},
+		metrics.MetricTag{Key: "container_name", Val: c.containerName})
+	return nil
 }
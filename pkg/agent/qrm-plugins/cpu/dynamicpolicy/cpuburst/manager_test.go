@@ -18,12 +18,14 @@ package cpuburst
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/bytedance/mockey"
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/ptr"
 
 	"github.com/kubewharf/katalyst-api/pkg/consts"
@@ -32,11 +34,16 @@ import (
 	"github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic/adminqos"
 	"github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic/adminqos/finegrainedresource"
 	"github.com/kubewharf/katalyst-core/pkg/config/generic"
+	coreconsts "github.com/kubewharf/katalyst-core/pkg/consts"
 	"github.com/kubewharf/katalyst-core/pkg/metaserver"
 	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent/metric"
 	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent/pod"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
 	"github.com/kubewharf/katalyst-core/pkg/util/cgroup/common"
 	"github.com/kubewharf/katalyst-core/pkg/util/cgroup/manager"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+	utilmetric "github.com/kubewharf/katalyst-core/pkg/util/metric"
 )
 
 func generateTestMetaServer(pods []*v1.Pod) *metaserver.MetaServer {
@@ -640,7 +647,7 @@ func TestManagerImpl_UpdateCPUBurst(t *testing.T) {
 				tt.mocks(results)
 			}
 
-			cpuBurstManager := newManager(generateTestMetaServer(tt.pods))
+			cpuBurstManager := newManager(generateTestMetaServer(tt.pods), metrics.DummyMetrics{})
 
 			dynamicConfig := dynamic.NewDynamicAgentConfiguration()
 			if tt.adminQoSConfig != nil {
@@ -661,3 +668,156 @@ func TestManagerImpl_UpdateCPUBurst(t *testing.T) {
 		})
 	}
 }
+
+func makeDynamicCPUBurstPod(uid string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			UID:  types.UID(uid),
+			Name: uid,
+			Annotations: map[string]string{
+				consts.PodAnnotationQoSLevelKey:       consts.PodAnnotationQoSLevelDedicatedCores,
+				consts.PodAnnotationCPUEnhancementKey: `{"cpu_burst_policy":"dynamic"}`,
+			},
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{{Name: "test-container"}},
+		},
+		Status: v1.PodStatus{
+			ContainerStatuses: []v1.ContainerStatus{
+				{Name: "test-container", ContainerID: "test-container-id"},
+			},
+		},
+	}
+}
+
+func TestTuneDynamicCPUBurstPercent(t *testing.T) {
+	t.Parallel()
+
+	burstConf := &finegrainedresource.DynamicCPUBurstConfiguration{
+		DynamicCPUBurstMinPercent:              10,
+		DynamicCPUBurstMaxPercent:              100,
+		DynamicCPUBurstStepPercent:             20,
+		DynamicCPUBurstThrottledRatioThreshold: 0.1,
+		DynamicCPUBurstNodeUsageLowWatermark:   0.6,
+		DynamicCPUBurstNodeUsageHighWatermark:  0.85,
+	}
+
+	for _, tc := range []struct {
+		comment        string
+		lastPercent    float64
+		throttledRatio float64
+		nodeUsage      float64
+		expected       float64
+	}{
+		{comment: "throttled with idle node should raise", lastPercent: 10, throttledRatio: 0.2, nodeUsage: 0.3, expected: 30},
+		{comment: "raising should be capped by max percent", lastPercent: 90, throttledRatio: 0.2, nodeUsage: 0.3, expected: 100},
+		{comment: "not throttled should hold", lastPercent: 50, throttledRatio: 0.05, nodeUsage: 0.3, expected: 50},
+		{comment: "throttled with busy node should hold", lastPercent: 50, throttledRatio: 0.2, nodeUsage: 0.7, expected: 50},
+		{comment: "saturated node should lower", lastPercent: 50, throttledRatio: 0.2, nodeUsage: 0.9, expected: 30},
+		{comment: "lowering should be capped by min percent", lastPercent: 20, throttledRatio: 0, nodeUsage: 0.9, expected: 10},
+	} {
+		assert.Equal(t, tc.expected, tuneDynamicCPUBurstPercent(tc.lastPercent, tc.throttledRatio, tc.nodeUsage, burstConf), tc.comment)
+	}
+}
+
+func TestManagerImpl_UpdateCPUBurstDynamically(t *testing.T) {
+	mockey.PatchConvey("dynamic cpu burst should follow throttling and node pressure", t, func() {
+		results := make(map[string]uint64)
+		mockey.Mock(common.IsContainerCgroupExist).Return(true, nil).Build()
+		mockey.Mock(common.GetContainerAbsCgroupPath).To(func(_, podUID, containerID string) (string, error) {
+			return "/sys/fs/cgroup/cpu/" + podUID + "/" + containerID, nil
+		}).Build()
+		mockey.Mock(manager.GetCPUWithAbsolutePath).To(func(absPath string) (*common.CPUStats, error) {
+			stats := &common.CPUStats{CpuQuota: 100000, CpuPeriod: 100000}
+			if strings.Contains(absPath, "pod-3") {
+				stats.CpuBurst = 30000
+			}
+			return stats, nil
+		}).Build()
+		mockey.Mock(manager.ApplyCPUWithAbsolutePath).
+			To(func(absPath string, cpuData *common.CPUData) error {
+				results[absPath] = *cpuData.CpuBurst
+				return nil
+			}).Build()
+
+		conf := config.NewConfiguration()
+		conf.QoSConfiguration = generic.NewQoSConfiguration()
+
+		dynamicConf := dynamic.NewConfiguration()
+		dynamicConf.DynamicCPUBurstConfiguration = &finegrainedresource.DynamicCPUBurstConfiguration{
+			DynamicCPUBurstMinPercent:              0,
+			DynamicCPUBurstMaxPercent:              200,
+			DynamicCPUBurstStepPercent:             50,
+			DynamicCPUBurstThrottledRatioThreshold: 0.1,
+			DynamicCPUBurstNodeUsageLowWatermark:   0.6,
+			DynamicCPUBurstNodeUsageHighWatermark:  0.85,
+			// 4 cpus * 0.25 means at most 1 core for burst
+			DynamicCPUBurstNodeBudgetRatio: 0.25,
+		}
+		dynamicConfig := dynamic.NewDynamicAgentConfiguration()
+		dynamicConfig.SetDynamicConfiguration(dynamicConf)
+
+		fakeFetcher := metric.NewFakeMetricsFetcher(metrics.DummyMetrics{}).(*metric.FakeMetricsFetcher)
+		setThrottledRatio := func(podUID string, ratio float64) {
+			fakeFetcher.SetContainerMetric(podUID, "test-container", coreconsts.MetricCPUNrPeriodRateContainer, utilmetric.MetricData{Value: 10})
+			fakeFetcher.SetContainerMetric(podUID, "test-container", coreconsts.MetricCPUNrThrottledRateContainer, utilmetric.MetricData{Value: 10 * ratio})
+		}
+
+		cpuTopology, err := machine.GenerateDummyCPUTopology(4, 1, 1)
+		assert.NoError(t, err)
+		podFetcher := &pod.PodFetcherStub{PodList: []*v1.Pod{makeDynamicCPUBurstPod("pod-1"), makeDynamicCPUBurstPod("pod-2")}}
+		metaServer := &metaserver.MetaServer{
+			MetaAgent: &agent.MetaAgent{
+				PodFetcher:          podFetcher,
+				MetricsFetcher:      fakeFetcher,
+				KatalystMachineInfo: &machine.KatalystMachineInfo{CPUTopology: cpuTopology},
+			},
+		}
+		m := newManager(metaServer, metrics.DummyMetrics{})
+
+		// only pod-1 is throttled, so only its burst is raised
+		fakeFetcher.SetNodeMetric(coreconsts.MetricCPUUsageRatioSystem, utilmetric.MetricData{Value: 0.3})
+		setThrottledRatio("pod-1", 0.5)
+		setThrottledRatio("pod-2", 0)
+		assert.NoError(t, m.UpdateCPUBurst(conf, dynamicConfig))
+		assert.Equal(t, map[string]uint64{
+			"/sys/fs/cgroup/cpu/pod-1/test-container-id": 50000,
+			"/sys/fs/cgroup/cpu/pod-2/test-container-id": 0,
+		}, results)
+
+		// both are throttled, and the total burst of 1.5 cores is scaled to fit in the budget
+		setThrottledRatio("pod-2", 0.5)
+		assert.NoError(t, m.UpdateCPUBurst(conf, dynamicConfig))
+		assert.Equal(t, map[string]uint64{
+			"/sys/fs/cgroup/cpu/pod-1/test-container-id": 66666,
+			"/sys/fs/cgroup/cpu/pod-2/test-container-id": 33333,
+		}, results)
+
+		// node is saturated, so burst of both is lowered from the percents before scaling
+		fakeFetcher.SetNodeMetric(coreconsts.MetricCPUUsageRatioSystem, utilmetric.MetricData{Value: 0.9})
+		assert.NoError(t, m.UpdateCPUBurst(conf, dynamicConfig))
+		assert.Equal(t, map[string]uint64{
+			"/sys/fs/cgroup/cpu/pod-1/test-container-id": 50000,
+			"/sys/fs/cgroup/cpu/pod-2/test-container-id": 0,
+		}, results)
+
+		// history of deleted pods should be cleaned up
+		podFetcher.PodList = []*v1.Pod{makeDynamicCPUBurstPod("pod-1")}
+		assert.NoError(t, m.UpdateCPUBurst(conf, dynamicConfig))
+		assert.Len(t, m.dynamicBurstPercents, 1)
+
+		// percent of new containers starts from the minimum rather than their current
+		// cpu burst, which may have been scaled by the budget, and steps up from there
+		fakeFetcher.SetNodeMetric(coreconsts.MetricCPUUsageRatioSystem, utilmetric.MetricData{Value: 0.3})
+		setThrottledRatio("pod-3", 0.5)
+		podFetcher.PodList = []*v1.Pod{makeDynamicCPUBurstPod("pod-3")}
+		assert.NoError(t, m.UpdateCPUBurst(conf, dynamicConfig))
+		assert.Equal(t, uint64(50000), results["/sys/fs/cgroup/cpu/pod-3/test-container-id"])
+		assert.Equal(t, map[string]float64{"pod-3/test-container": 50}, m.dynamicBurstPercents)
+
+		// step percent must be positive
+		dynamicConf.DynamicCPUBurstStepPercent = 0
+		dynamicConfig.SetDynamicConfiguration(dynamicConf)
+		assert.Error(t, m.UpdateCPUBurst(conf, dynamicConfig))
+	})
+}
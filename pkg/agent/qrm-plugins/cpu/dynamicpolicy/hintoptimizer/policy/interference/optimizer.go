@@ -0,0 +1,350 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package interference
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	pluginapi "k8s.io/kubelet/pkg/apis/resourceplugin/v1alpha1"
+
+	apiconsts "github.com/kubewharf/katalyst-api/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/commonstate"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/hintoptimizer"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/hintoptimizer/policy"
+	hintoptimizerutil "github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/hintoptimizer/util"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/util"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+	utilmetric "github.com/kubewharf/katalyst-core/pkg/util/metric"
+)
+
+const HintOptimizerNameInterference = "interference"
+
+const (
+	defaultCollectInterval = 30 * time.Second
+	defaultDecayFactor     = 0.2
+	defaultNoisyThreshold  = 1.2
+)
+
+// numaMetricNames are interference metrics collected at numa level (e.g. memory
+// latency reported by malachite), while other metrics are aggregated from the
+// containers that are bound to the numa.
+var numaMetricNames = sets.NewString(
+	consts.MetricMemLatencyReadNuma,
+	consts.MetricMemLatencyWriteNuma,
+	consts.MetricMemAMDL3MissLatencyNuma,
+)
+
+// movingAverage is the exponentially weighted moving average of an interference metric.
+type movingAverage struct {
+	value   float64
+	samples int
+}
+
+// interferenceHintOptimizer learns the interference history of each numa, and prefers
+// hints away from the noisy numas for latency-sensitive pods. The interference score of
+// a numa is the weighted average of its metrics normalized by the average of all numas,
+// so a score larger than 1 means the numa is noisier than the others.
+type interferenceHintOptimizer struct {
+	mutex      sync.RWMutex
+	emitter    metrics.MetricEmitter
+	metaServer *metaserver.MetaServer
+	state      state.State
+
+	latencySensitiveQoSLevels sets.String
+	collectInterval           time.Duration
+	decayFactor               float64
+	minSampleCount            int
+	noisyThreshold            float64
+	metricWeights             map[string]float64
+
+	// numaHistory maps numa id to the moving averages of its interference metrics
+	numaHistory map[int]map[string]*movingAverage
+}
+
+func NewInterferenceHintOptimizer(
+	options policy.HintOptimizerFactoryOptions,
+) (hintoptimizer.HintOptimizer, error) {
+	conf := options.Conf.InterferenceHintOptimizerConfig
+
+	numaHistory := make(map[int]map[string]*movingAverage)
+	for _, numaID := range options.MetaServer.CPUDetails.NUMANodes().ToSliceNoSortInt() {
+		numaHistory[numaID] = make(map[string]*movingAverage)
+	}
+
+	o := &interferenceHintOptimizer{
+		emitter:                   options.Emitter,
+		metaServer:                options.MetaServer,
+		state:                     options.State,
+		latencySensitiveQoSLevels: sets.NewString(conf.InterferenceLatencySensitiveQoSLevels...),
+		collectInterval:           conf.InterferenceCollectInterval,
+		decayFactor:               conf.InterferenceHistoryDecayFactor,
+		minSampleCount:            conf.InterferenceMinSampleCount,
+		noisyThreshold:            conf.InterferenceNoisyThreshold,
+		metricWeights:             make(map[string]float64),
+		numaHistory:               numaHistory,
+	}
+	if o.collectInterval <= 0 {
+		o.collectInterval = defaultCollectInterval
+	}
+	if o.decayFactor <= 0 || o.decayFactor > 1 {
+		o.decayFactor = defaultDecayFactor
+	}
+	if o.noisyThreshold <= 0 {
+		o.noisyThreshold = defaultNoisyThreshold
+	}
+	for metricName, weight := range conf.InterferenceMetricWeights {
+		if weight > 0 {
+			o.metricWeights[metricName] = weight
+		}
+	}
+	return o, nil
+}
+
+func (o *interferenceHintOptimizer) Run(stopCh <-chan struct{}) error {
+	// wait for metrics cache sync
+	if !cache.WaitForCacheSync(stopCh, o.metaServer.MetricsFetcher.HasSynced) {
+		return fmt.Errorf("wait for cache sync failed")
+	}
+	go wait.Until(o.collectNUMAInterference, o.collectInterval, stopCh)
+	return nil
+}
+
+// OptimizeHints un-prefers the hints containing noisy numas if there are any quiet
+// preferred hints left, and sorts hints by their interference scores in ascending order.
+func (o *interferenceHintOptimizer) OptimizeHints(
+	request hintoptimizer.Request,
+	hints *pluginapi.ListOfTopologyHints,
+) error {
+	err := hintoptimizerutil.GenericOptimizeHintsCheck(request, hints)
+	if err != nil {
+		general.Errorf("GenericOptimizeHintsCheck failed with error: %v", err)
+		return err
+	}
+
+	qosLevel := request.Annotations[apiconsts.PodAnnotationQoSLevelKey]
+	if !o.latencySensitiveQoSLevels.Has(qosLevel) {
+		return pkgerrors.Wrapf(hintoptimizerutil.ErrHintOptimizerSkip, "qos level %s is not latency sensitive", qosLevel)
+	}
+
+	scores, err := o.getNUMAInterferenceScores()
+	if err != nil {
+		return pkgerrors.Wrapf(hintoptimizerutil.ErrHintOptimizerSkip, "getNUMAInterferenceScores failed: %v", err)
+	}
+
+	if err := o.populateHintsByInterference(hints, scores); err != nil {
+		return pkgerrors.Wrapf(hintoptimizerutil.ErrHintOptimizerSkip, "populateHintsByInterference failed: %v", err)
+	}
+
+	general.Infof("pod %s/%s container %s hints optimized by interference scores %v: %+v",
+		request.PodNamespace, request.PodName, request.ContainerName, scores, hints.Hints)
+	return nil
+}
+
+func (o *interferenceHintOptimizer) populateHintsByInterference(hints *pluginapi.ListOfTopologyHints, scores map[int]float64) error {
+	hintScores := make(map[*pluginapi.TopologyHint]float64, len(hints.Hints))
+	quietPreferredExists := false
+	for _, hint := range hints.Hints {
+		if hint == nil {
+			return fmt.Errorf("found nil hint")
+		}
+
+		// a hint is as noisy as the noisiest numa in it
+		hintScore := 0.0
+		for _, numaID := range hint.Nodes {
+			score, ok := scores[int(numaID)]
+			if !ok {
+				return fmt.Errorf("interference score of numa %d not found", numaID)
+			}
+			hintScore = general.MaxFloat64(hintScore, score)
+		}
+		hintScores[hint] = hintScore
+
+		if hint.Preferred && hintScore < o.noisyThreshold {
+			quietPreferredExists = true
+		}
+	}
+
+	// keep the preference unchanged if all preferred hints are noisy, since
+	// the noisy hints are still better than those not preferred at all
+	if quietPreferredExists {
+		for _, hint := range hints.Hints {
+			if hint.Preferred && hintScores[hint] >= o.noisyThreshold {
+				hint.Preferred = false
+			}
+		}
+	}
+
+	sort.SliceStable(hints.Hints, func(i, j int) bool {
+		if hints.Hints[i].Preferred != hints.Hints[j].Preferred {
+			return hints.Hints[i].Preferred
+		}
+		return hintScores[hints.Hints[i]] < hintScores[hints.Hints[j]]
+	})
+	return nil
+}
+
+// getNUMAInterferenceScores returns the interference score of each numa, and only
+// the metric history with enough samples is trusted; numas without trusted history
+// of a metric are regarded as average for that metric.
+func (o *interferenceHintOptimizer) getNUMAInterferenceScores() (map[int]float64, error) {
+	o.mutex.RLock()
+	defer o.mutex.RUnlock()
+	return o.getNUMAInterferenceScoresLocked()
+}
+
+func (o *interferenceHintOptimizer) getNUMAInterferenceScoresLocked() (map[int]float64, error) {
+	if len(o.numaHistory) == 0 {
+		return nil, fmt.Errorf("no numa found")
+	}
+
+	scores := make(map[int]float64, len(o.numaHistory))
+	totalWeight := 0.0
+	for metricName, weight := range o.metricWeights {
+		trustedValues := make(map[int]float64)
+		sum := 0.0
+		for numaID, history := range o.numaHistory {
+			if avg, ok := history[metricName]; ok && avg.samples >= o.minSampleCount {
+				trustedValues[numaID] = avg.value
+				sum += avg.value
+			}
+		}
+		if len(trustedValues) == 0 || sum <= 0 {
+			continue
+		}
+
+		mean := sum / float64(len(trustedValues))
+		for numaID := range o.numaHistory {
+			normalized := 1.0
+			if value, ok := trustedValues[numaID]; ok {
+				normalized = value / mean
+			}
+			scores[numaID] += weight * normalized
+		}
+		totalWeight += weight
+	}
+
+	if totalWeight == 0 {
+		return nil, fmt.Errorf("insufficient interference history")
+	}
+
+	for numaID := range scores {
+		scores[numaID] /= totalWeight
+	}
+	return scores, nil
+}
+
+func (o *interferenceHintOptimizer) collectNUMAInterference() {
+	machineState := o.state.GetMachineState()
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	for numaID, history := range o.numaHistory {
+		for metricName := range o.metricWeights {
+			value, err := o.getNUMAMetric(numaID, metricName, machineState)
+			if err != nil {
+				general.Warningf("get metric %s for numa %d failed: %v", metricName, numaID, err)
+				continue
+			}
+
+			avg, ok := history[metricName]
+			if !ok {
+				history[metricName] = &movingAverage{value: value, samples: 1}
+				continue
+			}
+			avg.value = o.decayFactor*value + (1-o.decayFactor)*avg.value
+			avg.samples++
+		}
+	}
+
+	scores, err := o.getNUMAInterferenceScoresLocked()
+	if err != nil {
+		general.Infof("skip emitting numa interference scores: %v", err)
+		return
+	}
+
+	for numaID, score := range scores {
+		_ = o.emitter.StoreFloat64(util.MetricNameNUMAInterferenceScore, score, metrics.MetricTypeNameRaw,
+			metrics.MetricTag{Key: "numa_id", Val: fmt.Sprintf("%d", numaID)})
+		noisy := int64(0)
+		if score >= o.noisyThreshold {
+			noisy = 1
+		}
+		_ = o.emitter.StoreInt64(util.MetricNameNUMAInterferenceNoisy, noisy, metrics.MetricTypeNameRaw,
+			metrics.MetricTag{Key: "numa_id", Val: fmt.Sprintf("%d", numaID)})
+	}
+}
+
+// isMetricStale returns true if the sample was updated more than two collect
+// intervals ago; samples without timestamp are considered fresh.
+func (o *interferenceHintOptimizer) isMetricStale(data utilmetric.MetricData, now time.Time) bool {
+	if data.Time == nil || data.Time.IsZero() {
+		return false
+	}
+	return data.Time.Before(now.Add(-2 * o.collectInterval))
+}
+
+// getNUMAMetric returns the value of the given metric for the numa; container level
+// metrics are averaged over the containers bound to the numa.
+func (o *interferenceHintOptimizer) getNUMAMetric(numaID int, metricName string, machineState state.NUMANodeMap) (float64, error) {
+	now := time.Now()
+	if numaMetricNames.Has(metricName) {
+		data, err := o.metaServer.GetNumaMetric(numaID, metricName)
+		if err != nil {
+			return 0, err
+		}
+		if o.isMetricStale(data, now) {
+			return 0, fmt.Errorf("metric data is stale")
+		}
+		return data.Value, nil
+	}
+
+	if machineState == nil || machineState[numaID] == nil {
+		return 0, fmt.Errorf("invalid machineState")
+	}
+
+	entries := machineState[numaID].PodEntries.GetFilteredPodEntries(
+		state.WrapAllocationMetaFilter((*commonstate.AllocationMeta).CheckSharedOrDedicatedNUMABinding))
+
+	sum, count := 0.0, 0
+	for podUID, containerEntries := range entries {
+		for containerName := range containerEntries {
+			data, err := o.metaServer.GetContainerMetric(podUID, containerName, metricName)
+			if err != nil || o.isMetricStale(data, now) {
+				// containers may be just started without any metric
+				continue
+			}
+			sum += data.Value
+			count++
+		}
+	}
+
+	if count == 0 {
+		return 0, fmt.Errorf("no container metric found")
+	}
+	return sum / float64(count), nil
+}
@@ -0,0 +1,317 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package interference
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pluginapi "k8s.io/kubelet/pkg/apis/resourceplugin/v1alpha1"
+
+	apiconsts "github.com/kubewharf/katalyst-api/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/commonstate"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/hintoptimizer"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/hintoptimizer/policy"
+	hintoptimizerutil "github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/hintoptimizer/util"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/config"
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/qrm/statedirectory"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent/metric"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+	utilmetric "github.com/kubewharf/katalyst-core/pkg/util/metric"
+)
+
+const testMinSampleCount = 3
+
+func makeNUMABindingEntries(podUID string) state.PodEntries {
+	return state.PodEntries{
+		podUID: state.ContainerEntries{
+			"c": &state.AllocationInfo{
+				AllocationMeta: commonstate.AllocationMeta{
+					PodUid:        podUID,
+					ContainerName: "c",
+					QoSLevel:      apiconsts.PodAnnotationQoSLevelDedicatedCores,
+					Annotations: map[string]string{
+						apiconsts.PodAnnotationMemoryEnhancementNumaBinding: apiconsts.PodAnnotationMemoryEnhancementNumaBindingEnable,
+					},
+				},
+			},
+		},
+	}
+}
+
+// newTestOptimizer builds an optimizer on a machine with 2 numas, where numa 0 is
+// noisier than numa 1 in both memory latency and cpi of the bound containers.
+func newTestOptimizer(t *testing.T) (*interferenceHintOptimizer, *metric.FakeMetricsFetcher) {
+	conf := config.NewConfiguration()
+	conf.InterferenceLatencySensitiveQoSLevels = []string{apiconsts.PodAnnotationQoSLevelDedicatedCores}
+	conf.InterferenceMinSampleCount = testMinSampleCount
+	conf.InterferenceHistoryDecayFactor = 0.5
+	conf.InterferenceNoisyThreshold = 1.2
+	conf.InterferenceMetricWeights = map[string]float64{
+		consts.MetricMemLatencyReadNuma:          1,
+		consts.MetricCPUCPIContainer:             1,
+		consts.MetricCPUNrThrottledRateContainer: 0.5,
+	}
+
+	fakeFetcher := metric.NewFakeMetricsFetcher(metrics.DummyMetrics{}).(*metric.FakeMetricsFetcher)
+	fakeFetcher.SetNumaMetric(0, consts.MetricMemLatencyReadNuma, utilmetric.MetricData{Value: 200})
+	fakeFetcher.SetNumaMetric(1, consts.MetricMemLatencyReadNuma, utilmetric.MetricData{Value: 100})
+	fakeFetcher.SetContainerMetric("pod-0", "c", consts.MetricCPUCPIContainer, utilmetric.MetricData{Value: 2})
+	fakeFetcher.SetContainerMetric("pod-1", "c", consts.MetricCPUCPIContainer, utilmetric.MetricData{Value: 1})
+
+	cpuTopology, err := machine.GenerateDummyCPUTopology(16, 2, 2)
+	require.NoError(t, err)
+	metaServer := &metaserver.MetaServer{
+		MetaAgent: &agent.MetaAgent{
+			KatalystMachineInfo: &machine.KatalystMachineInfo{
+				CPUTopology: cpuTopology,
+			},
+			MetricsFetcher: fakeFetcher,
+		},
+	}
+
+	tmpDir, err := ioutil.TempDir("", "checkpoint-TestInterferenceHintOptimizer")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	stateImpl, err := state.NewCheckpointState(&statedirectory.StateDirectoryConfiguration{StateFileDirectory: tmpDir},
+		"test", "test", cpuTopology, false, state.GenerateMachineStateFromPodEntries, metrics.DummyMetrics{})
+	require.NoError(t, err)
+	stateImpl.SetMachineState(state.NUMANodeMap{
+		0: &state.NUMANodeState{PodEntries: makeNUMABindingEntries("pod-0")},
+		1: &state.NUMANodeState{PodEntries: makeNUMABindingEntries("pod-1")},
+	}, false)
+
+	optimizer, err := NewInterferenceHintOptimizer(policy.HintOptimizerFactoryOptions{
+		Conf:         conf,
+		MetaServer:   metaServer,
+		Emitter:      metrics.DummyMetrics{},
+		State:        stateImpl,
+		ReservedCPUs: machine.NewCPUSet(),
+	})
+	require.NoError(t, err)
+	return optimizer.(*interferenceHintOptimizer), fakeFetcher
+}
+
+func TestNewInterferenceHintOptimizer(t *testing.T) {
+	t.Parallel()
+
+	o, _ := newTestOptimizer(t)
+	assert.Len(t, o.numaHistory, 2)
+	assert.Equal(t, defaultCollectInterval, o.collectInterval)
+	assert.Equal(t, 0.5, o.decayFactor)
+	assert.Len(t, o.metricWeights, 3)
+}
+
+func TestInterferenceHintOptimizer_collectNUMAInterference(t *testing.T) {
+	t.Parallel()
+
+	o, fakeFetcher := newTestOptimizer(t)
+
+	for i := 0; i < testMinSampleCount-1; i++ {
+		o.collectNUMAInterference()
+	}
+	_, err := o.getNUMAInterferenceScores()
+	assert.Error(t, err, "history should not be trusted before enough samples")
+
+	o.collectNUMAInterference()
+	scores, err := o.getNUMAInterferenceScores()
+	require.NoError(t, err)
+	// throttling metrics are missing, so only memory latency and cpi are scored
+	assert.InDelta(t, 4.0/3, scores[0], 1e-6)
+	assert.InDelta(t, 2.0/3, scores[1], 1e-6)
+
+	// interference moves to numa 1, and the history should follow it gradually
+	fakeFetcher.SetNumaMetric(0, consts.MetricMemLatencyReadNuma, utilmetric.MetricData{Value: 100})
+	fakeFetcher.SetNumaMetric(1, consts.MetricMemLatencyReadNuma, utilmetric.MetricData{Value: 200})
+	fakeFetcher.SetContainerMetric("pod-0", "c", consts.MetricCPUCPIContainer, utilmetric.MetricData{Value: 1})
+	fakeFetcher.SetContainerMetric("pod-1", "c", consts.MetricCPUCPIContainer, utilmetric.MetricData{Value: 2})
+	o.collectNUMAInterference()
+	scores, err = o.getNUMAInterferenceScores()
+	require.NoError(t, err)
+	assert.InDelta(t, 1.0, scores[0], 1e-6)
+	assert.InDelta(t, 1.0, scores[1], 1e-6)
+
+	for i := 0; i < 5; i++ {
+		o.collectNUMAInterference()
+	}
+	scores, err = o.getNUMAInterferenceScores()
+	require.NoError(t, err)
+	assert.Less(t, scores[0], scores[1])
+}
+
+func TestInterferenceHintOptimizer_collectNUMAInterferenceStale(t *testing.T) {
+	t.Parallel()
+
+	o, fakeFetcher := newTestOptimizer(t)
+	// keep the stale samples within the insurance period of the metrics fetcher
+	o.collectInterval = 10 * time.Second
+	for i := 0; i < testMinSampleCount; i++ {
+		o.collectNUMAInterference()
+	}
+	scores, err := o.getNUMAInterferenceScores()
+	require.NoError(t, err)
+
+	// stale samples should not move the history
+	staleTime := time.Now().Add(-3 * o.collectInterval)
+	fakeFetcher.SetNumaMetric(0, consts.MetricMemLatencyReadNuma, utilmetric.MetricData{Value: 100, Time: &staleTime})
+	fakeFetcher.SetNumaMetric(1, consts.MetricMemLatencyReadNuma, utilmetric.MetricData{Value: 200, Time: &staleTime})
+	fakeFetcher.SetContainerMetric("pod-0", "c", consts.MetricCPUCPIContainer, utilmetric.MetricData{Value: 1, Time: &staleTime})
+	fakeFetcher.SetContainerMetric("pod-1", "c", consts.MetricCPUCPIContainer, utilmetric.MetricData{Value: 2, Time: &staleTime})
+	o.collectNUMAInterference()
+	staleScores, err := o.getNUMAInterferenceScores()
+	require.NoError(t, err)
+	assert.InDelta(t, scores[0], staleScores[0], 1e-6)
+	assert.InDelta(t, scores[1], staleScores[1], 1e-6)
+
+	freshTime := time.Now()
+	fakeFetcher.SetNumaMetric(0, consts.MetricMemLatencyReadNuma, utilmetric.MetricData{Value: 100, Time: &freshTime})
+	fakeFetcher.SetNumaMetric(1, consts.MetricMemLatencyReadNuma, utilmetric.MetricData{Value: 200, Time: &freshTime})
+	o.collectNUMAInterference()
+	freshScores, err := o.getNUMAInterferenceScores()
+	require.NoError(t, err)
+	assert.Less(t, freshScores[0], scores[0])
+}
+
+func TestInterferenceHintOptimizer_OptimizeHints(t *testing.T) {
+	t.Parallel()
+
+	makeRequest := func(qosLevel string) hintoptimizer.Request {
+		return hintoptimizer.Request{
+			ResourceRequest: &pluginapi.ResourceRequest{
+				PodUid:        "test-pod",
+				PodNamespace:  "test",
+				PodName:       "test",
+				ContainerName: "test",
+				Annotations: map[string]string{
+					apiconsts.PodAnnotationQoSLevelKey: qosLevel,
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		collectTimes  int
+		request       hintoptimizer.Request
+		hints         *pluginapi.ListOfTopologyHints
+		wantSkip      bool
+		expectedHints []*pluginapi.TopologyHint
+	}{
+		{
+			name:         "not latency sensitive",
+			collectTimes: testMinSampleCount,
+			request:      makeRequest(apiconsts.PodAnnotationQoSLevelSharedCores),
+			hints: &pluginapi.ListOfTopologyHints{Hints: []*pluginapi.TopologyHint{
+				{Nodes: []uint64{0}, Preferred: true},
+				{Nodes: []uint64{1}, Preferred: true},
+			}},
+			wantSkip: true,
+			expectedHints: []*pluginapi.TopologyHint{
+				{Nodes: []uint64{0}, Preferred: true},
+				{Nodes: []uint64{1}, Preferred: true},
+			},
+		},
+		{
+			name:         "insufficient history",
+			collectTimes: testMinSampleCount - 1,
+			request:      makeRequest(apiconsts.PodAnnotationQoSLevelDedicatedCores),
+			hints: &pluginapi.ListOfTopologyHints{Hints: []*pluginapi.TopologyHint{
+				{Nodes: []uint64{0}, Preferred: true},
+				{Nodes: []uint64{1}, Preferred: true},
+			}},
+			wantSkip: true,
+			expectedHints: []*pluginapi.TopologyHint{
+				{Nodes: []uint64{0}, Preferred: true},
+				{Nodes: []uint64{1}, Preferred: true},
+			},
+		},
+		{
+			name:         "prefer hints away from noisy numa",
+			collectTimes: testMinSampleCount,
+			request:      makeRequest(apiconsts.PodAnnotationQoSLevelDedicatedCores),
+			hints: &pluginapi.ListOfTopologyHints{Hints: []*pluginapi.TopologyHint{
+				{Nodes: []uint64{0}, Preferred: true},
+				{Nodes: []uint64{0, 1}, Preferred: false},
+				{Nodes: []uint64{1}, Preferred: true},
+			}},
+			expectedHints: []*pluginapi.TopologyHint{
+				{Nodes: []uint64{1}, Preferred: true},
+				{Nodes: []uint64{0}, Preferred: false},
+				{Nodes: []uint64{0, 1}, Preferred: false},
+			},
+		},
+		{
+			name:         "keep preference if all preferred hints are noisy",
+			collectTimes: testMinSampleCount,
+			request:      makeRequest(apiconsts.PodAnnotationQoSLevelDedicatedCores),
+			hints: &pluginapi.ListOfTopologyHints{Hints: []*pluginapi.TopologyHint{
+				{Nodes: []uint64{1}, Preferred: false},
+				{Nodes: []uint64{0}, Preferred: true},
+			}},
+			expectedHints: []*pluginapi.TopologyHint{
+				{Nodes: []uint64{0}, Preferred: true},
+				{Nodes: []uint64{1}, Preferred: false},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			o, _ := newTestOptimizer(t)
+			for i := 0; i < tt.collectTimes; i++ {
+				o.collectNUMAInterference()
+			}
+
+			err := o.OptimizeHints(tt.request, tt.hints)
+			if tt.wantSkip {
+				assert.True(t, hintoptimizerutil.IsSkipOptimizeHintsError(err))
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedHints, tt.hints.Hints)
+		})
+	}
+}
+
+func TestInterferenceHintOptimizer_Run(t *testing.T) {
+	t.Parallel()
+
+	o, _ := newTestOptimizer(t)
+	o.collectInterval = 10 * time.Millisecond
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	require.NoError(t, o.Run(stopCh))
+
+	assert.Eventually(t, func() bool {
+		_, err := o.getNUMAInterferenceScores()
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+}
@@ -19,6 +19,7 @@ package registry
 import (
 	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/hintoptimizer/policy"
 	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/hintoptimizer/policy/canonical"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/hintoptimizer/policy/interference"
 	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/hintoptimizer/policy/memorybandwidth"
 	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/hintoptimizer/policy/metricbased"
 	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/hintoptimizer/policy/resourcepackage"
@@ -26,12 +27,14 @@ import (
 
 var SharedCoresHintOptimizerRegistry = policy.HintOptimizerRegistry{
 	canonical.HintOptimizerNameCanonical:             canonical.NewCanonicalHintOptimizer,
+	interference.HintOptimizerNameInterference:       interference.NewInterferenceHintOptimizer,
 	memorybandwidth.HintOptimizerNameMemoryBandwidth: memorybandwidth.NewMemoryBandwidthHintOptimizer,
 	metricbased.HintOptimizerNameMetricBased:         metricbased.NewMetricBasedHintOptimizer,
 	resourcepackage.HintOptimizerNameResourcePackage: resourcepackage.NewResourcePackageHintOptimizer,
 }
 
 var DedicatedCoresHintOptimizerRegistry = policy.HintOptimizerRegistry{
+	interference.HintOptimizerNameInterference:       interference.NewInterferenceHintOptimizer,
 	memorybandwidth.HintOptimizerNameMemoryBandwidth: memorybandwidth.NewMemoryBandwidthHintOptimizer,
 	resourcepackage.HintOptimizerNameResourcePackage: resourcepackage.NewResourcePackageHintOptimizer,
 }
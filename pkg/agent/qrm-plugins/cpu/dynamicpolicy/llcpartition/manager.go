@@ -0,0 +1,263 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package llcpartition
+
+import (
+	"fmt"
+	"math/bits"
+	"sort"
+	"sync"
+
+	"github.com/spf13/afero"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/commonstate"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	qrmutil "github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/util"
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/qrm"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+)
+
+// Manager partitions L3 cache ways between online pools (share and dedicated) and the
+// reclaimed pool through resctrl; the ways of the reclaimed pool grow when online pods
+// rarely miss L3 cache, and shrink when online pods suffer from cache misses.
+//
+// It follows the resctrl group layout hinted by the memory plugin (i.e. shared-xx groups
+// for shared_cores pods, and reclaim and dedicated groups for the others), and tasks are
+// put into those groups by the runtime, so only L3 masks of the groups are maintained here.
+type Manager interface {
+	Sync() error
+}
+
+type managerImpl struct {
+	mutex sync.Mutex
+
+	metaServer *metaserver.MetaServer
+	emitter    metrics.MetricEmitter
+	state      state.ReadonlyState
+	resctrl    *resctrlFS
+
+	resctrlConf       *qrm.ResctrlConfig
+	minReclaimedWays  int
+	maxReclaimedWays  int
+	mpkiLowThreshold  float64
+	mpkiHighThreshold float64
+
+	// reclaimedWays is the current number of L3 ways for the reclaimed pool,
+	// and zero means that it hasn't been initialized yet
+	reclaimedWays int
+}
+
+func NewManager(metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter, st state.ReadonlyState,
+	conf *qrm.CPUDynamicPolicyConfig, resctrlConf *qrm.ResctrlConfig,
+) (Manager, error) {
+	return newManager(metaServer, emitter, st, afero.NewOsFs(), resctrlRoot, conf, resctrlConf)
+}
+
+func newManager(metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter, st state.ReadonlyState,
+	fs afero.Fs, root string, conf *qrm.CPUDynamicPolicyConfig, resctrlConf *qrm.ResctrlConfig,
+) (*managerImpl, error) {
+	if resctrlConf == nil {
+		return nil, fmt.Errorf("nil resctrl config")
+	}
+
+	if conf.LLCPartitionMinReclaimedWays <= 0 || conf.LLCPartitionMinReclaimedWays > conf.LLCPartitionMaxReclaimedWays {
+		return nil, fmt.Errorf("invalid reclaimed ways range [%d, %d]", conf.LLCPartitionMinReclaimedWays, conf.LLCPartitionMaxReclaimedWays)
+	} else if conf.LLCPartitionMPKILowThreshold > conf.LLCPartitionMPKIHighThreshold {
+		return nil, fmt.Errorf("mpki low threshold %v is larger than high threshold %v",
+			conf.LLCPartitionMPKILowThreshold, conf.LLCPartitionMPKIHighThreshold)
+	}
+
+	return &managerImpl{
+		metaServer:        metaServer,
+		emitter:           emitter,
+		state:             st,
+		resctrl:           newResctrlFS(fs, root),
+		resctrlConf:       resctrlConf,
+		minReclaimedWays:  conf.LLCPartitionMinReclaimedWays,
+		maxReclaimedWays:  conf.LLCPartitionMaxReclaimedWays,
+		mpkiLowThreshold:  conf.LLCPartitionMPKILowThreshold,
+		mpkiHighThreshold: conf.LLCPartitionMPKIHighThreshold,
+	}, nil
+}
+
+// Sync tunes the ways of the reclaimed pool by one step, and writes L3 masks of the groups of
+// all the pools. The reclaimed pool takes the lowest ways exclusively, and online pools share
+// the rest of ways.
+func (m *managerImpl) Sync() error {
+	info, err := m.resctrl.getL3Info()
+	if err != nil {
+		return fmt.Errorf("get L3 info failed: %v", err)
+	}
+
+	// both the reclaimed pool and online pools need at least min_cbm_bits ways
+	minWays := general.Max(m.minReclaimedWays, info.minCBMBits)
+	maxWays := general.Min(m.maxReclaimedWays, info.numWays()-info.minCBMBits)
+	if minWays > maxWays {
+		return fmt.Errorf("no enough L3 ways (%d) for partition", info.numWays())
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	podEntries := m.state.GetPodEntries()
+	m.tuneReclaimedWays(podEntries, info, minWays, maxWays)
+
+	reclaimedMask := getContiguousMask(info.cbmMask, m.reclaimedWays, 0)
+	onlineMask := info.cbmMask &^ reclaimedMask
+	general.Infof("reclaimed ways: %d, reclaimed mask: %x, online mask: %x", m.reclaimedWays, reclaimedMask, onlineMask)
+
+	// system group is hinted by memory plugin for system_cores pods, and its tasks should never
+	// share the ways of the reclaimed pool either
+	groupMasks := map[string]uint64{
+		commonstate.PoolNameReclaim:      reclaimedMask,
+		commonstate.PoolNamePrefixSystem: onlineMask,
+	}
+	for _, group := range m.getOnlineGroups(podEntries) {
+		groupMasks[group] = onlineMask
+	}
+
+	groups := make([]string, 0, len(groupMasks))
+	for group := range groupMasks {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+
+	var errList []error
+	for _, group := range groups {
+		// groups are created along with pods by the runtime, so we never create them here
+		if exist, err := m.resctrl.isGroupExist(group); err != nil {
+			errList = append(errList, err)
+			continue
+		} else if !exist {
+			general.Infof("resctrl group %s does not exist, skip writing L3 mask", group)
+			continue
+		}
+
+		if err := m.resctrl.writeL3Schemata(group, info.cacheIDs, groupMasks[group]); err != nil {
+			errList = append(errList, fmt.Errorf("write schemata for group %s failed: %v", group, err))
+		}
+	}
+	return utilerrors.NewAggregate(errList)
+}
+
+// tuneReclaimedWays starts the reclaimed pool with the ways in its current L3 mask (or the
+// minimum ways if it's not partitioned yet), and adjusts it by one way each time according
+// to the cache misses of online pods.
+func (m *managerImpl) tuneReclaimedWays(podEntries state.PodEntries, info *l3Info, minWays, maxWays int) {
+	if m.reclaimedWays == 0 {
+		m.reclaimedWays = m.getCurrentReclaimedWays(info)
+		if m.reclaimedWays == 0 {
+			m.reclaimedWays = minWays
+		}
+	} else if mpki, err := m.getOnlineMPKI(podEntries); err != nil {
+		general.Warningf("get online mpki failed, keep reclaimed ways unchanged: %v", err)
+	} else {
+		_ = m.emitter.StoreFloat64(qrmutil.MetricNameLLCOnlineMPKI, mpki, metrics.MetricTypeNameRaw)
+		if mpki >= m.mpkiHighThreshold {
+			m.reclaimedWays--
+		} else if mpki < m.mpkiLowThreshold {
+			m.reclaimedWays++
+		}
+		general.Infof("online mpki: %.2f, low threshold: %.2f, high threshold: %.2f", mpki, m.mpkiLowThreshold, m.mpkiHighThreshold)
+	}
+
+	m.reclaimedWays = general.Max(m.reclaimedWays, minWays)
+	m.reclaimedWays = general.Min(m.reclaimedWays, maxWays)
+	_ = m.emitter.StoreInt64(qrmutil.MetricNameLLCReclaimedWays, int64(m.reclaimedWays), metrics.MetricTypeNameRaw)
+}
+
+// getCurrentReclaimedWays returns the number of ways in the current L3 mask of the reclaim group,
+// so that the tuning goes on after the agent restarts; zero is returned if the reclaim group isn't
+// partitioned yet, i.e. it doesn't exist or it still takes all the ways.
+func (m *managerImpl) getCurrentReclaimedWays(info *l3Info) int {
+	masks, err := m.resctrl.readL3Schemata(commonstate.PoolNameReclaim)
+	if err != nil {
+		general.Infof("read current L3 mask of group %s failed: %v", commonstate.PoolNameReclaim, err)
+		return 0
+	}
+
+	mask, ok := masks[info.cacheIDs[0]]
+	if !ok || mask == 0 || mask == info.cbmMask {
+		return 0
+	}
+	return bits.OnesCount64(mask)
+}
+
+// getOnlineGroups returns the resctrl groups of online pods, and the groups are the same
+// as the ones hinted by the memory plugin.
+func (m *managerImpl) getOnlineGroups(podEntries state.PodEntries) []string {
+	groups := make(map[string]struct{})
+	for _, entries := range podEntries {
+		if entries.IsPoolEntry() {
+			continue
+		}
+
+		for _, allocationInfo := range entries {
+			switch {
+			case allocationInfo == nil:
+				continue
+			case allocationInfo.CheckShared():
+				groups[qrmutil.GetResctrlSharedSubgroupByPool(m.resctrlConf, allocationInfo.GetSpecifiedPoolName())] = struct{}{}
+			case allocationInfo.CheckDedicated():
+				groups[commonstate.PoolNameDedicated] = struct{}{}
+			}
+		}
+	}
+
+	result := make([]string, 0, len(groups))
+	for group := range groups {
+		result = append(result, group)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// getOnlineMPKI returns L3 cache misses per kilo instructions of all online containers.
+func (m *managerImpl) getOnlineMPKI(podEntries state.PodEntries) (float64, error) {
+	totalMisses, totalInstructions := 0.0, 0.0
+	for podUID, entries := range podEntries {
+		if entries.IsPoolEntry() {
+			continue
+		}
+
+		for containerName, allocationInfo := range entries {
+			if allocationInfo == nil || commonstate.GetPoolType(allocationInfo.GetPoolName()) == commonstate.PoolNameReclaim {
+				continue
+			}
+
+			misses, err := m.metaServer.GetContainerMetric(podUID, containerName, consts.MetricCPUL3CacheMissRateContainer)
+			if err != nil {
+				continue
+			}
+			instructions, err := m.metaServer.GetContainerMetric(podUID, containerName, consts.MetricCPUInstructionsRateContainer)
+			if err != nil {
+				continue
+			}
+			totalMisses += misses.Value
+			totalInstructions += instructions.Value
+		}
+	}
+
+	if totalInstructions <= 0 {
+		return 0, fmt.Errorf("no instructions of online containers found")
+	}
+	return totalMisses / totalInstructions * 1000, nil
+}
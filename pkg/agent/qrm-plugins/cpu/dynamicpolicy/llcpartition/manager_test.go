@@ -0,0 +1,230 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package llcpartition
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiconsts "github.com/kubewharf/katalyst-api/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/commonstate"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/qrm"
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/qrm/statedirectory"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent/metric"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+	utilmetric "github.com/kubewharf/katalyst-core/pkg/util/metric"
+)
+
+func makeContainerEntries(podUID, qosLevel, poolName string) state.ContainerEntries {
+	annotations := map[string]string{}
+	if qosLevel == apiconsts.PodAnnotationQoSLevelSharedCores {
+		annotations[apiconsts.PodAnnotationCPUEnhancementCPUSet] = poolName
+	}
+
+	return state.ContainerEntries{
+		"c": &state.AllocationInfo{
+			AllocationMeta: commonstate.AllocationMeta{
+				PodUid:        podUID,
+				ContainerName: "c",
+				QoSLevel:      qosLevel,
+				OwnerPoolName: poolName,
+				Annotations:   annotations,
+			},
+		},
+	}
+}
+
+func newTestConf() *qrm.CPUDynamicPolicyConfig {
+	return &qrm.CPUDynamicPolicyConfig{
+		EnableLLCPartition:            true,
+		LLCPartitionMinReclaimedWays:  2,
+		LLCPartitionMaxReclaimedWays:  4,
+		LLCPartitionMPKILowThreshold:  5,
+		LLCPartitionMPKIHighThreshold: 10,
+	}
+}
+
+// newTestResctrlConf puts shared_cores pods in bmq pool into shared-30 group, and the others into shared-50 group
+func newTestResctrlConf() *qrm.ResctrlConfig {
+	return &qrm.ResctrlConfig{
+		CPUSetPoolToSharedSubgroup: map[string]int{"bmq": 30},
+		DefaultSharedSubgroup:      50,
+	}
+}
+
+// newTestGroup creates a resctrl group in the fake resctrl tree, as it's created by the runtime
+func newTestGroup(t *testing.T, fs afero.Fs, group, schemata string) {
+	require.NoError(t, afero.WriteFile(fs, filepath.Join(testResctrlRoot, group, fileSchemata), []byte(schemata), filePermResctrl))
+}
+
+// newTestManager builds a manager against a fake resctrl tree, with two shared_cores pods in share
+// and bmq pools, one dedicated_cores pod and one reclaimed_cores pod; the groups of the pods in share
+// pool and reclaim pool have been created.
+func newTestManager(t *testing.T, reclaimSchemata string) (*managerImpl, *metric.FakeMetricsFetcher) {
+	fakeFetcher := metric.NewFakeMetricsFetcher(metrics.DummyMetrics{}).(*metric.FakeMetricsFetcher)
+	metaServer := &metaserver.MetaServer{
+		MetaAgent: &agent.MetaAgent{
+			MetricsFetcher: fakeFetcher,
+		},
+	}
+
+	cpuTopology, err := machine.GenerateDummyCPUTopology(16, 2, 2)
+	require.NoError(t, err)
+	tmpDir, err := ioutil.TempDir("", "checkpoint-TestLLCPartitionManager")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+
+	stateImpl, err := state.NewCheckpointState(&statedirectory.StateDirectoryConfiguration{StateFileDirectory: tmpDir},
+		"test", "test", cpuTopology, false, state.GenerateMachineStateFromPodEntries, metrics.DummyMetrics{})
+	require.NoError(t, err)
+	stateImpl.SetPodEntries(state.PodEntries{
+		"pod-share":     makeContainerEntries("pod-share", apiconsts.PodAnnotationQoSLevelSharedCores, commonstate.PoolNameShare),
+		"pod-bmq":       makeContainerEntries("pod-bmq", apiconsts.PodAnnotationQoSLevelSharedCores, "bmq"),
+		"pod-dedicated": makeContainerEntries("pod-dedicated", apiconsts.PodAnnotationQoSLevelDedicatedCores, commonstate.PoolNameDedicated),
+		"pod-reclaim":   makeContainerEntries("pod-reclaim", apiconsts.PodAnnotationQoSLevelReclaimedCores, commonstate.PoolNameReclaim),
+	}, false)
+
+	fs := newFakeResctrlFS(t)
+	newTestGroup(t, fs, "shared-50", "MB:0=100;1=100\nL3:0=7ff;1=7ff\n")
+	newTestGroup(t, fs, "reclaim", reclaimSchemata)
+
+	m, err := newManager(metaServer, metrics.DummyMetrics{}, stateImpl, fs, testResctrlRoot, newTestConf(), newTestResctrlConf())
+	require.NoError(t, err)
+	return m, fakeFetcher
+}
+
+// setOnlineMPKI sets metrics of online containers, so that their overall mpki equals to the given value
+func setOnlineMPKI(fetcher *metric.FakeMetricsFetcher, mpki float64) {
+	for _, podUID := range []string{"pod-share", "pod-bmq", "pod-dedicated"} {
+		fetcher.SetContainerMetric(podUID, "c", consts.MetricCPUInstructionsRateContainer, utilmetric.MetricData{Value: 1e6})
+		fetcher.SetContainerMetric(podUID, "c", consts.MetricCPUL3CacheMissRateContainer, utilmetric.MetricData{Value: mpki * 1e3})
+	}
+	// misses of reclaimed containers should never be taken into account
+	fetcher.SetContainerMetric("pod-reclaim", "c", consts.MetricCPUInstructionsRateContainer, utilmetric.MetricData{Value: 1e6})
+	fetcher.SetContainerMetric("pod-reclaim", "c", consts.MetricCPUL3CacheMissRateContainer, utilmetric.MetricData{Value: 1e6})
+}
+
+func TestNewManager(t *testing.T) {
+	t.Parallel()
+
+	_, err := newManager(nil, metrics.DummyMetrics{}, nil, newFakeResctrlFS(t), testResctrlRoot, newTestConf(), nil)
+	assert.Error(t, err, "resctrl config is required")
+
+	for _, tc := range []struct {
+		comment string
+		modify  func(conf *qrm.CPUDynamicPolicyConfig)
+	}{
+		{
+			comment: "invalid ways range",
+			modify: func(conf *qrm.CPUDynamicPolicyConfig) {
+				conf.LLCPartitionMinReclaimedWays = 5
+			},
+		},
+		{
+			comment: "invalid mpki thresholds",
+			modify: func(conf *qrm.CPUDynamicPolicyConfig) {
+				conf.LLCPartitionMPKILowThreshold = 20
+			},
+		},
+	} {
+		conf := newTestConf()
+		tc.modify(conf)
+		_, err := newManager(nil, metrics.DummyMetrics{}, nil, newFakeResctrlFS(t), testResctrlRoot, conf, newTestResctrlConf())
+		assert.Error(t, err, tc.comment)
+	}
+}
+
+func TestManagerImpl_Sync(t *testing.T) {
+	t.Parallel()
+
+	m, fetcher := newTestManager(t, "MB:0=100;1=100\nL3:0=7ff;1=7ff\n")
+	assertSchemata := func(reclaimedMask, onlineMask uint64) {
+		masks, err := m.resctrl.readL3Schemata("reclaim")
+		require.NoError(t, err)
+		assert.Equal(t, map[int]uint64{0: reclaimedMask, 1: reclaimedMask}, masks)
+
+		masks, err = m.resctrl.readL3Schemata("shared-50")
+		require.NoError(t, err)
+		assert.Equal(t, map[int]uint64{0: onlineMask, 1: onlineMask}, masks)
+	}
+
+	// reclaimed pool starts with the minimum ways since it still takes all the ways
+	require.NoError(t, m.Sync())
+	assert.Equal(t, 2, m.reclaimedWays)
+	assertSchemata(0x3, 0x7fc)
+
+	// groups not created by the runtime yet are left as they are
+	for _, group := range []string{"shared-30", "dedicated", "system"} {
+		exist, err := m.resctrl.isGroupExist(group)
+		require.NoError(t, err)
+		assert.False(t, exist, group)
+	}
+
+	// ways are kept unchanged without metrics
+	require.NoError(t, m.Sync())
+	assert.Equal(t, 2, m.reclaimedWays)
+
+	// grow by one way each time when online pods rarely miss cache, bounded by the maximum ways
+	setOnlineMPKI(fetcher, 1)
+	for _, expected := range []int{3, 4, 4} {
+		require.NoError(t, m.Sync())
+		assert.Equal(t, expected, m.reclaimedWays)
+	}
+	assertSchemata(0xf, 0x7f0)
+
+	// stay unchanged between thresholds
+	setOnlineMPKI(fetcher, 7)
+	require.NoError(t, m.Sync())
+	assert.Equal(t, 4, m.reclaimedWays)
+
+	// shrink when online pods suffer from cache misses, bounded by the minimum ways
+	setOnlineMPKI(fetcher, 20)
+	for _, expected := range []int{3, 2, 2} {
+		require.NoError(t, m.Sync())
+		assert.Equal(t, expected, m.reclaimedWays)
+	}
+	assertSchemata(0x3, 0x7fc)
+}
+
+func TestManagerImpl_SyncWithPartitionedGroup(t *testing.T) {
+	t.Parallel()
+
+	// reclaimed pool goes on with the ways it has taken before the agent restarts
+	m, _ := newTestManager(t, "MB:0=100;1=100\nL3:0=7;1=7\n")
+	newTestGroup(t, m.resctrl.fs, "dedicated", "L3:0=7ff;1=7ff\n")
+	newTestGroup(t, m.resctrl.fs, "system", "L3:0=7ff;1=7ff\n")
+
+	require.NoError(t, m.Sync())
+	assert.Equal(t, 3, m.reclaimedWays)
+
+	for group, expected := range map[string]uint64{"reclaim": 0x7, "shared-50": 0x7f8, "dedicated": 0x7f8, "system": 0x7f8} {
+		masks, err := m.resctrl.readL3Schemata(group)
+		require.NoError(t, err)
+		assert.Equal(t, map[int]uint64{0: expected, 1: expected}, masks, group)
+	}
+}
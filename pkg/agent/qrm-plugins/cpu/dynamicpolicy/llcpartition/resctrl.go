@@ -0,0 +1,176 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package llcpartition
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"math/bits"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+const (
+	resctrlRoot = "/sys/fs/resctrl"
+
+	fileSchemata   = "schemata"
+	fileCBMMask    = "info/L3/cbm_mask"
+	fileMinCBMBits = "info/L3/min_cbm_bits"
+
+	schemataPrefixL3 = "L3:"
+
+	filePermResctrl = 0o644
+)
+
+// l3Info describes the L3 cache allocation capability reported by resctrl.
+type l3Info struct {
+	// cbmMask is the full capacity bitmask, and each bit stands for one cache way
+	cbmMask uint64
+	// minCBMBits is the minimum number of consecutive bits of a valid mask
+	minCBMBits int
+	// cacheIDs are the ids of L3 cache domains
+	cacheIDs []int
+}
+
+func (i *l3Info) numWays() int {
+	return bits.OnesCount64(i.cbmMask)
+}
+
+// resctrlFS operates resctrl filesystem through afero, so that it can be tested
+// against a fake resctrl tree.
+type resctrlFS struct {
+	fs   afero.Fs
+	root string
+}
+
+func newResctrlFS(fs afero.Fs, root string) *resctrlFS {
+	return &resctrlFS{fs: fs, root: root}
+}
+
+func (r *resctrlFS) getL3Info() (*l3Info, error) {
+	content, err := afero.ReadFile(r.fs, filepath.Join(r.root, fileCBMMask))
+	if err != nil {
+		return nil, fmt.Errorf("read cbm mask failed: %v", err)
+	}
+	cbmMask, err := strconv.ParseUint(strings.TrimSpace(string(content)), 16, 64)
+	if err != nil || cbmMask == 0 {
+		return nil, fmt.Errorf("invalid cbm mask %q: %v", content, err)
+	}
+
+	minCBMBits := 1
+	if content, err = afero.ReadFile(r.fs, filepath.Join(r.root, fileMinCBMBits)); err == nil {
+		if minCBMBits, err = strconv.Atoi(strings.TrimSpace(string(content))); err != nil {
+			return nil, fmt.Errorf("invalid min cbm bits %q: %v", content, err)
+		}
+	}
+
+	masks, err := r.readL3Schemata("")
+	if err != nil {
+		return nil, err
+	} else if len(masks) == 0 {
+		return nil, fmt.Errorf("no L3 cache domain found in root schemata")
+	}
+
+	cacheIDs := make([]int, 0, len(masks))
+	for id := range masks {
+		cacheIDs = append(cacheIDs, id)
+	}
+	sort.Ints(cacheIDs)
+
+	return &l3Info{cbmMask: cbmMask, minCBMBits: minCBMBits, cacheIDs: cacheIDs}, nil
+}
+
+// readL3Schemata parses the L3 line (e.g. "L3:0=7ff;1=7ff") of the schemata of the given
+// group, and root group is represented by empty group name.
+func (r *resctrlFS) readL3Schemata(group string) (map[int]uint64, error) {
+	content, err := afero.ReadFile(r.fs, filepath.Join(r.root, group, fileSchemata))
+	if err != nil {
+		return nil, fmt.Errorf("read schemata of group %q failed: %v", group, err)
+	}
+
+	masks := make(map[int]uint64)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, schemataPrefixL3) {
+			continue
+		}
+
+		for _, domain := range strings.Split(strings.TrimPrefix(line, schemataPrefixL3), ";") {
+			parts := strings.SplitN(strings.TrimSpace(domain), "=", 2)
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("invalid L3 schemata domain %q", domain)
+			}
+			id, err := strconv.Atoi(parts[0])
+			if err != nil {
+				return nil, fmt.Errorf("invalid L3 cache id %q: %v", parts[0], err)
+			}
+			mask, err := strconv.ParseUint(parts[1], 16, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid L3 mask %q: %v", parts[1], err)
+			}
+			masks[id] = mask
+		}
+	}
+	return masks, scanner.Err()
+}
+
+// isGroupExist returns true if the resctrl group has been created
+func (r *resctrlFS) isGroupExist(group string) (bool, error) {
+	if err := validateGroup(group); err != nil {
+		return false, err
+	}
+	return afero.DirExists(r.fs, filepath.Join(r.root, group))
+}
+
+// writeL3Schemata sets the same L3 mask for all the given cache domains of the group. Only the
+// L3 line is written, and kernel keeps other resources of the group (e.g. MB lines maintained
+// by the mb plugin) unchanged.
+func (r *resctrlFS) writeL3Schemata(group string, cacheIDs []int, mask uint64) error {
+	if err := validateGroup(group); err != nil {
+		return err
+	}
+
+	domains := make([]string, 0, len(cacheIDs))
+	for _, id := range cacheIDs {
+		domains = append(domains, fmt.Sprintf("%d=%x", id, mask))
+	}
+	content := schemataPrefixL3 + strings.Join(domains, ";") + "\n"
+	return afero.WriteFile(r.fs, filepath.Join(r.root, group, fileSchemata), []byte(content), filePermResctrl)
+}
+
+func validateGroup(group string) error {
+	if group == "" || strings.ContainsRune(group, '/') || group == "info" || group == "mon_groups" || group == "mon_data" {
+		return fmt.Errorf("invalid resctrl group name %q", group)
+	}
+	return nil
+}
+
+// getContiguousMask returns the mask with the given number of consecutive ways, starting from
+// the way with the given offset to the lowest way of the full mask.
+func getContiguousMask(cbmMask uint64, ways, offset int) uint64 {
+	if ways <= 0 {
+		return 0
+	}
+	mask := (uint64(1)<<uint(ways) - 1) << uint(offset+bits.TrailingZeros64(cbmMask))
+	return mask & cbmMask
+}
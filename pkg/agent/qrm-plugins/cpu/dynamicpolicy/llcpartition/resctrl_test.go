@@ -0,0 +1,110 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package llcpartition
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testResctrlRoot = "/sys/fs/resctrl"
+
+// newFakeResctrlFS builds a fake resctrl tree with 11 L3 ways in 2 cache domains.
+func newFakeResctrlFS(t *testing.T) afero.Fs {
+	fs := afero.NewMemMapFs()
+	for file, content := range map[string]string{
+		fileCBMMask:    "7ff\n",
+		fileMinCBMBits: "1\n",
+		fileSchemata:   "    MB:0=100;1=100\n    L3:0=7ff;1=7ff\n",
+	} {
+		require.NoError(t, afero.WriteFile(fs, filepath.Join(testResctrlRoot, file), []byte(content), filePermResctrl))
+	}
+	return fs
+}
+
+func TestResctrlFS_GetL3Info(t *testing.T) {
+	t.Parallel()
+
+	r := newResctrlFS(newFakeResctrlFS(t), testResctrlRoot)
+	info, err := r.getL3Info()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0x7ff), info.cbmMask)
+	assert.Equal(t, 1, info.minCBMBits)
+	assert.Equal(t, []int{0, 1}, info.cacheIDs)
+	assert.Equal(t, 11, info.numWays())
+
+	_, err = newResctrlFS(afero.NewMemMapFs(), testResctrlRoot).getL3Info()
+	assert.Error(t, err)
+}
+
+func TestResctrlFS_Schemata(t *testing.T) {
+	t.Parallel()
+
+	r := newResctrlFS(newFakeResctrlFS(t), testResctrlRoot)
+	require.NoError(t, r.writeL3Schemata("reclaim", []int{0, 1}, 0x3))
+
+	masks, err := r.readL3Schemata("reclaim")
+	require.NoError(t, err)
+	assert.Equal(t, map[int]uint64{0: 0x3, 1: 0x3}, masks)
+
+	assert.Error(t, r.writeL3Schemata("", []int{0}, 0x3))
+	assert.Error(t, r.writeL3Schemata("info", []int{0}, 0x3))
+	assert.Error(t, r.writeL3Schemata("a/b", []int{0}, 0x3))
+}
+
+func TestResctrlFS_IsGroupExist(t *testing.T) {
+	t.Parallel()
+
+	fs := newFakeResctrlFS(t)
+	require.NoError(t, fs.MkdirAll(filepath.Join(testResctrlRoot, "reclaim"), 0o755))
+	r := newResctrlFS(fs, testResctrlRoot)
+
+	exist, err := r.isGroupExist("reclaim")
+	require.NoError(t, err)
+	assert.True(t, exist)
+
+	exist, err = r.isGroupExist("shared-00")
+	require.NoError(t, err)
+	assert.False(t, exist)
+
+	_, err = r.isGroupExist("mon_groups")
+	assert.Error(t, err)
+}
+
+func TestGetContiguousMask(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		comment  string
+		cbmMask  uint64
+		ways     int
+		offset   int
+		expected uint64
+	}{
+		{comment: "no ways", cbmMask: 0x7ff, ways: 0, expected: 0},
+		{comment: "lowest ways", cbmMask: 0x7ff, ways: 3, expected: 0x7},
+		{comment: "ways with offset", cbmMask: 0x7ff, ways: 2, offset: 3, expected: 0x18},
+		{comment: "full mask not starting from bit 0", cbmMask: 0xff0, ways: 2, expected: 0x30},
+		{comment: "ways beyond full mask", cbmMask: 0xf, ways: 3, offset: 2, expected: 0xc},
+	} {
+		assert.Equal(t, tc.expected, getContiguousMask(tc.cbmMask, tc.ways, tc.offset), tc.comment)
+	}
+}
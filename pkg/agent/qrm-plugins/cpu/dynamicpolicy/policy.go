@@ -41,6 +41,7 @@ import (
 	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/commonstate"
 	cpuconsts "github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/consts"
 	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/calculator"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/coresched"
 	advisorapi "github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/cpuadvisor"
 	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/cpueviction"
 	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/hintoptimizer"
@@ -48,6 +49,7 @@ import (
 	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/hintoptimizer/registry"
 	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/irqtuner"
 	irqtuingcontroller "github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/irqtuner/controller"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/llcpartition"
 	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
 	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/validator"
 	cpuutil "github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/util"
@@ -73,11 +75,13 @@ const (
 
 	reservedReclaimedCPUsSize = 4
 
-	cpusetCheckPeriod  = 10 * time.Second
-	stateCheckPeriod   = 30 * time.Second
-	maxResidualTime    = 5 * time.Minute
-	syncCPUIdlePeriod  = 30 * time.Second
-	syncCPUBurstPeriod = 10 * time.Second
+	cpusetCheckPeriod      = 10 * time.Second
+	stateCheckPeriod       = 30 * time.Second
+	maxResidualTime        = 5 * time.Minute
+	syncCPUIdlePeriod      = 30 * time.Second
+	syncCPUBurstPeriod     = 10 * time.Second
+	syncCoreSchedPeriod    = 10 * time.Second
+	syncLLCPartitionPeriod = 30 * time.Second
 
 	healthCheckTolerationTimes = 3
 )
@@ -129,6 +133,8 @@ type DynamicPolicy struct {
 	enableCPUIdle                             bool
 	enableSyncingCPUIdle                      bool
 	enableCPUBurst                            bool
+	coreSchedManager                          coresched.Manager
+	llcPartitionManager                       llcpartition.Manager
 	reclaimRelativeRootCgroupPath             string
 	numaBindingReclaimRelativeRootCgroupPaths map[int]string
 	qosConfig                                 *generic.QoSConfiguration
@@ -239,6 +245,22 @@ func NewDynamicPolicy(agentCtx *agent.GenericContext, conf *config.Configuration
 		return false, nil, err
 	}
 
+	if conf.EnableCoreScheduling {
+		policyImplement.coreSchedManager, err = coresched.NewManager(agentCtx.MetaServer, conf.QoSConfiguration,
+			conf.CoreSchedulingGranularity, conf.CoreSchedulingQoSLevels)
+		if err != nil {
+			return false, agent.ComponentStub{}, fmt.Errorf("failed to new core sched manager: %v", err)
+		}
+	}
+
+	if conf.EnableLLCPartition {
+		policyImplement.llcPartitionManager, err = llcpartition.NewManager(agentCtx.MetaServer, policyImplement.emitter,
+			stateImpl, &conf.CPUQRMPluginConfig.CPUDynamicPolicyConfig, &conf.MemoryQRMPluginConfig.ResctrlConfig)
+		if err != nil {
+			return false, agent.ComponentStub{}, fmt.Errorf("failed to new llc partition manager: %v", err)
+		}
+	}
+
 	if conf.EnableIRQTuner {
 		irqTuner, err := irqtuingcontroller.NewIrqTuningController(conf.AgentConfiguration, policyImplement, policyImplement.emitter, policyImplement.machineInfo)
 		if err != nil {
@@ -383,6 +405,28 @@ func (p *DynamicPolicy) Start() (err error) {
 		}
 	}
 
+	// start core scheduling cookies sync if needed
+	if p.coreSchedManager != nil {
+		general.Infof("core scheduling is enabled")
+
+		err = periodicalhandler.RegisterPeriodicalHandlerWithHealthz(cpuconsts.SyncCoreSched, general.HealthzCheckStateNotReady,
+			qrm.QRMCPUPluginPeriodicalHandlerGroupName, p.syncCoreSched, syncCoreSchedPeriod, healthCheckTolerationTimes)
+		if err != nil {
+			general.Errorf("start %v failed,err:%v", cpuconsts.SyncCoreSched, err)
+		}
+	}
+
+	// start llc partition sync if needed
+	if p.llcPartitionManager != nil {
+		general.Infof("llc partition is enabled")
+
+		err = periodicalhandler.RegisterPeriodicalHandlerWithHealthz(cpuconsts.SyncLLCPartition, general.HealthzCheckStateNotReady,
+			qrm.QRMCPUPluginPeriodicalHandlerGroupName, p.syncLLCPartition, syncLLCPartitionPeriod, healthCheckTolerationTimes)
+		if err != nil {
+			general.Errorf("start %v failed,err:%v", cpuconsts.SyncLLCPartition, err)
+		}
+	}
+
 	// start cpu-pressure eviction plugin if needed
 	if p.cpuPressureEviction != nil {
 		var ctx context.Context
@@ -953,6 +997,7 @@ func (p *DynamicPolicy) Allocate(ctx context.Context,
 		}
 
 		p.Unlock()
+		util.PublishAllocationEvent(req, qosLevel, resp, respErr)
 		if respErr != nil {
 			general.ErrorS(respErr, "Allocate failed",
 				"podNamespace", req.PodNamespace,
@@ -487,6 +487,41 @@ func (p *DynamicPolicy) syncCPUBurst(_ *coreconfig.Configuration,
 		_ = general.UpdateHealthzStateByError(cpuconsts.SyncCPUBurst, err)
 	}()
 
-	cpuBurstManager := cpuburst.GetManager(p.metaServer)
+	cpuBurstManager := cpuburst.GetManager(p.metaServer, p.emitter)
 	err = cpuBurstManager.UpdateCPUBurst(p.conf, p.dynamicConfig)
 }
+
+// syncCoreSched is used to periodically keep core scheduling cookies consistent for tasks in each group
+func (p *DynamicPolicy) syncCoreSched(_ *coreconfig.Configuration,
+	_ interface{},
+	_ *dynamicconfig.DynamicAgentConfiguration,
+	_ metrics.MetricEmitter,
+	_ *metaserver.MetaServer,
+) {
+	general.Infof("exec syncCoreSched")
+
+	var err error
+	defer func() {
+		_ = general.UpdateHealthzStateByError(cpuconsts.SyncCoreSched, err)
+	}()
+
+	err = p.coreSchedManager.SyncCookies()
+}
+
+// syncLLCPartition is used to periodically tune L3 cache ways of the reclaimed pool, and write them
+// into resctrl groups shared with memory plugin
+func (p *DynamicPolicy) syncLLCPartition(_ *coreconfig.Configuration,
+	_ interface{},
+	_ *dynamicconfig.DynamicAgentConfiguration,
+	_ metrics.MetricEmitter,
+	_ *metaserver.MetaServer,
+) {
+	general.Infof("exec syncLLCPartition")
+
+	var err error
+	defer func() {
+		_ = general.UpdateHealthzStateByError(cpuconsts.SyncLLCPartition, err)
+	}()
+
+	err = p.llcPartitionManager.Sync()
+}
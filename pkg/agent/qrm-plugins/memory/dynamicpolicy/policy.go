@@ -183,6 +183,14 @@ func NewDynamicPolicy(agentCtx *agent.GenericContext, conf *config.Configuration
 	resourcesReservedMemory := map[v1.ResourceName]map[int]uint64{
 		v1.ResourceMemory: reservedMemory,
 	}
+
+	var hugePagesResourceNames []v1.ResourceName
+	if conf.EnableHugePages {
+		hugePagesResourceNames = state.GetHugePagesResourceNames(agentCtx.MachineInfo)
+		for _, resourceName := range hugePagesResourceNames {
+			resourcesReservedMemory[resourceName] = getReservedHugePages(conf, agentCtx.MachineInfo, resourceName)
+		}
+	}
 	stateImpl, err := state.NewCheckpointState(conf.StateDirectoryConfiguration, memoryPluginStateFileName,
 		memconsts.MemoryResourcePluginPolicyNameDynamic, agentCtx.CPUTopology, agentCtx.MachineInfo, resourcesReservedMemory, conf.SkipMemoryStateCorruption, wrappedEmitter)
 	if err != nil {
@@ -303,7 +311,25 @@ func NewDynamicPolicy(agentCtx *agent.GenericContext, conf *config.Configuration
 			))
 	}
 
-	return true, &agent.PluginWrapper{GenericPlugin: pluginWrapper}, nil
+	if len(hugePagesResourceNames) == 0 {
+		return true, &agent.PluginWrapper{GenericPlugin: pluginWrapper}, nil
+	}
+
+	plugins := multiPluginComponent{&agent.PluginWrapper{GenericPlugin: pluginWrapper}}
+	for _, resourceName := range hugePagesResourceNames {
+		hugePagesPluginWrapper, err := skeleton.NewRegistrationPluginWrapper(newHugePagesPolicy(policyImplement, resourceName),
+			conf.QRMPluginSocketDirs, func(key string, value int64) {
+				_ = wrappedEmitter.StoreInt64(key, value, metrics.MetricTypeNameRaw,
+					metrics.MetricTag{Key: "resource", Val: string(resourceName)})
+			})
+		if err != nil {
+			return false, agent.ComponentStub{}, fmt.Errorf("dynamic policy new %s plugin wrapper failed with error: %v", resourceName, err)
+		}
+		general.Infof("hugepages plugin for %s is enabled", resourceName)
+		plugins = append(plugins, &agent.PluginWrapper{GenericPlugin: hugePagesPluginWrapper})
+	}
+
+	return true, plugins, nil
 }
 
 func (p *DynamicPolicy) registerControlKnobHandlerCheckRules() {
@@ -1039,6 +1065,7 @@ func (p *DynamicPolicy) Allocate(ctx context.Context,
 		}
 
 		p.Unlock()
+		util.PublishAllocationEvent(req, qosLevel, resp, respErr)
 		if respErr != nil {
 			general.ErrorS(respErr, "Allocate failed",
 				"podNamespace", req.PodNamespace,
@@ -187,8 +187,14 @@ func (p *DynamicPolicy) applyExternalCgroupParams(_ *coreconfig.Configuration,
 		_ = general.UpdateHealthzStateByError(memconsts.ApplyExternalCGParams, errors.NewAggregate(errList))
 	}()
 
-	podEntries := p.state.GetPodResourceEntries()[v1.ResourceMemory]
+	// hugepages allocations also carry hugetlb limits as external cgroup params
+	for _, podEntries := range p.state.GetPodResourceEntries() {
+		errList = append(errList, p.applyExternalCgroupParamsForPodEntries(podEntries)...)
+	}
+}
 
+func (p *DynamicPolicy) applyExternalCgroupParamsForPodEntries(podEntries state.PodEntries) []error {
+	var errList []error
 	for podUID, containerEntries := range podEntries {
 		for containerName, allocationInfo := range containerEntries {
 			if allocationInfo == nil {
@@ -262,6 +268,7 @@ func (p *DynamicPolicy) applyExternalCgroupParams(_ *coreconfig.Configuration,
 			}
 		}
 	}
+	return errList
 }
 
 // checkMemorySet emit errors if the memory allocation falls into unexpected results
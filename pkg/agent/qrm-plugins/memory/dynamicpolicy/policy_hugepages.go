@@ -0,0 +1,579 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamicpolicy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	pluginapi "k8s.io/kubelet/pkg/apis/resourceplugin/v1alpha1"
+	v1helper "k8s.io/kubernetes/pkg/apis/core/v1/helper"
+
+	apiconsts "github.com/kubewharf/katalyst-api/pkg/consts"
+	"github.com/kubewharf/katalyst-core/cmd/katalyst-agent/app/agent"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/commonstate"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/memory/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/util"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+	"github.com/kubewharf/katalyst-core/pkg/util/metric"
+	qosutil "github.com/kubewharf/katalyst-core/pkg/util/qos"
+)
+
+const cgroupSubsysHugeTLB = "hugetlb"
+
+var errNoAvailableHugePagesHints = fmt.Errorf("no available hugepages hints")
+
+// hugePagesPolicy serves a single hugepage resource (e.g. hugepages-2Mi) as
+// a standalone QRM plugin, since kubelet only dispatches one resource to each
+// plugin. It shares state and lock with the memory DynamicPolicy, so that
+// memory and hugepages of the same pod are tracked in one checkpoint.
+type hugePagesPolicy struct {
+	*DynamicPolicy
+	resourceName v1.ResourceName
+}
+
+func newHugePagesPolicy(p *DynamicPolicy, resourceName v1.ResourceName) *hugePagesPolicy {
+	return &hugePagesPolicy{
+		DynamicPolicy: p,
+		resourceName:  resourceName,
+	}
+}
+
+// Start and Stop are no-ops since all the periodical works are maintained by memory DynamicPolicy
+func (h *hugePagesPolicy) Start() error {
+	return nil
+}
+
+func (h *hugePagesPolicy) Stop() error {
+	return nil
+}
+
+func (h *hugePagesPolicy) Name() string {
+	return fmt.Sprintf("%s_%s", h.DynamicPolicy.Name(), h.resourceName)
+}
+
+func (h *hugePagesPolicy) ResourceName() string {
+	return string(h.resourceName)
+}
+
+// GetTopologyHints returns single NUMA hints with enough free hugepages for
+// NUMA binding pods, and nil hints (i.e. no preference) for the others.
+func (h *hugePagesPolicy) GetTopologyHints(_ context.Context,
+	req *pluginapi.ResourceRequest,
+) (resp *pluginapi.ResourceHintsResponse, err error) {
+	if req == nil {
+		return nil, fmt.Errorf("GetTopologyHints got nil req")
+	}
+
+	reqInt, _, err := util.GetQuantityFromResourceReq(req)
+	if err != nil {
+		return nil, fmt.Errorf("getReqQuantityFromResourceReq failed with error: %v", err)
+	}
+
+	general.InfoS("called",
+		"resourceName", h.resourceName,
+		"podNamespace", req.PodNamespace,
+		"podName", req.PodName,
+		"containerName", req.ContainerName,
+		"containerType", req.ContainerType,
+		"hugePagesReq(bytes)", reqInt)
+
+	if req.ContainerType == pluginapi.ContainerType_INIT || util.IsDebugPod(req.Annotations, h.podDebugAnnoKeys) ||
+		!qosutil.AnnotationsIndicateNUMABinding(req.Annotations) {
+		return util.PackResourceHintsResponse(req, string(h.resourceName),
+			map[string]*pluginapi.ListOfTopologyHints{
+				string(h.resourceName): nil,
+			})
+	}
+
+	h.RLock()
+	defer func() {
+		h.RUnlock()
+		if err != nil {
+			_ = h.emitter.StoreInt64(util.MetricNameGetTopologyHintsFailed, 1, metrics.MetricTypeNameRaw,
+				metrics.MetricTag{Key: "error_message", Val: metric.MetricTagValueFormat(err)},
+				metrics.MetricTag{Key: "resource", Val: string(h.resourceName)})
+		}
+	}()
+
+	var hints []*pluginapi.TopologyHint
+	if allocationInfo := h.state.GetAllocationInfo(h.resourceName, req.PodUid, req.ContainerName); allocationInfo != nil {
+		hints = []*pluginapi.TopologyHint{{
+			Nodes:     allocationInfo.NumaAllocationResult.ToSliceUInt64(),
+			Preferred: true,
+		}}
+	} else {
+		hints, err = calculateHugePagesHints(uint64(reqInt), h.state.GetMachineState()[h.resourceName])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return util.PackResourceHintsResponse(req, string(h.resourceName),
+		map[string]*pluginapi.ListOfTopologyHints{
+			string(h.resourceName): {Hints: hints},
+		})
+}
+
+// calculateHugePagesHints returns hints of all single NUMA nodes whose free
+// hugepages are enough for the request; hugepages can't be reclaimed or
+// migrated, so we never spread a NUMA binding container across NUMA nodes.
+func calculateHugePagesHints(reqInt uint64, machineState state.NUMANodeMap) ([]*pluginapi.TopologyHint, error) {
+	numaNodes := make([]int, 0, len(machineState))
+	for numaNode := range machineState {
+		numaNodes = append(numaNodes, numaNode)
+	}
+	sort.Ints(numaNodes)
+
+	var hints []*pluginapi.TopologyHint
+	for _, numaNode := range numaNodes {
+		numaNodeState := machineState[numaNode]
+		if numaNodeState == nil || numaNodeState.Free < reqInt {
+			continue
+		}
+
+		hints = append(hints, &pluginapi.TopologyHint{
+			Nodes:     []uint64{uint64(numaNode)},
+			Preferred: true,
+		})
+	}
+
+	// same as memory, grpc can't distinguish between an empty array and nil
+	if len(hints) == 0 {
+		return nil, errNoAvailableHugePagesHints
+	}
+	return hints, nil
+}
+
+// Allocate assigns hugepages from NUMA nodes in the hint (or all NUMA nodes if
+// no hint is given), and limits the container by hugetlb cgroup accordingly.
+func (h *hugePagesPolicy) Allocate(_ context.Context,
+	req *pluginapi.ResourceRequest,
+) (resp *pluginapi.ResourceAllocationResponse, err error) {
+	if req == nil {
+		return nil, fmt.Errorf("Allocate got nil req")
+	}
+
+	isDebugPod := util.IsDebugPod(req.Annotations, h.podDebugAnnoKeys)
+
+	qosLevel, err := util.GetKatalystQoSLevelFromResourceReq(h.qosConfig, req, h.podAnnotationKeptKeys, h.podLabelKeptKeys)
+	if err != nil {
+		err = fmt.Errorf("GetKatalystQoSLevelFromResourceReq for pod: %s/%s, container: %s failed with error: %v",
+			req.PodNamespace, req.PodName, req.ContainerName, err)
+		general.Errorf("%s", err.Error())
+		return nil, err
+	}
+
+	reqInt, _, err := util.GetQuantityFromResourceReq(req)
+	if err != nil {
+		return nil, fmt.Errorf("getReqQuantityFromResourceReq failed with error: %v", err)
+	}
+
+	general.InfoS("called",
+		"resourceName", h.resourceName,
+		"podNamespace", req.PodNamespace,
+		"podName", req.PodName,
+		"containerName", req.ContainerName,
+		"containerType", req.ContainerType,
+		"qosLevel", qosLevel,
+		"hugePagesReq(bytes)", reqInt,
+		"hint", req.Hint)
+
+	if req.ContainerType == pluginapi.ContainerType_INIT || isDebugPod || reqInt == 0 {
+		return h.packAllocationResponse(req, nil), nil
+	}
+
+	startTime := time.Now()
+	h.Lock()
+	defer func() {
+		if err != nil {
+			_ = h.emitter.StoreInt64(util.MetricNameAllocateFailed, 1, metrics.MetricTypeNameRaw,
+				metrics.MetricTag{Key: "error_message", Val: metric.MetricTagValueFormat(err)},
+				metrics.MetricTag{Key: "resource", Val: string(h.resourceName)})
+			general.ErrorS(err, "Allocate failed",
+				"resourceName", h.resourceName,
+				"podNamespace", req.PodNamespace,
+				"podName", req.PodName,
+				"containerName", req.ContainerName)
+		} else if sErr := h.state.StoreState(); sErr != nil {
+			general.ErrorS(sErr, "store state failed", "podName", req.PodName, "containerName", req.ContainerName)
+		}
+		h.Unlock()
+		general.InfoS("finished",
+			"duration", time.Since(startTime),
+			"resourceName", h.resourceName,
+			"podNamespace", req.PodNamespace,
+			"podName", req.PodName,
+			"containerName", req.ContainerName)
+	}()
+
+	allocationInfo := h.state.GetAllocationInfo(h.resourceName, req.PodUid, req.ContainerName)
+	if allocationInfo != nil && allocationInfo.AggregatedQuantity == uint64(reqInt) {
+		general.InfoS("already allocated and meet requirement",
+			"resourceName", h.resourceName,
+			"podNamespace", req.PodNamespace,
+			"podName", req.PodName,
+			"containerName", req.ContainerName)
+		return h.packAllocationResponse(req, allocationInfo), nil
+	} else if allocationInfo != nil {
+		// hugepages request changes, release the original allocation before re-allocating,
+		// and put it back if re-allocating fails; machine state is only updated on success,
+		// so restoring the pod entry is enough.
+		originAllocationInfo := allocationInfo.Clone()
+		h.state.Delete(h.resourceName, req.PodUid, req.ContainerName, false)
+		defer func() {
+			if err != nil {
+				h.state.SetAllocationInfo(h.resourceName, req.PodUid, req.ContainerName, originAllocationInfo, false)
+			}
+		}()
+	}
+
+	machineState, err := state.GenerateMachineStateFromPodEntries(h.state.GetMachineInfo(), h.state.GetPodResourceEntries(),
+		h.state.GetMachineState(), h.state.GetReservedMemory())
+	if err != nil {
+		return nil, fmt.Errorf("calculate machineState by pod entries failed with error: %v", err)
+	}
+
+	hugePagesState := machineState[h.resourceName]
+	var numaNodes []int
+	if req.Hint != nil && len(req.Hint.Nodes) > 0 {
+		for _, numaNode := range req.Hint.Nodes {
+			numaNodes = append(numaNodes, int(numaNode))
+		}
+	} else {
+		for numaNode := range hugePagesState {
+			numaNodes = append(numaNodes, numaNode)
+		}
+	}
+	sort.Ints(numaNodes)
+
+	topologyAwareAllocations, err := allocateHugePagesInNUMANodes(uint64(reqInt), hugePagesState, numaNodes)
+	if err != nil {
+		return nil, err
+	}
+
+	result := machine.NewCPUSet()
+	for numaNode := range topologyAwareAllocations {
+		result = result.Union(machine.NewCPUSet(numaNode))
+	}
+
+	allocationInfo = &state.AllocationInfo{
+		AllocationMeta:           state.GenerateMemoryContainerAllocationMeta(req, qosLevel),
+		AggregatedQuantity:       uint64(reqInt),
+		NumaAllocationResult:     result,
+		TopologyAwareAllocations: topologyAwareAllocations,
+		ExtraControlKnobInfo: map[string]commonstate.ControlKnobInfo{
+			string(h.resourceName): getHugeTLBLimitControlKnob(h.resourceName, uint64(reqInt)),
+		},
+	}
+	h.state.SetAllocationInfo(h.resourceName, req.PodUid, req.ContainerName, allocationInfo, false)
+
+	machineState, err = state.GenerateMachineStateFromPodEntries(h.state.GetMachineInfo(), h.state.GetPodResourceEntries(),
+		h.state.GetMachineState(), h.state.GetReservedMemory())
+	if err != nil {
+		h.state.Delete(h.resourceName, req.PodUid, req.ContainerName, false)
+		return nil, fmt.Errorf("calculate machineState by updated pod entries failed with error: %v", err)
+	}
+	h.state.SetMachineState(machineState, false)
+
+	general.InfoS("allocate hugepages successfully",
+		"resourceName", h.resourceName,
+		"podNamespace", req.PodNamespace,
+		"podName", req.PodName,
+		"containerName", req.ContainerName,
+		"numaAllocationResult", result.String())
+
+	return h.packAllocationResponse(req, allocationInfo), nil
+}
+
+// allocateHugePagesInNUMANodes takes hugepages from the given NUMA nodes in order
+// until the request is satisfied, and returns the bytes assigned in each NUMA node.
+func allocateHugePagesInNUMANodes(reqInt uint64, machineState state.NUMANodeMap, numaNodes []int) (map[int]uint64, error) {
+	topologyAwareAllocations := make(map[int]uint64)
+	left := reqInt
+	for _, numaNode := range numaNodes {
+		if left == 0 {
+			break
+		}
+
+		numaNodeState := machineState[numaNode]
+		if numaNodeState == nil || numaNodeState.Free == 0 {
+			continue
+		}
+
+		quantity := general.MinUInt64(left, numaNodeState.Free)
+		topologyAwareAllocations[numaNode] = quantity
+		left -= quantity
+	}
+
+	if left > 0 {
+		return nil, fmt.Errorf("insufficient hugepages in NUMA nodes: %v, request: %d, lack: %d", numaNodes, reqInt, left)
+	}
+	return topologyAwareAllocations, nil
+}
+
+// getHugeTLBLimitControlKnob returns the control knob to limit the container's hugetlb
+// usage, and it will be applied by applyExternalCgroupParams periodically.
+func getHugeTLBLimitControlKnob(resourceName v1.ResourceName, limit uint64) commonstate.ControlKnobInfo {
+	sizeName := hugeTLBSizeName(resourceName)
+	return commonstate.ControlKnobInfo{
+		ControlKnobValue: fmt.Sprintf("%d", limit),
+		CgroupVersionToIfaceName: map[string]string{
+			apiconsts.CgroupV1: fmt.Sprintf("%s.%s.limit_in_bytes", cgroupSubsysHugeTLB, sizeName),
+			apiconsts.CgroupV2: fmt.Sprintf("%s.%s.max", cgroupSubsysHugeTLB, sizeName),
+		},
+		CgroupSubsysName: cgroupSubsysHugeTLB,
+	}
+}
+
+// hugeTLBSizeName converts the hugepage resource name to the page size used by
+// hugetlb cgroup interfaces, e.g. hugepages-2Mi to 2MB and hugepages-1Gi to 1GB.
+func hugeTLBSizeName(resourceName v1.ResourceName) string {
+	pageSize, err := v1helper.HugePageSizeFromResourceName(resourceName)
+	if err != nil {
+		return ""
+	}
+
+	size := pageSize.Value()
+	for _, unit := range []struct {
+		name  string
+		bytes int64
+	}{
+		{name: "GB", bytes: 1 << 30},
+		{name: "MB", bytes: 1 << 20},
+		{name: "KB", bytes: 1 << 10},
+	} {
+		if size >= unit.bytes && size%unit.bytes == 0 {
+			return fmt.Sprintf("%d%s", size/unit.bytes, unit.name)
+		}
+	}
+	return fmt.Sprintf("%dB", size)
+}
+
+func (h *hugePagesPolicy) packAllocationResponse(req *pluginapi.ResourceRequest,
+	allocationInfo *state.AllocationInfo,
+) *pluginapi.ResourceAllocationResponse {
+	resourceAllocationInfo := &pluginapi.ResourceAllocationInfo{
+		IsNodeResource:   false,
+		IsScalarResource: true,
+	}
+	if allocationInfo != nil {
+		resourceAllocationInfo.AllocatedQuantity = float64(allocationInfo.AggregatedQuantity)
+		resourceAllocationInfo.AllocationResult = allocationInfo.NumaAllocationResult.String()
+		resourceAllocationInfo.ResourceHints = &pluginapi.ListOfTopologyHints{
+			Hints: []*pluginapi.TopologyHint{req.Hint},
+		}
+	}
+
+	return &pluginapi.ResourceAllocationResponse{
+		PodUid:         req.PodUid,
+		PodNamespace:   req.PodNamespace,
+		PodName:        req.PodName,
+		ContainerName:  req.ContainerName,
+		ContainerType:  req.ContainerType,
+		ContainerIndex: req.ContainerIndex,
+		PodRole:        req.PodRole,
+		PodType:        req.PodType,
+		ResourceName:   string(h.resourceName),
+		AllocationResult: &pluginapi.ResourceAllocation{
+			ResourceAllocation: map[string]*pluginapi.ResourceAllocationInfo{
+				string(h.resourceName): resourceAllocationInfo,
+			},
+		},
+		Labels:      general.DeepCopyMap(req.Labels),
+		Annotations: general.DeepCopyMap(req.Annotations),
+	}
+}
+
+// RemovePod only releases hugepages of the pod, and memory of the pod
+// will be released when kubelet calls RemovePod of the memory plugin.
+func (h *hugePagesPolicy) RemovePod(_ context.Context,
+	req *pluginapi.RemovePodRequest,
+) (*pluginapi.RemovePodResponse, error) {
+	if req == nil {
+		return nil, fmt.Errorf("RemovePod got nil req")
+	}
+
+	h.Lock()
+	defer h.Unlock()
+
+	podResourceEntries := h.state.GetPodResourceEntries()
+	if _, ok := podResourceEntries[h.resourceName][req.PodUid]; !ok {
+		return &pluginapi.RemovePodResponse{}, nil
+	}
+	delete(podResourceEntries[h.resourceName], req.PodUid)
+
+	machineState, err := state.GenerateMachineStateFromPodEntries(h.state.GetMachineInfo(), podResourceEntries,
+		h.state.GetMachineState(), h.state.GetReservedMemory())
+	if err != nil {
+		general.ErrorS(err, "remove pod failed", "resourceName", h.resourceName, "podUID", req.PodUid)
+		return nil, fmt.Errorf("calculate machineState by updated pod entries failed with error: %v", err)
+	}
+
+	h.state.SetPodResourceEntries(podResourceEntries, false)
+	h.state.SetMachineState(machineState, false)
+	if err := h.state.StoreState(); err != nil {
+		general.ErrorS(err, "store state failed", "podUID", req.PodUid)
+	}
+	return &pluginapi.RemovePodResponse{}, nil
+}
+
+// GetResourcesAllocation returns hugepages allocation results of all containers
+func (h *hugePagesPolicy) GetResourcesAllocation(_ context.Context,
+	req *pluginapi.GetResourcesAllocationRequest,
+) (*pluginapi.GetResourcesAllocationResponse, error) {
+	if req == nil {
+		return nil, fmt.Errorf("GetResourcesAllocation got nil req")
+	}
+
+	h.RLock()
+	defer h.RUnlock()
+
+	podResources := make(map[string]*pluginapi.ContainerResources)
+	for podUID, containerEntries := range h.state.GetPodResourceEntries()[h.resourceName] {
+		for containerName, allocationInfo := range containerEntries {
+			if allocationInfo == nil {
+				continue
+			}
+
+			if podResources[podUID] == nil {
+				podResources[podUID] = &pluginapi.ContainerResources{
+					ContainerResources: make(map[string]*pluginapi.ResourceAllocation),
+				}
+			}
+			podResources[podUID].ContainerResources[containerName] = &pluginapi.ResourceAllocation{
+				ResourceAllocation: map[string]*pluginapi.ResourceAllocationInfo{
+					string(h.resourceName): {
+						IsNodeResource:    false,
+						IsScalarResource:  true,
+						AllocatedQuantity: float64(allocationInfo.AggregatedQuantity),
+						AllocationResult:  allocationInfo.NumaAllocationResult.String(),
+					},
+				},
+			}
+		}
+	}
+
+	return &pluginapi.GetResourcesAllocationResponse{
+		PodResources: podResources,
+	}, nil
+}
+
+// GetTopologyAwareResources returns hugepages allocation results of the container as topology aware format
+func (h *hugePagesPolicy) GetTopologyAwareResources(_ context.Context,
+	req *pluginapi.GetTopologyAwareResourcesRequest,
+) (*pluginapi.GetTopologyAwareResourcesResponse, error) {
+	if req == nil {
+		return nil, fmt.Errorf("GetTopologyAwareResources got nil req")
+	}
+
+	h.RLock()
+	defer h.RUnlock()
+
+	allocationInfo := h.state.GetAllocationInfo(h.resourceName, req.PodUid, req.ContainerName)
+	if allocationInfo == nil {
+		return nil, fmt.Errorf("pod: %s, container: %s is not show up in %s state", req.PodUid, req.ContainerName, h.resourceName)
+	}
+
+	topologyAwareQuantityList := util.GetTopologyAwareQuantityFromAssignmentsSize(allocationInfo.TopologyAwareAllocations)
+	return &pluginapi.GetTopologyAwareResourcesResponse{
+		PodUid:       allocationInfo.PodUid,
+		PodName:      allocationInfo.PodName,
+		PodNamespace: allocationInfo.PodNamespace,
+		ContainerTopologyAwareResources: &pluginapi.ContainerTopologyAwareResources{
+			ContainerName: allocationInfo.ContainerName,
+			AllocatedResources: map[string]*pluginapi.TopologyAwareResource{
+				string(h.resourceName): {
+					IsNodeResource:                    false,
+					IsScalarResource:                  true,
+					AggregatedQuantity:                float64(allocationInfo.AggregatedQuantity),
+					OriginalAggregatedQuantity:        float64(allocationInfo.AggregatedQuantity),
+					TopologyAwareQuantityList:         topologyAwareQuantityList,
+					OriginalTopologyAwareQuantityList: topologyAwareQuantityList,
+				},
+			},
+		},
+	}, nil
+}
+
+// GetTopologyAwareAllocatableResources returns hugepages allocatable resources as topology aware format
+func (h *hugePagesPolicy) GetTopologyAwareAllocatableResources(context.Context,
+	*pluginapi.GetTopologyAwareAllocatableResourcesRequest,
+) (*pluginapi.GetTopologyAwareAllocatableResourcesResponse, error) {
+	h.RLock()
+	defer h.RUnlock()
+
+	machineState := h.state.GetMachineState()[h.resourceName]
+
+	numaNodes := h.topology.CPUDetails.NUMANodes().ToSliceInt()
+	topologyAwareAllocatableQuantityList := make([]*pluginapi.TopologyAwareQuantity, 0, len(machineState))
+	topologyAwareCapacityQuantityList := make([]*pluginapi.TopologyAwareQuantity, 0, len(machineState))
+
+	var aggregatedAllocatableQuantity, aggregatedCapacityQuantity uint64 = 0, 0
+	for _, numaNode := range numaNodes {
+		numaNodeState := machineState[numaNode]
+		if numaNodeState == nil {
+			return nil, fmt.Errorf("nil %s numaNodeState for NUMA: %d", h.resourceName, numaNode)
+		}
+
+		topologyAwareAllocatableQuantityList = append(topologyAwareAllocatableQuantityList, &pluginapi.TopologyAwareQuantity{
+			ResourceValue: float64(numaNodeState.Allocatable),
+			Node:          uint64(numaNode),
+		})
+		topologyAwareCapacityQuantityList = append(topologyAwareCapacityQuantityList, &pluginapi.TopologyAwareQuantity{
+			ResourceValue: float64(numaNodeState.TotalMemSize),
+			Node:          uint64(numaNode),
+		})
+		aggregatedAllocatableQuantity += numaNodeState.Allocatable
+		aggregatedCapacityQuantity += numaNodeState.TotalMemSize
+	}
+
+	return &pluginapi.GetTopologyAwareAllocatableResourcesResponse{
+		AllocatableResources: map[string]*pluginapi.AllocatableTopologyAwareResource{
+			string(h.resourceName): {
+				IsNodeResource:                       false,
+				IsScalarResource:                     true,
+				AggregatedAllocatableQuantity:        float64(aggregatedAllocatableQuantity),
+				TopologyAwareAllocatableQuantityList: topologyAwareAllocatableQuantityList,
+				AggregatedCapacityQuantity:           float64(aggregatedCapacityQuantity),
+				TopologyAwareCapacityQuantityList:    topologyAwareCapacityQuantityList,
+			},
+		},
+	}, nil
+}
+
+// multiPluginComponent runs the memory plugin along with hugepage plugins,
+// and it returns after all of them exit.
+type multiPluginComponent []*agent.PluginWrapper
+
+func (m multiPluginComponent) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, plugin := range m {
+		wg.Add(1)
+		go func(plugin *agent.PluginWrapper) {
+			defer wg.Done()
+			plugin.Run(ctx)
+		}(plugin)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,306 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dynamicpolicy
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	info "github.com/google/cadvisor/info/v1"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	pluginapi "k8s.io/kubelet/pkg/apis/resourceplugin/v1alpha1"
+
+	apiconsts "github.com/kubewharf/katalyst-api/pkg/consts"
+	memconsts "github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/memory/consts"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/memory/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/qrm/statedirectory"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+const testHugePages2Mi = v1.ResourceName("hugepages-2Mi")
+
+// getTestHugePagesMachineInfo returns 4 NUMA nodes with 8Gi memory, 1Gi of which is hugepages-2Mi
+func getTestHugePagesMachineInfo() (*info.MachineInfo, error) {
+	machineInfo, err := machine.GenerateDummyMachineInfo(4, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range machineInfo.Topology {
+		machineInfo.Topology[i].HugePages = []info.HugePagesInfo{{PageSize: 2048, NumPages: 512}}
+	}
+	return machineInfo, nil
+}
+
+func getTestHugePagesReservedMemory(machineInfo *info.MachineInfo) (map[v1.ResourceName]map[int]uint64, error) {
+	reservedMemory, err := getReservedMemory(fakeConf, &metaserver.MetaServer{}, machineInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	reservedHugePages := make(map[int]uint64)
+	for _, node := range machineInfo.Topology {
+		reservedHugePages[node.Id] = 0
+	}
+
+	return map[v1.ResourceName]map[int]uint64{
+		v1.ResourceMemory: reservedMemory,
+		testHugePages2Mi:  reservedHugePages,
+	}, nil
+}
+
+func getTestHugePagesPolicy(topology *machine.CPUTopology, machineInfo *info.MachineInfo,
+	stateFileDirectory string,
+) (*hugePagesPolicy, error) {
+	dynamicPolicy, err := getTestDynamicPolicyWithInitialization(topology, machineInfo, stateFileDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	resourcesReservedMemory, err := getTestHugePagesReservedMemory(machineInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	stateImpl, err := state.NewCheckpointState(&statedirectory.StateDirectoryConfiguration{
+		StateFileDirectory: stateFileDirectory,
+	}, memoryPluginStateFileName, memconsts.MemoryResourcePluginPolicyNameDynamic,
+		topology, machineInfo, resourcesReservedMemory, false, metrics.DummyMetrics{})
+	if err != nil {
+		return nil, fmt.Errorf("NewCheckpointState failed with error: %v", err)
+	}
+	dynamicPolicy.state = stateImpl
+
+	return newHugePagesPolicy(dynamicPolicy, testHugePages2Mi), nil
+}
+
+func getTestHugePagesRequest(podUID string, quantity float64, numaNode uint64) *pluginapi.ResourceRequest {
+	return &pluginapi.ResourceRequest{
+		PodUid:         podUID,
+		PodNamespace:   "test",
+		PodName:        "test",
+		ContainerName:  "test",
+		ContainerType:  pluginapi.ContainerType_MAIN,
+		ContainerIndex: 0,
+		ResourceName:   string(testHugePages2Mi),
+		Hint: &pluginapi.TopologyHint{
+			Nodes:     []uint64{numaNode},
+			Preferred: true,
+		},
+		ResourceRequests: map[string]float64{
+			string(testHugePages2Mi): quantity,
+		},
+		Annotations: map[string]string{
+			apiconsts.PodAnnotationQoSLevelKey:          apiconsts.PodAnnotationQoSLevelDedicatedCores,
+			apiconsts.PodAnnotationMemoryEnhancementKey: `{"numa_binding": "true"}`,
+		},
+		Labels: map[string]string{
+			apiconsts.PodAnnotationQoSLevelKey: apiconsts.PodAnnotationQoSLevelDedicatedCores,
+		},
+	}
+}
+
+func TestCalculateHugePagesHints(t *testing.T) {
+	t.Parallel()
+
+	as := require.New(t)
+	machineState := state.NUMANodeMap{
+		0: {Allocatable: 1 << 30, Free: 256 << 20},
+		1: {Allocatable: 1 << 30, Free: 1 << 30},
+		2: {Allocatable: 1 << 30, Free: 512 << 20},
+	}
+
+	hints, err := calculateHugePagesHints(512<<20, machineState)
+	as.Nil(err)
+	as.Equal([]*pluginapi.TopologyHint{
+		{Nodes: []uint64{1}, Preferred: true},
+		{Nodes: []uint64{2}, Preferred: true},
+	}, hints)
+
+	_, err = calculateHugePagesHints(2<<30, machineState)
+	as.Equal(errNoAvailableHugePagesHints, err)
+}
+
+func TestAllocateHugePagesInNUMANodes(t *testing.T) {
+	t.Parallel()
+
+	as := require.New(t)
+	machineState := state.NUMANodeMap{
+		0: {Allocatable: 1 << 30, Free: 256 << 20},
+		1: {Allocatable: 1 << 30, Free: 1 << 30},
+	}
+
+	allocations, err := allocateHugePagesInNUMANodes(512<<20, machineState, []int{0, 1})
+	as.Nil(err)
+	as.Equal(map[int]uint64{0: 256 << 20, 1: 256 << 20}, allocations)
+
+	allocations, err = allocateHugePagesInNUMANodes(512<<20, machineState, []int{1})
+	as.Nil(err)
+	as.Equal(map[int]uint64{1: 512 << 20}, allocations)
+
+	_, err = allocateHugePagesInNUMANodes(512<<20, machineState, []int{0})
+	as.NotNil(err)
+}
+
+func TestGetHugeTLBLimitControlKnob(t *testing.T) {
+	t.Parallel()
+
+	as := require.New(t)
+
+	controlKnob := getHugeTLBLimitControlKnob(v1.ResourceName("hugepages-2Mi"), 512<<20)
+	as.Equal("536870912", controlKnob.ControlKnobValue)
+	as.Equal(cgroupSubsysHugeTLB, controlKnob.CgroupSubsysName)
+	as.Equal("hugetlb.2MB.limit_in_bytes", controlKnob.CgroupVersionToIfaceName[apiconsts.CgroupV1])
+	as.Equal("hugetlb.2MB.max", controlKnob.CgroupVersionToIfaceName[apiconsts.CgroupV2])
+
+	controlKnob = getHugeTLBLimitControlKnob(v1.ResourceName("hugepages-1Gi"), 2<<30)
+	as.Equal("hugetlb.1GB.limit_in_bytes", controlKnob.CgroupVersionToIfaceName[apiconsts.CgroupV1])
+}
+
+func TestHugePagesPolicyAllocate(t *testing.T) {
+	t.Parallel()
+
+	as := require.New(t)
+
+	cpuTopology, err := machine.GenerateDummyCPUTopology(16, 2, 4)
+	as.Nil(err)
+
+	machineInfo, err := getTestHugePagesMachineInfo()
+	as.Nil(err)
+
+	policy, err := getTestHugePagesPolicy(cpuTopology, machineInfo, t.TempDir())
+	as.Nil(err)
+
+	// hugepages are not counted into memory
+	memoryState := policy.state.GetMachineState()[v1.ResourceMemory]
+	as.Equal(uint64(7<<30), memoryState[0].TotalMemSize)
+
+	req := getTestHugePagesRequest(string(uuid.NewUUID()), 512<<20, 1)
+	resp, err := policy.Allocate(context.Background(), req)
+	as.Nil(err)
+	as.Equal(float64(512<<20), resp.AllocationResult.ResourceAllocation[string(testHugePages2Mi)].AllocatedQuantity)
+	as.Equal("1", resp.AllocationResult.ResourceAllocation[string(testHugePages2Mi)].AllocationResult)
+
+	allocationInfo := policy.state.GetAllocationInfo(testHugePages2Mi, req.PodUid, req.ContainerName)
+	as.NotNil(allocationInfo)
+	as.Equal(map[int]uint64{1: 512 << 20}, allocationInfo.TopologyAwareAllocations)
+	as.Equal("536870912", allocationInfo.ExtraControlKnobInfo[string(testHugePages2Mi)].ControlKnobValue)
+	as.Equal(uint64(512<<20), policy.state.GetMachineState()[testHugePages2Mi][1].Free)
+	as.Equal(uint64(1<<30), policy.state.GetMachineState()[testHugePages2Mi][0].Free)
+
+	// the request grows beyond the NUMA node, and the original allocation is kept
+	_, err = policy.Allocate(context.Background(), getTestHugePagesRequest(req.PodUid, 2<<30, 1))
+	as.NotNil(err)
+
+	allocationInfo = policy.state.GetAllocationInfo(testHugePages2Mi, req.PodUid, req.ContainerName)
+	as.NotNil(allocationInfo)
+	as.Equal(uint64(512<<20), allocationInfo.AggregatedQuantity)
+	as.Equal(map[int]uint64{1: 512 << 20}, allocationInfo.TopologyAwareAllocations)
+	as.Equal(uint64(512<<20), policy.state.GetMachineState()[testHugePages2Mi][1].Free)
+
+	// the request grows within the NUMA node
+	_, err = policy.Allocate(context.Background(), getTestHugePagesRequest(req.PodUid, 768<<20, 1))
+	as.Nil(err)
+
+	allocationInfo = policy.state.GetAllocationInfo(testHugePages2Mi, req.PodUid, req.ContainerName)
+	as.NotNil(allocationInfo)
+	as.Equal(map[int]uint64{1: 768 << 20}, allocationInfo.TopologyAwareAllocations)
+	as.Equal(uint64(256<<20), policy.state.GetMachineState()[testHugePages2Mi][1].Free)
+}
+
+func TestHugePagesPolicyRemovePod(t *testing.T) {
+	t.Parallel()
+
+	as := require.New(t)
+
+	cpuTopology, err := machine.GenerateDummyCPUTopology(16, 2, 4)
+	as.Nil(err)
+
+	machineInfo, err := getTestHugePagesMachineInfo()
+	as.Nil(err)
+
+	policy, err := getTestHugePagesPolicy(cpuTopology, machineInfo, t.TempDir())
+	as.Nil(err)
+
+	req := getTestHugePagesRequest(string(uuid.NewUUID()), 512<<20, 2)
+	_, err = policy.Allocate(context.Background(), req)
+	as.Nil(err)
+	as.Equal(uint64(512<<20), policy.state.GetMachineState()[testHugePages2Mi][2].Free)
+
+	_, err = policy.RemovePod(context.Background(), &pluginapi.RemovePodRequest{PodUid: req.PodUid})
+	as.Nil(err)
+
+	as.Nil(policy.state.GetAllocationInfo(testHugePages2Mi, req.PodUid, req.ContainerName))
+	as.Equal(uint64(1<<30), policy.state.GetMachineState()[testHugePages2Mi][2].Free)
+
+	// removing an unknown pod is a no-op
+	_, err = policy.RemovePod(context.Background(), &pluginapi.RemovePodRequest{PodUid: req.PodUid})
+	as.Nil(err)
+}
+
+func TestHugePagesPolicyRestoreState(t *testing.T) {
+	t.Parallel()
+
+	as := require.New(t)
+
+	cpuTopology, err := machine.GenerateDummyCPUTopology(16, 2, 4)
+	as.Nil(err)
+
+	machineInfo, err := getTestHugePagesMachineInfo()
+	as.Nil(err)
+
+	stateFileDirectory := t.TempDir()
+	policy, err := getTestHugePagesPolicy(cpuTopology, machineInfo, stateFileDirectory)
+	as.Nil(err)
+
+	req := getTestHugePagesRequest(string(uuid.NewUUID()), 256<<20, 3)
+	_, err = policy.Allocate(context.Background(), req)
+	as.Nil(err)
+
+	stateDirectoryConfig := &statedirectory.StateDirectoryConfiguration{
+		StateFileDirectory: stateFileDirectory,
+	}
+	resourcesReservedMemory, err := getTestHugePagesReservedMemory(machineInfo)
+	as.Nil(err)
+
+	restored, err := state.NewCheckpointState(stateDirectoryConfig, memoryPluginStateFileName,
+		memconsts.MemoryResourcePluginPolicyNameDynamic, cpuTopology, machineInfo, resourcesReservedMemory,
+		false, metrics.DummyMetrics{})
+	as.Nil(err)
+
+	allocationInfo := restored.GetAllocationInfo(testHugePages2Mi, req.PodUid, req.ContainerName)
+	as.NotNil(allocationInfo)
+	as.Equal(map[int]uint64{3: 256 << 20}, allocationInfo.TopologyAwareAllocations)
+	as.Equal(uint64(768<<20), restored.GetMachineState()[testHugePages2Mi][3].Free)
+	as.Equal(uint64(256<<20), restored.GetMachineState()[testHugePages2Mi][3].Allocated)
+
+	// hugepages are no longer managed once they are disabled
+	restored, err = state.NewCheckpointState(stateDirectoryConfig, memoryPluginStateFileName,
+		memconsts.MemoryResourcePluginPolicyNameDynamic, cpuTopology, machineInfo,
+		map[v1.ResourceName]map[int]uint64{v1.ResourceMemory: resourcesReservedMemory[v1.ResourceMemory]},
+		false, metrics.DummyMetrics{})
+	as.Nil(err)
+
+	_, ok := restored.GetMachineState()[testHugePages2Mi]
+	as.False(ok)
+	as.Equal(uint64(8<<30), restored.GetMachineState()[v1.ResourceMemory][0].TotalMemSize)
+}
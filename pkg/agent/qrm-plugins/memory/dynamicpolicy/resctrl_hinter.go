@@ -18,7 +18,6 @@ package dynamicpolicy
 
 import (
 	"bytes"
-	"fmt"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -39,10 +38,8 @@ import (
 )
 
 const (
-	templateSharedSubgroup = "shared-%02d"
-	sharedGroup            = "share"
-	resctrlRoot            = "/sys/fs/resctrl"
-	monGroups              = "mon_groups"
+	resctrlRoot = "/sys/fs/resctrl"
+	monGroups   = "mon_groups"
 
 	metricNameResctrlMonGroupsNum       = "resctrl_mon_groups_num"
 	metricNameResctrlMonGroupsOverlimit = "resctrl_mon_groups_over_limit"
@@ -63,20 +60,8 @@ type resctrlHinter struct {
 	root                 string
 }
 
-func getSharedSubgroup(val int) string {
-	// typical mon group is like "shared-xx", except for
-	// negative value indicates using "shared" mon group
-	if val < 0 {
-		return sharedGroup
-	}
-	return fmt.Sprintf(templateSharedSubgroup, val)
-}
-
 func (r *resctrlHinter) getSharedSubgroupByPool(pool string) string {
-	if v, ok := r.config.CPUSetPoolToSharedSubgroup[pool]; ok {
-		return getSharedSubgroup(v)
-	}
-	return getSharedSubgroup(r.config.DefaultSharedSubgroup)
+	return util.GetResctrlSharedSubgroupByPool(r.config, pool)
 }
 
 func injectRespAnnotation(resourceAllocation *pluginapi.ResourceAllocation, k, v string) {
@@ -186,3 +186,89 @@ func TestNewMemoryPluginCheckpoint(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateMachineStateWithHugePages(t *testing.T) {
+	t.Parallel()
+
+	as := require.New(t)
+
+	hugePages2Mi := v1.ResourceName("hugepages-2Mi")
+	hugePages1Gi := v1.ResourceName("hugepages-1Gi")
+	machineInfo := &info.MachineInfo{
+		Topology: []info.Node{
+			{
+				Id:     0,
+				Memory: 100 << 30,
+				HugePages: []info.HugePagesInfo{
+					{PageSize: 2048, NumPages: 512},
+					{PageSize: 1048576, NumPages: 0},
+				},
+			},
+			{
+				Id:     1,
+				Memory: 100 << 30,
+				HugePages: []info.HugePagesInfo{
+					{PageSize: 2048, NumPages: 1024},
+					{PageSize: 1048576, NumPages: 2},
+				},
+			},
+		},
+	}
+
+	as.Equal([]v1.ResourceName{hugePages1Gi, hugePages2Mi}, GetHugePagesResourceNames(machineInfo))
+
+	// hugepages-1Gi is not enabled, so it is not reserved and not managed
+	reserved := map[v1.ResourceName]map[int]uint64{
+		v1.ResourceMemory: {0: 1 << 30, 1: 1 << 30},
+		hugePages2Mi:      {0: 0, 1: 512 << 20},
+	}
+	as.Equal([]v1.ResourceName{v1.ResourceMemory, hugePages2Mi}, GetManagedResourceNames(reserved))
+	as.Equal([]v1.ResourceName{v1.ResourceMemory}, GetManagedResourceNames(map[v1.ResourceName]map[int]uint64{
+		v1.ResourceMemory: {0: 1 << 30, 1: 1 << 30},
+	}))
+
+	podResourceEntries := PodResourceEntries{
+		hugePages2Mi: PodEntries{
+			"pod-1": ContainerEntries{
+				"c-1": &AllocationInfo{
+					AllocationMeta: commonstate.AllocationMeta{
+						PodUid:        "pod-1",
+						ContainerName: "c-1",
+						ContainerType: pluginapi.ContainerType_MAIN.String(),
+					},
+					AggregatedQuantity:       256 << 20,
+					NumaAllocationResult:     machine.NewCPUSet(1),
+					TopologyAwareAllocations: map[int]uint64{1: 256 << 20},
+				},
+			},
+		},
+	}
+
+	machineState, err := GenerateMachineStateFromPodEntries(machineInfo, podResourceEntries, nil, reserved)
+	as.Nil(err)
+	as.Len(machineState, 2)
+
+	as.Equal(uint64(1<<30), machineState[hugePages2Mi][0].TotalMemSize)
+	as.Equal(uint64(1<<30), machineState[hugePages2Mi][0].Free)
+	as.Equal(uint64(2<<30), machineState[hugePages2Mi][1].TotalMemSize)
+	as.Equal(uint64(1536<<20), machineState[hugePages2Mi][1].Allocatable)
+	as.Equal(uint64(256<<20), machineState[hugePages2Mi][1].Allocated)
+	as.Equal(uint64(1280<<20), machineState[hugePages2Mi][1].Free)
+
+	_, ok := machineState[hugePages1Gi]
+	as.False(ok)
+
+	// managed hugepages must not be counted into memory, while unmanaged ones are ignored
+	as.Equal(uint64(99<<30), machineState[v1.ResourceMemory][0].TotalMemSize)
+	as.Equal(uint64(98<<30), machineState[v1.ResourceMemory][1].TotalMemSize)
+	as.Equal(uint64(97<<30), machineState[v1.ResourceMemory][1].Free)
+
+	// memory is not affected by hugepages if hugepages are not enabled
+	machineState, err = GenerateMachineStateFromPodEntries(machineInfo, nil, nil, map[v1.ResourceName]map[int]uint64{
+		v1.ResourceMemory: {0: 1 << 30, 1: 1 << 30},
+	})
+	as.Nil(err)
+	as.Len(machineState, 1)
+	as.Equal(uint64(100<<30), machineState[v1.ResourceMemory][0].TotalMemSize)
+	as.Equal(uint64(100<<30), machineState[v1.ResourceMemory][1].TotalMemSize)
+}
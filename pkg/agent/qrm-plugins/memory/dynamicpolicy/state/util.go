@@ -18,12 +18,15 @@ package state
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
 	info "github.com/google/cadvisor/info/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/klog/v2"
 	pluginapi "k8s.io/kubelet/pkg/apis/resourceplugin/v1alpha1"
+	v1helper "k8s.io/kubernetes/pkg/apis/core/v1/helper"
 
 	"github.com/kubewharf/katalyst-api/pkg/consts"
 	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/commonstate"
@@ -54,9 +57,8 @@ func GenerateMachineState(machineInfo *info.MachineInfo, reserved map[v1.Resourc
 		return nil, fmt.Errorf("GenerateMachineState got nil machineInfo")
 	}
 
-	// todo: currently only support memory, we will support huge page later.
 	defaultResourcesMachineState := make(NUMANodeResourcesMap)
-	for _, resourceName := range []v1.ResourceName{v1.ResourceMemory} {
+	for _, resourceName := range GetManagedResourceNames(reserved) {
 		machineState, err := GenerateResourceState(machineInfo, reserved, resourceName)
 		if err != nil {
 			return nil, fmt.Errorf("GenerateResourceState for resource: %s failed with error: %v", resourceName, err)
@@ -95,35 +97,116 @@ func GetReclaimedNUMAHeadroom(numaHeadroom map[int]int64, numaSet machine.CPUSet
 func GenerateResourceState(machineInfo *info.MachineInfo, reserved map[v1.ResourceName]map[int]uint64, resourceName v1.ResourceName) (NUMANodeMap, error) {
 	defaultMachineState := make(NUMANodeMap)
 
-	switch resourceName {
-	case v1.ResourceMemory:
-		for _, node := range machineInfo.Topology {
-			totalMemSizeQuantity := node.Memory
-			numaReservedMemQuantity := reserved[resourceName][node.Id]
-
-			if totalMemSizeQuantity < numaReservedMemQuantity {
-				return nil, fmt.Errorf("invalid reserved memory: %d in NUMA: %d with total memory size: %d", numaReservedMemQuantity, node.Id, totalMemSizeQuantity)
+	for _, node := range machineInfo.Topology {
+		var totalMemSizeQuantity uint64
+		switch {
+		case resourceName == v1.ResourceMemory:
+			// memory held by managed hugepage pools is allocated as hugepage resources,
+			// so it can never be allocated as normal memory
+			totalMemSizeQuantity = node.Memory
+			if hugePagesCapacity := getNUMAManagedHugePagesCapacity(node, reserved); hugePagesCapacity < totalMemSizeQuantity {
+				totalMemSizeQuantity -= hugePagesCapacity
+			} else {
+				totalMemSizeQuantity = 0
 			}
+		case v1helper.IsHugePageResourceName(resourceName):
+			totalMemSizeQuantity = GetNUMAHugePagesCapacity(node, resourceName)
+		default:
+			return nil, fmt.Errorf("unsupported resource name: %s", resourceName)
+		}
+		numaReservedMemQuantity := reserved[resourceName][node.Id]
+
+		if totalMemSizeQuantity < numaReservedMemQuantity {
+			return nil, fmt.Errorf("invalid reserved %s: %d in NUMA: %d with total size: %d", resourceName, numaReservedMemQuantity, node.Id, totalMemSizeQuantity)
+		}
 
-			allocatableQuantity := totalMemSizeQuantity - numaReservedMemQuantity
-			freeQuantity := allocatableQuantity
+		allocatableQuantity := totalMemSizeQuantity - numaReservedMemQuantity
+		freeQuantity := allocatableQuantity
 
-			defaultMachineState[node.Id] = &NUMANodeState{
-				TotalMemSize:   totalMemSizeQuantity,
-				SystemReserved: numaReservedMemQuantity,
-				Allocatable:    allocatableQuantity,
-				Allocated:      0,
-				Free:           freeQuantity,
-				PodEntries:     make(PodEntries),
-			}
+		defaultMachineState[node.Id] = &NUMANodeState{
+			TotalMemSize:   totalMemSizeQuantity,
+			SystemReserved: numaReservedMemQuantity,
+			Allocatable:    allocatableQuantity,
+			Allocated:      0,
+			Free:           freeQuantity,
+			PodEntries:     make(PodEntries),
 		}
-	default:
-		return nil, fmt.Errorf("unsupported resource name: %s", resourceName)
 	}
 
 	return defaultMachineState, nil
 }
 
+// GetManagedResourceNames returns all resources tracked in machine state, i.e. memory
+// along with hugepages enabled by the plugin; the policy only reserves hugepage resources
+// when they are enabled in config, so the enabled ones are exactly those in reserved.
+func GetManagedResourceNames(reserved map[v1.ResourceName]map[int]uint64) []v1.ResourceName {
+	hugePagesResourceNames := make([]v1.ResourceName, 0, len(reserved))
+	for resourceName := range reserved {
+		if v1helper.IsHugePageResourceName(resourceName) {
+			hugePagesResourceNames = append(hugePagesResourceNames, resourceName)
+		}
+	}
+	sort.Slice(hugePagesResourceNames, func(i, j int) bool {
+		return hugePagesResourceNames[i] < hugePagesResourceNames[j]
+	})
+	return append([]v1.ResourceName{v1.ResourceMemory}, hugePagesResourceNames...)
+}
+
+// GetHugePagesResourceNames returns sorted hugepage resource names (e.g. hugepages-2Mi)
+// with non-empty pools in any NUMA node; the pools are discovered from sysfs by cadvisor.
+func GetHugePagesResourceNames(machineInfo *info.MachineInfo) []v1.ResourceName {
+	if machineInfo == nil {
+		return nil
+	}
+
+	resourceNameSet := make(map[v1.ResourceName]struct{})
+	for _, node := range machineInfo.Topology {
+		for _, hugePages := range node.HugePages {
+			if hugePages.NumPages == 0 {
+				continue
+			}
+			resourceNameSet[HugePagesResourceName(hugePages.PageSize)] = struct{}{}
+		}
+	}
+
+	resourceNames := make([]v1.ResourceName, 0, len(resourceNameSet))
+	for resourceName := range resourceNameSet {
+		resourceNames = append(resourceNames, resourceName)
+	}
+	sort.Slice(resourceNames, func(i, j int) bool {
+		return resourceNames[i] < resourceNames[j]
+	})
+	return resourceNames
+}
+
+// HugePagesResourceName returns the hugepage resource name for the given page size in KiB
+func HugePagesResourceName(pageSizeKB uint64) v1.ResourceName {
+	return v1helper.HugePageResourceName(*resource.NewQuantity(int64(pageSizeKB)*1024, resource.BinarySI))
+}
+
+// GetNUMAHugePagesCapacity returns the total bytes of hugepages with the given resource name in the NUMA node
+func GetNUMAHugePagesCapacity(node info.Node, resourceName v1.ResourceName) uint64 {
+	var capacity uint64
+	for _, hugePages := range node.HugePages {
+		if HugePagesResourceName(hugePages.PageSize) == resourceName {
+			capacity += hugePages.NumPages * hugePages.PageSize * 1024
+		}
+	}
+	return capacity
+}
+
+// getNUMAManagedHugePagesCapacity returns the total bytes of hugepages managed by the plugin
+// in the NUMA node, i.e. those of hugepage resources in reserved.
+func getNUMAManagedHugePagesCapacity(node info.Node, reserved map[v1.ResourceName]map[int]uint64) uint64 {
+	var capacity uint64
+	for _, resourceName := range GetManagedResourceNames(reserved) {
+		if v1helper.IsHugePageResourceName(resourceName) {
+			capacity += GetNUMAHugePagesCapacity(node, resourceName)
+		}
+	}
+	return capacity
+}
+
 // GenerateMachineStateFromPodEntries returns NUMANodeResourcesMap based on
 // machine info and reserved resources (along with existed pod entries)
 func GenerateMachineStateFromPodEntries(machineInfo *info.MachineInfo,
@@ -138,9 +221,8 @@ func GenerateMachineStateFromPodEntries(machineInfo *info.MachineInfo,
 		originResourcesMachineState = make(NUMANodeResourcesMap)
 	}
 
-	// todo: currently only support memory, we will support huge page later.
 	currentResourcesMachineState := make(NUMANodeResourcesMap)
-	for _, resourceName := range []v1.ResourceName{v1.ResourceMemory} {
+	for _, resourceName := range GetManagedResourceNames(reserved) {
 		machineState, err := GenerateResourceStateFromPodEntries(machineInfo, podResourceEntries[resourceName],
 			originResourcesMachineState[resourceName], reserved, resourceName)
 		if err != nil {
@@ -218,18 +300,17 @@ func updateMachineStatePreOccPodEntries(currentMachineState, originMachineState
 func GenerateResourceStateFromPodEntries(machineInfo *info.MachineInfo,
 	podEntries PodEntries, originMachineState NUMANodeMap, reserved map[v1.ResourceName]map[int]uint64, resourceName v1.ResourceName,
 ) (NUMANodeMap, error) {
-	switch resourceName {
-	case v1.ResourceMemory:
-		currentMachineState, err := GenerateMemoryStateFromPodEntries(machineInfo, podEntries, reserved)
-		if err != nil {
-			return nil, err
-		}
-
-		updateMachineStatePreOccPodEntries(currentMachineState, originMachineState)
-		return currentMachineState, nil
-	default:
+	if resourceName != v1.ResourceMemory && !v1helper.IsHugePageResourceName(resourceName) {
 		return nil, fmt.Errorf("unsupported resource name: %s", resourceName)
 	}
+
+	currentMachineState, err := generateStateFromPodEntries(machineInfo, podEntries, reserved, resourceName)
+	if err != nil {
+		return nil, err
+	}
+
+	updateMachineStatePreOccPodEntries(currentMachineState, originMachineState)
+	return currentMachineState, nil
 }
 
 // GenerateMemoryStateFromPodEntries returns NUMANodeMap for memory based on
@@ -237,7 +318,13 @@ func GenerateResourceStateFromPodEntries(machineInfo *info.MachineInfo,
 func GenerateMemoryStateFromPodEntries(machineInfo *info.MachineInfo,
 	podEntries PodEntries, reserved map[v1.ResourceName]map[int]uint64,
 ) (NUMANodeMap, error) {
-	machineState, err := GenerateResourceState(machineInfo, reserved, v1.ResourceMemory)
+	return generateStateFromPodEntries(machineInfo, podEntries, reserved, v1.ResourceMemory)
+}
+
+func generateStateFromPodEntries(machineInfo *info.MachineInfo,
+	podEntries PodEntries, reserved map[v1.ResourceName]map[int]uint64, resourceName v1.ResourceName,
+) (NUMANodeMap, error) {
+	machineState, err := GenerateResourceState(machineInfo, reserved, resourceName)
 	if err != nil {
 		return nil, fmt.Errorf("GenerateResourceState failed with error: %v", err)
 	}
@@ -271,9 +358,9 @@ func GenerateMemoryStateFromPodEntries(machineInfo *info.MachineInfo,
 
 		numaNodeState.Allocated = allocatedMemQuantityInNumaNode
 		if numaNodeState.Allocatable < numaNodeState.Allocated {
-			klog.Warningf("[GenerateMemoryStateFromPodEntries] invalid allocated memory: %d in NUMA: %d"+
+			klog.Warningf("[generateStateFromPodEntries] invalid allocated %s: %d in NUMA: %d"+
 				" with allocatable memory size: %d, total memory size: %d, reserved memory size: %d",
-				numaNodeState.Allocated, numaId, numaNodeState.Allocatable, numaNodeState.TotalMemSize, numaNodeState.SystemReserved)
+				resourceName, numaNodeState.Allocated, numaId, numaNodeState.Allocatable, numaNodeState.TotalMemSize, numaNodeState.SystemReserved)
 			numaNodeState.Allocatable = numaNodeState.Allocated
 		}
 		numaNodeState.Free = numaNodeState.Allocatable - numaNodeState.Allocated
@@ -96,6 +96,20 @@ func getReservedMemory(conf *config.Configuration, metaServer *metaserver.MetaSe
 	return reservedMemory, nil
 }
 
+// getReservedHugePages returns reserved bytes of the given hugepage resource in each NUMA node,
+// and it's parsed from the NUMA level reservation in memory plugin configuration.
+func getReservedHugePages(conf *config.Configuration, machineInfo *info.MachineInfo, resourceName v1.ResourceName) map[int]uint64 {
+	reservedHugePages := make(map[int]uint64)
+	for _, node := range machineInfo.Topology {
+		reservedHugePages[node.Id] = 0
+		if quantity, ok := conf.ReservedNumaMemory[int32(node.Id)][resourceName]; ok && quantity.Value() > 0 {
+			reservedHugePages[node.Id] = uint64(quantity.Value())
+		}
+	}
+	general.Infof("reserved %s: %+v", resourceName, reservedHugePages)
+	return reservedHugePages
+}
+
 func applySidecarAllocationInfoFromMainContainer(sidecarAllocationInfo, mainAllocationInfo *state.AllocationInfo) bool {
 	changed := false
 	if !sidecarAllocationInfo.NumaAllocationResult.Equals(mainAllocationInfo.NumaAllocationResult) {
@@ -0,0 +1,70 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"sort"
+	"time"
+
+	pluginapi "k8s.io/kubelet/pkg/apis/resourceplugin/v1alpha1"
+
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/util/eventbus"
+)
+
+// PublishAllocationEvent publishes the result of allocating resources for the
+// given container to event bus for auditing.
+func PublishAllocationEvent(req *pluginapi.ResourceRequest, qosLevel string,
+	resp *pluginapi.ResourceAllocationResponse, respErr error,
+) {
+	if req == nil {
+		return
+	}
+
+	event := eventbus.AllocationEvent{
+		BaseEventImpl: eventbus.BaseEventImpl{
+			Time: time.Now(),
+		},
+		PodUID:        req.PodUid,
+		PodNamespace:  req.PodNamespace,
+		PodName:       req.PodName,
+		ContainerName: req.ContainerName,
+		ResourceName:  req.ResourceName,
+		QoSLevel:      qosLevel,
+	}
+	if respErr != nil {
+		event.Error = respErr.Error()
+	}
+
+	if resp != nil && resp.AllocationResult != nil {
+		for resourceName, info := range resp.AllocationResult.ResourceAllocation {
+			if info == nil {
+				continue
+			}
+			event.Results = append(event.Results, eventbus.AllocationResult{
+				ResourceName:      resourceName,
+				AllocatedQuantity: info.AllocatedQuantity,
+				AllocationResult:  info.AllocationResult,
+			})
+		}
+		sort.Slice(event.Results, func(i, j int) bool {
+			return event.Results[i].ResourceName < event.Results[j].ResourceName
+		})
+	}
+
+	_ = eventbus.GetDefaultEventBus().Publish(consts.TopicNameAllocation, event)
+}
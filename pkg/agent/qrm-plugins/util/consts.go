@@ -43,6 +43,10 @@ const (
 	MetricNameGetMemBWPreferenceFailed    = "get_mem_bw_preference_failed"
 	MetricNameGetNUMAAllocatedMemBWFailed = "get_numa_allocated_mem_bw_failed"
 	MetricNameSetExclusiveIRQCPUSize      = "set_exclusive_irq_cpu_size"
+	MetricNameCPUBurstPercent             = "cpu_burst_percent"
+	MetricNameCPUBurstBudgetExceeded      = "cpu_burst_budget_exceeded"
+	MetricNameLLCReclaimedWays            = "llc_reclaimed_ways"
+	MetricNameLLCOnlineMPKI               = "llc_online_mpki"
 
 	// metrics for memory plugin
 	MetricNameMemSetInvalid                           = "memset_invalid"
@@ -73,6 +77,8 @@ const (
 	MetricNameMetricBasedNUMAAllocationSuccess = "metric_based_numa_allocation_success"
 	MetricNameCollectNUMAMetrics               = "collect_numa_metrics"
 	MetricNameNUMAMetricOverThreshold          = "numa_metric_over_threshold"
+	MetricNameNUMAInterferenceScore            = "numa_interference_score"
+	MetricNameNUMAInterferenceNoisy            = "numa_interference_noisy"
 
 	// metrics for irq tuning
 	MetricNameIrqTuningEnabled                        = "irq_tuning_enabled"
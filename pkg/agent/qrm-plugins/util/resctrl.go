@@ -0,0 +1,46 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/qrm"
+)
+
+const (
+	templateResctrlSharedSubgroup = "shared-%02d"
+	resctrlSharedGroup            = "share"
+)
+
+// GetResctrlSharedSubgroup returns the resctrl group of shared_cores pods with the given subgroup id
+func GetResctrlSharedSubgroup(val int) string {
+	// typical mon group is like "shared-xx", except for
+	// negative value indicates using "shared" mon group
+	if val < 0 {
+		return resctrlSharedGroup
+	}
+	return fmt.Sprintf(templateResctrlSharedSubgroup, val)
+}
+
+// GetResctrlSharedSubgroupByPool returns the resctrl group of shared_cores pods in the given cpuset pool
+func GetResctrlSharedSubgroupByPool(conf *qrm.ResctrlConfig, pool string) string {
+	if v, ok := conf.CPUSetPoolToSharedSubgroup[pool]; ok {
+		return GetResctrlSharedSubgroup(v)
+	}
+	return GetResctrlSharedSubgroup(conf.DefaultSharedSubgroup)
+}
@@ -65,3 +65,77 @@ func (cp *MetaCacheCheckpoint) VerifyChecksum() error {
 	cp.Checksum = ck
 	return err
 }
+
+var _ checkpointmanager.Checkpoint = &PIDTuningCheckpoint{}
+
+// PIDTuningCheckpoint persists the auto-tuned pid gains, and it is kept apart from
+// MetaCacheCheckpoint since gains are updated independently of other entries.
+type PIDTuningCheckpoint struct {
+	PIDTuningEntries types.PIDTuningEntries `json:"pid_tuning_entries"`
+	Checksum         checksum.Checksum      `json:"checksum"`
+}
+
+func NewPIDTuningCheckpoint() *PIDTuningCheckpoint {
+	return &PIDTuningCheckpoint{
+		PIDTuningEntries: make(types.PIDTuningEntries),
+	}
+}
+
+// MarshalCheckpoint returns marshaled checkpoint
+func (cp *PIDTuningCheckpoint) MarshalCheckpoint() ([]byte, error) {
+	// make sure checksum wasn't set before so it doesn't affect output checksum
+	cp.Checksum = 0
+	cp.Checksum = checksum.New(cp)
+	return json.Marshal(*cp)
+}
+
+// UnmarshalCheckpoint tries to unmarshal passed bytes to checkpoint
+func (cp *PIDTuningCheckpoint) UnmarshalCheckpoint(blob []byte) error {
+	return json.Unmarshal(blob, cp)
+}
+
+// VerifyChecksum verifies that current checksum of checkpoint is valid
+func (cp *PIDTuningCheckpoint) VerifyChecksum() error {
+	ck := cp.Checksum
+	cp.Checksum = 0
+	err := ck.Verify(cp)
+	cp.Checksum = ck
+	return err
+}
+
+var _ checkpointmanager.Checkpoint = &UsageProfileCheckpoint{}
+
+// UsageProfileCheckpoint persists the hour-of-week usage profiles of headroom policies,
+// so that profiles don't need to be warmed up again after restarting.
+type UsageProfileCheckpoint struct {
+	UsageProfileEntries types.UsageProfileEntries `json:"usage_profile_entries"`
+	Checksum            checksum.Checksum         `json:"checksum"`
+}
+
+func NewUsageProfileCheckpoint() *UsageProfileCheckpoint {
+	return &UsageProfileCheckpoint{
+		UsageProfileEntries: make(types.UsageProfileEntries),
+	}
+}
+
+// MarshalCheckpoint returns marshaled checkpoint
+func (cp *UsageProfileCheckpoint) MarshalCheckpoint() ([]byte, error) {
+	// make sure checksum wasn't set before so it doesn't affect output checksum
+	cp.Checksum = 0
+	cp.Checksum = checksum.New(cp)
+	return json.Marshal(*cp)
+}
+
+// UnmarshalCheckpoint tries to unmarshal passed bytes to checkpoint
+func (cp *UsageProfileCheckpoint) UnmarshalCheckpoint(blob []byte) error {
+	return json.Unmarshal(blob, cp)
+}
+
+// VerifyChecksum verifies that current checksum of checkpoint is valid
+func (cp *UsageProfileCheckpoint) VerifyChecksum() error {
+	ck := cp.Checksum
+	cp.Checksum = 0
+	err := ck.Verify(cp)
+	cp.Checksum = ck
+	return err
+}
@@ -26,6 +26,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/kubelet/checkpointmanager"
+	"k8s.io/kubernetes/pkg/kubelet/checkpointmanager/errors"
 
 	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/advisorsvc"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
@@ -45,7 +46,10 @@ import (
 // 3. not use omitempty in map property and must make new map to do initialization
 
 const (
-	stateFileName string = "sys_advisor_state"
+	stateFileName          string = "sys_advisor_state"
+	pidTuningStateFileName string = "sys_advisor_pid_tuning_state"
+
+	usageProfileStateFileName string = "sys_advisor_usage_profile_state"
 )
 
 // MetaReader provides a standard interface to refer to metadata type
@@ -70,6 +74,15 @@ type MetaReader interface {
 	// RangeRegionInfo applies a function to every regionName, regionInfo set.
 	// If f returns false, range stops the iteration.
 	RangeRegionInfo(f func(regionName string, regionInfo *types.RegionInfo) bool)
+	// GetRegionUsageHistory returns a copy of the cpu usage history of the region,
+	// ordered from the oldest to the latest
+	GetRegionUsageHistory(regionName string) []float64
+
+	// GetPIDTuningInfo returns a PIDTuningInfo copy by region key and indicator name
+	GetPIDTuningInfo(regionKey, indicatorName string) (*types.PIDTuningInfo, bool)
+
+	// GetUsageProfile returns a UsageProfile copy by profile key
+	GetUsageProfile(profileKey string) (*types.UsageProfile, bool)
 
 	// GetFilteredInferenceResult gets specified model inference result with filter function
 	GetFilteredInferenceResult(filterFunc func(result interface{}) (interface{}, error), modelName string) (interface{}, error)
@@ -117,9 +130,21 @@ type MetaWriter interface {
 	SetRegionEntries(entries types.RegionEntries) error
 	// SetRegionInfo stores a RegionInfo by region name
 	SetRegionInfo(regionName string, regionInfo *types.RegionInfo) error
+	// AddRegionUsage appends the cpu usage of the region to its history, and only the
+	// latest maxSize samples are kept if maxSize is positive; the history of a region
+	// is dropped once the region is removed from region entries
+	AddRegionUsage(regionName string, usage float64, maxSize int) error
 	// SetHeadroomEntries store the headroomInfo of resourceName
 	SetHeadroomEntries(resourceName string, headroomInfo *types.HeadroomInfo) error
 
+	// SetPIDTuningInfo stores a PIDTuningInfo by region key and indicator name, and
+	// persists pid tuning entries into their own checkpoint; nil PIDTuningInfo deletes the existing one
+	SetPIDTuningInfo(regionKey, indicatorName string, info *types.PIDTuningInfo) error
+
+	// SetUsageProfile stores a UsageProfile by profile key, and persists usage
+	// profile entries into their own checkpoint
+	SetUsageProfile(profileKey string, profile *types.UsageProfile) error
+
 	// SetInferenceResult sets specified model inference result
 	SetInferenceResult(modelName string, result interface{}) error
 
@@ -153,12 +178,19 @@ type MetaCacheImp struct {
 	poolEntries types.PoolEntries
 	poolMutex   sync.RWMutex
 
-	regionEntries types.RegionEntries
-	regionMutex   sync.RWMutex
+	regionEntries      types.RegionEntries
+	regionUsageHistory map[string][]float64
+	regionMutex        sync.RWMutex
 
 	headroomEntries types.HeadroomEntries
 	headroomMutex   sync.RWMutex
 
+	pidTuningEntries types.PIDTuningEntries
+	pidTuningMutex   sync.RWMutex
+
+	usageProfileEntries types.UsageProfileEntries
+	usageProfileMutex   sync.RWMutex
+
 	checkpointManager checkpointmanager.CheckpointManager
 	checkpointName    string
 
@@ -203,6 +235,9 @@ func NewMetaCacheImp(conf *config.Configuration, emitterPool metricspool.Metrics
 		podEntries:               make(types.PodEntries),
 		poolEntries:              make(types.PoolEntries),
 		regionEntries:            make(types.RegionEntries),
+		regionUsageHistory:       make(map[string][]float64),
+		pidTuningEntries:         make(types.PIDTuningEntries),
+		usageProfileEntries:      make(types.UsageProfileEntries),
 		checkpointManager:        checkpointManager,
 		checkpointName:           stateFileName,
 		emitter:                  emitter,
@@ -212,6 +247,13 @@ func NewMetaCacheImp(conf *config.Configuration, emitterPool metricspool.Metrics
 		containerCreateTimestamp: make(map[string]int64),
 	}
 
+	if err := mc.restorePIDTuningState(); err != nil {
+		return nil, err
+	}
+	if err := mc.restoreUsageProfileState(); err != nil {
+		return nil, err
+	}
+
 	return mc, nil
 }
 
@@ -291,6 +333,22 @@ func (mc *MetaCacheImp) GetHeadroomEntries(resourceName string) (*types.Headroom
 	return headroomInfo.Clone(), ok
 }
 
+func (mc *MetaCacheImp) GetPIDTuningInfo(regionKey, indicatorName string) (*types.PIDTuningInfo, bool) {
+	mc.pidTuningMutex.RLock()
+	defer mc.pidTuningMutex.RUnlock()
+
+	info, ok := mc.pidTuningEntries[regionKey][indicatorName]
+	return info.Clone(), ok
+}
+
+func (mc *MetaCacheImp) GetUsageProfile(profileKey string) (*types.UsageProfile, bool) {
+	mc.usageProfileMutex.RLock()
+	defer mc.usageProfileMutex.RUnlock()
+
+	profile, ok := mc.usageProfileEntries[profileKey]
+	return profile.Clone(), ok
+}
+
 // GetFilteredInferenceResult gets specified model inference result with filter function
 // whether it returns a deep copied result depends on the implementation of filterFunc
 func (mc *MetaCacheImp) GetFilteredInferenceResult(filterFunc func(result interface{}) (interface{}, error),
@@ -346,6 +404,14 @@ func (mc *MetaCacheImp) RangeRegionInfo(f func(regionName string, regionInfo *ty
 	}
 }
 
+func (mc *MetaCacheImp) GetRegionUsageHistory(regionName string) []float64 {
+	mc.regionMutex.RLock()
+	defer mc.regionMutex.RUnlock()
+
+	history := mc.regionUsageHistory[regionName]
+	return append(make([]float64, 0, len(history)), history...)
+}
+
 /*
 	standard implementation for MetaWriter
 */
@@ -529,6 +595,11 @@ func (mc *MetaCacheImp) SetRegionEntries(entries types.RegionEntries) error {
 	defer mc.regionMutex.Unlock()
 
 	mc.regionEntries = entries.Clone()
+	for regionName := range mc.regionUsageHistory {
+		if _, ok := mc.regionEntries[regionName]; !ok {
+			delete(mc.regionUsageHistory, regionName)
+		}
+	}
 
 	return nil
 }
@@ -546,6 +617,60 @@ func (mc *MetaCacheImp) SetRegionInfo(regionName string, regionInfo *types.Regio
 	return nil
 }
 
+func (mc *MetaCacheImp) AddRegionUsage(regionName string, usage float64, maxSize int) error {
+	mc.regionMutex.Lock()
+	defer mc.regionMutex.Unlock()
+
+	history := append(mc.regionUsageHistory[regionName], usage)
+	if maxSize > 0 && len(history) > maxSize {
+		history = append([]float64(nil), history[len(history)-maxSize:]...)
+	}
+	mc.regionUsageHistory[regionName] = history
+
+	return nil
+}
+
+func (mc *MetaCacheImp) SetPIDTuningInfo(regionKey, indicatorName string, info *types.PIDTuningInfo) error {
+	mc.pidTuningMutex.Lock()
+	defer mc.pidTuningMutex.Unlock()
+
+	if info == nil {
+		if _, ok := mc.pidTuningEntries[regionKey][indicatorName]; !ok {
+			return nil
+		}
+		delete(mc.pidTuningEntries[regionKey], indicatorName)
+		if len(mc.pidTuningEntries[regionKey]) == 0 {
+			delete(mc.pidTuningEntries, regionKey)
+		}
+	} else {
+		if reflect.DeepEqual(mc.pidTuningEntries[regionKey][indicatorName], info) {
+			return nil
+		}
+		if _, ok := mc.pidTuningEntries[regionKey]; !ok {
+			mc.pidTuningEntries[regionKey] = make(map[string]*types.PIDTuningInfo)
+		}
+		mc.pidTuningEntries[regionKey][indicatorName] = info.Clone()
+	}
+
+	return mc.storePIDTuningState()
+}
+
+func (mc *MetaCacheImp) SetUsageProfile(profileKey string, profile *types.UsageProfile) error {
+	mc.usageProfileMutex.Lock()
+	defer mc.usageProfileMutex.Unlock()
+
+	if profile == nil {
+		if _, ok := mc.usageProfileEntries[profileKey]; !ok {
+			return nil
+		}
+		delete(mc.usageProfileEntries, profileKey)
+	} else {
+		mc.usageProfileEntries[profileKey] = profile.Clone()
+	}
+
+	return mc.storeUsageProfileState()
+}
+
 // SetInferenceResult sets specified model inference result
 func (mc *MetaCacheImp) SetInferenceResult(modelName string, result interface{}) error {
 	general.InfoS("called", "modelName", modelName)
@@ -598,6 +723,81 @@ func (mc *MetaCacheImp) SetHeadroomEntries(resourceName string, headroomInfo *ty
 	other helper functions
 */
 
+// storePIDTuningState writes pid tuning entries into their own checkpoint,
+// and it must be called with pidTuningMutex held.
+func (mc *MetaCacheImp) storePIDTuningState() error {
+	if mc.checkpointManager == nil {
+		return nil
+	}
+
+	checkpoint := NewPIDTuningCheckpoint()
+	checkpoint.PIDTuningEntries = mc.pidTuningEntries.Clone()
+
+	if err := mc.checkpointManager.CreateCheckpoint(pidTuningStateFileName, checkpoint); err != nil {
+		klog.Errorf("[metacache] store pid tuning state failed: %v", err)
+		return err
+	}
+	return nil
+}
+
+// restorePIDTuningState restores the auto-tuned pid gains from checkpoint;
+// other entries will be rebuilt from the latest node status after starting.
+func (mc *MetaCacheImp) restorePIDTuningState() error {
+	checkpoint := NewPIDTuningCheckpoint()
+	if err := mc.checkpointManager.GetCheckpoint(pidTuningStateFileName, checkpoint); err != nil {
+		if err == errors.ErrCheckpointNotFound {
+			return nil
+		} else if err == errors.ErrCorruptCheckpoint && mc.skipStateCorruption {
+			klog.Warningf("[metacache] skip corrupted checkpoint %v", pidTuningStateFileName)
+			return nil
+		}
+		return fmt.Errorf("failed to restore checkpoint %v: %v", pidTuningStateFileName, err)
+	}
+
+	if checkpoint.PIDTuningEntries != nil {
+		mc.pidTuningEntries = checkpoint.PIDTuningEntries
+	}
+	klog.Infof("[metacache] restore pid tuning state succeeded")
+	return nil
+}
+
+// storeUsageProfileState writes usage profile entries into their own checkpoint,
+// and it must be called with usageProfileMutex held.
+func (mc *MetaCacheImp) storeUsageProfileState() error {
+	if mc.checkpointManager == nil {
+		return nil
+	}
+
+	checkpoint := NewUsageProfileCheckpoint()
+	checkpoint.UsageProfileEntries = mc.usageProfileEntries.Clone()
+
+	if err := mc.checkpointManager.CreateCheckpoint(usageProfileStateFileName, checkpoint); err != nil {
+		klog.Errorf("[metacache] store usage profile state failed: %v", err)
+		return err
+	}
+	return nil
+}
+
+// restoreUsageProfileState restores usage profiles from checkpoint
+func (mc *MetaCacheImp) restoreUsageProfileState() error {
+	checkpoint := NewUsageProfileCheckpoint()
+	if err := mc.checkpointManager.GetCheckpoint(usageProfileStateFileName, checkpoint); err != nil {
+		if err == errors.ErrCheckpointNotFound {
+			return nil
+		} else if err == errors.ErrCorruptCheckpoint && mc.skipStateCorruption {
+			klog.Warningf("[metacache] skip corrupted checkpoint %v", usageProfileStateFileName)
+			return nil
+		}
+		return fmt.Errorf("failed to restore checkpoint %v: %v", usageProfileStateFileName, err)
+	}
+
+	if checkpoint.UsageProfileEntries != nil {
+		mc.usageProfileEntries = checkpoint.UsageProfileEntries
+	}
+	klog.Infof("[metacache] restore usage profile state succeeded")
+	return nil
+}
+
 func (mc *MetaCacheImp) setContainerCreateTimestamp(podUID, containerName string, timestamp int64) {
 	mc.containerCreateTimestamp[fmt.Sprintf("%s/%s", podUID, containerName)] = timestamp
 }
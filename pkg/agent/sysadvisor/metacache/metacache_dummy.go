@@ -26,6 +26,9 @@ func NewDummyMetaCacheImp() *MetaCacheImp {
 		podEntries:               make(types.PodEntries),
 		poolEntries:              make(types.PoolEntries),
 		regionEntries:            make(types.RegionEntries),
+		regionUsageHistory:       make(map[string][]float64),
+		usageProfileEntries:      make(types.UsageProfileEntries),
+		pidTuningEntries:         make(types.PIDTuningEntries),
 		modelToResult:            make(map[string]interface{}),
 		containerCreateTimestamp: make(map[string]int64),
 		emitter:                  metrics.DummyMetrics{},
@@ -297,3 +297,60 @@ func TestRangeAndDeleteContainerWithSafeTime(t *testing.T) {
 	require.Equal(t, 0, len(mc.podEntries), "failed to delete container before safe time")
 	require.Equal(t, 0, len(mc.containerCreateTimestamp), "failed to delete container create timestamp before safe time")
 }
+
+func TestPIDTuningInfoCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	checkpointManager, err := checkpointmanager.NewCheckpointManager(t.TempDir())
+	require.NoError(t, err, "failed to create checkpoint manager")
+
+	newMetaCache := func() *MetaCacheImp {
+		return &MetaCacheImp{
+			podEntries:        make(types.PodEntries),
+			poolEntries:       make(types.PoolEntries),
+			regionEntries:     make(types.RegionEntries),
+			pidTuningEntries:  make(types.PIDTuningEntries),
+			checkpointManager: checkpointManager,
+			emitter:           metrics.DummyMetrics{},
+		}
+	}
+
+	// checkpoint of other entries should not affect pid tuning state
+	require.NoError(t, checkpointManager.CreateCheckpoint(stateFileName, NewMetaCacheCheckpoint()))
+
+	mc := newMetaCache()
+	require.NoError(t, mc.restorePIDTuningState(), "failed to restore state without checkpoint")
+
+	info := &types.PIDTuningInfo{KpScale: 0.8, KdScale: 1.25, UpdateTime: 1}
+	require.NoError(t, mc.SetPIDTuningInfo("share-share", "cpu_sched_wait", info))
+	require.NoError(t, mc.SetPIDTuningInfo("share-share", "cpu_usage_ratio", info))
+	require.NoError(t, mc.SetPIDTuningInfo("share-share", "cpu_usage_ratio", nil))
+
+	restored := newMetaCache()
+	require.NoError(t, restored.restorePIDTuningState(), "failed to restore state")
+
+	got, ok := restored.GetPIDTuningInfo("share-share", "cpu_sched_wait")
+	require.True(t, ok)
+	require.Equal(t, info, got)
+
+	_, ok = restored.GetPIDTuningInfo("share-share", "cpu_usage_ratio")
+	require.False(t, ok)
+}
+
+func TestRegionUsageHistory(t *testing.T) {
+	t.Parallel()
+
+	mc := NewDummyMetaCacheImp()
+	for _, usage := range []float64{1, 2, 3, 4} {
+		require.NoError(t, mc.AddRegionUsage("share", usage, 3))
+	}
+	require.Equal(t, []float64{2, 3, 4}, mc.GetRegionUsageHistory("share"))
+	require.Empty(t, mc.GetRegionUsageHistory("isolation"))
+
+	// history of removed regions should be dropped with region entries
+	require.NoError(t, mc.SetRegionEntries(types.RegionEntries{"share": &types.RegionInfo{}}))
+	require.NoError(t, mc.AddRegionUsage("dedicated", 1, 3))
+	require.NoError(t, mc.SetRegionEntries(types.RegionEntries{"share": &types.RegionInfo{}}))
+	require.Equal(t, []float64{2, 3, 4}, mc.GetRegionUsageHistory("share"))
+	require.Empty(t, mc.GetRegionUsageHistory("dedicated"))
+}
@@ -46,7 +46,7 @@ type HeadroomPolicy interface {
 }
 
 type InitFunc func(regionName string, regionType configapi.QoSRegionType, ownerPoolName string,
-	conf *config.Configuration, extraConfig interface{}, metaReader metacache.MetaReader,
+	conf *config.Configuration, extraConfig interface{}, metaCache metacache.MetaCache,
 	metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter) HeadroomPolicy
 
 var initializers sync.Map
@@ -35,12 +35,13 @@ type PolicyBase struct {
 	bindingNumas  machine.CPUSet
 
 	metaReader metacache.MetaReader
+	metaWriter metacache.MetaWriter
 	metaServer *metaserver.MetaServer
 	emitter    metrics.MetricEmitter
 }
 
 func NewPolicyBase(regionName string, regionType configapi.QoSRegionType, ownerPoolName string,
-	metaReader metacache.MetaReader, metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter,
+	metaCache metacache.MetaCache, metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter,
 ) *PolicyBase {
 	cp := &PolicyBase{
 		regionName:    regionName,
@@ -48,7 +49,8 @@ func NewPolicyBase(regionName string, regionType configapi.QoSRegionType, ownerP
 		ownerPoolName: ownerPoolName,
 		podSet:        make(types.PodSet),
 
-		metaReader: metaReader,
+		metaReader: metaCache,
+		metaWriter: metaCache,
 		metaServer: metaServer,
 		emitter:    emitter,
 	}
@@ -37,23 +37,39 @@ type PolicyCanonical struct {
 }
 
 func NewPolicyCanonical(regionName string, regionType configapi.QoSRegionType, ownerPoolName string,
-	_ *config.Configuration, _ interface{}, metaReader metacache.MetaReader,
+	_ *config.Configuration, _ interface{}, metaCache metacache.MetaCache,
 	metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter,
 ) HeadroomPolicy {
 	p := &PolicyCanonical{
-		PolicyBase: NewPolicyBase(regionName, regionType, ownerPoolName, metaReader, metaServer, emitter),
+		PolicyBase: NewPolicyBase(regionName, regionType, ownerPoolName, metaCache, metaServer, emitter),
 	}
 	return p
 }
 
 func (p *PolicyCanonical) Update() error {
+	cpuEstimation, err := p.estimateCPUUsage()
+	if err != nil {
+		return err
+	}
+	cpuEstimation += p.ReservedForAllocate
+
+	p.headroom = math.Max(p.ResourceUpperBound-cpuEstimation+p.ReservedForReclaim, 0)
+
+	klog.Infof("[qosaware-cpu-canonical] region %v cpuEstimation %v with reservedForAllocate %v reservedForReclaim %v headroom %v",
+		p.regionName, cpuEstimation, p.ReservedForAllocate, p.ReservedForReclaim, p.headroom)
+
+	return nil
+}
+
+// estimateCPUUsage estimates cpu usage of all containers in region
+func (p *PolicyCanonical) estimateCPUUsage() (float64, error) {
 	cpuEstimation := 0.0
 	containerCnt := 0
 
 	for podUID, containerSet := range p.podSet {
 		enableReclaim, err := helper.PodEnableReclaim(context.Background(), p.metaServer, podUID, p.EnableReclaim)
 		if err != nil {
-			return err
+			return 0, err
 		}
 
 		for containerName := range containerSet {
@@ -64,7 +80,7 @@ func (p *PolicyCanonical) Update() error {
 			}
 			containerEstimation, err := helper.EstimateContainerCPUUsage(ci, p.metaReader, enableReclaim)
 			if err != nil {
-				return err
+				return 0, err
 			}
 
 			// FIXME: metric server doesn't support to report cpu usage in numa granularity,
@@ -88,14 +104,9 @@ func (p *PolicyCanonical) Update() error {
 			containerCnt += 1
 		}
 	}
-	cpuEstimation += p.ReservedForAllocate
 
-	p.headroom = math.Max(p.ResourceUpperBound-cpuEstimation+p.ReservedForReclaim, 0)
-
-	klog.Infof("[qosaware-cpu-canonical] region %v cpuEstimation %v with reservedForAllocate %v reservedForReclaim %v headroom %v #container %v",
-		p.regionName, cpuEstimation, p.ReservedForAllocate, p.ReservedForReclaim, p.headroom, containerCnt)
-
-	return nil
+	klog.Infof("[qosaware-cpu-canonical] region %v cpuEstimation %v #container %v", p.regionName, cpuEstimation, containerCnt)
+	return cpuEstimation, nil
 }
 
 func (p *PolicyCanonical) GetHeadroom() (float64, error) {
@@ -0,0 +1,126 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package headroompolicy
+
+import (
+	"math"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	configapi "github.com/kubewharf/katalyst-api/pkg/apis/config/v1alpha1"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/metacache"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/helper"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/config"
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/sysadvisor/qosaware/resource/cpu/headroom"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+)
+
+const metricCPUHeadroomHistoricalPeak = "cpu_headroom_historical_peak"
+
+// PolicyHistorical estimates cpu headroom with hour-of-week percentile usage profile of
+// the region, so that headroom follows the diurnal patterns and shrinks ahead of known peaks.
+// It falls back to the same estimation as canonical policy before profile is warmed up,
+// and the profile is persisted through metacache to survive restarting.
+type PolicyHistorical struct {
+	*PolicyCanonical
+
+	conf    *headroom.CPUPolicyHistoricalConfiguration
+	profile *helper.HourOfWeekUsageProfile
+	now     func() time.Time
+
+	profileRestored    bool
+	profilePersistTime time.Time
+}
+
+func NewPolicyHistorical(regionName string, regionType configapi.QoSRegionType, ownerPoolName string,
+	conf *config.Configuration, extraConf interface{}, metaCache metacache.MetaCache,
+	metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter,
+) HeadroomPolicy {
+	p := &PolicyHistorical{
+		PolicyCanonical: NewPolicyCanonical(regionName, regionType, ownerPoolName, conf, extraConf,
+			metaCache, metaServer, emitter).(*PolicyCanonical),
+		conf:    conf.CPUPolicyHistorical,
+		profile: helper.NewHourOfWeekUsageProfile(conf.CPUPolicyHistorical.MaxSamplesPerBucket, conf.CPUPolicyHistorical.DownsamplePeriod),
+		now:     time.Now,
+	}
+	return p
+}
+
+func (p *PolicyHistorical) Update() error {
+	cpuUsage, err := p.estimateCPUUsage()
+	if err != nil {
+		return err
+	}
+
+	now := p.now()
+	p.restoreProfile()
+	p.profile.AddSample(now, cpuUsage)
+	p.persistProfile(now)
+
+	cpuEstimation := cpuUsage
+	if peak, ok := p.profile.PeakPercentile(now, p.conf.Lookahead, p.conf.Percentile, p.conf.MinSamplesPerBucket); ok {
+		cpuEstimation = math.Max(cpuEstimation, peak)
+		_ = p.emitter.StoreFloat64(metricCPUHeadroomHistoricalPeak, peak, metrics.MetricTypeNameRaw,
+			metrics.MetricTag{Key: "region_name", Val: p.regionName})
+	}
+	cpuEstimation += p.ReservedForAllocate
+
+	p.headroom = math.Max(p.ResourceUpperBound-cpuEstimation+p.ReservedForReclaim, 0)
+
+	klog.Infof("[qosaware-cpu-historical] region %v cpuUsage %v cpuEstimation %v with reservedForAllocate %v reservedForReclaim %v headroom %v",
+		p.regionName, cpuUsage, cpuEstimation, p.ReservedForAllocate, p.ReservedForReclaim, p.headroom)
+
+	return nil
+}
+
+// restoreProfile resumes the profile from metacache before the first sample is added
+func (p *PolicyHistorical) restoreProfile() {
+	if p.profileRestored {
+		return
+	}
+	p.profileRestored = true
+
+	if profile, ok := p.metaReader.GetUsageProfile(p.profileKey()); ok {
+		p.profile.Restore(profile)
+	}
+}
+
+// persistProfile stores the profile into metacache periodically
+func (p *PolicyHistorical) persistProfile(now time.Time) {
+	if now.Sub(p.profilePersistTime) < helper.UsageProfilePersistPeriod {
+		return
+	}
+
+	if err := p.metaWriter.SetUsageProfile(p.profileKey(), p.profile.Snapshot(now)); err != nil {
+		klog.Errorf("[qosaware-cpu-historical] region %v failed to persist usage profile: %v", p.regionName, err)
+		return
+	}
+	p.profilePersistTime = now
+}
+
+// profileKey identifies the region regardless of its name, since region names
+// are generated randomly and may change after restarting.
+func (p *PolicyHistorical) profileKey() string {
+	key := "cpu" + types.RegionNameSeparator + string(p.regionType) + types.RegionNameSeparator + p.ownerPoolName
+	if p.bindingNumas.Size() > 0 {
+		key += types.RegionNameSeparator + p.bindingNumas.String()
+	}
+	return key
+}
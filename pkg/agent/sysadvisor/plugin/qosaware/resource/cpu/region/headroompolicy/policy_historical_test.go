@@ -0,0 +1,101 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package headroompolicy
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	configapi "github.com/kubewharf/katalyst-api/pkg/apis/config/v1alpha1"
+	katalyst_base "github.com/kubewharf/katalyst-core/cmd/base"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/metacache"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/helper"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent/metric"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	metricspool "github.com/kubewharf/katalyst-core/pkg/metrics/metrics-pool"
+)
+
+func TestPolicyHistorical(t *testing.T) {
+	t.Parallel()
+
+	checkpointDir, err := os.MkdirTemp("", "checkpoint-TestPolicyHistorical")
+	require.NoError(t, err)
+	defer os.RemoveAll(checkpointDir)
+
+	stateFileDir, err := os.MkdirTemp("", "statefile-TestPolicyHistorical")
+	require.NoError(t, err)
+	defer os.RemoveAll(stateFileDir)
+
+	checkpointManagerDir, err := os.MkdirTemp("", "checkpointmanager-TestPolicyHistorical")
+	require.NoError(t, err)
+	defer os.RemoveAll(checkpointManagerDir)
+
+	conf := generateCanonicalTestConfiguration(t, checkpointDir, stateFileDir, checkpointManagerDir)
+	conf.CPUPolicyHistorical.MinSamplesPerBucket = 3
+
+	metaCache, err := metacache.NewMetaCacheImp(conf, metricspool.DummyMetricsEmitterPool{}, metric.NewFakeMetricsFetcher(metrics.DummyMetrics{}))
+	require.NoError(t, err)
+
+	genericCtx, err := katalyst_base.GenerateFakeGenericContext([]runtime.Object{})
+	require.NoError(t, err)
+	metaServer, err := metaserver.NewMetaServer(genericCtx.Client, metrics.DummyMetrics{}, conf)
+	require.NoError(t, err)
+
+	p := NewPolicyHistorical("share-xxx", configapi.QoSRegionTypeShare, "share", conf, nil,
+		metaCache, metaServer, metrics.DummyMetrics{}).(*PolicyHistorical)
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.Local)
+	p.now = func() time.Time { return now }
+	p.SetPodSet(types.PodSet{})
+	p.SetEssentials(types.ResourceEssentials{EnableReclaim: true, ResourceUpperBound: 90, ReservedForAllocate: 4})
+
+	// headroom is the same as canonical before profile is warmed up
+	require.NoError(t, p.Update())
+	headroom, err := p.GetHeadroom()
+	require.NoError(t, err)
+	assert.Equal(t, 86.0, headroom)
+
+	// headroom shrinks ahead of the peak in profile of the next hour a week ago
+	for i := 0; i < 3; i++ {
+		p.profile.AddSample(now.AddDate(0, 0, -7).Add(time.Hour+time.Duration(i)*conf.CPUPolicyHistorical.DownsamplePeriod), 30)
+	}
+	require.NoError(t, p.Update())
+	headroom, err = p.GetHeadroom()
+	require.NoError(t, err)
+	assert.Equal(t, 56.0, headroom)
+
+	// profile is persisted periodically and restored by the policy of the same region
+	// even if region name changes after restarting
+	now = now.Add(helper.UsageProfilePersistPeriod)
+	require.NoError(t, p.Update())
+
+	restarted := NewPolicyHistorical("share-yyy", configapi.QoSRegionTypeShare, "share", conf, nil,
+		metaCache, metaServer, metrics.DummyMetrics{}).(*PolicyHistorical)
+	restarted.now = func() time.Time { return now }
+	restarted.SetPodSet(types.PodSet{})
+	restarted.SetEssentials(types.ResourceEssentials{EnableReclaim: true, ResourceUpperBound: 90, ReservedForAllocate: 4})
+	require.NoError(t, restarted.Update())
+	headroom, err = restarted.GetHeadroom()
+	require.NoError(t, err)
+	assert.Equal(t, 56.0, headroom)
+}
@@ -29,7 +29,7 @@ import (
 type PolicyNone struct{}
 
 func NewPolicyNone(_ string, _ configapi.QoSRegionType, _ string,
-	_ *config.Configuration, _ interface{}, _ metacache.MetaReader,
+	_ *config.Configuration, _ interface{}, _ metacache.MetaCache,
 	_ *metaserver.MetaServer, _ metrics.MetricEmitter,
 ) HeadroomPolicy {
 	return &PolicyNone{}
@@ -43,11 +43,11 @@ type PolicyNUMADedicated struct {
 // NOTE: NewPolicyNUMADedicated can only for dedicated_cores with numa exclusive region
 
 func NewPolicyNUMADedicated(regionName string, regionType configapi.QoSRegionType, ownerPoolName string,
-	_ *config.Configuration, _ interface{}, metaReader metacache.MetaReader,
+	_ *config.Configuration, _ interface{}, metaCache metacache.MetaCache,
 	metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter,
 ) HeadroomPolicy {
 	p := &PolicyNUMADedicated{
-		PolicyBase: NewPolicyBase(regionName, regionType, ownerPoolName, metaReader, metaServer, emitter),
+		PolicyBase: NewPolicyBase(regionName, regionType, ownerPoolName, metaCache, metaServer, emitter),
 	}
 	return p
 }
@@ -40,18 +40,20 @@ type PolicyBase struct {
 	controlKnobAdjusted types.ControlKnob
 
 	metaReader metacache.MetaReader
+	metaWriter metacache.MetaWriter
 	metaServer *metaserver.MetaServer
 	emitter    metrics.MetricEmitter
 }
 
 func NewPolicyBase(regionName string, regionType configapi.QoSRegionType, ownerPoolName string,
-	metaReader metacache.MetaReader, metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter,
+	metaCache metacache.MetaCache, metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter,
 ) *PolicyBase {
 	cp := &PolicyBase{
 		regionName:    regionName,
 		regionType:    regionType,
 		ownerPoolName: ownerPoolName,
-		metaReader:    metaReader,
+		metaReader:    metaCache,
+		metaWriter:    metaCache,
 		metaServer:    metaServer,
 		emitter:       emitter,
 	}
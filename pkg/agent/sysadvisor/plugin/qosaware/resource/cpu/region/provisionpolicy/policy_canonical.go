@@ -39,11 +39,11 @@ type PolicyCanonical struct {
 }
 
 func NewPolicyCanonical(regionName string, regionType configapi.QoSRegionType, ownerPoolName string,
-	_ *config.Configuration, _ interface{}, metaReader metacache.MetaReader,
+	_ *config.Configuration, _ interface{}, metaCache metacache.MetaCache,
 	metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter,
 ) ProvisionPolicy {
 	p := &PolicyCanonical{
-		PolicyBase: NewPolicyBase(regionName, regionType, ownerPoolName, metaReader, metaServer, emitter),
+		PolicyBase: NewPolicyBase(regionName, regionType, ownerPoolName, metaCache, metaServer, emitter),
 	}
 	return p
 }
@@ -41,11 +41,11 @@ type PolicyDynamicQuota struct {
 }
 
 func NewPolicyDynamicQuota(regionName string, regionType configapi.QoSRegionType, ownerPoolName string,
-	conf *config.Configuration, _ interface{}, metaReader metacache.MetaReader,
+	conf *config.Configuration, _ interface{}, metaCache metacache.MetaCache,
 	metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter,
 ) ProvisionPolicy {
 	p := &PolicyDynamicQuota{
-		PolicyBase: NewPolicyBase(regionName, regionType, ownerPoolName, metaReader, metaServer, emitter),
+		PolicyBase: NewPolicyBase(regionName, regionType, ownerPoolName, metaCache, metaServer, emitter),
 		conf:       conf,
 	}
 	return p
@@ -0,0 +1,217 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionpolicy
+
+import (
+	"math"
+
+	configapi "github.com/kubewharf/katalyst-api/pkg/apis/config/v1alpha1"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/metacache"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/config"
+	provisionconfig "github.com/kubewharf/katalyst-core/pkg/config/agent/sysadvisor/qosaware/resource/cpu/provision"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+)
+
+const (
+	metricMPCForecastCPU = "mpc_forecast_cpu"
+)
+
+// PolicyMPC is a model-predictive provision policy. It keeps the recent cpu
+// usage of the region sampled from metacache, forecasts the usage over the next
+// few control intervals, and picks the cpu requirement sequence that minimizes
+// the cost of shortage, over-provision and movement within the bounds and ramp
+// limits; only the first step of the plan is applied in each round, so share
+// pools can be grown before latency spikes rather than after.
+type PolicyMPC struct {
+	*PolicyCanonical
+	conf *config.Configuration
+}
+
+func NewPolicyMPC(regionName string, regionType configapi.QoSRegionType, ownerPoolName string,
+	conf *config.Configuration, extraConf interface{}, metaCache metacache.MetaCache,
+	metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter,
+) ProvisionPolicy {
+	p := &PolicyMPC{
+		PolicyCanonical: NewPolicyCanonical(regionName, regionType, ownerPoolName, conf, extraConf,
+			metaCache, metaServer, emitter).(*PolicyCanonical),
+		conf: conf,
+	}
+	return p
+}
+
+func (p *PolicyMPC) Update() error {
+	// sanity check
+	if err := p.sanityCheck(); err != nil {
+		return err
+	}
+
+	cpuEstimation, err := p.estimateCPUUsage()
+	if err != nil {
+		return err
+	}
+
+	p.controlKnobAdjusted = types.ControlKnob{
+		configapi.ControlKnobNonReclaimedCPURequirement: types.ControlKnobItem{
+			Value:  p.provision(cpuEstimation),
+			Action: types.ControlKnobActionNone,
+		},
+	}
+	return nil
+}
+
+// provision records the latest cpu usage and returns the cpu requirement for next interval
+func (p *PolicyMPC) provision(cpuUsage float64) float64 {
+	mpcConf := p.conf.PolicyMPC
+
+	if err := p.metaWriter.AddRegionUsage(p.regionName, cpuUsage, mpcConf.HistorySize); err != nil {
+		general.ErrorS(err, "failed to add region usage", "regionName", p.regionName)
+	}
+	usageHistory := p.metaReader.GetRegionUsageHistory(p.regionName)
+	if len(usageHistory) == 0 {
+		usageHistory = []float64{cpuUsage}
+	}
+
+	horizon := general.Max(mpcConf.Horizon, 1)
+	forecast := forecastDoubleExponential(usageHistory, mpcConf.SmoothingAlpha, mpcConf.TrendBeta, horizon)
+
+	targetUtilization := mpcConf.TargetUtilization
+	if targetUtilization <= 0 {
+		targetUtilization = 1
+	}
+	demands := make([]float64, len(forecast))
+	for i, usage := range forecast {
+		demands[i] = usage / targetUtilization
+	}
+
+	initial := cpuUsage
+	if knob, ok := p.ControlKnobs[configapi.ControlKnobNonReclaimedCPURequirement]; ok {
+		initial = knob.Value
+	}
+
+	plan := solveMPCPlan(demands, initial, p.ResourceLowerBound, p.ResourceUpperBound,
+		p.conf.MaxRampUpStep, p.conf.MaxRampDownStep, mpcConf)
+
+	general.InfoS("[qosaware-cpu-mpc] plan result", "meta", p.GetMetaInfo(), "usage", cpuUsage,
+		"forecast", forecast, "initial", initial, "plan", plan)
+	_ = p.emitter.StoreFloat64(metricMPCForecastCPU, forecast[len(forecast)-1], metrics.MetricTypeNameRaw,
+		metrics.MetricTag{Key: "region_name", Val: p.regionName})
+
+	return plan[0]
+}
+
+// forecastDoubleExponential forecasts the next steps by double exponential smoothing
+// (Holt's linear method), and the forecasted values are no less than zero.
+func forecastDoubleExponential(history []float64, alpha, beta float64, steps int) []float64 {
+	forecast := make([]float64, steps)
+	if len(history) == 0 {
+		return forecast
+	}
+
+	level, trend := history[0], 0.0
+	for i := 1; i < len(history); i++ {
+		lastLevel := level
+		level = alpha*history[i] + (1-alpha)*(level+trend)
+		trend = beta*(level-lastLevel) + (1-beta)*trend
+	}
+
+	for i := range forecast {
+		forecast[i] = math.Max(level+float64(i+1)*trend, 0)
+	}
+	return forecast
+}
+
+// solveMPCPlan finds the optimal integral cpu requirement for each step by dynamic
+// programming, where the cost of each step consists of squared shortage, linear
+// over-provision and squared movement from the previous step, and cpu requirements
+// are restricted in [lower, upper] and changed by at most maxUp/maxDown each step.
+func solveMPCPlan(demands []float64, initial, lower, upper float64, maxUp, maxDown int,
+	conf *provisionconfig.PolicyMPCConfiguration,
+) []float64 {
+	minCPU := int(math.Ceil(math.Max(lower, 0)))
+	maxCPU := int(math.Floor(upper))
+	if maxCPU < minCPU {
+		maxCPU = minCPU
+	}
+	if maxUp <= 0 {
+		maxUp = maxCPU - minCPU
+	}
+	if maxDown <= 0 {
+		maxDown = maxCPU - minCPU
+	}
+
+	stepCost := func(demand float64, prev, cur int) float64 {
+		shortage := math.Max(demand-float64(cur), 0)
+		overProvision := math.Max(float64(cur)-demand, 0)
+		movement := float64(cur - prev)
+		return conf.ShortageWeight*shortage*shortage + conf.OverProvisionWeight*overProvision +
+			conf.MovementWeight*movement*movement
+	}
+
+	// the initial requirement may be out of bounds, then it's moved to the nearest bound
+	start := general.Min(general.Max(int(math.Round(initial)), minCPU), maxCPU)
+	stateNum := maxCPU - minCPU + 1
+
+	// costs[s] is the min cost to reach state s at current step, and
+	// parents[k][s] is the state at step k-1 on the optimal path
+	costs := make([]float64, stateNum)
+	parents := make([][]int, len(demands))
+	for s := range costs {
+		costs[s] = math.Inf(1)
+	}
+
+	for s := general.Max(start-maxDown, minCPU); s <= general.Min(start+maxUp, maxCPU); s++ {
+		costs[s-minCPU] = stepCost(demands[0], start, s)
+	}
+	parents[0] = make([]int, stateNum)
+
+	for k := 1; k < len(demands); k++ {
+		nextCosts := make([]float64, stateNum)
+		parents[k] = make([]int, stateNum)
+		for s := range nextCosts {
+			nextCosts[s] = math.Inf(1)
+			for prev := general.Max(s-maxUp, 0); prev <= general.Min(s+maxDown, stateNum-1); prev++ {
+				if math.IsInf(costs[prev], 1) {
+					continue
+				}
+
+				cost := costs[prev] + stepCost(demands[k], prev+minCPU, s+minCPU)
+				if cost < nextCosts[s] {
+					nextCosts[s] = cost
+					parents[k][s] = prev
+				}
+			}
+		}
+		costs = nextCosts
+	}
+
+	best := 0
+	for s := range costs {
+		if costs[s] < costs[best] {
+			best = s
+		}
+	}
+
+	plan := make([]float64, len(demands))
+	for k := len(demands) - 1; k >= 0; k-- {
+		plan[k] = float64(best + minCPU)
+		best = parents[k][best]
+	}
+	return plan
+}
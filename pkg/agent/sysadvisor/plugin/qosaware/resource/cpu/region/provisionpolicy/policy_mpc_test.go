@@ -0,0 +1,144 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionpolicy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	configapi "github.com/kubewharf/katalyst-api/pkg/apis/config/v1alpha1"
+	"github.com/kubewharf/katalyst-core/cmd/katalyst-agent/app/options"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/metacache"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	provisionconfig "github.com/kubewharf/katalyst-core/pkg/config/agent/sysadvisor/qosaware/resource/cpu/provision"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+)
+
+func TestForecastDoubleExponential(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []float64{0, 0}, forecastDoubleExponential(nil, 0.5, 0.5, 2))
+	assert.Equal(t, []float64{4, 4}, forecastDoubleExponential([]float64{4, 4, 4}, 0.5, 0.5, 2))
+
+	// an increasing load should be forecasted to keep increasing
+	forecast := forecastDoubleExponential([]float64{2, 4, 6, 8}, 0.8, 0.8, 3)
+	assert.True(t, forecast[0] > 8)
+	assert.True(t, forecast[1] > forecast[0] && forecast[2] > forecast[1])
+
+	// a decreasing load should never be forecasted as negative
+	assert.Equal(t, 0.0, forecastDoubleExponential([]float64{8, 4, 1}, 1, 1, 3)[2])
+}
+
+func TestSolveMPCPlan(t *testing.T) {
+	t.Parallel()
+
+	conf := &provisionconfig.PolicyMPCConfiguration{
+		ShortageWeight:      10,
+		OverProvisionWeight: 1,
+		MovementWeight:      0.1,
+	}
+
+	for _, tc := range []struct {
+		comment  string
+		demands  []float64
+		initial  float64
+		lower    float64
+		upper    float64
+		maxUp    int
+		maxDown  int
+		expected []float64
+	}{
+		{
+			comment:  "stable demand should keep stable",
+			demands:  []float64{4, 4, 4},
+			initial:  4,
+			lower:    2,
+			upper:    20,
+			expected: []float64{4, 4, 4},
+		},
+		{
+			comment:  "requirement should be raised in advance with ramp up limits",
+			demands:  []float64{4, 4, 12},
+			initial:  4,
+			lower:    2,
+			upper:    20,
+			maxUp:    4,
+			maxDown:  2,
+			expected: []float64{4, 8, 12},
+		},
+		{
+			comment:  "requirement should be restricted by bounds",
+			demands:  []float64{30, 30},
+			initial:  40,
+			lower:    2,
+			upper:    20,
+			expected: []float64{20, 20},
+		},
+		{
+			comment:  "requirement should be decreased with ramp down limits",
+			demands:  []float64{2, 2},
+			initial:  10,
+			lower:    2,
+			upper:    20,
+			maxUp:    4,
+			maxDown:  2,
+			expected: []float64{8, 6},
+		},
+	} {
+		plan := solveMPCPlan(tc.demands, tc.initial, tc.lower, tc.upper, tc.maxUp, tc.maxDown, conf)
+		assert.Equal(t, tc.expected, plan, tc.comment)
+	}
+}
+
+func TestPolicyMPC_provision(t *testing.T) {
+	t.Parallel()
+
+	conf, err := options.NewOptions().Config()
+	require.NoError(t, err)
+	conf.PolicyMPC = &provisionconfig.PolicyMPCConfiguration{
+		HistorySize:         3,
+		Horizon:             3,
+		SmoothingAlpha:      0.8,
+		TrendBeta:           0.8,
+		TargetUtilization:   1,
+		ShortageWeight:      10,
+		OverProvisionWeight: 1,
+		MovementWeight:      0.1,
+	}
+	conf.MaxRampUpStep = 10
+	conf.MaxRampDownStep = 2
+
+	metaCache := metacache.NewDummyMetaCacheImp()
+	p := NewPolicyMPC("share", configapi.QoSRegionTypeShare, "share", conf, nil, metaCache, nil, metrics.DummyMetrics{}).(*PolicyMPC)
+	p.SetEssentials(types.ResourceEssentials{ResourceLowerBound: 2, ResourceUpperBound: 40}, types.ControlEssentials{
+		ControlKnobs: types.ControlKnob{
+			configapi.ControlKnobNonReclaimedCPURequirement: {Value: 4},
+		},
+	})
+
+	assert.Equal(t, 4.0, p.provision(4))
+	assert.Equal(t, 4.0, p.provision(4))
+	assert.Len(t, metaCache.GetRegionUsageHistory("share"), 2)
+
+	// rising load should be provisioned beyond current usage
+	assert.True(t, p.provision(8) > 8)
+	assert.Len(t, metaCache.GetRegionUsageHistory("share"), 3)
+	assert.True(t, p.provision(12) > 12)
+	assert.Len(t, metaCache.GetRegionUsageHistory("share"), 3)
+}
@@ -36,6 +36,7 @@ import (
 
 const (
 	metricRamaDominantIndicator = "rama_dominant_indicator"
+	metricRamaPIDGainScale      = "rama_pid_gain_scale"
 	controlActingDirect         = "direct"
 	controlActingReverse        = "reverse"
 )
@@ -63,16 +64,18 @@ type PolicyRama struct {
 	*PolicyBase
 	conf        *config.Configuration
 	controllers map[string]*helper.PIDController // map[metricName]controller
+	tuners      map[string]*helper.PIDAutoTuner  // map[metricName]tuner
 }
 
 func NewPolicyRama(regionName string, regionType configapi.QoSRegionType, ownerPoolName string,
-	conf *config.Configuration, _ interface{}, metaReader metacache.MetaReader,
+	conf *config.Configuration, _ interface{}, metaCache metacache.MetaCache,
 	metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter,
 ) ProvisionPolicy {
 	p := &PolicyRama{
 		conf:        conf,
-		PolicyBase:  NewPolicyBase(regionName, regionType, ownerPoolName, metaReader, metaServer, emitter),
+		PolicyBase:  NewPolicyBase(regionName, regionType, ownerPoolName, metaCache, metaServer, emitter),
 		controllers: make(map[string]*helper.PIDController),
+		tuners:      make(map[string]*helper.PIDAutoTuner),
 	}
 
 	return p
@@ -107,10 +110,16 @@ func (p *PolicyRama) Update() error {
 			continue
 		}
 
+		if p.conf.PolicyRama.EnablePIDAutoTuning {
+			params = p.autoTunePIDParams(metricName, params, indicator)
+		}
+
 		controller, ok := p.controllers[metricName]
 		if !ok {
 			controller = helper.NewPIDController(metricName, params, p.GetMetaInfo())
 			p.controllers[metricName] = controller
+		} else if p.conf.PolicyRama.EnablePIDAutoTuning {
+			controller.SetParams(params)
 		}
 
 		direction := controlActingDirect
@@ -146,6 +155,14 @@ func (p *PolicyRama) Update() error {
 		}
 	}
 
+	for metricName := range p.tuners {
+		_, ok := p.conf.PolicyRama.PIDParameters[metricName]
+		if !ok || !p.conf.PolicyRama.EnablePIDAutoTuning {
+			delete(p.tuners, metricName)
+			p.storePIDTuningInfo(metricName, nil)
+		}
+	}
+
 	general.Infof("rama update ret: %s, %v", knobName, cpuAdjustedRaw)
 
 	cpuAdjustedRestricted := cpuAdjustedRaw
@@ -160,6 +177,57 @@ func (p *PolicyRama) Update() error {
 	return nil
 }
 
+// autoTunePIDParams observes indicator response and returns the pid params with tuned gains
+func (p *PolicyRama) autoTunePIDParams(metricName string, params types.FirstOrderPIDParams,
+	indicator types.IndicatorValue,
+) types.FirstOrderPIDParams {
+	tuner, ok := p.tuners[metricName]
+	if !ok {
+		// resume from the gains tuned before restarting
+		info, _ := p.metaReader.GetPIDTuningInfo(p.pidTuningRegionKey(), metricName)
+		tuner = helper.NewPIDAutoTuner(metricName, p.conf.PolicyRama.PIDAutoTuningParams, info)
+		p.tuners[metricName] = tuner
+	}
+
+	if tuner.Observe(params, indicator.Target, indicator.Current) {
+		info := tuner.GetTuningInfo()
+		general.InfoS("[qosaware-cpu-rama] pid gains tuned", "meta", p.GetMetaInfo(), "metricName", metricName,
+			"kpScale", info.KpScale, "kdScale", info.KdScale)
+		p.storePIDTuningInfo(metricName, info)
+	}
+
+	info := tuner.GetTuningInfo()
+	_ = p.emitter.StoreFloat64(metricRamaPIDGainScale, info.KpScale, metrics.MetricTypeNameRaw, []metrics.MetricTag{
+		{Key: "metric_name", Val: metricName},
+		{Key: "region_type", Val: string(p.regionType)},
+		{Key: "gain", Val: "kp"},
+	}...)
+	_ = p.emitter.StoreFloat64(metricRamaPIDGainScale, info.KdScale, metrics.MetricTypeNameRaw, []metrics.MetricTag{
+		{Key: "metric_name", Val: metricName},
+		{Key: "region_type", Val: string(p.regionType)},
+		{Key: "gain", Val: "kd"},
+	}...)
+
+	return tuner.TunedParams(params)
+}
+
+// storePIDTuningInfo persists tuned gains through metacache checkpoint
+func (p *PolicyRama) storePIDTuningInfo(metricName string, info *types.PIDTuningInfo) {
+	if err := p.metaWriter.SetPIDTuningInfo(p.pidTuningRegionKey(), metricName, info); err != nil {
+		general.ErrorS(err, "failed to store pid tuning info", "meta", p.GetMetaInfo(), "metricName", metricName)
+	}
+}
+
+// pidTuningRegionKey identifies the region regardless of its name, since region names
+// are generated randomly and may change after restarting.
+func (p *PolicyRama) pidTuningRegionKey() string {
+	key := string(p.regionType) + types.RegionNameSeparator + p.ownerPoolName
+	if p.bindingNumas.Size() > 0 {
+		key += types.RegionNameSeparator + p.bindingNumas.String()
+	}
+	return key
+}
+
 func (p *PolicyRama) sanityCheck() error {
 	var (
 		isLegal bool
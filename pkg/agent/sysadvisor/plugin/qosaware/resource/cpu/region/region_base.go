@@ -60,6 +60,7 @@ const (
 	metricCPUProvisionControlKnobRaw       = "cpu_provision_control_knob_raw"
 	metricCPUProvisionControlKnobRegulated = "cpu_provision_control_knob_regulated"
 	metricRegionIndicatorTargetRaw         = "region_indicator_target_raw"
+	metricCPURegulatorDamping              = "cpu_regulator_damping"
 
 	metricTagKeyPolicyName        = "policy_name"
 	metricTagKeyRegionType        = "region_type"
@@ -230,7 +231,7 @@ type QoSRegionBase struct {
 // NewQoSRegionBase returns a base qos region instance with common region methods
 func NewQoSRegionBase(name string, ownerPoolName string, regionType v1alpha1.QoSRegionType,
 	conf *config.Configuration, extraConf interface{}, isNumaBinding bool, isNumaExclusive bool,
-	metaReader metacache.MetaReader, metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter,
+	metaCache metacache.MetaCache, metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter,
 ) *QoSRegionBase {
 	r := &QoSRegionBase{
 		conf:          conf,
@@ -250,9 +251,13 @@ func NewQoSRegionBase(name string, ownerPoolName string, regionType v1alpha1.QoS
 
 		provisionPolicyResults: make(map[types.CPUProvisionPolicyName]*provisionPolicyResult),
 		cpuRegulatorOptions: regulator.RegulatorOptions{
-			MaxRampUpStep:     conf.MaxRampUpStep,
-			MaxRampDownStep:   conf.MaxRampDownStep,
-			MinRampDownPeriod: conf.MinRampDownPeriod,
+			MaxRampUpStep:        conf.MaxRampUpStep,
+			MaxRampDownStep:      conf.MaxRampDownStep,
+			MinRampDownPeriod:    conf.MinRampDownPeriod,
+			OscillationWindow:    conf.OscillationWindow,
+			OscillationThreshold: conf.OscillationThreshold,
+			DampingDeadband:      conf.DampingDeadband,
+			DampingDuration:      conf.DampingDuration,
 			NeedHTAligned: func() bool {
 				return machine.SmtActive() &&
 					!conf.GetDynamicConfiguration().AllowSharedCoresOverlapReclaimedCores &&
@@ -262,7 +267,7 @@ func NewQoSRegionBase(name string, ownerPoolName string, regionType v1alpha1.QoS
 
 		ctrlKnobsNeedPolicyRestrict: map[v1alpha1.ControlKnobName]bool{configapi.ControlKnobReclaimedCoresCPUQuota: true},
 
-		metaReader: metaReader,
+		metaReader: metaCache,
 		metaServer: metaServer,
 		emitter:    emitter,
 
@@ -273,15 +278,17 @@ func NewQoSRegionBase(name string, ownerPoolName string, regionType v1alpha1.QoS
 		isNumaExclusive: isNumaExclusive,
 	}
 
-	r.initHeadroomPolicy(conf, extraConf, metaReader, metaServer, emitter)
-	r.initProvisionPolicy(conf, extraConf, metaReader, metaServer, emitter)
+	r.cpuRegulatorOptions.OnDamping = r.onRegulatorDamping
+
+	r.initHeadroomPolicy(conf, extraConf, metaCache, metaServer, emitter)
+	r.initProvisionPolicy(conf, extraConf, metaCache, metaServer, emitter)
 
 	// enableBorweinModel is initialized according to rama provision policy config.
 	// it only takes effect when updating target indicators,
 	// if there are more code positions depending on it,
 	// we should consider provide a dummy borwein controller to avoid redundant judgement.
 	if r.conf.PolicyRama.EnableBorwein {
-		r.borweinController = borweinctrl.NewBorweinController(name, regionType, ownerPoolName, conf, metaReader, emitter)
+		r.borweinController = borweinctrl.NewBorweinController(name, regionType, ownerPoolName, conf, metaCache, emitter)
 	}
 	r.enableReclaim = r.EnableReclaimFunc
 
@@ -477,6 +484,17 @@ func (r *QoSRegionBase) GetProvision() (types.ControlKnob, error) {
 	return nil, fmt.Errorf("failed to get legal provision")
 }
 
+// onRegulatorDamping is called by cpu regulators when oscillation of control knob is detected
+func (r *QoSRegionBase) onRegulatorDamping(reversals int) {
+	klog.Warningf("[qosaware-cpu] region %v control knob oscillates with %v reversals, start damping", r.name, reversals)
+	_ = r.emitter.StoreInt64(metricCPURegulatorDamping, int64(reversals), metrics.MetricTypeNameRaw,
+		metrics.ConvertMapToTags(map[string]string{
+			metricTagKeyRegionType:  string(r.regionType),
+			metricTagKeyRegionName:  r.name,
+			metricTagKeyRegionNUMAs: r.bindingNumas.String(),
+		})...)
+}
+
 func (r *QoSRegionBase) GetHeadroom() (float64, error) {
 	r.Lock()
 	defer r.Unlock()
@@ -610,7 +628,7 @@ func getRegionNameFromMetaCache(ci *types.ContainerInfo, numaID int, metaReader
 
 // initProvisionPolicy initializes provision by adding additional policies into default ones
 func (r *QoSRegionBase) initProvisionPolicy(conf *config.Configuration, extraConf interface{},
-	metaReader metacache.MetaReader, metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter,
+	metaCache metacache.MetaCache, metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter,
 ) {
 	configuredProvisionPolicy, ok := conf.CPUAdvisorConfiguration.ProvisionPolicies[r.regionType]
 	if !ok {
@@ -623,7 +641,7 @@ func (r *QoSRegionBase) initProvisionPolicy(conf *config.Configuration, extraCon
 	initializers := provisionpolicy.GetRegisteredInitializers()
 	for _, policyName := range configuredProvisionPolicy {
 		if initializer, ok := initializers[policyName]; ok {
-			policy := initializer(r.name, r.regionType, r.ownerPoolName, conf, extraConf, metaReader, metaServer, emitter)
+			policy := initializer(r.name, r.regionType, r.ownerPoolName, conf, extraConf, metaCache, metaServer, emitter)
 			policy.SetBindingNumas(r.bindingNumas, false)
 			r.provisionPolicies = append(r.provisionPolicies, &internalProvisionPolicy{
 				name:                policyName,
@@ -638,7 +656,7 @@ func (r *QoSRegionBase) initProvisionPolicy(conf *config.Configuration, extraCon
 
 // initHeadroomPolicy initializes headroom by adding additional policies into default ones
 func (r *QoSRegionBase) initHeadroomPolicy(conf *config.Configuration, extraConf interface{},
-	metaReader metacache.MetaReader, metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter,
+	metaCache metacache.MetaCache, metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter,
 ) {
 	configuredHeadroomPolicy, ok := conf.CPUAdvisorConfiguration.HeadroomPolicies[r.regionType]
 	if !ok {
@@ -650,7 +668,7 @@ func (r *QoSRegionBase) initHeadroomPolicy(conf *config.Configuration, extraConf
 	headroomInitializers := headroompolicy.GetRegisteredInitializers()
 	for _, policyName := range configuredHeadroomPolicy {
 		if initializer, ok := headroomInitializers[policyName]; ok {
-			policy := initializer(r.name, r.regionType, r.ownerPoolName, conf, extraConf, metaReader, metaServer, emitter)
+			policy := initializer(r.name, r.regionType, r.ownerPoolName, conf, extraConf, metaCache, metaServer, emitter)
 			r.headroomPolicies = append(r.headroomPolicies, &internalHeadroomPolicy{
 				name:                policyName,
 				policy:              policy,
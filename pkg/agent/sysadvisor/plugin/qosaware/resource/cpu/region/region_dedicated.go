@@ -55,9 +55,9 @@ type QoSRegionDedicated struct {
 // NewQoSRegionDedicated returns a region instance for dedicated cores
 // with numa binding and numa exclusive container
 func NewQoSRegionDedicated(ci *types.ContainerInfo, conf *config.Configuration, numaID int,
-	extraConf interface{}, metaReader metacache.MetaReader, metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter,
+	extraConf interface{}, metaCache metacache.MetaCache, metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter,
 ) QoSRegion {
-	regionName := getRegionNameFromMetaCache(ci, numaID, metaReader)
+	regionName := getRegionNameFromMetaCache(ci, numaID, metaCache)
 	if regionName == "" {
 		regionName = string(configapi.QoSRegionTypeDedicated) + types.RegionNameSeparator + string(uuid.NewUUID())
 	}
@@ -65,7 +65,7 @@ func NewQoSRegionDedicated(ci *types.ContainerInfo, conf *config.Configuration,
 	isNumaBinding := numaID != commonstate.FakedNUMAID
 	r := &QoSRegionDedicated{
 		QoSRegionBase: NewQoSRegionBase(regionName, ci.OwnerPoolName, configapi.QoSRegionTypeDedicated, conf, extraConf,
-			isNumaBinding, ci.IsDedicatedNumaExclusive(), metaReader, metaServer, emitter),
+			isNumaBinding, ci.IsDedicatedNumaExclusive(), metaCache, metaServer, emitter),
 	}
 
 	if isNumaBinding {
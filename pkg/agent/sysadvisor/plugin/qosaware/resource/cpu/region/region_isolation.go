@@ -40,11 +40,11 @@ type QoSRegionIsolation struct {
 
 // NewQoSRegionIsolation returns a region instance for isolated pods
 func NewQoSRegionIsolation(ci *types.ContainerInfo, customRegionName string, conf *config.Configuration, extraConf interface{}, numaID int,
-	metaReader metacache.MetaReader, metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter,
+	metaCache metacache.MetaCache, metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter,
 ) QoSRegion {
 	regionName := customRegionName
 	if regionName == "" {
-		regionName = getRegionNameFromMetaCache(ci, numaID, metaReader)
+		regionName = getRegionNameFromMetaCache(ci, numaID, metaCache)
 		if regionName == "" {
 			regionName = string(configapi.QoSRegionTypeIsolation) + types.RegionNameSeparator + ci.PodName + types.RegionNameSeparator + string(uuid.NewUUID())
 		}
@@ -56,7 +56,7 @@ func NewQoSRegionIsolation(ci *types.ContainerInfo, customRegionName string, con
 		ownerPoolName = isolationRegionNUMAOwnerPoolName
 	}
 	r := &QoSRegionIsolation{
-		QoSRegionBase: NewQoSRegionBase(regionName, ownerPoolName, configapi.QoSRegionTypeIsolation, conf, extraConf, isNumaBinding, false, metaReader, metaServer, emitter),
+		QoSRegionBase: NewQoSRegionBase(regionName, ownerPoolName, configapi.QoSRegionTypeIsolation, conf, extraConf, isNumaBinding, false, metaCache, metaServer, emitter),
 	}
 	if isNumaBinding {
 		r.bindingNumas = machine.NewCPUSet(numaID)
@@ -45,9 +45,9 @@ type QoSRegionShare struct {
 
 // NewQoSRegionShare returns a region instance for shared pool
 func NewQoSRegionShare(ci *types.ContainerInfo, conf *config.Configuration, extraConf interface{}, numaID int,
-	metaReader metacache.MetaReader, metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter,
+	metaCache metacache.MetaCache, metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter,
 ) QoSRegion {
-	regionName := getRegionNameFromMetaCache(ci, numaID, metaReader)
+	regionName := getRegionNameFromMetaCache(ci, numaID, metaCache)
 	if regionName == "" {
 		regionName = string(configapi.QoSRegionTypeShare) + types.RegionNameSeparator + string(uuid.NewUUID())
 	}
@@ -59,7 +59,7 @@ func NewQoSRegionShare(ci *types.ContainerInfo, conf *config.Configuration, extr
 	//	When put isolation pods back to share pool, advisor should create a new share region with OriginOwnerPoolName (OriginOwnerPoolName != OwnerPoolName).
 	isNumaBinding := numaID != commonstate.FakedNUMAID
 	r := &QoSRegionShare{
-		QoSRegionBase:    NewQoSRegionBase(regionName, ci.OriginOwnerPoolName, configapi.QoSRegionTypeShare, conf, extraConf, isNumaBinding, false, metaReader, metaServer, emitter),
+		QoSRegionBase:    NewQoSRegionBase(regionName, ci.OriginOwnerPoolName, configapi.QoSRegionTypeShare, conf, extraConf, isNumaBinding, false, metaCache, metaServer, emitter),
 		configTranslator: general.NewCommonSuffixTranslator(commonstate.NUMAPoolInfix),
 	}
 
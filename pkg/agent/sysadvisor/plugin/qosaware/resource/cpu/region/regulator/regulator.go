@@ -74,6 +74,16 @@ type CPURegulator struct {
 
 	// latestRampDownTime is the latest ramp down timestamp
 	latestRampDownTime time.Time
+
+	// latestChanges records the directions of cpu requirement changes in oscillation window
+	latestChanges []requirementChange
+	// dampingUntil is the deadline of damping triggered by the latest oscillation
+	dampingUntil time.Time
+}
+
+type requirementChange struct {
+	direction int
+	timestamp time.Time
 }
 
 type RegulatorOptions struct {
@@ -89,6 +99,22 @@ type RegulatorOptions struct {
 
 	// MinRampDownPeriod is the min time gap between two consecutive cpu requirement ramp down
 	MinRampDownPeriod time.Duration
+
+	// OscillationWindow is the time window to detect oscillation of cpu requirement
+	OscillationWindow time.Duration
+
+	// OscillationThreshold is the min number of direction reversals of cpu requirement changes
+	// in window regarded as oscillation, and non-positive value disables damping
+	OscillationThreshold int
+
+	// DampingDeadband is the max cpu cores of requirement changes to be ignored during damping
+	DampingDeadband int
+
+	// DampingDuration is the time to keep damping after oscillation is detected
+	DampingDuration time.Duration
+
+	// OnDamping is called when damping is triggered with the number of reversals detected
+	OnDamping func(reversals int)
 }
 
 // NewCPURegulator returns a cpu regulator instance with immutable parameters
@@ -112,22 +138,91 @@ func (c *CPURegulator) Regulate(controlKnob types.ControlKnobItem, effectiveCont
 	cpuRequirement := controlKnob.Value
 	cpuRequirementReserved := cpuRequirement + c.ReservedForAllocate
 	cpuRequirementRound := c.round(cpuRequirementReserved)
-	cpuRequirementSlowdown := c.slowdown(cpuRequirementRound, effectiveControlKnob)
+	cpuRequirementDamped := c.dampen(cpuRequirementRound, effectiveControlKnob)
+	cpuRequirementSlowdown := c.slowdown(cpuRequirementDamped, effectiveControlKnob)
 	cpuRequirementClamp := c.clamp(cpuRequirementSlowdown)
 
-	klog.Infof("[qosaware-cpu] cpu requirement by policy: %.2f, with reserve: %.2f, after round: %d, after slowdown: %d, after clamp: %d",
-		cpuRequirement, cpuRequirementReserved, cpuRequirementRound, cpuRequirementSlowdown, cpuRequirementClamp)
+	klog.Infof("[qosaware-cpu] cpu requirement by policy: %.2f, with reserve: %.2f, after round: %d, after damping: %d, after slowdown: %d, after clamp: %d",
+		cpuRequirement, cpuRequirementReserved, cpuRequirementRound, cpuRequirementDamped, cpuRequirementSlowdown, cpuRequirementClamp)
 
 	c.updateControlKnob(float64(cpuRequirementClamp))
 }
 
 func (c *CPURegulator) updateControlKnob(value float64) {
 	if int(value) != int(c.latestControlKnobItem.Value) {
+		now := time.Now()
+		direction := 1
 		if value < c.latestControlKnobItem.Value {
-			c.latestRampDownTime = time.Now()
+			c.latestRampDownTime = now
+			direction = -1
 		}
 		c.latestControlKnobItem.Value = value
+		c.detectOscillation(direction, now)
+	}
+}
+
+// detectOscillation records the direction of the latest cpu requirement change, and
+// starts damping if the direction keeps reversing in oscillation window
+func (c *CPURegulator) detectOscillation(direction int, now time.Time) {
+	if c.OscillationThreshold <= 0 {
+		return
+	}
+
+	idx := 0
+	for idx < len(c.latestChanges) && now.Sub(c.latestChanges[idx].timestamp) > c.OscillationWindow {
+		idx++
+	}
+	c.latestChanges = append(c.latestChanges[idx:], requirementChange{direction: direction, timestamp: now})
+
+	reversals := 0
+	for i := 1; i < len(c.latestChanges); i++ {
+		if c.latestChanges[i].direction != c.latestChanges[i-1].direction {
+			reversals++
+		}
+	}
+	if reversals < c.OscillationThreshold {
+		return
+	}
+
+	klog.Infof("[qosaware-cpu] cpu requirement oscillation detected with %d reversals in %v, damping until %v",
+		reversals, c.OscillationWindow, now.Add(c.DampingDuration))
+	c.dampingUntil = now.Add(c.DampingDuration)
+	c.latestChanges = nil
+	if c.OnDamping != nil {
+		c.OnDamping(reversals)
+	}
+}
+
+func (c *CPURegulator) isDamping() bool {
+	return time.Now().Before(c.dampingUntil)
+}
+
+// dampen ignores small cpu requirement changes during damping to stop pool flapping
+func (c *CPURegulator) dampen(cpuRequirement int, effectiveControlKnobItem *types.ControlKnobItem) int {
+	if effectiveControlKnobItem == nil || !c.isDamping() {
+		return cpuRequirement
+	}
+
+	effective := int(effectiveControlKnobItem.Value)
+	if diff := cpuRequirement - effective; diff >= -c.DampingDeadband && diff <= c.DampingDeadband {
+		return effective
+	}
+	return cpuRequirement
+}
+
+// maxRampSteps returns the max ramp up and down steps, which are halved during damping
+func (c *CPURegulator) maxRampSteps() (int, int) {
+	if !c.isDamping() {
+		return c.MaxRampUpStep, c.MaxRampDownStep
+	}
+	return halveStep(c.MaxRampUpStep), halveStep(c.MaxRampDownStep)
+}
+
+func halveStep(step int) int {
+	if step <= 1 {
+		return step
 	}
+	return step / 2
 }
 
 // GetRequirement returns the latest regulated cpu requirement
@@ -149,10 +244,11 @@ func (c *CPURegulator) slowdown(cpuRequirement int, effectiveControlKnobItem *ty
 	}
 
 	// Restrict ramp up and down step
-	if cpuRequirement-int(effectiveControlKnobItem.Value) > c.MaxRampUpStep {
-		cpuRequirement = int(effectiveControlKnobItem.Value) + c.MaxRampUpStep
-	} else if int(effectiveControlKnobItem.Value)-cpuRequirement > c.MaxRampDownStep {
-		cpuRequirement = int(effectiveControlKnobItem.Value) - c.MaxRampDownStep
+	maxRampUpStep, maxRampDownStep := c.maxRampSteps()
+	if cpuRequirement-int(effectiveControlKnobItem.Value) > maxRampUpStep {
+		cpuRequirement = int(effectiveControlKnobItem.Value) + maxRampUpStep
+	} else if int(effectiveControlKnobItem.Value)-cpuRequirement > maxRampDownStep {
+		cpuRequirement = int(effectiveControlKnobItem.Value) - maxRampDownStep
 	}
 
 	return cpuRequirement
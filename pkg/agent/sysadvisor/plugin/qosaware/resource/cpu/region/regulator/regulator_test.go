@@ -0,0 +1,67 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package regulator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+)
+
+func TestCPURegulatorDamping(t *testing.T) {
+	t.Parallel()
+
+	dampingReversals := 0
+	c := NewCPURegulator(types.ResourceEssentials{ResourceUpperBound: 100}, RegulatorOptions{
+		MaxRampUpStep:        8,
+		MaxRampDownStep:      8,
+		NeedHTAligned:        func() bool { return false },
+		OscillationWindow:    time.Minute,
+		OscillationThreshold: 3,
+		DampingDeadband:      2,
+		DampingDuration:      time.Minute,
+		OnDamping:            func(reversals int) { dampingReversals = reversals },
+	}).(*CPURegulator)
+
+	regulate := func(value float64) int {
+		effective := types.ControlKnobItem{Value: c.latestControlKnobItem.Value}
+		c.Regulate(types.ControlKnobItem{Value: value}, &effective)
+		return c.GetRequirement()
+	}
+
+	c.latestControlKnobItem.Value = 20
+
+	// requirement flaps between 20 and 24 without damping
+	for i, value := range []float64{24, 20, 24, 20} {
+		assert.Equal(t, int(value), regulate(value), "step %v", i)
+	}
+	assert.Equal(t, 3, dampingReversals)
+	assert.True(t, c.isDamping())
+
+	// small changes are ignored during damping
+	assert.Equal(t, 20, regulate(22))
+	// large changes are restricted by halved steps during damping
+	assert.Equal(t, 24, regulate(40))
+
+	// damping stops after damping duration
+	c.dampingUntil = time.Now().Add(-time.Second)
+	assert.Equal(t, 26, regulate(26))
+	assert.Equal(t, 34, regulate(40))
+}
@@ -52,6 +52,11 @@ func (c *PIDController) SetEssentials(resourceEssentials types.ResourceEssential
 	c.resourceEssentials = resourceEssentials
 }
 
+// SetParams updates parameters of the controller, e.g. the gains tuned at runtime
+func (c *PIDController) SetParams(params types.FirstOrderPIDParams) {
+	c.params = params
+}
+
 func (c *PIDController) Adjust(controlKnob, target, current float64, direct bool) float64 {
 	var (
 		kp, kd, kpSign, kdSign float64
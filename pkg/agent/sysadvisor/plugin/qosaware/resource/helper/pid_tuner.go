@@ -0,0 +1,164 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helper
+
+import (
+	"math"
+	"time"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+)
+
+// PIDAutoTuner tunes gains of a first-order pid controller online according to the
+// observed response of indicator. Proportional gains are decreased (and derivative gains
+// increased to damp) if the error keeps flipping its sign, i.e. the pool size oscillates;
+// proportional gains are increased if the error stays on the same side without decreasing,
+// i.e. the control is too sluggish. All gains are kept as scales of the configured ones
+// and restricted in [MinGainScale, MaxGainScale].
+type PIDAutoTuner struct {
+	variableName string
+	params       types.PIDAutoTuningParams
+	info         types.PIDTuningInfo
+
+	errorSigns    []int
+	errorAbsPrev  float64
+	sluggishSteps int
+	cooldownSteps int
+}
+
+// NewPIDAutoTuner returns a PIDAutoTuner starting from the given tuning info,
+// and the configured gains will be used as-is if info is nil.
+func NewPIDAutoTuner(variableName string, params types.PIDAutoTuningParams, info *types.PIDTuningInfo) *PIDAutoTuner {
+	t := &PIDAutoTuner{
+		variableName: variableName,
+		params:       params,
+		info:         types.PIDTuningInfo{KpScale: 1, KdScale: 1},
+	}
+	if info != nil {
+		t.info = *info
+		t.info.KpScale = t.clampScale(t.info.KpScale)
+		t.info.KdScale = t.clampScale(t.info.KdScale)
+	}
+	return t
+}
+
+// GetTuningInfo returns a copy of current tuning info
+func (t *PIDAutoTuner) GetTuningInfo() *types.PIDTuningInfo {
+	return t.info.Clone()
+}
+
+// TunedParams applies the tuned gain scales to the given base params
+func (t *PIDAutoTuner) TunedParams(base types.FirstOrderPIDParams) types.FirstOrderPIDParams {
+	tuned := base
+	tuned.Kpp *= t.info.KpScale
+	tuned.Kpn *= t.info.KpScale
+	tuned.Kdp *= t.info.KdScale
+	tuned.Kdn *= t.info.KdScale
+	return tuned
+}
+
+// Observe feeds the latest indicator values into tuner, and returns true if gains are tuned
+func (t *PIDAutoTuner) Observe(base types.FirstOrderPIDParams, target, current float64) bool {
+	if target <= 0 || current <= 0 {
+		return false
+	}
+
+	// errors in deadband are regarded as zero, the same as pid controller
+	errorRaw := current - target
+	errorSign := 0
+	if errorRaw > 0 && errorRaw/target > base.DeadbandUpperPct {
+		errorSign = 1
+	} else if errorRaw < 0 && errorRaw/target < -base.DeadbandLowerPct {
+		errorSign = -1
+	}
+	errorAbs := math.Abs(math.Log(current) - math.Log(target))
+
+	lastSign := t.lastNonZeroSign()
+	if errorSign != 0 && errorSign == lastSign && errorAbs >= t.errorAbsPrev {
+		t.sluggishSteps++
+	} else {
+		t.sluggishSteps = 0
+	}
+	t.errorAbsPrev = errorAbs
+
+	t.errorSigns = append(t.errorSigns, errorSign)
+	if len(t.errorSigns) > t.params.Window {
+		t.errorSigns = t.errorSigns[len(t.errorSigns)-t.params.Window:]
+	}
+
+	if t.cooldownSteps > 0 {
+		t.cooldownSteps--
+		return false
+	}
+
+	ratio := 1 + t.params.GainAdjustRatio
+	kpScale, kdScale := t.info.KpScale, t.info.KdScale
+	if t.countSignFlips() >= t.params.OscillationThreshold {
+		kpScale, kdScale = t.clampScale(kpScale/ratio), t.clampScale(kdScale*ratio)
+		general.InfoS("[qosaware-cpu-pid-tuner] oscillation detected", "indicator", t.variableName,
+			"errorSigns", t.errorSigns, "kpScale", kpScale, "kdScale", kdScale)
+	} else if t.params.SluggishSteps > 0 && t.sluggishSteps >= t.params.SluggishSteps {
+		kpScale = t.clampScale(kpScale * ratio)
+		general.InfoS("[qosaware-cpu-pid-tuner] sluggish control detected", "indicator", t.variableName,
+			"sluggishSteps", t.sluggishSteps, "kpScale", kpScale, "kdScale", kdScale)
+	} else {
+		return false
+	}
+
+	// start over observing with new gains
+	t.errorSigns = nil
+	t.sluggishSteps = 0
+	t.cooldownSteps = t.params.CooldownSteps
+
+	if kpScale == t.info.KpScale && kdScale == t.info.KdScale {
+		return false
+	}
+	t.info = types.PIDTuningInfo{KpScale: kpScale, KdScale: kdScale, UpdateTime: time.Now().Unix()}
+	return true
+}
+
+func (t *PIDAutoTuner) lastNonZeroSign() int {
+	for i := len(t.errorSigns) - 1; i >= 0; i-- {
+		if t.errorSigns[i] != 0 {
+			return t.errorSigns[i]
+		}
+	}
+	return 0
+}
+
+// countSignFlips counts the sign flips of out-of-deadband errors in window
+func (t *PIDAutoTuner) countSignFlips() int {
+	flips, lastSign := 0, 0
+	for _, sign := range t.errorSigns {
+		if sign == 0 {
+			continue
+		}
+		if lastSign != 0 && sign != lastSign {
+			flips++
+		}
+		lastSign = sign
+	}
+	return flips
+}
+
+func (t *PIDAutoTuner) clampScale(scale float64) float64 {
+	if scale <= 0 {
+		scale = 1
+	}
+	return general.Clamp(scale, t.params.MinGainScale, t.params.MaxGainScale)
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+)
+
+func TestPIDAutoTuner(t *testing.T) {
+	t.Parallel()
+
+	tuningParams := types.PIDAutoTuningParams{
+		Window:               6,
+		OscillationThreshold: 3,
+		SluggishSteps:        3,
+		CooldownSteps:        2,
+		GainAdjustRatio:      0.25,
+		MinGainScale:         0.5,
+		MaxGainScale:         2,
+	}
+	base := types.FirstOrderPIDParams{Kpp: 0.1, Kpn: 0.04, Kdp: 0.01, Kdn: 0.01, DeadbandUpperPct: 0.05, DeadbandLowerPct: 0.05}
+
+	for _, tc := range []struct {
+		comment         string
+		info            *types.PIDTuningInfo
+		currents        []float64
+		expectedKpScale float64
+		expectedKdScale float64
+	}{
+		{
+			comment:         "stable indicator should not be tuned",
+			currents:        []float64{100, 101, 99, 100, 102, 98},
+			expectedKpScale: 1,
+			expectedKdScale: 1,
+		},
+		{
+			comment:         "oscillating indicator should decrease kp and increase kd",
+			currents:        []float64{120, 80, 120, 80},
+			expectedKpScale: 0.8,
+			expectedKdScale: 1.25,
+		},
+		{
+			comment:         "sluggish indicator should increase kp",
+			currents:        []float64{120, 120, 121, 122},
+			expectedKpScale: 1.25,
+			expectedKdScale: 1,
+		},
+		{
+			comment:         "gains should be tuned again only after cooldown",
+			currents:        []float64{120, 120, 121, 122, 123, 124, 125, 126},
+			expectedKpScale: 1.5625,
+			expectedKdScale: 1,
+		},
+		{
+			comment:         "restored gains should be restricted by guard rails",
+			info:            &types.PIDTuningInfo{KpScale: 1.9, KdScale: 0.1},
+			currents:        []float64{120, 120, 121, 122},
+			expectedKpScale: 2,
+			expectedKdScale: 0.5,
+		},
+	} {
+		tuner := NewPIDAutoTuner("test", tuningParams, tc.info)
+		for _, current := range tc.currents {
+			tuner.Observe(base, 100, current)
+		}
+
+		info := tuner.GetTuningInfo()
+		assert.InDelta(t, tc.expectedKpScale, info.KpScale, 1e-6, tc.comment)
+		assert.InDelta(t, tc.expectedKdScale, info.KdScale, 1e-6, tc.comment)
+
+		tuned := tuner.TunedParams(base)
+		assert.InDelta(t, base.Kpp*tc.expectedKpScale, tuned.Kpp, 1e-6, tc.comment)
+		assert.InDelta(t, base.Kdn*tc.expectedKdScale, tuned.Kdn, 1e-6, tc.comment)
+		assert.Equal(t, base.DeadbandUpperPct, tuned.DeadbandUpperPct, tc.comment)
+	}
+}
@@ -0,0 +1,199 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helper
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+)
+
+const hoursPerWeek = 7 * 24
+
+// UsageProfilePersistPeriod is the minimal period for policies to persist usage profiles
+// into metacache, since profiles only change slowly and are much larger than other states.
+const UsageProfilePersistPeriod = 5 * time.Minute
+
+// HourOfWeekUsageProfile keeps resource usage samples in buckets of hour-of-week, so that
+// percentile usage of a certain hour can be referred to predict the usage of the same hour
+// in the following weeks, i.e. the diurnal and weekly patterns of online workloads.
+//
+// Samples are downsampled before being kept, i.e. samples in the same downsample period are
+// merged into their max, so that each bucket covers several weeks with only a few samples.
+type HourOfWeekUsageProfile struct {
+	mutex               sync.RWMutex
+	maxSamplesPerBucket int
+	downsamplePeriod    time.Duration
+	buckets             [hoursPerWeek]*usageRing
+}
+
+// usageRing holds the latest downsampled usage samples in a ring buffer
+type usageRing struct {
+	samples []types.UsageSample
+	next    int
+}
+
+// latest returns the latest sample in the ring
+func (r *usageRing) latest() *types.UsageSample {
+	if len(r.samples) == 0 {
+		return nil
+	}
+	return &r.samples[(r.next+len(r.samples)-1)%len(r.samples)]
+}
+
+// NewHourOfWeekUsageProfile returns a profile keeping at most maxSamplesPerBucket downsampled
+// samples for each hour-of-week, and non-positive downsamplePeriod keeps all samples as they are.
+func NewHourOfWeekUsageProfile(maxSamplesPerBucket int, downsamplePeriod time.Duration) *HourOfWeekUsageProfile {
+	if maxSamplesPerBucket <= 0 {
+		maxSamplesPerBucket = 1
+	}
+	return &HourOfWeekUsageProfile{maxSamplesPerBucket: maxSamplesPerBucket, downsamplePeriod: downsamplePeriod}
+}
+
+// AddSample records usage sample at the given time
+func (p *HourOfWeekUsageProfile) AddSample(t time.Time, value float64) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	idx := hourOfWeek(t)
+	ring := p.buckets[idx]
+	if ring == nil {
+		ring = &usageRing{}
+		p.buckets[idx] = ring
+	}
+
+	sample := types.UsageSample{Time: t.Unix(), Value: value}
+	if p.downsamplePeriod > 0 {
+		sample.Time = t.Truncate(p.downsamplePeriod).Unix()
+		if latest := ring.latest(); latest != nil && latest.Time == sample.Time {
+			latest.Value = math.Max(latest.Value, value)
+			return
+		}
+	}
+
+	if len(ring.samples) < p.maxSamplesPerBucket {
+		ring.samples = append(ring.samples, sample)
+		ring.next = len(ring.samples) % p.maxSamplesPerBucket
+		return
+	}
+	ring.samples[ring.next] = sample
+	ring.next = (ring.next + 1) % p.maxSamplesPerBucket
+}
+
+// Percentile returns the percentile usage of the hour-of-week the given time belongs to,
+// and false is returned if the bucket doesn't have enough samples yet.
+func (p *HourOfWeekUsageProfile) Percentile(t time.Time, percentile float64, minSamples int) (float64, bool) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.percentile(hourOfWeek(t), percentile, minSamples)
+}
+
+// PeakPercentile returns the max percentile usage among hours-of-week in [t, t+lookahead],
+// so that estimation based on it can prepare for the known peaks ahead.
+func (p *HourOfWeekUsageProfile) PeakPercentile(t time.Time, lookahead time.Duration, percentile float64, minSamples int) (float64, bool) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	peak, found := 0.0, false
+	hours := int(lookahead / time.Hour)
+	if hours >= hoursPerWeek {
+		hours = hoursPerWeek - 1
+	}
+	for i := 0; i <= hours; i++ {
+		value, ok := p.percentile((hourOfWeek(t)+i)%hoursPerWeek, percentile, minSamples)
+		if !ok {
+			continue
+		}
+		peak = math.Max(peak, value)
+		found = true
+	}
+	return peak, found
+}
+
+// Snapshot returns the samples of all buckets, ordered from the oldest to the latest
+func (p *HourOfWeekUsageProfile) Snapshot(now time.Time) *types.UsageProfile {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	profile := &types.UsageProfile{
+		Buckets:    make([][]types.UsageSample, hoursPerWeek),
+		UpdateTime: now.UnixNano(),
+	}
+	for idx, ring := range p.buckets {
+		if ring == nil {
+			continue
+		}
+		samples := make([]types.UsageSample, 0, len(ring.samples))
+		samples = append(samples, ring.samples[ring.next:]...)
+		samples = append(samples, ring.samples[:ring.next]...)
+		profile.Buckets[idx] = samples
+	}
+	return profile
+}
+
+// Restore overwrites all buckets with the given snapshot, and only the latest samples are
+// kept if the snapshot holds more samples than the bucket capacity.
+func (p *HourOfWeekUsageProfile) Restore(profile *types.UsageProfile) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.buckets = [hoursPerWeek]*usageRing{}
+	if profile == nil {
+		return
+	}
+	for idx, samples := range profile.Buckets {
+		if idx >= hoursPerWeek || len(samples) == 0 {
+			continue
+		}
+		if len(samples) > p.maxSamplesPerBucket {
+			samples = samples[len(samples)-p.maxSamplesPerBucket:]
+		}
+		p.buckets[idx] = &usageRing{
+			samples: append([]types.UsageSample(nil), samples...),
+			next:    len(samples) % p.maxSamplesPerBucket,
+		}
+	}
+}
+
+func (p *HourOfWeekUsageProfile) percentile(idx int, percentile float64, minSamples int) (float64, bool) {
+	ring := p.buckets[idx]
+	if ring == nil || len(ring.samples) == 0 || len(ring.samples) < minSamples {
+		return 0, false
+	}
+
+	sorted := make([]float64, 0, len(ring.samples))
+	for _, sample := range ring.samples {
+		sorted = append(sorted, sample.Value)
+	}
+	sort.Float64s(sorted)
+
+	rank := int(math.Ceil(percentile*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	} else if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank], true
+}
+
+func hourOfWeek(t time.Time) int {
+	return int(t.Weekday())*24 + t.Hour()
+}
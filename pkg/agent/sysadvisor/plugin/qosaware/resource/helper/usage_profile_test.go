@@ -0,0 +1,134 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+)
+
+func sampleValues(samples []types.UsageSample) []float64 {
+	if samples == nil {
+		return nil
+	}
+	values := make([]float64, 0, len(samples))
+	for _, sample := range samples {
+		values = append(values, sample.Value)
+	}
+	return values
+}
+
+func TestHourOfWeekUsageProfile(t *testing.T) {
+	t.Parallel()
+
+	// 2024-01-01 is Monday
+	monday := time.Date(2024, 1, 1, 10, 0, 0, 0, time.Local)
+	profile := NewHourOfWeekUsageProfile(10, 10*time.Minute)
+
+	// bucket without enough samples should be ignored
+	profile.AddSample(monday, 5)
+	_, ok := profile.Percentile(monday, 0.9, 2)
+	assert.False(t, ok)
+
+	// samples of the same hour in different weeks fall into the same bucket
+	for i := 1; i <= 10; i++ {
+		profile.AddSample(monday.AddDate(0, 0, 7*i).Add(time.Duration(i)*time.Minute), float64(i))
+	}
+	value, ok := profile.Percentile(monday, 0.9, 2)
+	assert.True(t, ok)
+	assert.Equal(t, 9.0, value)
+
+	// the oldest samples are dropped when bucket is full
+	profile.AddSample(monday, 100)
+	value, ok = profile.Percentile(monday.Add(30*time.Minute), 1, 2)
+	assert.True(t, ok)
+	assert.Equal(t, 100.0, value)
+
+	// peaks in lookahead window are considered
+	peakHour := monday.Add(2 * time.Hour)
+	for i := 0; i < 5; i++ {
+		profile.AddSample(peakHour.AddDate(0, 0, 7*i), 200)
+	}
+	value, ok = profile.PeakPercentile(monday, time.Hour, 0.9, 2)
+	assert.True(t, ok)
+	assert.Equal(t, 10.0, value)
+	value, ok = profile.PeakPercentile(monday, 2*time.Hour, 0.9, 2)
+	assert.True(t, ok)
+	assert.Equal(t, 200.0, value)
+
+	_, ok = profile.PeakPercentile(monday.Add(-3*time.Hour), time.Hour, 0.9, 2)
+	assert.False(t, ok)
+}
+
+func TestHourOfWeekUsageProfile_SnapshotAndRestore(t *testing.T) {
+	t.Parallel()
+
+	monday := time.Date(2024, 1, 1, 10, 0, 0, 0, time.Local)
+	profile := NewHourOfWeekUsageProfile(3, 10*time.Minute)
+	for i := 1; i <= 4; i++ {
+		profile.AddSample(monday.AddDate(0, 0, 7*i), float64(i))
+	}
+	profile.AddSample(monday.Add(time.Hour), 10)
+
+	snapshot := profile.Snapshot(monday)
+	assert.Equal(t, []float64{2, 3, 4}, sampleValues(snapshot.Buckets[hourOfWeek(monday)]))
+	assert.Equal(t, []float64{10}, sampleValues(snapshot.Buckets[hourOfWeek(monday.Add(time.Hour))]))
+	assert.Nil(t, snapshot.Buckets[hourOfWeek(monday.Add(2*time.Hour))])
+
+	// only the latest samples are kept if the restored profile has smaller buckets,
+	// and the oldest restored sample is replaced first
+	restored := NewHourOfWeekUsageProfile(2, 10*time.Minute)
+	restored.Restore(snapshot)
+	assert.Equal(t, []float64{3, 4}, sampleValues(restored.Snapshot(monday).Buckets[hourOfWeek(monday)]))
+	restored.AddSample(monday.AddDate(0, 0, 35), 5)
+	assert.Equal(t, []float64{4, 5}, sampleValues(restored.Snapshot(monday).Buckets[hourOfWeek(monday)]))
+
+	value, ok := restored.Percentile(monday.Add(time.Hour), 1, 1)
+	assert.True(t, ok)
+	assert.Equal(t, 10.0, value)
+}
+
+func TestHourOfWeekUsageProfile_Downsample(t *testing.T) {
+	t.Parallel()
+
+	monday := time.Date(2024, 1, 1, 10, 0, 0, 0, time.Local)
+	profile := NewHourOfWeekUsageProfile(12, 10*time.Minute)
+
+	// samples in the same downsample period are merged into their max
+	for week := 0; week < 4; week++ {
+		for i := 0; i < 60; i++ {
+			profile.AddSample(monday.AddDate(0, 0, 7*week).Add(time.Duration(i)*time.Minute), float64(week*100+i))
+		}
+	}
+
+	// each bucket only keeps the latest 12 downsampled samples, i.e. the latest 2 weeks
+	samples := profile.Snapshot(monday).Buckets[hourOfWeek(monday)]
+	assert.Equal(t, []float64{209, 219, 229, 239, 249, 259, 309, 319, 329, 339, 349, 359}, sampleValues(samples))
+	assert.Equal(t, monday.AddDate(0, 0, 14).Unix(), samples[0].Time)
+	assert.Equal(t, monday.AddDate(0, 0, 21).Add(50*time.Minute).Unix(), samples[11].Time)
+
+	// non-positive downsample period keeps all samples as they are
+	raw := NewHourOfWeekUsageProfile(12, 0)
+	for i := 0; i < 3; i++ {
+		raw.AddSample(monday, float64(i))
+	}
+	assert.Equal(t, []float64{0, 1, 2}, sampleValues(raw.Snapshot(monday).Buckets[hourOfWeek(monday)]))
+}
@@ -46,7 +46,7 @@ type HeadroomPolicy interface {
 	GetHeadroom() (resource.Quantity, map[int]resource.Quantity, error)
 }
 
-type InitFunc func(conf *config.Configuration, extraConfig interface{}, metaReader metacache.MetaReader,
+type InitFunc func(conf *config.Configuration, extraConfig interface{}, metaCache metacache.MetaCache,
 	metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter) HeadroomPolicy
 
 var initializers sync.Map
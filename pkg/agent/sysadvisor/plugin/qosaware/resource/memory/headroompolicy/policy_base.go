@@ -27,14 +27,16 @@ type PolicyBase struct {
 	essentials types.ResourceEssentials
 
 	metaReader metacache.MetaReader
+	metaWriter metacache.MetaWriter
 	metaServer *metaserver.MetaServer
 }
 
-func NewPolicyBase(metaReader metacache.MetaReader, metaServer *metaserver.MetaServer) *PolicyBase {
+func NewPolicyBase(metaCache metacache.MetaCache, metaServer *metaserver.MetaServer) *PolicyBase {
 	cp := &PolicyBase{
 		podSet: make(types.PodSet),
 
-		metaReader: metaReader,
+		metaReader: metaCache,
+		metaWriter: metaCache,
 		metaServer: metaServer,
 	}
 	return cp
@@ -44,15 +44,17 @@ type PolicyCanonical struct {
 	memoryHeadroom     float64
 	numaMemoryHeadroom map[int]resource.Quantity
 	updateStatus       types.PolicyUpdateStatus
+	// memoryRequirement is the latest memory requirement estimation of non-reclaimed containers
+	memoryRequirement float64
 
 	conf *config.Configuration
 }
 
-func NewPolicyCanonical(conf *config.Configuration, _ interface{}, metaReader metacache.MetaReader,
+func NewPolicyCanonical(conf *config.Configuration, _ interface{}, metaCache metacache.MetaCache,
 	metaServer *metaserver.MetaServer, _ metrics.MetricEmitter,
 ) HeadroomPolicy {
 	p := PolicyCanonical{
-		PolicyBase:         NewPolicyBase(metaReader, metaServer),
+		PolicyBase:         NewPolicyBase(metaCache, metaServer),
 		numaMemoryHeadroom: make(map[int]resource.Quantity),
 		updateStatus:       types.PolicyUpdateFailed,
 		conf:               conf,
@@ -150,6 +152,7 @@ func (p *PolicyCanonical) Update() (err error) {
 	if err != nil {
 		return err
 	}
+	p.memoryRequirement = memoryEstimateRequirement
 	memoryHeadroomWithoutBuffer := math.Max(maxAllocatableMemory-memoryEstimateRequirement, 0)
 
 	if dynamicConfig.MemoryUtilBasedConfiguration.Enable {
@@ -0,0 +1,142 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package headroompolicy
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/metacache"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/helper"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/config"
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/sysadvisor/qosaware/resource/memory/headroom"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+)
+
+const (
+	metricMemoryHeadroomHistoricalPeak = "memory_headroom_historical_peak"
+
+	memoryUsageProfileKey = "memory"
+)
+
+// PolicyHistorical estimates memory headroom the same as canonical policy, and then shrinks
+// it according to the hour-of-week percentile requirement profile of non-reclaimed containers,
+// so that headroom follows the diurnal patterns and shrinks ahead of known peaks. The profile
+// is persisted through metacache to survive restarting.
+type PolicyHistorical struct {
+	*PolicyCanonical
+
+	historicalConf *headroom.MemoryPolicyHistoricalConfiguration
+	profile        *helper.HourOfWeekUsageProfile
+	emitter        metrics.MetricEmitter
+	now            func() time.Time
+
+	profileRestored    bool
+	profilePersistTime time.Time
+}
+
+func NewPolicyHistorical(conf *config.Configuration, extraConf interface{}, metaCache metacache.MetaCache,
+	metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter,
+) HeadroomPolicy {
+	p := &PolicyHistorical{
+		PolicyCanonical: NewPolicyCanonical(conf, extraConf, metaCache, metaServer, emitter).(*PolicyCanonical),
+		historicalConf:  conf.MemoryPolicyHistorical,
+		profile:         helper.NewHourOfWeekUsageProfile(conf.MemoryPolicyHistorical.MaxSamplesPerBucket, conf.MemoryPolicyHistorical.DownsamplePeriod),
+		emitter:         emitter,
+		now:             time.Now,
+	}
+	return p
+}
+
+func (p *PolicyHistorical) Name() types.MemoryHeadroomPolicyName {
+	return types.MemoryHeadroomPolicyHistorical
+}
+
+func (p *PolicyHistorical) Update() error {
+	if err := p.PolicyCanonical.Update(); err != nil {
+		return err
+	}
+
+	now := p.now()
+	p.restoreProfile()
+	p.profile.AddSample(now, p.memoryRequirement)
+	p.persistProfile(now)
+
+	peak, ok := p.profile.PeakPercentile(now, p.historicalConf.Lookahead, p.historicalConf.Percentile,
+		p.historicalConf.MinSamplesPerBucket)
+	if !ok {
+		return nil
+	}
+	_ = p.emitter.StoreFloat64(metricMemoryHeadroomHistoricalPeak, peak, metrics.MetricTypeNameRaw,
+		metrics.MetricTag{Key: "policy_name", Val: string(p.Name())},
+		metrics.MetricTag{Key: "percentile", Val: strconv.FormatFloat(p.historicalConf.Percentile, 'f', -1, 64)},
+		metrics.MetricTag{Key: "lookahead", Val: p.historicalConf.Lookahead.String()})
+
+	if peak <= p.memoryRequirement || p.memoryHeadroom <= 0 {
+		return nil
+	}
+
+	// reserve the gap between historical peak and current requirement, and numa headroom
+	// is shrunk in proportion to keep consistent with the total one
+	memoryHeadroom := math.Max(p.memoryHeadroom-(peak-p.memoryRequirement), 0)
+	ratio := memoryHeadroom / p.memoryHeadroom
+	numaHeadroom := make(map[int]resource.Quantity, len(p.numaMemoryHeadroom))
+	for numaID, quantity := range p.numaMemoryHeadroom {
+		numaHeadroom[numaID] = *resource.NewQuantity(int64(float64(quantity.Value())*ratio), resource.BinarySI)
+	}
+
+	general.InfoS("memory headroom shrunk by historical peak",
+		"requirement", general.FormatMemoryQuantity(p.memoryRequirement),
+		"historical peak", general.FormatMemoryQuantity(peak),
+		"original headroom", general.FormatMemoryQuantity(p.memoryHeadroom),
+		"final headroom", general.FormatMemoryQuantity(memoryHeadroom))
+
+	p.memoryHeadroom = memoryHeadroom
+	p.numaMemoryHeadroom = numaHeadroom
+	return nil
+}
+
+// restoreProfile resumes the profile from metacache before the first sample is added
+func (p *PolicyHistorical) restoreProfile() {
+	if p.profileRestored {
+		return
+	}
+	p.profileRestored = true
+
+	if profile, ok := p.metaReader.GetUsageProfile(memoryUsageProfileKey); ok {
+		p.profile.Restore(profile)
+	}
+}
+
+// persistProfile stores the profile into metacache periodically
+func (p *PolicyHistorical) persistProfile(now time.Time) {
+	if now.Sub(p.profilePersistTime) < helper.UsageProfilePersistPeriod {
+		return
+	}
+
+	if err := p.metaWriter.SetUsageProfile(memoryUsageProfileKey, p.profile.Snapshot(now)); err != nil {
+		general.Errorf("failed to persist memory usage profile: %v", err)
+		return
+	}
+	p.profilePersistTime = now
+}
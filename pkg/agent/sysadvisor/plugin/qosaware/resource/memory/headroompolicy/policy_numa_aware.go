@@ -49,11 +49,11 @@ type PolicyNUMAAware struct {
 	numaBindingReclaimRelativeRootCgroupPaths map[int]string
 }
 
-func NewPolicyNUMAAware(conf *config.Configuration, _ interface{}, metaReader metacache.MetaReader,
+func NewPolicyNUMAAware(conf *config.Configuration, _ interface{}, metaCache metacache.MetaCache,
 	metaServer *metaserver.MetaServer, _ metrics.MetricEmitter,
 ) HeadroomPolicy {
 	p := PolicyNUMAAware{
-		PolicyBase:         NewPolicyBase(metaReader, metaServer),
+		PolicyBase:         NewPolicyBase(metaCache, metaServer),
 		numaMemoryHeadroom: make(map[int]resource.Quantity),
 		updateStatus:       types.PolicyUpdateFailed,
 		conf:               conf,
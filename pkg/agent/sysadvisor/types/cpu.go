@@ -43,6 +43,7 @@ const (
 	CPUProvisionPolicyCanonical    CPUProvisionPolicyName = "canonical"
 	CPUProvisionPolicyRama         CPUProvisionPolicyName = "rama"
 	CPUProvisionPolicyDynamicQuota CPUProvisionPolicyName = "dynamic-quota"
+	CPUProvisionPolicyMPC          CPUProvisionPolicyName = "mpc"
 )
 
 // CPUHeadroomPolicyName defines policy names for cpu advisor headroom estimation
@@ -53,6 +54,7 @@ const (
 	CPUHeadroomPolicyNonReclaim    CPUHeadroomPolicyName = "non-reclaim"
 	CPUHeadroomPolicyCanonical     CPUHeadroomPolicyName = "canonical"
 	CPUHeadroomPolicyNUMADedicated CPUHeadroomPolicyName = "numa-dedicated"
+	CPUHeadroomPolicyHistorical    CPUHeadroomPolicyName = "historical"
 	// CPUHeadroomPolicyNUMAExclusive deprecated, use CPUHeadroomPolicyNUMADedicated instead
 	CPUHeadroomPolicyNUMAExclusive CPUHeadroomPolicyName = "numa-exclusive"
 )
@@ -223,3 +225,30 @@ type FirstOrderPIDParams struct {
 	DeadbandUpperPct     float64
 	DeadbandLowerPct     float64
 }
+
+// PIDAutoTuningParams holds guard rails for auto-tuning pid gains in rama policy
+type PIDAutoTuningParams struct {
+	// Window is the number of latest control steps used to detect oscillation
+	Window int
+	// OscillationThreshold is the number of error sign flips in window regarded as oscillation
+	OscillationThreshold int
+	// SluggishSteps is the number of consecutive steps without error decrease regarded as sluggish
+	SluggishSteps int
+	// CooldownSteps is the number of steps to wait before gains can be tuned again
+	CooldownSteps int
+	// GainAdjustRatio is the relative change of gains in each tuning
+	GainAdjustRatio float64
+	// MinGainScale and MaxGainScale restrict tuned gains relative to configured ones
+	MinGainScale float64
+	MaxGainScale float64
+}
+
+// PIDTuningEntries holds auto-tuned pid gains keyed by region key and indicator name
+type PIDTuningEntries map[string]map[string]*PIDTuningInfo
+
+// PIDTuningInfo holds auto-tuned gains of pid controller as scales of configured ones
+type PIDTuningInfo struct {
+	KpScale    float64 `json:"kp_scale"`
+	KdScale    float64 `json:"kd_scale"`
+	UpdateTime int64   `json:"update_time"`
+}
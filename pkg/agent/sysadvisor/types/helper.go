@@ -234,6 +234,55 @@ func (ps PodSet) Clone() PodSet {
 	return clone
 }
 
+func (pe PIDTuningEntries) Clone() PIDTuningEntries {
+	if pe == nil {
+		return nil
+	}
+	clone := make(PIDTuningEntries)
+	for regionKey, infos := range pe {
+		clone[regionKey] = make(map[string]*PIDTuningInfo)
+		for indicatorName, info := range infos {
+			clone[regionKey][indicatorName] = info.Clone()
+		}
+	}
+	return clone
+}
+
+func (pi *PIDTuningInfo) Clone() *PIDTuningInfo {
+	if pi == nil {
+		return nil
+	}
+	clone := *pi
+	return &clone
+}
+
+func (ue UsageProfileEntries) Clone() UsageProfileEntries {
+	if ue == nil {
+		return nil
+	}
+	clone := make(UsageProfileEntries)
+	for profileKey, profile := range ue {
+		clone[profileKey] = profile.Clone()
+	}
+	return clone
+}
+
+func (up *UsageProfile) Clone() *UsageProfile {
+	if up == nil {
+		return nil
+	}
+	clone := &UsageProfile{UpdateTime: up.UpdateTime}
+	if up.Buckets != nil {
+		clone.Buckets = make([][]UsageSample, len(up.Buckets))
+		for i, samples := range up.Buckets {
+			if samples != nil {
+				clone.Buckets[i] = append(make([]UsageSample, 0, len(samples)), samples...)
+			}
+		}
+	}
+	return clone
+}
+
 func (hi *HeadroomInfo) Clone() *HeadroomInfo {
 	if hi == nil {
 		return nil
@@ -101,3 +101,19 @@ const (
 type TriggerInfo struct {
 	TimeStamp time.Time
 }
+
+// UsageProfileEntries holds usage profiles keyed by profile key
+type UsageProfileEntries map[string]*UsageProfile
+
+// UsageProfile holds usage samples of each hour-of-week bucket, and samples
+// in each bucket are ordered from the oldest to the latest
+type UsageProfile struct {
+	Buckets    [][]UsageSample `json:"buckets"`
+	UpdateTime int64           `json:"update_time"`
+}
+
+// UsageSample is the max usage in the downsample period starting at Time (unix seconds)
+type UsageSample struct {
+	Time  int64   `json:"time"`
+	Value float64 `json:"value"`
+}
@@ -25,6 +25,21 @@ type EvictionConfiguration struct {
 	// first item for a particular name wins
 	DryRun []string
 
+	// ShadowModePlugins is the list of plugins to run in shadow mode, different from dryrun,
+	// candidates of those plugins will go through candidate selection, rule queueing and
+	// pod killer selection, but they will be recorded by a dry-run killer instead of being killed
+	// '*' means "all in shadow mode"
+	// 'foo' means "shadow mode for 'foo'"
+	// first item for a particular name wins
+	ShadowModePlugins []string
+
+	// EvictionCandidateComparators is the ordered name list of comparators used
+	// to sort eviction candidates, the default chain will be used if it's empty
+	EvictionCandidateComparators []string
+	// EvictionCandidateOwnerKinds is the ordered list of controller kinds whose pods
+	// are preferred to be evicted, it only works with the owner-kind comparator
+	EvictionCandidateOwnerKinds []string
+
 	*CPUPressureEvictionConfiguration
 	*MemoryPressureEvictionConfiguration
 	*RootfsPressureEvictionConfiguration
@@ -45,9 +60,24 @@ func NewEvictionConfiguration() *EvictionConfiguration {
 }
 
 func (c *EvictionConfiguration) ApplyConfiguration(conf *crd.DynamicConfigCRD) {
-	if aqc := conf.AdminQoSConfiguration; aqc != nil && aqc.Spec.Config.EvictionConfig != nil &&
-		len(aqc.Spec.Config.EvictionConfig.DryRun) > 0 {
-		c.DryRun = aqc.Spec.Config.EvictionConfig.DryRun
+	if aqc := conf.AdminQoSConfiguration; aqc != nil && aqc.Spec.Config.EvictionConfig != nil {
+		config := aqc.Spec.Config.EvictionConfig
+		if len(config.DryRun) > 0 {
+			c.DryRun = config.DryRun
+		}
+
+		// an empty but non-nil list turns shadow mode off for all plugins
+		if config.ShadowModePlugins != nil {
+			c.ShadowModePlugins = config.ShadowModePlugins
+		}
+
+		if len(config.CandidateComparators) > 0 {
+			c.EvictionCandidateComparators = config.CandidateComparators
+		}
+
+		if len(config.CandidateOwnerKinds) > 0 {
+			c.EvictionCandidateOwnerKinds = config.CandidateOwnerKinds
+		}
 	}
 
 	c.CPUPressureEvictionConfiguration.ApplyConfiguration(conf)
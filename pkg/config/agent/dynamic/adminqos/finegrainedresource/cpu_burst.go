@@ -42,11 +42,36 @@ type CPUBurstConfiguration struct {
 
 	// DefaultCPUBurstPercent indicates the default cpu burst percent for dedicated cores
 	DefaultCPUBurstPercent int64
+
+	*DynamicCPUBurstConfiguration
+}
+
+// DynamicCPUBurstConfiguration is the configuration for pods with dynamic cpu burst policy, whose
+// cpu burst percent is raised or lowered step by step according to throttling and node pressure.
+// It is only set from command line flags and not overridden by dynamic configuration.
+type DynamicCPUBurstConfiguration struct {
+	// DynamicCPUBurstMinPercent and DynamicCPUBurstMaxPercent are the bounds of cpu burst percent
+	DynamicCPUBurstMinPercent int64
+	DynamicCPUBurstMaxPercent int64
+	// DynamicCPUBurstStepPercent is the cpu burst percent raised or lowered in each round
+	DynamicCPUBurstStepPercent int64
+	// DynamicCPUBurstThrottledRatioThreshold is the ratio of throttled periods (nr_throttled / nr_periods),
+	// above which the cpu burst of the container will be raised
+	DynamicCPUBurstThrottledRatioThreshold float64
+	// DynamicCPUBurstNodeUsageLowWatermark is the node cpu usage ratio, below which cpu burst can be raised
+	DynamicCPUBurstNodeUsageLowWatermark float64
+	// DynamicCPUBurstNodeUsageHighWatermark is the node cpu usage ratio, above which the node is considered
+	// saturated and cpu burst will be lowered
+	DynamicCPUBurstNodeUsageHighWatermark float64
+	// DynamicCPUBurstNodeBudgetRatio caps the total cpu burst of all containers with dynamic policy,
+	// and it is the ratio to the cpu capacity of the node
+	DynamicCPUBurstNodeBudgetRatio float64
 }
 
 func NewCPUBurstConfiguration() *CPUBurstConfiguration {
 	return &CPUBurstConfiguration{
-		DefaultCPUBurstPercent: defaultCPUBurstPercent,
+		DefaultCPUBurstPercent:       defaultCPUBurstPercent,
+		DynamicCPUBurstConfiguration: &DynamicCPUBurstConfiguration{},
 	}
 }
 
@@ -43,12 +43,37 @@ type GenericEvictionConfiguration struct {
 	// EvictionBurst limit the burst eviction counts
 	EvictionBurst int
 
+	// EvictionBudgetWindow is the sliding window to count evictions for eviction budgets,
+	// and eviction budgets are disabled if it's non-positive
+	EvictionBudgetWindow time.Duration
+	// NodeEvictionBudget limits the eviction counts of the whole node in each window
+	NodeEvictionBudget int
+	// QoSEvictionBudgets limits the eviction counts of each QoS level in each window
+	QoSEvictionBudgets map[string]int
+	// OwnerEvictionBudget limits the eviction counts of each workload owner in each window
+	OwnerEvictionBudget int
+
 	// PodKiller specify the pod killer implementation
 	PodKiller string
 
 	// QoSPodKillers specify the pod killer implementation for different QoS levels
 	QoSPodKillers map[string]string
 
+	// FreezeKillerMode specifies how freeze-killer suspends pods, either freezing
+	// pods by cgroup freezer or hard-throttling pods by cpu quota
+	FreezeKillerMode string
+	// FreezeKillerEscalationDuration is the duration a pod can be kept suspended by
+	// freeze-killer, and it will be evicted by escalation killer if pressure persists
+	FreezeKillerEscalationDuration time.Duration
+	// FreezeKillerReleaseTimeout is the duration after which a suspended pod will
+	// be resumed if no more eviction request comes, i.e. pressure has cleared
+	FreezeKillerReleaseTimeout time.Duration
+	// FreezeKillerEscalationKiller specifies the pod killer used by freeze-killer to evict pods
+	FreezeKillerEscalationKiller string
+	// FreezeKillerCheckpointDir is the directory to checkpoint pods suspended by
+	// freeze-killer, and checkpoint is disabled if it's empty
+	FreezeKillerCheckpointDir string
+
 	// StrictAuthentication means whether to authenticate plugins strictly
 	StrictAuthentication bool
 
@@ -73,6 +98,7 @@ func NewGenericEvictionConfiguration() *GenericEvictionConfiguration {
 		EvictionSkippedAnnotationKeys: sets.NewString(),
 		EvictionSkippedLabelKeys:      sets.NewString(),
 		PodMetricLabels:               sets.NewString(),
+		QoSEvictionBudgets:            map[string]int{},
 	}
 }
 
@@ -16,9 +16,33 @@ limitations under the License.
 
 package global
 
+import "time"
+
 type AuditConfiguration struct {
 	Sinks      []string
 	BufferSize int
+
+	// FileSinkPath is the path of the JSON-lines file for file sink, and the
+	// file is rotated according to max size, max backups and max age.
+	FileSinkPath       string
+	FileSinkMaxSizeMB  int
+	FileSinkMaxBackups int
+	FileSinkMaxAgeDays int
+
+	// WebhookSinkURL is the HTTP endpoint that webhook sink sends events to,
+	// and events are sent in batches with retries.
+	WebhookSinkURL           string
+	WebhookSinkBatchSize     int
+	WebhookSinkFlushInterval time.Duration
+	WebhookSinkTimeout       time.Duration
+	WebhookSinkMaxRetries    int
+	WebhookSinkRetryInterval time.Duration
+
+	// HistorySinkCapacity is the number of latest records kept in memory for
+	// each pod and each event type, and HistorySinkMaxPods limits the number of
+	// pods whose records are kept.
+	HistorySinkCapacity int
+	HistorySinkMaxPods  int
 }
 
 func NewAuditConfiguration() *AuditConfiguration {
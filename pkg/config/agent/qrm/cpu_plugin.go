@@ -69,6 +69,23 @@ type CPUDynamicPolicyConfig struct {
 	EnableDefaultDedicatedCoresCPUBurst bool
 	// EnableDefaultSharedCoresCPUBurst indicates whether to enable cpu burst for shared cores by default
 	EnableDefaultSharedCoresCPUBurst bool
+	// EnableCoreScheduling indicates whether to assign core scheduling cookies to avoid
+	// tasks of different groups running on SMT siblings simultaneously
+	EnableCoreScheduling bool
+	// CoreSchedulingGranularity is the granularity (qos or pod) to group tasks sharing the same cookie
+	CoreSchedulingGranularity string
+	// CoreSchedulingQoSLevels are the QoS levels whose tasks will be assigned core scheduling cookies
+	CoreSchedulingQoSLevels []string
+	// EnableLLCPartition indicates whether to partition L3 cache ways between online pools
+	// and the reclaimed pool through resctrl
+	EnableLLCPartition bool
+	// LLCPartitionMinReclaimedWays and LLCPartitionMaxReclaimedWays are the bounds of L3 ways for the reclaimed pool
+	LLCPartitionMinReclaimedWays int
+	LLCPartitionMaxReclaimedWays int
+	// LLCPartitionMPKILowThreshold and LLCPartitionMPKIHighThreshold are the thresholds of L3 misses per
+	// kilo instructions of online pods, below which the reclaimed pool grows and above which it shrinks
+	LLCPartitionMPKILowThreshold  float64
+	LLCPartitionMPKIHighThreshold float64
 
 	*hintoptimizer.HintOptimizerConfiguration
 	*irqtuner.IRQTunerConfiguration
@@ -23,6 +23,7 @@ type HintOptimizerConfiguration struct {
 	*CanonicalHintOptimizerConfig
 	*MemoryBandwidthHintOptimizerConfig
 	*MetricBasedHintOptimizerConfig
+	*InterferenceHintOptimizerConfig
 }
 
 func NewHintOptimizerConfiguration() *HintOptimizerConfiguration {
@@ -30,5 +31,6 @@ func NewHintOptimizerConfiguration() *HintOptimizerConfiguration {
 		CanonicalHintOptimizerConfig:       NewCanonicalHintOptimizerConfig(),
 		MemoryBandwidthHintOptimizerConfig: NewMemoryBandwidthHintOptimizerConfig(),
 		MetricBasedHintOptimizerConfig:     NewMetricBasedHintOptimizerConfig(),
+		InterferenceHintOptimizerConfig:    NewInterferenceHintOptimizerConfig(),
 	}
 }
@@ -0,0 +1,41 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hintoptimizer
+
+import "time"
+
+type InterferenceHintOptimizerConfig struct {
+	// InterferenceLatencySensitiveQoSLevels are the qos levels of pods whose hints will be optimized by interference history
+	InterferenceLatencySensitiveQoSLevels []string
+	// InterferenceCollectInterval is the interval to sample interference metrics for each numa
+	InterferenceCollectInterval time.Duration
+	// InterferenceHistoryDecayFactor is the weight of the newest sample when updating the moving average of interference
+	InterferenceHistoryDecayFactor float64
+	// InterferenceMinSampleCount is the minimum number of samples required before the history of a metric is trusted
+	InterferenceMinSampleCount int
+	// InterferenceNoisyThreshold is the ratio of a numa's interference score to the average of all numas,
+	// above which the numa is considered noisy
+	InterferenceNoisyThreshold float64
+	// InterferenceMetricWeights maps metric name to its weight when calculating the interference score
+	InterferenceMetricWeights map[string]float64
+}
+
+func NewInterferenceHintOptimizerConfig() *InterferenceHintOptimizerConfig {
+	return &InterferenceHintOptimizerConfig{
+		InterferenceMetricWeights: map[string]float64{},
+	}
+}
@@ -50,6 +50,8 @@ type MemoryQRMPluginConfig struct {
 	// NUMABindResultResourceAllocationAnnotationKey: the annotation key for numa bind result resource allocation
 	// it will be used to set cgroup path for numa bind result resource allocation
 	NUMABindResultResourceAllocationAnnotationKey string
+	// EnableHugePages: enable topology-aware allocation for hugepage resources (e.g. hugepages-2Mi)
+	EnableHugePages bool
 	// SockMemQRMPluginConfig: the configuration for sockmem limitation in cgroup and host level
 	SockMemQRMPluginConfig
 	// LogCacheQRMPluginConfig: the configuration for logcache evicting
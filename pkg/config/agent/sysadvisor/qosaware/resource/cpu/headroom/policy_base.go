@@ -16,8 +16,13 @@ limitations under the License.
 
 package headroom
 
-type CPUHeadroomPolicyConfiguration struct{}
+type CPUHeadroomPolicyConfiguration struct {
+	// CPUPolicyHistorical is the configuration for historical cpu headroom policy
+	CPUPolicyHistorical *CPUPolicyHistoricalConfiguration
+}
 
 func NewCPUHeadroomPolicyConfiguration() *CPUHeadroomPolicyConfiguration {
-	return &CPUHeadroomPolicyConfiguration{}
+	return &CPUHeadroomPolicyConfiguration{
+		CPUPolicyHistorical: NewCPUPolicyHistoricalConfiguration(),
+	}
 }
@@ -0,0 +1,39 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package headroom
+
+import "time"
+
+// CPUPolicyHistoricalConfiguration is the configuration for historical headroom policy,
+// which estimates headroom by hour-of-week percentile usage profiles of online workloads
+type CPUPolicyHistoricalConfiguration struct {
+	// Percentile is the percentile of usage samples in each hour-of-week to be referred to
+	Percentile float64
+	// Lookahead is the duration ahead to prepare for the known usage peaks
+	Lookahead time.Duration
+	// MinSamplesPerBucket is the min number of samples for an hour-of-week to be referred to
+	MinSamplesPerBucket int
+	// MaxSamplesPerBucket is the max number of latest downsampled samples kept for each hour-of-week
+	MaxSamplesPerBucket int
+	// DownsamplePeriod is the period in which usage samples are merged into their max, and
+	// non-positive value keeps all samples as they are
+	DownsamplePeriod time.Duration
+}
+
+func NewCPUPolicyHistoricalConfiguration() *CPUPolicyHistoricalConfiguration {
+	return &CPUPolicyHistoricalConfiguration{}
+}
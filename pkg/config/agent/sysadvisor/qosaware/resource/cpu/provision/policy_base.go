@@ -22,6 +22,8 @@ type CPUProvisionPolicyConfiguration struct {
 	CPURegulatorConfiguration
 	// PolicyRama is the configuration for policy rama
 	PolicyRama *PolicyRamaConfiguration
+	// PolicyMPC is the configuration for policy mpc
+	PolicyMPC *PolicyMPCConfiguration
 	// enable to use control knob cpu quota when cgroup2 available
 	EnableControlKnobCPUQuota bool
 }
@@ -29,6 +31,7 @@ type CPUProvisionPolicyConfiguration struct {
 func NewCPUProvisionPolicyConfiguration() *CPUProvisionPolicyConfiguration {
 	return &CPUProvisionPolicyConfiguration{
 		PolicyRama: NewPolicyRamaConfiguration(),
+		PolicyMPC:  NewPolicyMPCConfiguration(),
 	}
 }
 
@@ -41,4 +44,17 @@ type CPURegulatorConfiguration struct {
 
 	// MinRampDownPeriod is the min time gap between two consecutive cpu requirement ramp down
 	MinRampDownPeriod time.Duration
+
+	// OscillationWindow is the time window to detect oscillation of cpu requirement
+	OscillationWindow time.Duration
+
+	// OscillationThreshold is the min number of direction reversals of cpu requirement changes
+	// in window regarded as oscillation, and non-positive value disables damping
+	OscillationThreshold int
+
+	// DampingDeadband is the max cpu cores of requirement changes to be ignored during damping
+	DampingDeadband int
+
+	// DampingDuration is the time to keep damping after oscillation is detected
+	DampingDuration time.Duration
 }
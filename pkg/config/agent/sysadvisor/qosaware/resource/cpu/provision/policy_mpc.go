@@ -0,0 +1,40 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provision
+
+// PolicyMPCConfiguration is the configuration for model-predictive provision policy
+type PolicyMPCConfiguration struct {
+	// HistorySize is the number of cpu usage samples used to forecast load
+	HistorySize int
+	// Horizon is the number of control intervals to optimize over
+	Horizon int
+	// SmoothingAlpha and TrendBeta are the factors of level and trend for
+	// double exponential smoothing, both in (0, 1]
+	SmoothingAlpha float64
+	TrendBeta      float64
+	// TargetUtilization is the expected ratio of cpu usage to cpu requirement
+	TargetUtilization float64
+	// ShortageWeight, OverProvisionWeight and MovementWeight are the weights of
+	// the cost for lacking cpu, wasting cpu and changing cpu requirement
+	ShortageWeight      float64
+	OverProvisionWeight float64
+	MovementWeight      float64
+}
+
+func NewPolicyMPCConfiguration() *PolicyMPCConfiguration {
+	return &PolicyMPCConfiguration{}
+}
@@ -25,6 +25,8 @@ type PolicyRamaConfiguration struct {
 	PIDParameters                   map[string]types.FirstOrderPIDParams
 	EnableBorwein                   bool
 	EnableBorweinModelResultFetcher bool
+	EnablePIDAutoTuning             bool
+	PIDAutoTuningParams             types.PIDAutoTuningParams
 }
 
 func NewPolicyRamaConfiguration() *PolicyRamaConfiguration {
@@ -91,5 +93,14 @@ func NewPolicyRamaConfiguration() *PolicyRamaConfiguration {
 				DeadbandLowerPct:     0.2,
 			},
 		},
+		PIDAutoTuningParams: types.PIDAutoTuningParams{
+			Window:               10,
+			OscillationThreshold: 4,
+			SluggishSteps:        10,
+			CooldownSteps:        5,
+			GainAdjustRatio:      0.1,
+			MinGainScale:         0.2,
+			MaxGainScale:         5,
+		},
 	}
 }
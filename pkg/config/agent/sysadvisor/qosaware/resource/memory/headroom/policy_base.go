@@ -18,10 +18,13 @@ package headroom
 
 type MemoryHeadroomPolicyConfiguration struct {
 	*MemoryPolicyCanonicalConfiguration
+	// MemoryPolicyHistorical is the configuration for historical memory headroom policy
+	MemoryPolicyHistorical *MemoryPolicyHistoricalConfiguration
 }
 
 func NewMemoryHeadroomPolicyConfiguration() *MemoryHeadroomPolicyConfiguration {
 	return &MemoryHeadroomPolicyConfiguration{
 		MemoryPolicyCanonicalConfiguration: NewMemoryPolicyCanonicalConfiguration(),
+		MemoryPolicyHistorical:             NewMemoryPolicyHistoricalConfiguration(),
 	}
 }
@@ -16,12 +16,19 @@ limitations under the License.
 
 package controller
 
+import "time"
+
 type NPDConfig struct {
 	NPDMetricsPlugins []string
 
 	EnableScopeDuplicated bool
 
 	SyncWorkers int
+
+	// UsageSyncPeriod is the interval for usage plugins to fetch metrics from katalyst-metric
+	UsageSyncPeriod time.Duration
+	// UsageWindows are the windows over which usage statistics are aggregated
+	UsageWindows []time.Duration
 }
 
 func NewNPDConfig() *NPDConfig {
@@ -29,5 +36,7 @@ func NewNPDConfig() *NPDConfig {
 		NPDMetricsPlugins:     []string{},
 		EnableScopeDuplicated: false,
 		SyncWorkers:           1,
+		UsageSyncPeriod:       time.Minute,
+		UsageWindows:          []time.Duration{5 * time.Minute, time.Hour, 24 * time.Hour},
 	}
 }
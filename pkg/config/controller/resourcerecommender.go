@@ -28,10 +28,16 @@ type ResourceRecommenderConfig struct {
 	HealthProbeBindPort string
 	MetricsBindPort     string
 
-	// available datasource: prom
+	// available datasource: prom, katalyst-metric, offline
 	DataSource []string
 	// DataSourcePromConfig is the prometheus datasource config
 	DataSourcePromConfig prometheus.PromConfig
+	// DefaultDataSource is the datasource used for recommendation, and it must be one of DataSource
+	DefaultDataSource string
+	// DataSourceOfflinePath is the snapshot file or directory for offline datasource
+	DataSourceOfflinePath string
+	// DataSourceOfflineReplayAnchor is the time in snapshots to be replayed as the controller start time
+	DataSourceOfflineReplayAnchor string
 
 	// LogVerbosityLevel to specify log verbosity level. (The default level is 4)
 	// Set it to something larger than 4 if more detailed logs are needed.
@@ -40,6 +46,20 @@ type ResourceRecommenderConfig struct {
 	// number of workers to sync
 	RecSyncWorkers int
 	RecSyncPeriod  time.Duration
+
+	// OOMForecastEnabled enables raising memory recommendation for containers
+	// predicted to OOM by oom history and memory growth trends
+	OOMForecastEnabled    bool
+	OOMForecastLookback   time.Duration
+	OOMForecastHorizon    time.Duration
+	OOMForecastStep       time.Duration
+	OOMForecastMinSamples int
+
+	// DecayHalfLife, DecayingPercentile and HistogramCheckpointInterval are used by
+	// the decaying algorithm, and DecayHalfLife can be overridden in algorithm extensions
+	DecayHalfLife               time.Duration
+	DecayingPercentile          float64
+	HistogramCheckpointInterval time.Duration
 }
 
 func NewResourceRecommenderConfig() *ResourceRecommenderConfig {
@@ -23,6 +23,9 @@ type VPARecommendationConfig struct{}
 type ResourceRecommendConfig struct {
 	// time interval of resync VPA
 	VPAReSyncPeriod time.Duration
+	// ExtendedResourceMetrics maps extended resources to names of their usage
+	// metrics in custom metrics API, which are used to recommend extended resources
+	ExtendedResourceMetrics map[string]string
 }
 
 type VPAConfig struct {
@@ -31,6 +31,21 @@ type StoreConfiguration struct {
 	StoreServerShardCount   int
 	StoreServerReplicaTotal int
 
+	// MultiTierDataDir is the local directory where the multi-tier store persists
+	// the metrics that have been spilled out of memory
+	MultiTierDataDir string
+	// MultiTierSegmentDuration is the max time range an append-only segment covers
+	MultiTierSegmentDuration time.Duration
+	// MultiTierDownsampleAfter and MultiTierDownsampleResolution define how old
+	// segments will be downsampled, i.e. raw points in those segments will be
+	// averaged into buckets with the given resolution
+	MultiTierDownsampleAfter      time.Duration
+	MultiTierDownsampleResolution time.Duration
+	// MultiTierRetention is the max time range that the on-disk metrics will be kept
+	MultiTierRetention time.Duration
+	// MultiTierMaxQueryRange is the max time range of each query reading on-disk metrics
+	MultiTierMaxQueryRange time.Duration
+
 	*generic.ServiceDiscoveryConf
 }
 
@@ -39,5 +54,11 @@ func NewStoreConfiguration() *StoreConfiguration {
 		GCPeriod:             time.Second * 10,
 		PurgePeriod:          time.Second * 600,
 		ServiceDiscoveryConf: generic.NewServiceDiscoveryConf(),
+
+		MultiTierSegmentDuration:      time.Hour,
+		MultiTierDownsampleAfter:      time.Hour * 6,
+		MultiTierDownsampleResolution: time.Minute * 5,
+		MultiTierRetention:            time.Hour * 24 * 7,
+		MultiTierMaxQueryRange:        time.Hour * 24,
 	}
 }
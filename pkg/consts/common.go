@@ -45,6 +45,12 @@ const (
 	EventReasonNotifySuccess = "NotifySuccess"
 
 	EventReasonContainerStopped = "ContainerStopped"
+
+	EventReasonEvictDryRun    = "EvictDryRun"
+	EventReasonEvictThrottled = "EvictThrottled"
+
+	EventReasonPodFrozen = "PodFrozen"
+	EventReasonPodThawed = "PodThawed"
 )
 
 // const variable for pod eviction action identifier in event.
@@ -52,6 +58,7 @@ const (
 	EventActionEvicting          = "Evicting"
 	EventActionNotifying         = "Notifying"
 	EventActionContainerStopping = "ContainerStopping"
+	EventActionFreezing          = "Freezing"
 )
 
 // KeySeparator : to split parts of a key
@@ -98,6 +105,8 @@ const (
 	TopicNameApplyProcFS = "ApplyProcFS"
 	TopicNameApplySysFS  = "ApplySysFS"
 	TopicNameSyscall     = "Syscall"
+	TopicNameEviction    = "Eviction"
+	TopicNameAllocation  = "Allocation"
 )
 
 const (
@@ -21,10 +21,17 @@ const (
 	KillerNameEvictionKiller  = "eviction-api-killer"
 	KillerNameDeletionKiller  = "deletion-api-killer"
 	KillerNameContainerKiller = "container-killer"
+	KillerNameDryRunKiller    = "dry-run-killer"
+	KillerNameFreezeKiller    = "freeze-killer"
 
 	NotifierNameHostPath = "host-path-notifier"
 )
 
+const (
+	FreezeKillerModeFreeze   = "freeze"
+	FreezeKillerModeThrottle = "throttle"
+)
+
 const (
 	// EvictionPluginThresholdMetRPCTimeoutInSecs is timeout duration in secs for ThresholdMet RPC
 	EvictionPluginThresholdMetRPCTimeoutInSecs = 10
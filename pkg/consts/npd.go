@@ -0,0 +1,34 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package consts
+
+// const variables for npd scopes filled by the built-in usage metrics plugins
+const (
+	NPDScopeNodeUsage = "node-usage"
+	NPDScopePodUsage  = "pod-usage"
+)
+
+// const variables for aggregators of usage statistics in npd, each of them
+// is calculated over all samples within the window of the metric.
+const (
+	NPDAggregatorAvg = "avg"
+	NPDAggregatorMax = "max"
+	NPDAggregatorP50 = "p50"
+	NPDAggregatorP90 = "p90"
+	NPDAggregatorP95 = "p95"
+	NPDAggregatorP99 = "p99"
+)
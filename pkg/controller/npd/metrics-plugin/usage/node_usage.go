@@ -0,0 +1,213 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	customclient "k8s.io/metrics/pkg/client/custom_metrics"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
+	apimetricnode "github.com/kubewharf/katalyst-api/pkg/metric/node"
+	katalystbase "github.com/kubewharf/katalyst-core/cmd/base"
+	"github.com/kubewharf/katalyst-core/pkg/config/controller"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	metrics_plugin "github.com/kubewharf/katalyst-core/pkg/controller/npd/metrics-plugin"
+)
+
+const (
+	NodeUsagePluginName = "node-usage"
+
+	// NodeUsageScope is the npd node metrics scope for node usage statistics,
+	// and each metric is labeled with its aggregator and window.
+	NodeUsageScope = consts.NPDScopeNodeUsage
+)
+
+var (
+	nodeGroupKind = schema.GroupKind{Kind: "Node"}
+
+	// DefaultNodeUsageAggregators are aggregators of node usage reported to npd
+	DefaultNodeUsageAggregators = []v1alpha1.Aggregator{AggregatorAvg, AggregatorP90, AggregatorP95, AggregatorMax}
+)
+
+// NodeUsagePlugin fetches cpu and memory usage of nodes from katalyst-metric,
+// and reports usage statistics over long windows into npd, so that consumers
+// can get node load without maintaining the history by themselves.
+type NodeUsagePlugin struct {
+	ctx     context.Context
+	updater metrics_plugin.MetricsUpdater
+
+	client     customclient.CustomMetricsClient
+	nodeLister corelisters.NodeLister
+	syncedFunc []cache.InformerSynced
+
+	syncPeriod  time.Duration
+	windows     []time.Duration
+	aggregators []v1alpha1.Aggregator
+
+	store *usageStore
+	now   func() time.Time
+}
+
+var _ metrics_plugin.MetricsPlugin = &NodeUsagePlugin{}
+
+func NewNodeUsagePlugin(ctx context.Context, conf *controller.NPDConfig, _ interface{},
+	controlCtx *katalystbase.GenericContext, updater metrics_plugin.MetricsUpdater,
+) (metrics_plugin.MetricsPlugin, error) {
+	if controlCtx.Client.CustomClient == nil {
+		return nil, fmt.Errorf("custom metrics client is nil")
+	}
+
+	nodeInformer := controlCtx.KubeInformerFactory.Core().V1().Nodes()
+	return &NodeUsagePlugin{
+		ctx:         ctx,
+		updater:     updater,
+		client:      controlCtx.Client.CustomClient,
+		nodeLister:  nodeInformer.Lister(),
+		syncedFunc:  []cache.InformerSynced{nodeInformer.Informer().HasSynced},
+		syncPeriod:  conf.UsageSyncPeriod,
+		windows:     conf.UsageWindows,
+		aggregators: DefaultNodeUsageAggregators,
+		store:       newUsageStore(),
+		now:         time.Now,
+	}, nil
+}
+
+func (p *NodeUsagePlugin) Run() {
+	if !cache.WaitForCacheSync(p.ctx.Done(), p.syncedFunc...) {
+		klog.Errorf("[npd] unable to sync caches for %v plugin", NodeUsagePluginName)
+		return
+	}
+
+	wait.Until(p.sync, p.syncPeriod, p.ctx.Done())
+}
+
+func (p *NodeUsagePlugin) Name() string { return NodeUsagePluginName }
+
+func (p *NodeUsagePlugin) GetSupportedNodeMetricsScope() []string { return []string{NodeUsageScope} }
+
+func (p *NodeUsagePlugin) GetSupportedPodMetricsScope() []string { return []string{} }
+
+func (p *NodeUsagePlugin) sync() {
+	now := p.now()
+
+	cpuSamples, err := p.getNodeSamples(apimetricnode.CustomMetricNodeCPUUsage)
+	if err != nil {
+		klog.Errorf("[npd] failed to get node cpu usage: %v", err)
+	}
+	for nodeName, samples := range cpuSamples {
+		p.store.addSamples(nodeName, v1.ResourceCPU, samples)
+	}
+
+	memorySamples, err := p.getNodeMemoryUsageSamples()
+	if err != nil {
+		klog.Errorf("[npd] failed to get node memory usage: %v", err)
+	}
+	for nodeName, samples := range memorySamples {
+		p.store.addSamples(nodeName, v1.ResourceMemory, samples)
+	}
+
+	p.store.prune(now.Add(-maxWindow(p.windows)), func(nodeName string) bool {
+		_, err := p.nodeLister.Get(nodeName)
+		return !errors.IsNotFound(err)
+	})
+
+	for nodeName := range p.store.windows {
+		p.updater.UpdateNodeMetrics(nodeName, []v1alpha1.ScopedNodeMetrics{
+			{
+				Scope:   NodeUsageScope,
+				Metrics: p.store.metricValues(nodeName, now, p.windows, p.aggregators),
+			},
+		})
+	}
+}
+
+// getNodeMemoryUsageSamples calculates memory usage by total and available memory,
+// since page cache that can be reclaimed easily shouldn't be regarded as usage.
+func (p *NodeUsagePlugin) getNodeMemoryUsageSamples() (map[string][]sample, error) {
+	totalSamples, err := p.getNodeSamples(apimetricnode.CustomMetricNodeMemoryTotal)
+	if err != nil {
+		return nil, err
+	}
+	availableSamples, err := p.getNodeSamples(apimetricnode.CustomMetricNodeMemoryAvailable)
+	if err != nil {
+		return nil, err
+	}
+
+	usageSamples := make(map[string][]sample)
+	for nodeName, samples := range availableSamples {
+		total, ok := latestSample(totalSamples[nodeName])
+		if !ok {
+			continue
+		}
+		for _, s := range samples {
+			usageSamples[nodeName] = append(usageSamples[nodeName], sample{timestamp: s.timestamp, value: total.value - s.value})
+		}
+	}
+	return usageSamples, nil
+}
+
+func (p *NodeUsagePlugin) getNodeSamples(metricName string) (map[string][]sample, error) {
+	metricValueList, err := p.client.RootScopedMetrics().GetForObjects(nodeGroupKind,
+		labels.Everything(), metricName, labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	nodeSamples := make(map[string][]sample)
+	for _, item := range metricValueList.Items {
+		nodeSamples[item.DescribedObject.Name] = append(nodeSamples[item.DescribedObject.Name], sample{
+			timestamp: item.Timestamp.Time,
+			value:     item.Value.AsApproximateFloat64(),
+		})
+	}
+	return nodeSamples, nil
+}
+
+func latestSample(samples []sample) (sample, bool) {
+	if len(samples) == 0 {
+		return sample{}, false
+	}
+
+	latest := samples[0]
+	for _, s := range samples[1:] {
+		if s.timestamp.After(latest.timestamp) {
+			latest = s
+		}
+	}
+	return latest, true
+}
+
+func maxWindow(windows []time.Duration) time.Duration {
+	var result time.Duration
+	for _, window := range windows {
+		if window > result {
+			result = window
+		}
+	}
+	return result
+}
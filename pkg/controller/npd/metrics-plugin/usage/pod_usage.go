@@ -0,0 +1,225 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	customclient "k8s.io/metrics/pkg/client/custom_metrics"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
+	apimetricpod "github.com/kubewharf/katalyst-api/pkg/metric/pod"
+	katalystbase "github.com/kubewharf/katalyst-core/cmd/base"
+	"github.com/kubewharf/katalyst-core/pkg/config/controller"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	metrics_plugin "github.com/kubewharf/katalyst-core/pkg/controller/npd/metrics-plugin"
+	"github.com/kubewharf/katalyst-core/pkg/util/native"
+)
+
+const (
+	PodUsagePluginName = "pod-usage"
+
+	// PodUsageScope is the npd pod metrics scope for pod usage statistics,
+	// and each metric is labeled with its aggregator and window.
+	PodUsageScope = consts.NPDScopePodUsage
+)
+
+var (
+	podGroupKind = schema.GroupKind{Kind: "Pod"}
+
+	// DefaultPodUsageAggregators are aggregators of pod usage reported to npd;
+	// only a few of them are reported since npd holds all pods in the node.
+	DefaultPodUsageAggregators = []v1alpha1.Aggregator{AggregatorAvg, AggregatorMax}
+)
+
+// PodUsagePlugin fetches cpu and memory usage of pods from katalyst-metric,
+// and reports usage statistics over long windows into npd of the nodes
+// where those pods are running.
+type PodUsagePlugin struct {
+	ctx     context.Context
+	updater metrics_plugin.MetricsUpdater
+
+	client     customclient.CustomMetricsClient
+	podLister  corelisters.PodLister
+	syncedFunc []cache.InformerSynced
+
+	syncPeriod  time.Duration
+	windows     []time.Duration
+	aggregators []v1alpha1.Aggregator
+
+	store *usageStore
+	// reportedNodes are nodes with pod usage reported in the last round, and
+	// they should be updated with empty metrics once all pods are gone.
+	reportedNodes sets.String
+	now           func() time.Time
+}
+
+var _ metrics_plugin.MetricsPlugin = &PodUsagePlugin{}
+
+func NewPodUsagePlugin(ctx context.Context, conf *controller.NPDConfig, _ interface{},
+	controlCtx *katalystbase.GenericContext, updater metrics_plugin.MetricsUpdater,
+) (metrics_plugin.MetricsPlugin, error) {
+	if controlCtx.Client.CustomClient == nil {
+		return nil, fmt.Errorf("custom metrics client is nil")
+	}
+
+	podInformer := controlCtx.KubeInformerFactory.Core().V1().Pods()
+	return &PodUsagePlugin{
+		ctx:           ctx,
+		updater:       updater,
+		client:        controlCtx.Client.CustomClient,
+		podLister:     podInformer.Lister(),
+		syncedFunc:    []cache.InformerSynced{podInformer.Informer().HasSynced},
+		syncPeriod:    conf.UsageSyncPeriod,
+		windows:       conf.UsageWindows,
+		aggregators:   DefaultPodUsageAggregators,
+		store:         newUsageStore(),
+		reportedNodes: sets.NewString(),
+		now:           time.Now,
+	}, nil
+}
+
+func (p *PodUsagePlugin) Run() {
+	if !cache.WaitForCacheSync(p.ctx.Done(), p.syncedFunc...) {
+		klog.Errorf("[npd] unable to sync caches for %v plugin", PodUsagePluginName)
+		return
+	}
+
+	wait.Until(p.sync, p.syncPeriod, p.ctx.Done())
+}
+
+func (p *PodUsagePlugin) Name() string { return PodUsagePluginName }
+
+func (p *PodUsagePlugin) GetSupportedNodeMetricsScope() []string { return []string{} }
+
+func (p *PodUsagePlugin) GetSupportedPodMetricsScope() []string { return []string{PodUsageScope} }
+
+func (p *PodUsagePlugin) sync() {
+	now := p.now()
+
+	for resourceName, metricName := range map[v1.ResourceName]string{
+		v1.ResourceCPU:    apimetricpod.CustomMetricPodCPUUsage,
+		v1.ResourceMemory: apimetricpod.CustomMetricPodMemoryUsage,
+	} {
+		podSamples, err := p.getPodSamples(metricName)
+		if err != nil {
+			klog.Errorf("[npd] failed to get pod %v usage: %v", resourceName, err)
+			continue
+		}
+		for podKey, samples := range podSamples {
+			p.store.addSamples(podKey, resourceName, samples)
+		}
+	}
+
+	pods, err := p.podLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("[npd] failed to list pods: %v", err)
+		return
+	}
+
+	nodePods := make(map[string][]*v1.Pod)
+	alivePods := sets.NewString()
+	for _, pod := range pods {
+		if pod.Spec.NodeName == "" {
+			continue
+		}
+		nodePods[pod.Spec.NodeName] = append(nodePods[pod.Spec.NodeName], pod)
+		alivePods.Insert(native.GenerateUniqObjectNameKey(pod))
+	}
+	p.store.prune(now.Add(-maxWindow(p.windows)), alivePods.Has)
+
+	reportedNodes := sets.NewString()
+	for nodeName, pods := range nodePods {
+		sort.Slice(pods, func(i, j int) bool {
+			return native.GenerateUniqObjectNameKey(pods[i]) < native.GenerateUniqObjectNameKey(pods[j])
+		})
+
+		var podMetrics []v1alpha1.PodMetric
+		for _, pod := range pods {
+			podKey := native.GenerateUniqObjectNameKey(pod)
+			if !p.store.has(podKey) {
+				continue
+			}
+			podMetrics = append(podMetrics, v1alpha1.PodMetric{
+				Namespace: pod.Namespace,
+				Name:      pod.Name,
+				Metrics:   p.store.metricValues(podKey, now, p.windows, p.aggregators),
+			})
+		}
+		if len(podMetrics) == 0 {
+			continue
+		}
+
+		reportedNodes.Insert(nodeName)
+		p.updater.UpdatePodMetrics(nodeName, []v1alpha1.ScopedPodMetrics{
+			{
+				Scope:      PodUsageScope,
+				PodMetrics: podMetrics,
+			},
+		})
+	}
+
+	for _, nodeName := range p.reportedNodes.Difference(reportedNodes).List() {
+		p.updater.UpdatePodMetrics(nodeName, []v1alpha1.ScopedPodMetrics{
+			{
+				Scope:      PodUsageScope,
+				PodMetrics: []v1alpha1.PodMetric{},
+			},
+		})
+	}
+	p.reportedNodes = reportedNodes
+}
+
+// getPodSamples returns usage samples of all pods, and container usage
+// with the same timestamp is summed up as the pod usage.
+func (p *PodUsagePlugin) getPodSamples(metricName string) (map[string][]sample, error) {
+	metricValueList, err := p.client.NamespacedMetrics(metav1.NamespaceAll).GetForObjects(podGroupKind,
+		labels.Everything(), metricName, labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	// pod usage is indexed by unix timestamp of samples
+	podUsage := make(map[string]map[int64]float64)
+	for _, item := range metricValueList.Items {
+		podKey := native.GenerateNamespaceNameKey(item.DescribedObject.Namespace, item.DescribedObject.Name)
+		if _, ok := podUsage[podKey]; !ok {
+			podUsage[podKey] = make(map[int64]float64)
+		}
+		podUsage[podKey][item.Timestamp.Unix()] += item.Value.AsApproximateFloat64()
+	}
+
+	podSamples := make(map[string][]sample)
+	for podKey, usage := range podUsage {
+		for timestamp, value := range usage {
+			podSamples[podKey] = append(podSamples[podKey], sample{timestamp: time.Unix(timestamp, 0), value: value})
+		}
+	}
+	return podSamples, nil
+}
@@ -0,0 +1,241 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usage
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/metrics/pkg/apis/custom_metrics/v1beta2"
+	cmfake "k8s.io/metrics/pkg/client/custom_metrics/fake"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
+	apimetricnode "github.com/kubewharf/katalyst-api/pkg/metric/node"
+	apimetricpod "github.com/kubewharf/katalyst-api/pkg/metric/pod"
+	katalystbase "github.com/kubewharf/katalyst-core/cmd/base"
+	"github.com/kubewharf/katalyst-core/pkg/config/controller"
+)
+
+type fakeMetricsUpdater struct {
+	sync.Mutex
+	nodeMetrics map[string][]v1alpha1.ScopedNodeMetrics
+	podMetrics  map[string][]v1alpha1.ScopedPodMetrics
+}
+
+func newFakeMetricsUpdater() *fakeMetricsUpdater {
+	return &fakeMetricsUpdater{
+		nodeMetrics: make(map[string][]v1alpha1.ScopedNodeMetrics),
+		podMetrics:  make(map[string][]v1alpha1.ScopedPodMetrics),
+	}
+}
+
+func (f *fakeMetricsUpdater) UpdateNodeMetrics(name string, scopedNodeMetrics []v1alpha1.ScopedNodeMetrics) {
+	f.Lock()
+	defer f.Unlock()
+	f.nodeMetrics[name] = scopedNodeMetrics
+}
+
+func (f *fakeMetricsUpdater) UpdatePodMetrics(nodeName string, scopedPodMetrics []v1alpha1.ScopedPodMetrics) {
+	f.Lock()
+	defer f.Unlock()
+	f.podMetrics[nodeName] = scopedPodMetrics
+}
+
+func makeMetricValue(kind, namespace, name, value string, timestamp time.Time) v1beta2.MetricValue {
+	return v1beta2.MetricValue{
+		DescribedObject: v1.ObjectReference{Kind: kind, Namespace: namespace, Name: name},
+		Timestamp:       metav1.NewTime(timestamp),
+		Value:           resource.MustParse(value),
+	}
+}
+
+func newFakeUsageContext(t *testing.T, objects []runtime.Object, metrics map[string][]v1beta2.MetricValue) *katalystbase.GenericContext {
+	controlCtx, err := katalystbase.GenerateFakeGenericContext(objects)
+	assert.NoError(t, err)
+
+	client := &cmfake.FakeCustomMetricsClient{}
+	client.AddReactor("get", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		return true, &v1beta2.MetricValueList{Items: metrics[action.(cmfake.GetForAction).GetMetricName()]}, nil
+	})
+	controlCtx.Client.CustomClient = client
+	return controlCtx
+}
+
+func findMetricValue(metrics []v1alpha1.MetricValue, name string, window time.Duration, aggregator v1alpha1.Aggregator) *v1alpha1.MetricValue {
+	for i := range metrics {
+		if metrics[i].MetricName == name && metrics[i].Window.Duration == window && *metrics[i].Aggregator == aggregator {
+			return &metrics[i]
+		}
+	}
+	return nil
+}
+
+func TestNodeUsagePlugin(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	now := time.Now().Truncate(time.Second)
+	metrics := map[string][]v1beta2.MetricValue{
+		apimetricnode.CustomMetricNodeCPUUsage: {
+			makeMetricValue("Node", "", "node1", "2", now.Add(-10*time.Minute)),
+			makeMetricValue("Node", "", "node1", "4", now.Add(-time.Minute)),
+			// metrics of nodes that don't exist should be ignored
+			makeMetricValue("Node", "", "node2", "4", now),
+		},
+		apimetricnode.CustomMetricNodeMemoryTotal: {
+			makeMetricValue("Node", "", "node1", "16Gi", now.Add(-5*time.Minute)),
+			makeMetricValue("Node", "", "node1", "16Gi", now),
+		},
+		apimetricnode.CustomMetricNodeMemoryAvailable: {
+			makeMetricValue("Node", "", "node1", "12Gi", now.Add(-5*time.Minute)),
+			makeMetricValue("Node", "", "node1", "12Gi", now),
+		},
+	}
+	controlCtx := newFakeUsageContext(t, []runtime.Object{&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}}, metrics)
+
+	updater := newFakeMetricsUpdater()
+	plugin, err := NewNodeUsagePlugin(ctx, controller.NewNPDConfig(), nil, controlCtx, updater)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{NodeUsageScope}, plugin.GetSupportedNodeMetricsScope())
+
+	p := plugin.(*NodeUsagePlugin)
+	p.now = func() time.Time { return now }
+	controlCtx.StartInformer(ctx)
+	assert.True(t, cache.WaitForCacheSync(ctx.Done(), p.syncedFunc...))
+
+	p.sync()
+	assert.Len(t, updater.nodeMetrics, 1)
+	assert.Len(t, updater.nodeMetrics["node1"], 1)
+	nodeMetrics := updater.nodeMetrics["node1"][0]
+	assert.Equal(t, NodeUsageScope, nodeMetrics.Scope)
+
+	// samples fetched in one round are merged and stamped with the newest one
+	cpu := findMetricValue(nodeMetrics.Metrics, string(v1.ResourceCPU), 5*time.Minute, AggregatorMax)
+	assert.NotNil(t, cpu)
+	assert.Equal(t, int64(3000), cpu.Value.MilliValue())
+	assert.Equal(t, now.Add(-time.Minute).Unix(), cpu.Timestamp.Unix())
+
+	// windows longer than collected samples are not reported
+	assert.Nil(t, findMetricValue(nodeMetrics.Metrics, string(v1.ResourceCPU), time.Hour, AggregatorMax))
+	assert.Nil(t, findMetricValue(nodeMetrics.Metrics, string(v1.ResourceCPU), 24*time.Hour, AggregatorMax))
+
+	memory := findMetricValue(nodeMetrics.Metrics, string(v1.ResourceMemory), 5*time.Minute, AggregatorP95)
+	assert.NotNil(t, memory)
+	assert.Equal(t, int64(4<<30), memory.Value.Value())
+	assert.Equal(t, now.Unix(), memory.Timestamp.Unix())
+
+	metrics[apimetricnode.CustomMetricNodeCPUUsage] = []v1beta2.MetricValue{
+		makeMetricValue("Node", "", "node1", "5", now.Add(50*time.Minute)),
+	}
+	p.now = func() time.Time { return now.Add(50 * time.Minute) }
+	p.sync()
+	nodeMetrics = updater.nodeMetrics["node1"][0]
+	cpu = findMetricValue(nodeMetrics.Metrics, string(v1.ResourceCPU), time.Hour, AggregatorAvg)
+	assert.NotNil(t, cpu)
+	assert.Equal(t, int64(4000), cpu.Value.MilliValue())
+	assert.Equal(t, now.Add(50*time.Minute).Unix(), cpu.Timestamp.Unix())
+	assert.Nil(t, findMetricValue(nodeMetrics.Metrics, string(v1.ResourceCPU), 24*time.Hour, AggregatorMax))
+}
+
+func TestPodUsagePlugin(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	makePod := func(name, nodeName string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: name},
+			Spec:       v1.PodSpec{NodeName: nodeName},
+		}
+	}
+
+	now := time.Now().Truncate(time.Second)
+	metrics := map[string][]v1beta2.MetricValue{
+		apimetricpod.CustomMetricPodCPUUsage: {
+			// containers with the same timestamp are summed up
+			makeMetricValue("Pod", "ns1", "pod1", "1", now.Add(-5*time.Minute)),
+			makeMetricValue("Pod", "ns1", "pod1", "500m", now.Add(-5*time.Minute)),
+			makeMetricValue("Pod", "ns1", "pod1", "1", now),
+			makeMetricValue("Pod", "ns1", "pod1", "500m", now),
+			makeMetricValue("Pod", "ns1", "pod2", "2", now),
+			makeMetricValue("Pod", "ns1", "pending", "2", now),
+		},
+		apimetricpod.CustomMetricPodMemoryUsage: {
+			makeMetricValue("Pod", "ns1", "pod1", "1Gi", now.Add(-time.Hour)),
+			makeMetricValue("Pod", "ns1", "pod1", "1Gi", now),
+		},
+	}
+	controlCtx := newFakeUsageContext(t, []runtime.Object{
+		makePod("pod1", "node1"),
+		makePod("pod2", "node2"),
+		makePod("pending", ""),
+	}, metrics)
+
+	updater := newFakeMetricsUpdater()
+	plugin, err := NewPodUsagePlugin(ctx, controller.NewNPDConfig(), nil, controlCtx, updater)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{PodUsageScope}, plugin.GetSupportedPodMetricsScope())
+
+	p := plugin.(*PodUsagePlugin)
+	p.now = func() time.Time { return now }
+	controlCtx.StartInformer(ctx)
+	assert.True(t, cache.WaitForCacheSync(ctx.Done(), p.syncedFunc...))
+
+	p.sync()
+	assert.Len(t, updater.podMetrics, 2)
+	assert.Len(t, updater.podMetrics["node1"], 1)
+	podMetrics := updater.podMetrics["node1"][0]
+	assert.Equal(t, PodUsageScope, podMetrics.Scope)
+	assert.Len(t, podMetrics.PodMetrics, 1)
+	assert.Equal(t, "pod1", podMetrics.PodMetrics[0].Name)
+
+	cpu := findMetricValue(podMetrics.PodMetrics[0].Metrics, string(v1.ResourceCPU), 5*time.Minute, AggregatorAvg)
+	assert.NotNil(t, cpu)
+	assert.Equal(t, int64(1500), cpu.Value.MilliValue())
+	assert.Equal(t, now.Unix(), cpu.Timestamp.Unix())
+	assert.Nil(t, findMetricValue(podMetrics.PodMetrics[0].Metrics, string(v1.ResourceCPU), time.Hour, AggregatorAvg))
+	memory := findMetricValue(podMetrics.PodMetrics[0].Metrics, string(v1.ResourceMemory), time.Hour, AggregatorMax)
+	assert.NotNil(t, memory)
+	assert.Equal(t, int64(1<<30), memory.Value.Value())
+
+	// pods with only one round of samples don't cover any window yet
+	assert.Len(t, updater.podMetrics["node2"][0].PodMetrics, 1)
+	assert.Empty(t, updater.podMetrics["node2"][0].PodMetrics[0].Metrics)
+
+	// nodes without any pod usage should be cleared
+	assert.NoError(t, controlCtx.Client.KubeClient.CoreV1().Pods("ns1").Delete(ctx, "pod2", metav1.DeleteOptions{}))
+	assert.Eventually(t, func() bool {
+		_, err := p.podLister.Pods("ns1").Get("pod2")
+		return err != nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	p.sync()
+	assert.Len(t, updater.podMetrics["node2"], 1)
+	assert.Empty(t, updater.podMetrics["node2"][0].PodMetrics)
+}
@@ -0,0 +1,263 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usage
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+)
+
+// aggregators of usage statistics reported to npd, each of them is
+// calculated over all samples within the given window.
+const (
+	AggregatorAvg v1alpha1.Aggregator = consts.NPDAggregatorAvg
+	AggregatorMax v1alpha1.Aggregator = consts.NPDAggregatorMax
+	AggregatorP50 v1alpha1.Aggregator = consts.NPDAggregatorP50
+	AggregatorP90 v1alpha1.Aggregator = consts.NPDAggregatorP90
+	AggregatorP95 v1alpha1.Aggregator = consts.NPDAggregatorP95
+	AggregatorP99 v1alpha1.Aggregator = consts.NPDAggregatorP99
+)
+
+var aggregatorPercentiles = map[v1alpha1.Aggregator]float64{
+	AggregatorP50: 0.5,
+	AggregatorP90: 0.9,
+	AggregatorP95: 0.95,
+	AggregatorP99: 0.99,
+}
+
+type sample struct {
+	timestamp time.Time
+	value     float64
+}
+
+// usageWindow keeps samples of one resource in time order; samples
+// fetched in one sync round are merged into a single point, so that
+// the memory footprint only depends on the sync period.
+type usageWindow struct {
+	samples []sample
+	// firstSeen is the timestamp of the earliest raw sample since the window
+	// becomes non-empty, and it's used to tell whether a statistic window
+	// has been fully covered by samples.
+	firstSeen time.Time
+}
+
+// addSamples merges those samples newer than the latest one in the window
+// by averaging, and returns whether any new point is appended.
+func (w *usageWindow) addSamples(samples []sample) bool {
+	var last time.Time
+	if len(w.samples) > 0 {
+		last = w.samples[len(w.samples)-1].timestamp
+	}
+
+	var (
+		sum      float64
+		count    int
+		earliest time.Time
+		latest   time.Time
+	)
+	for _, s := range samples {
+		if !s.timestamp.After(last) {
+			continue
+		}
+		sum += s.value
+		count++
+		if s.timestamp.After(latest) {
+			latest = s.timestamp
+		}
+		if earliest.IsZero() || s.timestamp.Before(earliest) {
+			earliest = s.timestamp
+		}
+	}
+	if count == 0 {
+		return false
+	}
+
+	if len(w.samples) == 0 {
+		w.firstSeen = earliest
+	}
+
+	w.samples = append(w.samples, sample{timestamp: latest, value: sum / float64(count)})
+	return true
+}
+
+// prune drops samples that are not after the deadline.
+func (w *usageWindow) prune(deadline time.Time) {
+	idx := 0
+	for idx < len(w.samples) && !w.samples[idx].timestamp.After(deadline) {
+		idx++
+	}
+	w.samples = w.samples[idx:]
+}
+
+// covered returns whether samples have been collected since the start of
+// (now-window, now], otherwise statistics over the window only reflect part
+// of it and shouldn't be reported as the whole window.
+func (w *usageWindow) covered(now time.Time, window time.Duration) bool {
+	return len(w.samples) > 0 && !w.firstSeen.After(now.Add(-window))
+}
+
+// aggregate calculates the statistic for samples within (now-window, now]
+// along with the timestamp of the newest sample in it, and false is returned
+// if there is no sample in it.
+func (w *usageWindow) aggregate(now time.Time, window time.Duration, aggregator v1alpha1.Aggregator) (float64, time.Time, bool) {
+	deadline := now.Add(-window)
+	var (
+		values []float64
+		latest time.Time
+	)
+	for _, s := range w.samples {
+		if s.timestamp.After(deadline) && !s.timestamp.After(now) {
+			values = append(values, s.value)
+			latest = s.timestamp
+		}
+	}
+	if len(values) == 0 {
+		return 0, time.Time{}, false
+	}
+
+	switch aggregator {
+	case AggregatorAvg:
+		sum := 0.0
+		for _, v := range values {
+			sum += v
+		}
+		return sum / float64(len(values)), latest, true
+	case AggregatorMax:
+		maxValue := values[0]
+		for _, v := range values[1:] {
+			maxValue = math.Max(maxValue, v)
+		}
+		return maxValue, latest, true
+	default:
+		percentile, ok := aggregatorPercentiles[aggregator]
+		if !ok {
+			return 0, time.Time{}, false
+		}
+		sort.Float64s(values)
+		// nearest-rank percentile, so that the result is always an observed sample
+		idx := int(math.Ceil(percentile*float64(len(values)))) - 1
+		if idx < 0 {
+			idx = 0
+		}
+		return values[idx], latest, true
+	}
+}
+
+// usageStore keeps usage windows for each object and resource.
+type usageStore struct {
+	windows map[string]map[v1.ResourceName]*usageWindow
+}
+
+func newUsageStore() *usageStore {
+	return &usageStore{windows: make(map[string]map[v1.ResourceName]*usageWindow)}
+}
+
+func (s *usageStore) addSamples(key string, resourceName v1.ResourceName, samples []sample) {
+	if len(samples) == 0 {
+		return
+	}
+
+	if _, ok := s.windows[key]; !ok {
+		s.windows[key] = make(map[v1.ResourceName]*usageWindow)
+	}
+	if _, ok := s.windows[key][resourceName]; !ok {
+		s.windows[key][resourceName] = &usageWindow{}
+	}
+	s.windows[key][resourceName].addSamples(samples)
+}
+
+// prune drops samples older than the deadline, and removes those objects
+// that are no longer kept or don't have any sample.
+func (s *usageStore) prune(deadline time.Time, keep func(key string) bool) {
+	for key, windows := range s.windows {
+		if !keep(key) {
+			delete(s.windows, key)
+			continue
+		}
+
+		for resourceName, w := range windows {
+			w.prune(deadline)
+			if len(w.samples) == 0 {
+				delete(windows, resourceName)
+			}
+		}
+		if len(windows) == 0 {
+			delete(s.windows, key)
+		}
+	}
+}
+
+func (s *usageStore) has(key string) bool {
+	_, ok := s.windows[key]
+	return ok
+}
+
+// metricValues generates npd metrics for the given object, and the result is
+// sorted by resource, window and aggregator to keep npd status stable. Each
+// metric is stamped with the newest sample in its window, and those windows
+// not fully covered by samples yet are skipped.
+func (s *usageStore) metricValues(key string, now time.Time, windows []time.Duration,
+	aggregators []v1alpha1.Aggregator,
+) []v1alpha1.MetricValue {
+	resourceNames := make([]v1.ResourceName, 0, len(s.windows[key]))
+	for resourceName := range s.windows[key] {
+		resourceNames = append(resourceNames, resourceName)
+	}
+	sort.Slice(resourceNames, func(i, j int) bool { return resourceNames[i] < resourceNames[j] })
+
+	var metrics []v1alpha1.MetricValue
+	for _, resourceName := range resourceNames {
+		w := s.windows[key][resourceName]
+		for _, window := range windows {
+			if !w.covered(now, window) {
+				continue
+			}
+
+			for i := range aggregators {
+				value, timestamp, ok := w.aggregate(now, window, aggregators[i])
+				if !ok {
+					continue
+				}
+
+				aggregator := aggregators[i]
+				metrics = append(metrics, v1alpha1.MetricValue{
+					MetricName: string(resourceName),
+					Value:      usageQuantity(resourceName, value),
+					Timestamp:  metav1.NewTime(timestamp),
+					Window:     &metav1.Duration{Duration: window},
+					Aggregator: &aggregator,
+				})
+			}
+		}
+	}
+	return metrics
+}
+
+func usageQuantity(resourceName v1.ResourceName, value float64) resource.Quantity {
+	if resourceName == v1.ResourceCPU {
+		return *resource.NewMilliQuantity(int64(math.Ceil(value*1000)), resource.DecimalSI)
+	}
+	return *resource.NewQuantity(int64(math.Ceil(value)), resource.BinarySI)
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
+)
+
+func TestUsageWindow(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	w := &usageWindow{}
+	for i := 10; i > 0; i-- {
+		added := w.addSamples([]sample{{timestamp: now.Add(-time.Duration(i) * time.Minute), value: float64(i)}})
+		assert.True(t, added)
+	}
+	assert.False(t, w.addSamples([]sample{{timestamp: now.Add(-time.Hour), value: 100}}), "stale samples should be skipped")
+
+	// samples fetched in one round are merged by averaging
+	assert.True(t, w.addSamples([]sample{{timestamp: now.Add(-time.Second), value: 0}, {timestamp: now, value: 2}}))
+	assert.Len(t, w.samples, 11)
+	assert.Equal(t, now, w.samples[10].timestamp)
+	assert.Equal(t, now.Add(-10*time.Minute), w.firstSeen)
+
+	assert.True(t, w.covered(now, 10*time.Minute))
+	assert.False(t, w.covered(now, time.Hour), "window longer than collected samples shouldn't be covered")
+
+	for _, tc := range []struct {
+		window     time.Duration
+		aggregator v1alpha1.Aggregator
+		expected   float64
+	}{
+		{window: 3 * time.Minute, aggregator: AggregatorAvg, expected: 4.0 / 3},
+		{window: 3 * time.Minute, aggregator: AggregatorMax, expected: 2},
+		{window: time.Hour, aggregator: AggregatorMax, expected: 10},
+		{window: time.Hour, aggregator: AggregatorP50, expected: 5},
+		{window: time.Hour, aggregator: AggregatorP90, expected: 9},
+		{window: time.Hour, aggregator: AggregatorP99, expected: 10},
+	} {
+		value, timestamp, ok := w.aggregate(now, tc.window, tc.aggregator)
+		assert.True(t, ok)
+		assert.InDelta(t, tc.expected, value, 1e-9, "%v over %v", tc.aggregator, tc.window)
+		assert.Equal(t, now, timestamp)
+	}
+
+	// the newest sample within the window is returned rather than now
+	_, timestamp, ok := w.aggregate(now.Add(-30*time.Second), time.Hour, AggregatorAvg)
+	assert.True(t, ok)
+	assert.Equal(t, now.Add(-time.Minute), timestamp)
+
+	_, _, ok = w.aggregate(now, time.Hour, v1alpha1.Aggregator("unknown"))
+	assert.False(t, ok)
+
+	w.prune(now.Add(-5 * time.Minute))
+	assert.Len(t, w.samples, 5)
+	_, _, ok = w.aggregate(now.Add(-10*time.Minute), time.Minute, AggregatorAvg)
+	assert.False(t, ok)
+
+	// firstSeen is reset once the window is drained
+	w.prune(now)
+	assert.False(t, w.covered(now, time.Minute))
+	assert.True(t, w.addSamples([]sample{{timestamp: now.Add(time.Minute), value: 1}}))
+	assert.Equal(t, now.Add(time.Minute), w.firstSeen)
+}
+
+func TestUsageStore(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	s := newUsageStore()
+	s.addSamples("n1", v1.ResourceMemory, []sample{{timestamp: now.Add(-time.Minute), value: 1 << 30}})
+	s.addSamples("n1", v1.ResourceCPU, []sample{{timestamp: now.Add(-time.Minute), value: 1.5}, {timestamp: now, value: 2.5}})
+	s.addSamples("n2", v1.ResourceCPU, []sample{{timestamp: now.Add(-time.Hour), value: 1}})
+	s.addSamples("n3", v1.ResourceCPU, nil)
+	assert.False(t, s.has("n3"))
+
+	assert.Empty(t, s.metricValues("n1", now, []time.Duration{5 * time.Minute}, []v1alpha1.Aggregator{AggregatorAvg}),
+		"windows not covered by samples should be skipped")
+
+	later := now.Add(4 * time.Minute)
+	s.addSamples("n1", v1.ResourceMemory, []sample{{timestamp: now.Add(2 * time.Minute), value: 1 << 30}})
+	metrics := s.metricValues("n1", later, []time.Duration{5 * time.Minute, time.Hour},
+		[]v1alpha1.Aggregator{AggregatorAvg, AggregatorMax})
+	assert.Len(t, metrics, 4)
+	assert.Equal(t, string(v1.ResourceCPU), metrics[0].MetricName)
+	assert.Equal(t, AggregatorAvg, *metrics[0].Aggregator)
+	assert.Equal(t, 5*time.Minute, metrics[0].Window.Duration)
+	assert.Equal(t, int64(2000), metrics[0].Value.MilliValue())
+	assert.Equal(t, now.Unix(), metrics[0].Timestamp.Unix())
+	assert.Equal(t, string(v1.ResourceMemory), metrics[3].MetricName)
+	assert.Equal(t, 5*time.Minute, metrics[3].Window.Duration)
+	assert.Equal(t, int64(1<<30), metrics[3].Value.Value())
+	assert.Equal(t, now.Add(2*time.Minute).Unix(), metrics[3].Timestamp.Unix())
+
+	s.prune(now.Add(-30*time.Minute), func(key string) bool { return key != "n1" })
+	assert.False(t, s.has("n1"), "objects not kept should be removed")
+	assert.False(t, s.has("n2"), "objects without samples should be removed")
+}
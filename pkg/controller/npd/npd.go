@@ -38,11 +38,17 @@ import (
 	"github.com/kubewharf/katalyst-core/pkg/config/controller"
 	"github.com/kubewharf/katalyst-core/pkg/config/generic"
 	metrics_plugin "github.com/kubewharf/katalyst-core/pkg/controller/npd/metrics-plugin"
+	"github.com/kubewharf/katalyst-core/pkg/controller/npd/metrics-plugin/usage"
 	"github.com/kubewharf/katalyst-core/pkg/metrics"
 )
 
 const npdControllerName = "npd"
 
+func init() {
+	metrics_plugin.RegisterPluginInitializer(usage.NodeUsagePluginName, usage.NewNodeUsagePlugin)
+	metrics_plugin.RegisterPluginInitializer(usage.PodUsagePluginName, usage.NewPodUsagePlugin)
+}
+
 type NPDController struct {
 	ctx context.Context
 
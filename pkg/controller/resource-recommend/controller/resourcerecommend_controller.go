@@ -35,6 +35,7 @@ import (
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
+	customclient "k8s.io/metrics/pkg/client/custom_metrics"
 
 	"github.com/kubewharf/katalyst-api/pkg/apis/recommendation/v1alpha1"
 	reclister "github.com/kubewharf/katalyst-api/pkg/client/listers/recommendation/v1alpha1"
@@ -43,10 +44,14 @@ import (
 	"github.com/kubewharf/katalyst-core/pkg/config/controller"
 	"github.com/kubewharf/katalyst-core/pkg/config/generic"
 	"github.com/kubewharf/katalyst-core/pkg/controller/resource-recommend/datasource"
+	"github.com/kubewharf/katalyst-core/pkg/controller/resource-recommend/datasource/katalystmetric"
+	"github.com/kubewharf/katalyst-core/pkg/controller/resource-recommend/datasource/offline"
 	"github.com/kubewharf/katalyst-core/pkg/controller/resource-recommend/datasource/prometheus"
 	"github.com/kubewharf/katalyst-core/pkg/controller/resource-recommend/oom"
+	"github.com/kubewharf/katalyst-core/pkg/controller/resource-recommend/processor/decaying"
 	processormanager "github.com/kubewharf/katalyst-core/pkg/controller/resource-recommend/processor/manager"
 	recommendermanager "github.com/kubewharf/katalyst-core/pkg/controller/resource-recommend/recommender/manager"
+	"github.com/kubewharf/katalyst-core/pkg/controller/resource-recommend/recommender/recommenders"
 	conditionstypes "github.com/kubewharf/katalyst-core/pkg/util/resource-recommend/types/conditions"
 	errortypes "github.com/kubewharf/katalyst-core/pkg/util/resource-recommend/types/error"
 	processortypes "github.com/kubewharf/katalyst-core/pkg/util/resource-recommend/types/processor"
@@ -60,6 +65,12 @@ const (
 	defaultRecommendInterval               = 24 * time.Hour
 )
 
+// names of datasources in configurations
+const (
+	datasourceNameKatalystMetric = "katalyst-metric"
+	datasourceNameOffline        = "offline"
+)
+
 type ResourceRecommendController struct {
 	ctx  context.Context
 	conf *controller.ResourceRecommenderConfig
@@ -136,12 +147,29 @@ func NewResourceRecommendController(ctx context.Context,
 
 	// todo: add metricsEmitter
 
-	dataProxy := initDataSources(recConf)
+	dataProxy := initDataSources(recConf, controlCtx.Client.CustomClient)
 	klog.Infof("[resource-recommend] successfully init data proxy %v", *dataProxy)
 
 	recController.ProcessorManager = processormanager.NewManager(dataProxy, recController.recLister)
+	recController.ProcessorManager.ProcessorRegister(decaying.AlgorithmDecaying, decaying.NewProcessor(dataProxy, recController.recLister,
+		decaying.NewConfigMapCheckpointer(controlCtx.Client.KubeClient.CoreV1()), decaying.Config{
+			DecayHalfLife:      recConf.DecayHalfLife,
+			Percentile:         recConf.DecayingPercentile,
+			CheckpointInterval: recConf.HistogramCheckpointInterval,
+		}))
 	recController.OOMRecorder = OOMRecorder
-	recController.RecommenderManager = recommendermanager.NewManager(*recController.ProcessorManager, recController.OOMRecorder)
+	var oomForecaster *recommenders.OOMForecaster
+	if recConf.OOMForecastEnabled {
+		podInformer := controlCtx.KubeInformerFactory.Core().V1().Pods()
+		recController.syncedFunc = append(recController.syncedFunc, podInformer.Informer().HasSynced)
+		oomForecaster = recommenders.NewOOMForecaster(dataProxy, podInformer.Lister(), recommenders.OOMForecastConfig{
+			Lookback:   recConf.OOMForecastLookback,
+			Horizon:    recConf.OOMForecastHorizon,
+			Step:       recConf.OOMForecastStep,
+			MinSamples: recConf.OOMForecastMinSamples,
+		})
+	}
+	recController.RecommenderManager = recommendermanager.NewManager(*recController.ProcessorManager, recController.OOMRecorder, oomForecaster)
 
 	return recController, nil
 }
@@ -377,11 +405,15 @@ func (rrc *ResourceRecommendController) doRecommend(ctx context.Context, namespa
 
 func (rrc *ResourceRecommendController) RegisterTasks(recommendation recommendationtypes.Recommendation) *errortypes.CustomError {
 	processor := rrc.ProcessorManager.GetProcessor(recommendation.AlgorithmPolicy.Algorithm)
+	var taskConfig processortypes.TaskConfigStr
+	if extensions := recommendation.AlgorithmPolicy.Extensions; extensions != nil && len(extensions.Raw) > 0 {
+		taskConfig = processortypes.TaskConfigStr(extensions.Raw)
+	}
 	for _, container := range recommendation.Containers {
 		for _, containerConfig := range container.ContainerConfigs {
 			processConfig := processortypes.NewProcessConfig(recommendation.NamespacedName,
 				recommendation.Config.TargetRef, container.ContainerName,
-				containerConfig.ControlledResource, "")
+				containerConfig.ControlledResource, taskConfig)
 			if err := processor.Register(processConfig); err != nil {
 				return errortypes.DataProcessRegisteredFailedError(err.Error())
 			}
@@ -392,12 +424,16 @@ func (rrc *ResourceRecommendController) RegisterTasks(recommendation recommendat
 
 // CancelTasks Cancel all process task
 func (rrc *ResourceRecommendController) CancelTasks(namespacedName k8stypes.NamespacedName) *errortypes.CustomError {
-	processor := rrc.ProcessorManager.GetProcessor(v1alpha1.AlgorithmPercentile)
-	err := processor.Cancel(&processortypes.ProcessKey{ResourceRecommendNamespacedName: namespacedName})
-	if err != nil {
-		klog.ErrorS(err, "cancel processor task failed", "namespacedName", namespacedName)
+	var cErr *errortypes.CustomError
+	for algorithm, processor := range rrc.ProcessorManager.ListProcessors() {
+		err := processor.Cancel(&processortypes.ProcessKey{ResourceRecommendNamespacedName: namespacedName})
+		// tasks are only registered in the processor of the algorithm used by the ResourceRecommend
+		if err != nil && err.Code != errortypes.NotFoundTasks {
+			klog.ErrorS(err, "cancel processor task failed", "namespacedName", namespacedName, "algorithm", algorithm)
+			cErr = err
+		}
 	}
-	return err
+	return cErr
 }
 
 func (rrc *ResourceRecommendController) UpdateRecommendationStatus(namespacedName k8stypes.NamespacedName, recommendation *recommendationtypes.Recommendation) error {
@@ -414,12 +450,24 @@ func (rrc *ResourceRecommendController) UpdateRecommendationStatus(namespacedNam
 	return rrc.recUpdater.PatchResourceRecommend(rrc.ctx, oldRec, newRec)
 }
 
-func initDataSources(opts *controller.ResourceRecommenderConfig) *datasource.Proxy {
+func initDataSources(opts *controller.ResourceRecommenderConfig, customClient customclient.CustomMetricsClient) *datasource.Proxy {
 	dataProxy := datasource.NewProxy()
 	for _, datasourceProvider := range opts.DataSource {
-		switch datasourceProvider {
-		case string(datasource.PrometheusDatasource):
-			fallthrough
+		switch datasourceTypeOf(datasourceProvider) {
+		case datasource.KatalystMetricDatasource:
+			katalystMetricProvider, err := katalystmetric.NewKatalystMetric(customClient)
+			if err != nil {
+				klog.Exitf("unable to create datasource provider %v, err: %v", datasourceProvider, err)
+				panic(err)
+			}
+			dataProxy.RegisterDatasource(datasource.KatalystMetricDatasource, katalystMetricProvider)
+		case datasource.OfflineDatasource:
+			offlineProvider, err := offline.NewOffline(opts.DataSourceOfflinePath, opts.DataSourceOfflineReplayAnchor)
+			if err != nil {
+				klog.Exitf("unable to create datasource provider %v, err: %v", datasourceProvider, err)
+				panic(err)
+			}
+			dataProxy.RegisterDatasource(datasource.OfflineDatasource, offlineProvider)
 		default:
 			// default is prom
 			prometheusProvider, err := prometheus.NewPrometheus(&opts.DataSourcePromConfig)
@@ -430,5 +478,23 @@ func initDataSources(opts *controller.ResourceRecommenderConfig) *datasource.Pro
 			dataProxy.RegisterDatasource(datasource.PrometheusDatasource, prometheusProvider)
 		}
 	}
+
+	if err := dataProxy.SetDefaultDatasource(datasourceTypeOf(opts.DefaultDataSource)); err != nil {
+		klog.Exitf("unable to set default datasource %v, err: %v", opts.DefaultDataSource, err)
+		panic(err)
+	}
 	return dataProxy
 }
+
+// datasourceTypeOf returns the datasource type by its name in configurations,
+// and any unknown name is regarded as prometheus.
+func datasourceTypeOf(name string) datasource.DatasourceType {
+	switch name {
+	case datasourceNameKatalystMetric, string(datasource.KatalystMetricDatasource):
+		return datasource.KatalystMetricDatasource
+	case datasourceNameOffline, string(datasource.OfflineDatasource):
+		return datasource.OfflineDatasource
+	default:
+		return datasource.PrometheusDatasource
+	}
+}
@@ -54,6 +54,7 @@ func Test_initDataSources(t *testing.T) {
 	proxy := datasource.NewProxy()
 	mockDatasource := MockDatasource{}
 	proxy.RegisterDatasource(datasource.PrometheusDatasource, &mockDatasource)
+	_ = proxy.SetDefaultDatasource(datasource.PrometheusDatasource)
 	type args struct {
 		opts *controller.ResourceRecommenderConfig
 	}
@@ -77,7 +78,7 @@ func Test_initDataSources(t *testing.T) {
 		mockey.PatchConvey(tt.name, t, func() {
 			mockey.Mock(resourcerecommendprometheus.NewPrometheus).Return(&mockDatasource, nil).Build()
 
-			got := initDataSources(tt.args.opts)
+			got := initDataSources(tt.args.opts, nil)
 			convey.So(got, convey.ShouldResemble, tt.want)
 		})
 	}
@@ -18,6 +18,7 @@ package datasource
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	datasourcetypes "github.com/kubewharf/katalyst-core/pkg/util/resource-recommend/types/datasource"
@@ -26,7 +27,9 @@ import (
 type DatasourceType string
 
 const (
-	PrometheusDatasource DatasourceType = "Prometheus"
+	PrometheusDatasource     DatasourceType = "Prometheus"
+	KatalystMetricDatasource DatasourceType = "KatalystMetric"
+	OfflineDatasource        DatasourceType = "Offline"
 )
 
 type Datasource interface {
@@ -36,6 +39,8 @@ type Datasource interface {
 
 type Proxy struct {
 	datasourceMap map[DatasourceType]Datasource
+	// defaultDatasource is the datasource used for recommendation
+	defaultDatasource DatasourceType
 }
 
 func NewProxy() *Proxy {
@@ -48,6 +53,25 @@ func (p *Proxy) RegisterDatasource(name DatasourceType, datasource Datasource) {
 	p.datasourceMap[name] = datasource
 }
 
+// SetDefaultDatasource sets the datasource used for recommendation, and it must
+// have been registered before.
+func (p *Proxy) SetDefaultDatasource(name DatasourceType) error {
+	if _, ok := p.datasourceMap[name]; !ok {
+		return fmt.Errorf("default datasource %v is not registered", name)
+	}
+	p.defaultDatasource = name
+	return nil
+}
+
+// DefaultDatasource returns the datasource used for recommendation, and
+// prometheus is returned for compatibility if it is not set.
+func (p *Proxy) DefaultDatasource() DatasourceType {
+	if p.defaultDatasource == "" {
+		return PrometheusDatasource
+	}
+	return p.defaultDatasource
+}
+
 func (p *Proxy) getDatasource(name DatasourceType) (Datasource, error) {
 	if datasource, ok := p.datasourceMap[name]; ok {
 		return datasource, nil
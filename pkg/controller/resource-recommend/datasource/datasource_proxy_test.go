@@ -107,3 +107,18 @@ func TestProxy_QueryTimeSeries(t *testing.T) {
 		})
 	}
 }
+
+func TestProxy_DefaultDatasource(t *testing.T) {
+	proxy := NewProxy()
+	assert.Equal(t, PrometheusDatasource, proxy.DefaultDatasource())
+
+	proxy.RegisterDatasource(OfflineDatasource, &MockDatasource{})
+	proxy.RegisterDatasource(PrometheusDatasource, &MockDatasource{})
+	assert.Equal(t, PrometheusDatasource, proxy.DefaultDatasource())
+
+	assert.NoError(t, proxy.SetDefaultDatasource(OfflineDatasource))
+	assert.Equal(t, OfflineDatasource, proxy.DefaultDatasource())
+
+	assert.Error(t, proxy.SetDefaultDatasource(KatalystMetricDatasource))
+	assert.Equal(t, OfflineDatasource, proxy.DefaultDatasource())
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package katalystmetric
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+	customclient "k8s.io/metrics/pkg/client/custom_metrics"
+
+	apimetricpod "github.com/kubewharf/katalyst-api/pkg/metric/pod"
+	"github.com/kubewharf/katalyst-core/pkg/controller/resource-recommend/datasource"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+	datasourcetypes "github.com/kubewharf/katalyst-core/pkg/util/resource-recommend/types/datasource"
+)
+
+const (
+	// containerSelectorKey is the metric label of container name emitted by katalyst metric syncer
+	containerSelectorKey = "container"
+)
+
+var podGroupKind = schema.GroupKind{Kind: "Pod"}
+
+type katalystMetric struct {
+	client customclient.CustomMetricsClient
+}
+
+// NewKatalystMetric returns a datasource backed by katalyst custom metric store,
+// which is served through the custom metrics api.
+func NewKatalystMetric(client customclient.CustomMetricsClient) (datasource.Datasource, error) {
+	if client == nil {
+		return nil, fmt.Errorf("custom metrics client is nil")
+	}
+	return &katalystMetric{client: client}, nil
+}
+
+func (k *katalystMetric) ConvertMetricToQuery(metric datasourcetypes.Metric) (*datasourcetypes.Query, error) {
+	var metricName string
+	switch metric.Resource {
+	case v1.ResourceCPU:
+		metricName = apimetricpod.CustomMetricPodCPUUsage
+	case v1.ResourceMemory:
+		metricName = apimetricpod.CustomMetricPodMemoryUsage
+	default:
+		return nil, fmt.Errorf("query for resource type %v is not supported", metric.Resource)
+	}
+
+	return &datasourcetypes.Query{
+		KatalystMetric: &datasourcetypes.KatalystMetricQuery{
+			Namespace:      metric.Namespace,
+			MetricName:     metricName,
+			PodNamePattern: datasourcetypes.GetWorkloadPodNamePattern(metric.WorkloadName, metric.Kind),
+			MetricSelector: labels.SelectorFromSet(labels.Set{containerSelectorKey: metric.ContainerName}).String(),
+		},
+	}, nil
+}
+
+func (k *katalystMetric) QueryTimeSeries(query *datasourcetypes.Query, start time.Time, end time.Time, step time.Duration) (*datasourcetypes.TimeSeries, error) {
+	klog.InfoS("QueryTimeSeries", "query", general.StructToString(query), "start", start, "end", end)
+	if query == nil || query.KatalystMetric == nil {
+		return nil, fmt.Errorf("katalyst metric query is empty")
+	}
+	q := query.KatalystMetric
+
+	podNameRegexp, err := regexp.Compile(q.PodNamePattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pod name pattern %v: %v", q.PodNamePattern, err)
+	}
+	metricSelector, err := labels.Parse(q.MetricSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid metric selector %v: %v", q.MetricSelector, err)
+	}
+
+	metricValueList, err := k.client.NamespacedMetrics(q.Namespace).GetForObjects(podGroupKind,
+		labels.Everything(), q.MetricName, metricSelector)
+	if err != nil {
+		klog.ErrorS(err, "query katalyst metric failed", "query", general.StructToString(q))
+		return nil, err
+	}
+
+	// samples are grouped by pods before downsampling, since each pod has its own series
+	podSamples := make(map[string][]datasourcetypes.Sample)
+	for _, item := range metricValueList.Items {
+		if !podNameRegexp.MatchString(item.DescribedObject.Name) {
+			continue
+		}
+		if item.Timestamp.Time.Before(start) || item.Timestamp.Time.After(end) {
+			continue
+		}
+		podSamples[item.DescribedObject.Name] = append(podSamples[item.DescribedObject.Name], datasourcetypes.Sample{
+			Value:     item.Value.AsApproximateFloat64(),
+			Timestamp: item.Timestamp.Unix(),
+		})
+	}
+
+	timeSeries := datasourcetypes.NewTimeSeries()
+	timeSeries.AppendLabel("namespace", q.Namespace)
+	timeSeries.AppendLabel("__name__", q.MetricName)
+	for _, samples := range podSamples {
+		for _, sample := range datasourcetypes.DownsampleSamples(samples, step) {
+			timeSeries.AppendSample(sample.Timestamp, sample.Value)
+		}
+	}
+	return timeSeries, nil
+}
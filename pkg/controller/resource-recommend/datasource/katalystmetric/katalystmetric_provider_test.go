@@ -0,0 +1,90 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package katalystmetric
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/metrics/pkg/apis/custom_metrics/v1beta2"
+	cmfake "k8s.io/metrics/pkg/client/custom_metrics/fake"
+
+	apimetricpod "github.com/kubewharf/katalyst-api/pkg/metric/pod"
+	datasourcetypes "github.com/kubewharf/katalyst-core/pkg/util/resource-recommend/types/datasource"
+)
+
+func makeMetricValue(pod string, timestamp int64, value string) v1beta2.MetricValue {
+	return v1beta2.MetricValue{
+		DescribedObject: v1.ObjectReference{Kind: "Pod", Namespace: "ns1", Name: pod},
+		Metric:          v1beta2.MetricIdentifier{Name: apimetricpod.CustomMetricPodMemoryUsage},
+		Timestamp:       metav1.NewTime(time.Unix(timestamp, 0)),
+		Value:           resource.MustParse(value),
+	}
+}
+
+func TestKatalystMetricQueryTimeSeries(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewKatalystMetric(nil)
+	assert.Error(t, err)
+
+	client := &cmfake.FakeCustomMetricsClient{}
+	var requestedMetric string
+	client.AddReactor("get", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		getForAction := action.(cmfake.GetForAction)
+		requestedMetric = getForAction.GetMetricName()
+		assert.Equal(t, "ns1", action.GetNamespace())
+		return true, &v1beta2.MetricValueList{Items: []v1beta2.MetricValue{
+			makeMetricValue("workload1-abc12-x1y2z", 1700000000, "100"),
+			makeMetricValue("workload1-abc12-x1y2z", 1700000030, "110"),
+			makeMetricValue("workload1-abc12-x1y2z", 1700000060, "120"),
+			makeMetricValue("workload1-def34-a1b2c", 1700000060, "200"),
+			makeMetricValue("workload2-abc12-x1y2z", 1700000060, "999"),
+			makeMetricValue("workload1-abc12-x1y2z", 1700009999, "999"),
+		}}, nil
+	})
+
+	ds, err := NewKatalystMetric(client)
+	assert.NoError(t, err)
+
+	query, err := ds.ConvertMetricToQuery(datasourcetypes.Metric{
+		Namespace:     "ns1",
+		Kind:          string(datasourcetypes.WorkloadDeployment),
+		WorkloadName:  "workload1",
+		ContainerName: "c1",
+		Resource:      v1.ResourceMemory,
+	})
+	assert.NoError(t, err)
+
+	ts, err := ds.QueryTimeSeries(query, time.Unix(1700000000, 0), time.Unix(1700000100, 0), time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, apimetricpod.CustomMetricPodMemoryUsage, requestedMetric)
+	assert.ElementsMatch(t, []datasourcetypes.Sample{
+		{Timestamp: 1700000000, Value: 100},
+		{Timestamp: 1700000060, Value: 120},
+		{Timestamp: 1700000060, Value: 200},
+	}, ts.Samples)
+
+	_, err = ds.ConvertMetricToQuery(datasourcetypes.Metric{Resource: v1.ResourceStorage})
+	assert.Error(t, err)
+}
@@ -0,0 +1,231 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package offline
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/kubewharf/katalyst-core/pkg/controller/resource-recommend/datasource"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+	datasourcetypes "github.com/kubewharf/katalyst-core/pkg/util/resource-recommend/types/datasource"
+)
+
+// metrics in snapshots are expected to be named as below, and cpu usage
+// should already be converted to cores instead of the cumulative counter
+const (
+	MetricNameContainerCPUUsage    = "container_cpu_usage"
+	MetricNameContainerMemoryUsage = "container_memory_working_set_bytes"
+)
+
+const (
+	LabelNamespace = "namespace"
+	LabelPod       = "pod"
+	LabelContainer = "container"
+)
+
+// snapshot files are recognized by their extensions
+const (
+	fileExtensionCSV         = ".csv"
+	fileExtensionOpenMetrics = ".om"
+	fileExtensionPrometheus  = ".prom"
+	fileExtensionText        = ".txt"
+)
+
+type seriesKey struct {
+	metricName string
+	namespace  string
+	pod        string
+	container  string
+}
+
+// ReplayAnchorLatest anchors the latest sample in snapshots to the controller start time
+const ReplayAnchorLatest = "latest"
+
+type offline struct {
+	series map[seriesKey][]datasourcetypes.Sample
+	// offset is added to the time of snapshots to get the replayed time
+	offset time.Duration
+}
+
+// NewOffline returns a datasource that replays historical metrics from on-disk
+// snapshots, the path can be either a snapshot file or a directory of them;
+// both csv and OpenMetrics text formats are supported. If replayAnchor is set,
+// snapshots are shifted so that the anchor time (RFC3339 or ReplayAnchorLatest)
+// is replayed as the current time, otherwise they are replayed at their original time.
+func NewOffline(path string, replayAnchor string) (datasource.Datasource, error) {
+	return newOffline(path, replayAnchor, time.Now())
+}
+
+func newOffline(path string, replayAnchor string, now time.Time) (*offline, error) {
+	files, err := listSnapshotFiles(path)
+	if err != nil {
+		return nil, err
+	}
+
+	o := &offline{series: make(map[seriesKey][]datasourcetypes.Sample)}
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("read snapshot %v failed: %v", file, err)
+		}
+
+		var records []record
+		switch strings.ToLower(filepath.Ext(file)) {
+		case fileExtensionCSV:
+			records, err = parseCSV(string(content))
+		default:
+			records, err = parseOpenMetrics(string(content))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse snapshot %v failed: %v", file, err)
+		}
+
+		for _, r := range records {
+			o.series[r.key] = append(o.series[r.key], r.sample)
+		}
+		klog.InfoS("offline datasource loaded snapshot", "file", file, "records", len(records))
+	}
+
+	var latest int64
+	for key := range o.series {
+		samples := o.series[key]
+		sort.Slice(samples, func(i, j int) bool {
+			return samples[i].Timestamp < samples[j].Timestamp
+		})
+		if len(samples) > 0 && samples[len(samples)-1].Timestamp > latest {
+			latest = samples[len(samples)-1].Timestamp
+		}
+	}
+
+	switch replayAnchor {
+	case "":
+	case ReplayAnchorLatest:
+		if latest > 0 {
+			o.offset = now.Sub(time.Unix(latest, 0))
+		}
+	default:
+		anchor, err := time.Parse(time.RFC3339, replayAnchor)
+		if err != nil {
+			return nil, fmt.Errorf("invalid replay anchor %v: %v", replayAnchor, err)
+		}
+		o.offset = now.Sub(anchor)
+	}
+	// samples are in seconds, so is the offset
+	o.offset = o.offset.Truncate(time.Second)
+	klog.InfoS("offline datasource replay offset", "anchor", replayAnchor, "offset", o.offset)
+	return o, nil
+}
+
+func listSnapshotFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("stat snapshot path %v failed: %v", path, err)
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("read snapshot dir %v failed: %v", path, err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case fileExtensionCSV, fileExtensionOpenMetrics, fileExtensionPrometheus, fileExtensionText:
+			files = append(files, filepath.Join(path, entry.Name()))
+		}
+	}
+	return files, nil
+}
+
+func (o *offline) ConvertMetricToQuery(metric datasourcetypes.Metric) (*datasourcetypes.Query, error) {
+	var metricName string
+	switch metric.Resource {
+	case v1.ResourceCPU:
+		metricName = MetricNameContainerCPUUsage
+	case v1.ResourceMemory:
+		metricName = MetricNameContainerMemoryUsage
+	default:
+		return nil, fmt.Errorf("query for resource type %v is not supported", metric.Resource)
+	}
+
+	return &datasourcetypes.Query{
+		Offline: &datasourcetypes.OfflineQuery{
+			MetricName:     metricName,
+			Namespace:      metric.Namespace,
+			PodNamePattern: datasourcetypes.GetWorkloadPodNamePattern(metric.WorkloadName, metric.Kind),
+			ContainerName:  metric.ContainerName,
+		},
+	}, nil
+}
+
+func (o *offline) QueryTimeSeries(query *datasourcetypes.Query, start time.Time, end time.Time, step time.Duration) (*datasourcetypes.TimeSeries, error) {
+	klog.InfoS("QueryTimeSeries", "query", general.StructToString(query), "start", start, "end", end, "offset", o.offset)
+	if query == nil || query.Offline == nil {
+		return nil, fmt.Errorf("offline query is empty")
+	}
+	q := query.Offline
+
+	podNameRegexp, err := regexp.Compile(q.PodNamePattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pod name pattern %v: %v", q.PodNamePattern, err)
+	}
+
+	timeSeries := datasourcetypes.NewTimeSeries()
+	timeSeries.AppendLabel(LabelNamespace, q.Namespace)
+	timeSeries.AppendLabel(LabelContainer, q.ContainerName)
+	timeSeries.AppendLabel("__name__", q.MetricName)
+	for key, samples := range o.series {
+		if key.metricName != q.MetricName || key.namespace != q.Namespace || key.container != q.ContainerName ||
+			!podNameRegexp.MatchString(key.pod) {
+			continue
+		}
+
+		// samples are sorted, so only those in the range are copied out and shifted to the replayed time
+		begin := sort.Search(len(samples), func(i int) bool { return samples[i].Timestamp >= start.Add(-o.offset).Unix() })
+		stop := sort.Search(len(samples), func(i int) bool { return samples[i].Timestamp > end.Add(-o.offset).Unix() })
+		if begin >= stop {
+			continue
+		}
+		inRange := make([]datasourcetypes.Sample, stop-begin)
+		for i, sample := range samples[begin:stop] {
+			inRange[i] = datasourcetypes.Sample{
+				Timestamp: time.Unix(sample.Timestamp, 0).Add(o.offset).Unix(),
+				Value:     sample.Value,
+			}
+		}
+
+		for _, sample := range datasourcetypes.DownsampleSamples(inRange, step) {
+			timeSeries.AppendSample(sample.Timestamp, sample.Value)
+		}
+	}
+	return timeSeries, nil
+}
@@ -0,0 +1,199 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package offline
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+
+	datasourcetypes "github.com/kubewharf/katalyst-core/pkg/util/resource-recommend/types/datasource"
+)
+
+const testCSVSnapshot = `timestamp,namespace,pod,container,metric,value
+1700000000,ns1,workload1-abc12-x1y2z,c1,container_memory_working_set_bytes,100
+1700000030,ns1,workload1-abc12-x1y2z,c1,container_memory_working_set_bytes,110
+1700000060,ns1,workload1-abc12-x1y2z,c1,container_memory_working_set_bytes,120
+1700000060,ns1,workload2-abc12-x1y2z,c1,container_memory_working_set_bytes,999
+1700000060,ns1,workload1-abc12-x1y2z,c2,container_memory_working_set_bytes,999
+`
+
+const testOpenMetricsSnapshot = `# HELP container_cpu_usage cpu usage in cores
+# TYPE container_cpu_usage gauge
+container_cpu_usage{namespace="ns1",pod="workload1-abc12-x1y2z",container="c1"} 1.5 1700000000
+container_cpu_usage{namespace="ns1", pod="workload1-abc12-x1y2z", container="c1"} 2.5 1700000060000
+container_cpu_usage{namespace="ns1",pod="workload1-def34-a1b2c",container="c1",note="a \"quoted\" value"} 3 1700000120
+# EOF
+`
+
+func writeSnapshots(t *testing.T) string {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "memory.csv"), []byte(testCSVSnapshot), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "cpu.om"), []byte(testOpenMetricsSnapshot), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("not a snapshot"), 0o644))
+	return dir
+}
+
+func TestOfflineQueryTimeSeries(t *testing.T) {
+	t.Parallel()
+
+	ds, err := NewOffline(writeSnapshots(t), "")
+	assert.NoError(t, err)
+
+	start, end := time.Unix(1700000000, 0), time.Unix(1700000200, 0)
+	for _, tc := range []struct {
+		name          string
+		resource      v1.ResourceName
+		step          time.Duration
+		expectSamples []datasourcetypes.Sample
+	}{
+		{
+			name:     "memory from csv",
+			resource: v1.ResourceMemory,
+			step:     time.Second,
+			expectSamples: []datasourcetypes.Sample{
+				{Timestamp: 1700000000, Value: 100},
+				{Timestamp: 1700000030, Value: 110},
+				{Timestamp: 1700000060, Value: 120},
+			},
+		},
+		{
+			name:     "memory downsampled by step",
+			resource: v1.ResourceMemory,
+			step:     time.Minute,
+			expectSamples: []datasourcetypes.Sample{
+				{Timestamp: 1700000000, Value: 100},
+				{Timestamp: 1700000060, Value: 120},
+			},
+		},
+		{
+			name:     "cpu from open metrics",
+			resource: v1.ResourceCPU,
+			step:     time.Second,
+			expectSamples: []datasourcetypes.Sample{
+				{Timestamp: 1700000000, Value: 1.5},
+				{Timestamp: 1700000060, Value: 2.5},
+				{Timestamp: 1700000120, Value: 3},
+			},
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			query, err := ds.ConvertMetricToQuery(datasourcetypes.Metric{
+				Namespace:     "ns1",
+				Kind:          string(datasourcetypes.WorkloadDeployment),
+				WorkloadName:  "workload1",
+				ContainerName: "c1",
+				Resource:      tc.resource,
+			})
+			assert.NoError(t, err)
+
+			ts, err := ds.QueryTimeSeries(query, start, end, tc.step)
+			assert.NoError(t, err)
+			assert.ElementsMatch(t, tc.expectSamples, ts.Samples)
+		})
+	}
+
+	ts, err := ds.QueryTimeSeries(&datasourcetypes.Query{Offline: &datasourcetypes.OfflineQuery{
+		MetricName:     MetricNameContainerMemoryUsage,
+		Namespace:      "ns1",
+		PodNamePattern: "^workload1-.*",
+		ContainerName:  "c1",
+	}}, time.Unix(1700000010, 0), time.Unix(1700000050, 0), time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, []datasourcetypes.Sample{{Timestamp: 1700000030, Value: 110}}, ts.Samples)
+}
+
+func TestNewOfflineInvalidSnapshot(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{name: "missing csv column", file: "a.csv", content: "timestamp,namespace,pod,metric,value\n"},
+		{name: "invalid csv value", file: "a.csv", content: "timestamp,namespace,pod,container,metric,value\n1,ns,p,c,m,x\n"},
+		{name: "sample without timestamp", file: "a.prom", content: "m{pod=\"p\"} 1\n"},
+		{name: "unterminated label", file: "a.prom", content: "m{pod=\"p} 1 1\n"},
+	} {
+		dir := t.TempDir()
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, tc.file), []byte(tc.content), 0o644))
+		_, err := NewOffline(dir, "")
+		assert.Error(t, err, tc.name)
+	}
+
+	_, err := NewOffline(filepath.Join(t.TempDir(), "not-exist"), "")
+	assert.Error(t, err)
+
+	_, err = NewOffline(writeSnapshots(t), "yesterday")
+	assert.Error(t, err)
+}
+
+func TestOfflineReplayAnchor(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1800000000, 0)
+	query := &datasourcetypes.Query{Offline: &datasourcetypes.OfflineQuery{
+		MetricName:     MetricNameContainerMemoryUsage,
+		Namespace:      "ns1",
+		PodNamePattern: "^workload1-.*",
+		ContainerName:  "c1",
+	}}
+
+	for _, tc := range []struct {
+		name          string
+		anchor        string
+		expectSamples []datasourcetypes.Sample
+	}{
+		{
+			name:   "latest sample of all series replayed as now",
+			anchor: ReplayAnchorLatest,
+			expectSamples: []datasourcetypes.Sample{
+				{Timestamp: 1800000000 - 120, Value: 100},
+				{Timestamp: 1800000000 - 90, Value: 110},
+				{Timestamp: 1800000000 - 60, Value: 120},
+			},
+		},
+		{
+			name:   "anchor time replayed as now",
+			anchor: time.Unix(1700000030, 0).UTC().Format(time.RFC3339),
+			expectSamples: []datasourcetypes.Sample{
+				{Timestamp: 1800000000 - 30, Value: 100},
+				{Timestamp: 1800000000, Value: 110},
+			},
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ds, err := newOffline(writeSnapshots(t), tc.anchor, now)
+			assert.NoError(t, err)
+
+			ts, err := ds.QueryTimeSeries(query, now.Add(-time.Hour), now, time.Second)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectSamples, ts.Samples)
+		})
+	}
+}
@@ -0,0 +1,211 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package offline
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	datasourcetypes "github.com/kubewharf/katalyst-core/pkg/util/resource-recommend/types/datasource"
+)
+
+// columns required in csv snapshots
+const (
+	csvColumnTimestamp = "timestamp"
+	csvColumnNamespace = "namespace"
+	csvColumnPod       = "pod"
+	csvColumnContainer = "container"
+	csvColumnMetric    = "metric"
+	csvColumnValue     = "value"
+)
+
+// timestamps larger than this are regarded as milliseconds, which is used by
+// prometheus text format, while OpenMetrics and csv snapshots use seconds
+const millisecondTimestampThreshold = 1e11
+
+type record struct {
+	key    seriesKey
+	sample datasourcetypes.Sample
+}
+
+// parseCSV parses csv snapshots with a header line naming the columns,
+// e.g. timestamp,namespace,pod,container,metric,value
+func parseCSV(content string) ([]record, error) {
+	reader := csv.NewReader(strings.NewReader(content))
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read csv header failed: %v", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{csvColumnTimestamp, csvColumnNamespace, csvColumnPod, csvColumnContainer, csvColumnMetric, csvColumnValue} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("csv column %v is missing", name)
+		}
+	}
+
+	var records []record
+	for line := 2; ; line++ {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("line %v: %v", line, err)
+		}
+
+		timestamp, err := parseTimestamp(fields[columns[csvColumnTimestamp]])
+		if err != nil {
+			return nil, fmt.Errorf("line %v: %v", line, err)
+		}
+		value, err := strconv.ParseFloat(fields[columns[csvColumnValue]], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %v: invalid value: %v", line, err)
+		}
+
+		records = append(records, record{
+			key: seriesKey{
+				metricName: fields[columns[csvColumnMetric]],
+				namespace:  fields[columns[csvColumnNamespace]],
+				pod:        fields[columns[csvColumnPod]],
+				container:  fields[columns[csvColumnContainer]],
+			},
+			sample: datasourcetypes.Sample{Value: value, Timestamp: timestamp},
+		})
+	}
+	return records, nil
+}
+
+// parseOpenMetrics parses samples in OpenMetrics (or prometheus) text format, e.g.
+// container_memory_working_set_bytes{namespace="ns",pod="p",container="c"} 1024 1700000000;
+// comments and metadata lines are skipped, and each sample must carry a timestamp.
+func parseOpenMetrics(content string) ([]record, error) {
+	var records []record
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		r, err := parseOpenMetricsLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %v: %v", i+1, err)
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+func parseOpenMetricsLine(line string) (record, error) {
+	nameEnd := strings.IndexAny(line, "{ \t")
+	if nameEnd <= 0 {
+		return record{}, fmt.Errorf("invalid sample %q", line)
+	}
+	name, rest := line[:nameEnd], line[nameEnd:]
+
+	labels := make(map[string]string)
+	if strings.HasPrefix(rest, "{") {
+		var err error
+		labels, rest, err = parseLabels(rest[1:])
+		if err != nil {
+			return record{}, err
+		}
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) < 2 {
+		return record{}, fmt.Errorf("sample %q has no timestamp", line)
+	}
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return record{}, fmt.Errorf("invalid value: %v", err)
+	}
+	timestamp, err := parseTimestamp(fields[1])
+	if err != nil {
+		return record{}, err
+	}
+
+	return record{
+		key: seriesKey{
+			metricName: name,
+			namespace:  labels[LabelNamespace],
+			pod:        labels[LabelPod],
+			container:  labels[LabelContainer],
+		},
+		sample: datasourcetypes.Sample{Value: value, Timestamp: timestamp},
+	}, nil
+}
+
+// parseLabels parses label pairs until the closing brace, and returns the remaining string after it
+func parseLabels(s string) (map[string]string, string, error) {
+	labels := make(map[string]string)
+	for {
+		s = strings.TrimLeft(s, " \t,")
+		if strings.HasPrefix(s, "}") {
+			return labels, s[1:], nil
+		}
+
+		eq := strings.Index(s, "=")
+		if eq <= 0 {
+			return nil, "", fmt.Errorf("invalid labels %q", s)
+		}
+		key := strings.TrimSpace(s[:eq])
+		s = strings.TrimLeft(s[eq+1:], " \t")
+		if !strings.HasPrefix(s, "\"") {
+			return nil, "", fmt.Errorf("label %v is not quoted", key)
+		}
+
+		var value strings.Builder
+		i := 1
+		for ; i < len(s) && s[i] != '"'; i++ {
+			if s[i] == '\\' && i+1 < len(s) {
+				i++
+				switch s[i] {
+				case 'n':
+					value.WriteByte('\n')
+				default:
+					value.WriteByte(s[i])
+				}
+				continue
+			}
+			value.WriteByte(s[i])
+		}
+		if i >= len(s) {
+			return nil, "", fmt.Errorf("label %v is not terminated", key)
+		}
+		labels[key] = value.String()
+		s = s[i+1:]
+	}
+}
+
+// parseTimestamp parses timestamps in seconds or milliseconds, and returns seconds
+func parseTimestamp(s string) (int64, error) {
+	timestamp, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timestamp: %v", err)
+	}
+	if timestamp > millisecondTimestampThreshold {
+		timestamp /= 1000
+	}
+	return int64(timestamp), nil
+}
@@ -30,7 +30,7 @@ const (
 )
 
 const (
-	WorkloadSuffixRuleForDeployment = `[a-z0-9]+-[a-z0-9]{5}$`
+	WorkloadSuffixRuleForDeployment = datasourcetypes.WorkloadSuffixRuleForDeployment
 )
 
 func GetContainerCpuUsageQueryExp(namespace string, workloadName string, kind string, containerName string, extraFilters string) string {
@@ -50,11 +50,7 @@ func GetContainerMemUsageQueryExp(namespace string, workloadName string, kind st
 }
 
 func convertWorkloadNameToPods(workloadName string, workloadKind string) string {
-	switch workloadKind {
-	case string(datasourcetypes.WorkloadDeployment):
-		return fmt.Sprintf("^%s-%s", workloadName, WorkloadSuffixRuleForDeployment)
-	}
-	return fmt.Sprintf("^%s-%s", workloadName, `.*`)
+	return datasourcetypes.GetWorkloadPodNamePattern(workloadName, workloadKind)
 }
 
 func GetExtraFilters(extraFilters string, baseFilter string) string {
@@ -0,0 +1,182 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package decaying
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/recommendation/v1alpha1"
+	"github.com/kubewharf/katalyst-core/pkg/controller/resource-recommend/processor/percentile/task"
+	datasourcetypes "github.com/kubewharf/katalyst-core/pkg/util/resource-recommend/types/datasource"
+)
+
+const (
+	// ConfigMapCheckpointNameSuffix is the name suffix of ConfigMaps persisting histograms,
+	// each ResourceRecommend has its own ConfigMap in the same namespace.
+	ConfigMapCheckpointNameSuffix = "-histogram-checkpoint"
+
+	resourceRecommendKind = "ResourceRecommend"
+)
+
+// Checkpointer persists histograms of tasks, so that the decaying processor
+// doesn't need to wait for another full window of samples after restarts.
+type Checkpointer interface {
+	// Load returns the checkpoint of the given metric, and nil is returned if not found.
+	Load(namespacedName types.NamespacedName, metric datasourcetypes.Metric) (*task.HistogramTaskCheckpoint, error)
+	// Save replaces all checkpoints of the given ResourceRecommend, and checkpoints are
+	// owned by the ResourceRecommend so that they are garbage collected along with it.
+	// Checkpoints owned by others, e.g. a deleted ResourceRecommend with the same name,
+	// are never overwritten.
+	Save(resourceRecommend *v1alpha1.ResourceRecommend, checkpoints map[datasourcetypes.Metric]*task.HistogramTaskCheckpoint) error
+	// Delete removes all checkpoints of the given ResourceRecommend.
+	Delete(namespacedName types.NamespacedName) error
+}
+
+// metricCheckpoint is the record stored in ConfigMaps, workload info is kept to
+// avoid restoring histograms of another workload with the same container name.
+type metricCheckpoint struct {
+	Kind         string                        `json:"kind"`
+	APIVersion   string                        `json:"apiVersion"`
+	WorkloadName string                        `json:"workloadName"`
+	Checkpoint   *task.HistogramTaskCheckpoint `json:"checkpoint"`
+}
+
+type ConfigMapCheckpointer struct {
+	Client corev1.CoreV1Interface
+}
+
+func NewConfigMapCheckpointer(client corev1.CoreV1Interface) *ConfigMapCheckpointer {
+	return &ConfigMapCheckpointer{Client: client}
+}
+
+func GetCheckpointConfigMapName(namespacedName types.NamespacedName) string {
+	return namespacedName.Name + ConfigMapCheckpointNameSuffix
+}
+
+func getCheckpointKey(metric datasourcetypes.Metric) string {
+	return fmt.Sprintf("%s.%s", metric.ContainerName, metric.Resource)
+}
+
+func (c *ConfigMapCheckpointer) Load(namespacedName types.NamespacedName, metric datasourcetypes.Metric) (*task.HistogramTaskCheckpoint, error) {
+	cm, err := c.Client.ConfigMaps(namespacedName.Namespace).
+		Get(context.TODO(), GetCheckpointConfigMapName(namespacedName), metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "get checkpoint configmap failed")
+	}
+
+	data, ok := cm.Data[getCheckpointKey(metric)]
+	if !ok {
+		return nil, nil
+	}
+
+	record := &metricCheckpoint{}
+	if err := json.Unmarshal([]byte(data), record); err != nil {
+		return nil, errors.Wrapf(err, "unmarshal checkpoint of %s failed", getCheckpointKey(metric))
+	}
+	if record.Kind != metric.Kind || record.APIVersion != metric.APIVersion || record.WorkloadName != metric.WorkloadName {
+		klog.V(4).InfoS("checkpoint belongs to another workload, skip it", "ResourceRecommend", namespacedName,
+			"key", getCheckpointKey(metric), "checkpointWorkload", record.WorkloadName, "workload", metric.WorkloadName)
+		return nil, nil
+	}
+	return record.Checkpoint, nil
+}
+
+func (c *ConfigMapCheckpointer) Save(resourceRecommend *v1alpha1.ResourceRecommend, checkpoints map[datasourcetypes.Metric]*task.HistogramTaskCheckpoint) error {
+	namespacedName := types.NamespacedName{Namespace: resourceRecommend.Namespace, Name: resourceRecommend.Name}
+	ownerReferences := []metav1.OwnerReference{generateOwnerReference(resourceRecommend)}
+
+	data := make(map[string]string, len(checkpoints))
+	for metric, checkpoint := range checkpoints {
+		record, err := json.Marshal(&metricCheckpoint{
+			Kind:         metric.Kind,
+			APIVersion:   metric.APIVersion,
+			WorkloadName: metric.WorkloadName,
+			Checkpoint:   checkpoint,
+		})
+		if err != nil {
+			return errors.Wrapf(err, "marshal checkpoint of %s failed", getCheckpointKey(metric))
+		}
+		data[getCheckpointKey(metric)] = string(record)
+	}
+
+	name := GetCheckpointConfigMapName(namespacedName)
+	cm, err := c.Client.ConfigMaps(namespacedName.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return errors.Wrap(err, "get checkpoint configmap failed")
+		}
+		cm = &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				Namespace:       namespacedName.Namespace,
+				OwnerReferences: ownerReferences,
+			},
+			Data: data,
+		}
+		_, err = c.Client.ConfigMaps(namespacedName.Namespace).Create(context.TODO(), cm, metav1.CreateOptions{})
+		return err
+	}
+
+	if !isOwnedBy(cm, resourceRecommend) {
+		return fmt.Errorf("checkpoint configmap %s/%s is not owned by ResourceRecommend %s", cm.Namespace, cm.Name, resourceRecommend.GetUID())
+	}
+	cm.Data = data
+	cm.OwnerReferences = ownerReferences
+	_, err = c.Client.ConfigMaps(namespacedName.Namespace).Update(context.TODO(), cm, metav1.UpdateOptions{})
+	return err
+}
+
+func (c *ConfigMapCheckpointer) Delete(namespacedName types.NamespacedName) error {
+	err := c.Client.ConfigMaps(namespacedName.Namespace).
+		Delete(context.TODO(), GetCheckpointConfigMapName(namespacedName), metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// isOwnedBy checks whether the configmap is owned by the given ResourceRecommend
+func isOwnedBy(cm *v1.ConfigMap, resourceRecommend *v1alpha1.ResourceRecommend) bool {
+	for _, ownerReference := range cm.OwnerReferences {
+		if ownerReference.UID == resourceRecommend.GetUID() {
+			return true
+		}
+	}
+	return false
+}
+
+func generateOwnerReference(resourceRecommend *v1alpha1.ResourceRecommend) metav1.OwnerReference {
+	return metav1.OwnerReference{
+		APIVersion: v1alpha1.SchemeGroupVersion.String(),
+		Kind:       resourceRecommendKind,
+		Name:       resourceRecommend.GetName(),
+		UID:        resourceRecommend.GetUID(),
+	}
+}
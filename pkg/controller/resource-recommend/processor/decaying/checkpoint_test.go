@@ -0,0 +1,155 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package decaying
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/recommendation/v1alpha1"
+	"github.com/kubewharf/katalyst-core/pkg/controller/resource-recommend/processor/percentile/task"
+	datasourcetypes "github.com/kubewharf/katalyst-core/pkg/util/resource-recommend/types/datasource"
+)
+
+var (
+	testNamespacedName = types.NamespacedName{Namespace: "default", Name: "rec1"}
+
+	testResourceRecommend = &v1alpha1.ResourceRecommend{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "rec1", UID: "rec1-uid"},
+	}
+
+	testCPUMetric = datasourcetypes.Metric{
+		Namespace:     "default",
+		Kind:          "Deployment",
+		APIVersion:    "apps/v1",
+		WorkloadName:  "demo",
+		ContainerName: "c1",
+		Resource:      v1.ResourceCPU,
+	}
+)
+
+func newTestCheckpoint(t *testing.T, metric datasourcetypes.Metric) *task.HistogramTaskCheckpoint {
+	histogramTask, err := task.NewTask(metric, "")
+	assert.NoError(t, err)
+
+	now := time.Now()
+	for i := 0; i < 30; i++ {
+		histogramTask.AddSample(now.Add(-time.Duration(30-i)*time.Hour), float64(i%3+1), task.DefaultSampleWeight)
+	}
+	checkpoint, err := histogramTask.SaveToCheckpoint()
+	assert.NoError(t, err)
+	return checkpoint
+}
+
+func TestConfigMapCheckpointer(t *testing.T) {
+	t.Parallel()
+
+	client := fake.NewSimpleClientset()
+	checkpointer := NewConfigMapCheckpointer(client.CoreV1())
+
+	checkpoint, err := checkpointer.Load(testNamespacedName, testCPUMetric)
+	assert.NoError(t, err)
+	assert.Nil(t, checkpoint, "nothing should be loaded before saving")
+	assert.NoError(t, checkpointer.Delete(testNamespacedName), "deleting non-existing checkpoint should succeed")
+
+	expected := newTestCheckpoint(t, testCPUMetric)
+	assert.NoError(t, checkpointer.Save(testResourceRecommend, map[datasourcetypes.Metric]*task.HistogramTaskCheckpoint{
+		testCPUMetric: expected,
+	}))
+	cm, err := client.CoreV1().ConfigMaps(testNamespacedName.Namespace).
+		Get(context.TODO(), "rec1"+ConfigMapCheckpointNameSuffix, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Contains(t, cm.Data, "c1.cpu")
+	assert.Equal(t, []metav1.OwnerReference{{
+		APIVersion: v1alpha1.SchemeGroupVersion.String(),
+		Kind:       "ResourceRecommend",
+		Name:       "rec1",
+		UID:        "rec1-uid",
+	}}, cm.OwnerReferences)
+
+	checkpoint, err = checkpointer.Load(testNamespacedName, testCPUMetric)
+	assert.NoError(t, err)
+	assert.NotNil(t, checkpoint)
+	assert.Equal(t, expected.TotalSamplesCount, checkpoint.TotalSamplesCount)
+	assert.True(t, expected.LastSampleTime.Equal(checkpoint.LastSampleTime))
+
+	// checkpoints of another workload with the same container should be ignored
+	anotherWorkload := testCPUMetric
+	anotherWorkload.WorkloadName = "another"
+	checkpoint, err = checkpointer.Load(testNamespacedName, anotherWorkload)
+	assert.NoError(t, err)
+	assert.Nil(t, checkpoint)
+
+	// saving again should replace all existing checkpoints
+	memMetric := testCPUMetric
+	memMetric.Resource = v1.ResourceMemory
+	assert.NoError(t, checkpointer.Save(testResourceRecommend, map[datasourcetypes.Metric]*task.HistogramTaskCheckpoint{
+		memMetric: newTestCheckpoint(t, memMetric),
+	}))
+	checkpoint, err = checkpointer.Load(testNamespacedName, testCPUMetric)
+	assert.NoError(t, err)
+	assert.Nil(t, checkpoint)
+	checkpoint, err = checkpointer.Load(testNamespacedName, memMetric)
+	assert.NoError(t, err)
+	assert.NotNil(t, checkpoint)
+
+	assert.NoError(t, checkpointer.Delete(testNamespacedName))
+	checkpoint, err = checkpointer.Load(testNamespacedName, memMetric)
+	assert.NoError(t, err)
+	assert.Nil(t, checkpoint)
+}
+
+func TestConfigMapCheckpointer_SaveOwnership(t *testing.T) {
+	t.Parallel()
+
+	existing := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "rec1" + ConfigMapCheckpointNameSuffix,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: v1alpha1.SchemeGroupVersion.String(),
+				Kind:       "ResourceRecommend",
+				Name:       "rec1",
+				UID:        "stale-uid",
+			}},
+		},
+		Data: map[string]string{"foo": "bar"},
+	}
+	unowned := existing.DeepCopy()
+	unowned.OwnerReferences = nil
+
+	for _, cm := range []*v1.ConfigMap{existing, unowned} {
+		client := fake.NewSimpleClientset(cm)
+		checkpointer := NewConfigMapCheckpointer(client.CoreV1())
+
+		// configmaps owned by others should not be overwritten
+		assert.Error(t, checkpointer.Save(testResourceRecommend, map[datasourcetypes.Metric]*task.HistogramTaskCheckpoint{
+			testCPUMetric: newTestCheckpoint(t, testCPUMetric),
+		}))
+		got, err := client.CoreV1().ConfigMaps("default").Get(context.TODO(), cm.Name, metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, cm.OwnerReferences, got.OwnerReferences)
+		assert.Equal(t, map[string]string{"foo": "bar"}, got.Data)
+	}
+}
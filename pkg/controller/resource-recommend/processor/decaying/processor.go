@@ -0,0 +1,278 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package decaying
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/recommendation/v1alpha1"
+	lister "github.com/kubewharf/katalyst-api/pkg/client/listers/recommendation/v1alpha1"
+	"github.com/kubewharf/katalyst-core/pkg/controller/resource-recommend/datasource"
+	"github.com/kubewharf/katalyst-core/pkg/controller/resource-recommend/processor"
+	"github.com/kubewharf/katalyst-core/pkg/controller/resource-recommend/processor/percentile"
+	"github.com/kubewharf/katalyst-core/pkg/controller/resource-recommend/processor/percentile/task"
+	"github.com/kubewharf/katalyst-core/pkg/util/resource-recommend/log"
+	datasourcetypes "github.com/kubewharf/katalyst-core/pkg/util/resource-recommend/types/datasource"
+	errortypes "github.com/kubewharf/katalyst-core/pkg/util/resource-recommend/types/error"
+	processortypes "github.com/kubewharf/katalyst-core/pkg/util/resource-recommend/types/processor"
+	recommendationtypes "github.com/kubewharf/katalyst-core/pkg/util/resource-recommend/types/recommendation"
+)
+
+const (
+	ProcessorName = "decaying"
+
+	AlgorithmDecaying = v1alpha1.Algorithm(recommendationtypes.DecayingAlgorithmType)
+
+	DefaultCheckpointInterval = 10 * time.Minute
+)
+
+// Config is the configuration of decaying processor, and DecayHalfLife can be
+// overridden by decayHalfLife (in hours) in extensions of the algorithm policy.
+type Config struct {
+	DecayHalfLife      time.Duration
+	Percentile         float64
+	CheckpointInterval time.Duration
+}
+
+// Processor aggregates samples into exponentially decaying histograms like the
+// percentile processor, but with configurable half-life and percentile, and
+// histograms are checkpointed periodically to survive controller restarts.
+type Processor struct {
+	*percentile.Processor
+
+	config       Config
+	checkpointer Checkpointer
+
+	mutex sync.Mutex
+	// registeredMetrics records metrics of registered tasks for each ResourceRecommend,
+	// and they are used to save and clean up checkpoints.
+	registeredMetrics map[types.NamespacedName]map[datasourcetypes.Metric]struct{}
+}
+
+var defaultQueueRateLimiter = workqueue.NewMaxOfRateLimiter(
+	workqueue.NewItemExponentialFailureRateLimiter(percentile.ExceptionRequeueBaseDelay, percentile.ExceptionRequeueMaxDelay),
+	// 10 qps, 100 bucket size.  This is only for retry speed and its only the overall factor (not per item)
+	&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(10), 100)},
+)
+
+func NewProcessor(datasourceProxy *datasource.Proxy, lister lister.ResourceRecommendLister,
+	checkpointer Checkpointer, config Config,
+) processor.Processor {
+	if config.DecayHalfLife <= 0 {
+		config.DecayHalfLife = task.DefaultHistogramDecayHalfLife
+	}
+	if config.Percentile <= 0 || config.Percentile > 1 {
+		config.Percentile = percentile.DefaultPercentile
+	}
+	if config.CheckpointInterval <= 0 {
+		config.CheckpointInterval = DefaultCheckpointInterval
+	}
+
+	return &Processor{
+		Processor: &percentile.Processor{
+			DatasourceProxy:             datasourceProxy,
+			TaskQueue:                   workqueue.NewNamedRateLimitingQueue(defaultQueueRateLimiter, ProcessorName),
+			Lister:                      lister,
+			AggregateTasks:              &sync.Map{},
+			ResourceRecommendTaskIDsMap: make(map[types.NamespacedName]*map[datasourcetypes.Metric]processortypes.TaskID),
+		},
+		config:            config,
+		checkpointer:      checkpointer,
+		registeredMetrics: make(map[types.NamespacedName]map[datasourcetypes.Metric]struct{}),
+	}
+}
+
+func NewContext() context.Context {
+	return log.SetKeysAndValues(log.InitContext(context.Background()), "processor", ProcessorName)
+}
+
+func (p *Processor) Register(processConfig *processortypes.ProcessConfig) *errortypes.CustomError {
+	if cErr := p.Processor.RegisterWithTaskFactory(processConfig, p.newTask); cErr != nil {
+		return cErr
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	metrics, ok := p.registeredMetrics[processConfig.ResourceRecommendNamespacedName]
+	if !ok {
+		metrics = make(map[datasourcetypes.Metric]struct{})
+		p.registeredMetrics[processConfig.ResourceRecommendNamespacedName] = metrics
+	}
+	metrics[*processConfig.Metric] = struct{}{}
+	return nil
+}
+
+// newTask creates the histogram task with configured half-life, and restores it from
+// checkpoint if any; the task starts from scratch if the checkpoint can't be loaded.
+func (p *Processor) newTask(processConfig *processortypes.ProcessConfig) (*task.HistogramTask, error) {
+	taskConfig, err := task.GetTaskConfigWithDefault(processConfig.Config, p.config.DecayHalfLife)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get process config failed, config: %s", processConfig.Config)
+	}
+	t, err := task.NewTaskWithConfig(*processConfig.Metric, taskConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	checkpoint, err := p.checkpointer.Load(processConfig.ResourceRecommendNamespacedName, *processConfig.Metric)
+	if err != nil {
+		klog.ErrorS(err, "load histogram checkpoint failed", "processConfig", processConfig.ProcessKey)
+		return t, nil
+	}
+	if checkpoint != nil {
+		if err := t.LoadFromCheckpoint(checkpoint, time.Now()); err != nil {
+			klog.ErrorS(err, "restore task from histogram checkpoint failed", "processConfig", processConfig.ProcessKey)
+			return task.NewTaskWithConfig(*processConfig.Metric, taskConfig)
+		}
+		klog.InfoS("task restored from histogram checkpoint", "processConfig", processConfig.ProcessKey,
+			"lastSampleTime", checkpoint.LastSampleTime, "totalSamplesCount", checkpoint.TotalSamplesCount)
+	}
+	return t, nil
+}
+
+// Cancel cancels tasks like the percentile processor, and checkpoints are deleted
+// once all tasks of the ResourceRecommend are cancelled.
+func (p *Processor) Cancel(processKey *processortypes.ProcessKey) *errortypes.CustomError {
+	cErr := p.Processor.Cancel(processKey)
+	if processKey == nil {
+		return cErr
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	// tasks may have been cleared by garbage collection of percentile processor,
+	// so checkpoints are cleaned up regardless of the result of cancellation
+	namespacedName := processKey.ResourceRecommendNamespacedName
+	metrics, ok := p.registeredMetrics[namespacedName]
+	if !ok {
+		return cErr
+	}
+	if processKey.Metric != nil {
+		delete(metrics, *processKey.Metric)
+		if len(metrics) > 0 {
+			return cErr
+		}
+	}
+	delete(p.registeredMetrics, namespacedName)
+
+	if err := p.checkpointer.Delete(namespacedName); err != nil {
+		klog.ErrorS(err, "delete histogram checkpoint failed", "ResourceRecommend", namespacedName)
+	}
+	return cErr
+}
+
+func (p *Processor) Run(ctx context.Context) {
+	log.InfoS(ctx, "decaying processor starting")
+
+	// Get task from queue and run it
+	go p.ProcessTasks(ctx)
+
+	// Garbage collect every hour. Clearing timeout or no attribution task
+	go p.GarbageCollector(ctx)
+	go wait.Until(p.cleanupCheckpoints, percentile.DefaultGarbageCollectInterval, ctx.Done())
+
+	go wait.Until(p.saveCheckpoints, p.config.CheckpointInterval, ctx.Done())
+
+	log.InfoS(ctx, "decaying processor running")
+
+	<-ctx.Done()
+
+	// save histograms before exiting to lose as few samples as possible
+	p.saveCheckpoints()
+
+	log.InfoS(ctx, "decaying processor end")
+}
+
+func (p *Processor) QueryProcessedValues(processKey *processortypes.ProcessKey) (float64, error) {
+	t, err := p.GetTask(processKey)
+	if err != nil {
+		return 0, errors.Wrapf(err, "internal err, process task not found")
+	}
+	return t.QueryPercentileValue(NewContext(), p.config.Percentile)
+}
+
+func (p *Processor) listRegisteredMetrics() map[types.NamespacedName][]datasourcetypes.Metric {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	registered := make(map[types.NamespacedName][]datasourcetypes.Metric, len(p.registeredMetrics))
+	for namespacedName, metrics := range p.registeredMetrics {
+		for metric := range metrics {
+			registered[namespacedName] = append(registered[namespacedName], metric)
+		}
+	}
+	return registered
+}
+
+func (p *Processor) saveCheckpoints() {
+	for namespacedName, metrics := range p.listRegisteredMetrics() {
+		// checkpoints are not saved without the owner, and they will be cleaned up
+		// if the ResourceRecommend is deleted
+		resourceRecommend, err := p.Lister.ResourceRecommends(namespacedName.Namespace).Get(namespacedName.Name)
+		if err != nil {
+			klog.V(4).InfoS("skip saving histogram checkpoint of ResourceRecommend not found", "ResourceRecommend", namespacedName, "err", err)
+			continue
+		}
+
+		checkpoints := make(map[datasourcetypes.Metric]*task.HistogramTaskCheckpoint, len(metrics))
+		for i := range metrics {
+			t, err := p.GetTask(&processortypes.ProcessKey{ResourceRecommendNamespacedName: namespacedName, Metric: &metrics[i]})
+			if err != nil {
+				continue
+			}
+			checkpoint, err := t.SaveToCheckpoint()
+			if err != nil {
+				klog.ErrorS(err, "save task to histogram checkpoint failed", "ResourceRecommend", namespacedName, "metric", metrics[i])
+				continue
+			}
+			if checkpoint != nil {
+				checkpoints[metrics[i]] = checkpoint
+			}
+		}
+		if len(checkpoints) == 0 {
+			continue
+		}
+
+		if err := p.checkpointer.Save(resourceRecommend, checkpoints); err != nil {
+			klog.ErrorS(err, "save histogram checkpoint failed", "ResourceRecommend", namespacedName)
+		}
+	}
+}
+
+// cleanupCheckpoints cancels tasks of ResourceRecommends that no longer exist or no
+// longer use the decaying algorithm, so that their checkpoints are deleted as well.
+func (p *Processor) cleanupCheckpoints() {
+	for namespacedName := range p.listRegisteredMetrics() {
+		resourceRecommend, err := p.Lister.ResourceRecommends(namespacedName.Namespace).Get(namespacedName.Name)
+		if err == nil && resourceRecommend.Spec.ResourcePolicy.AlgorithmPolicy.Algorithm == AlgorithmDecaying {
+			continue
+		}
+		klog.InfoS("cancel decaying tasks of ResourceRecommend not using decaying algorithm", "ResourceRecommend", namespacedName)
+		_ = p.Cancel(&processortypes.ProcessKey{ResourceRecommendNamespacedName: namespacedName})
+	}
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package decaying
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/kubernetes/fake"
+
+	katalystbase "github.com/kubewharf/katalyst-core/cmd/base"
+	"github.com/kubewharf/katalyst-core/pkg/controller/resource-recommend/processor/percentile/task"
+	datasourcetypes "github.com/kubewharf/katalyst-core/pkg/util/resource-recommend/types/datasource"
+	processortypes "github.com/kubewharf/katalyst-core/pkg/util/resource-recommend/types/processor"
+)
+
+func TestProcessor(t *testing.T) {
+	t.Parallel()
+
+	controlCtx, err := katalystbase.GenerateFakeGenericContext()
+	assert.NoError(t, err)
+	checkpointer := NewConfigMapCheckpointer(fake.NewSimpleClientset().CoreV1())
+	assert.NoError(t, checkpointer.Save(testResourceRecommend, map[datasourcetypes.Metric]*task.HistogramTaskCheckpoint{
+		testCPUMetric: newTestCheckpoint(t, testCPUMetric),
+	}))
+
+	informer := controlCtx.InternalInformerFactory.Recommendation().V1alpha1().ResourceRecommends()
+	p := NewProcessor(nil, informer.Lister(), checkpointer, Config{DecayHalfLife: time.Hour, Percentile: 0.5}).(*Processor)
+	processConfig := &processortypes.ProcessConfig{
+		ProcessKey: processortypes.ProcessKey{
+			ResourceRecommendNamespacedName: testNamespacedName,
+			Metric:                          &testCPUMetric,
+		},
+	}
+
+	// the registered task should be restored from checkpoint, so it's ready right away
+	assert.Nil(t, p.Register(processConfig))
+	value, err := p.QueryProcessedValues(&processConfig.ProcessKey)
+	assert.NoError(t, err)
+	assert.InDelta(t, 2, value, 0.2)
+
+	// checkpoints are only saved for registered tasks of existing ResourceRecommends
+	assert.NoError(t, checkpointer.Delete(testNamespacedName))
+	p.saveCheckpoints()
+	checkpoint, err := checkpointer.Load(testNamespacedName, testCPUMetric)
+	assert.NoError(t, err)
+	assert.Nil(t, checkpoint)
+
+	assert.NoError(t, informer.Informer().GetStore().Add(testResourceRecommend))
+	p.saveCheckpoints()
+	checkpoint, err = checkpointer.Load(testNamespacedName, testCPUMetric)
+	assert.NoError(t, err)
+	assert.NotNil(t, checkpoint)
+
+	// the ResourceRecommend doesn't use decaying algorithm, so its tasks and checkpoints should be cleaned up
+	p.cleanupCheckpoints()
+	_, err = p.QueryProcessedValues(&processConfig.ProcessKey)
+	assert.Error(t, err)
+	checkpoint, err = checkpointer.Load(testNamespacedName, testCPUMetric)
+	assert.NoError(t, err)
+	assert.Nil(t, checkpoint)
+	assert.Empty(t, p.listRegisteredMetrics())
+}
@@ -43,7 +43,6 @@ func NewManager(datasourceProxy *datasource.Proxy, lister lister.ResourceRecomme
 }
 
 func (m *Manager) ProcessorRegister(algorithm v1alpha1.Algorithm, registerProcessor processor.Processor) {
-	m.processors = make(map[v1alpha1.Algorithm]processor.Processor)
 	m.processors[algorithm] = registerProcessor
 }
 
@@ -71,6 +70,11 @@ func (m *Manager) StartProcess(ctx context.Context) {
 	log.InfoS(ctx, "ProcessorManager stopped, all Processor end")
 }
 
+// ListProcessors returns all registered processors keyed by algorithm
+func (m *Manager) ListProcessors() map[v1alpha1.Algorithm]processor.Processor {
+	return m.processors
+}
+
 func (m *Manager) GetProcessor(algorithm v1alpha1.Algorithm) processor.Processor {
 	if dataProcessor, ok := m.processors[algorithm]; ok {
 		return dataProcessor
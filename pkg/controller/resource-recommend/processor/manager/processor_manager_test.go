@@ -116,3 +116,16 @@ func TestManager_GetProcessor(t *testing.T) {
 		})
 	}
 }
+
+func TestManager_ProcessorRegister(t *testing.T) {
+	manager := NewManager(nil, nil)
+	mockProcessor1 := &mockProcessor{algorithm: "mockAlgorithm1"}
+	manager.ProcessorRegister(mockProcessor1.algorithm, mockProcessor1)
+
+	if got := manager.GetProcessor(mockProcessor1.algorithm); got != mockProcessor1 {
+		t.Errorf("ProcessorRegister() failed, got %v, want %v", got, mockProcessor1)
+	}
+	if _, ok := manager.ListProcessors()[v1alpha1.AlgorithmPercentile]; !ok {
+		t.Errorf("ProcessorRegister() failed, existing processors should be kept")
+	}
+}
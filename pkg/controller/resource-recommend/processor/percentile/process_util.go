@@ -30,6 +30,14 @@ func NewContext() context.Context {
 	return log.SetKeysAndValues(log.InitContext(context.Background()), "processor", ProcessorName)
 }
 
+// GetTask returns the histogram task registered for the given ProcessKey
+func (p *Processor) GetTask(processKey *processortypes.ProcessKey) (*task.HistogramTask, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.getTaskForProcessKey(processKey)
+}
+
 func (p *Processor) getTaskForProcessKey(processKey *processortypes.ProcessKey) (*task.HistogramTask, error) {
 	if processKey == nil {
 		return nil, errors.Errorf("ProcessKey is nil")
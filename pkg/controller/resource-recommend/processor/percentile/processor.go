@@ -64,6 +64,9 @@ type Processor struct {
 	ResourceRecommendTaskIDsMap map[types.NamespacedName]*map[datasourcetypes.Metric]processortypes.TaskID
 }
 
+// TaskFactory creates the histogram task for the given process config
+type TaskFactory func(processConfig *processortypes.ProcessConfig) (*task.HistogramTask, error)
+
 var DefaultQueueRateLimiter = workqueue.NewMaxOfRateLimiter(
 	workqueue.NewItemExponentialFailureRateLimiter(ExceptionRequeueBaseDelay, ExceptionRequeueMaxDelay),
 	// 10 qps, 100 bucket size.  This is only for retry speed and its only the overall factor (not per item)
@@ -80,7 +83,15 @@ func NewProcessor(datasourceProxy *datasource.Proxy, lister v1alpha1.ResourceRec
 	}
 }
 
-func (p *Processor) Register(processConfig *processortypes.ProcessConfig) (cErr *errortypes.CustomError) {
+func (p *Processor) Register(processConfig *processortypes.ProcessConfig) *errortypes.CustomError {
+	return p.RegisterWithTaskFactory(processConfig, func(processConfig *processortypes.ProcessConfig) (*task.HistogramTask, error) {
+		return task.NewTask(*processConfig.Metric, processConfig.Config)
+	})
+}
+
+// RegisterWithTaskFactory registers the task created by the given factory, so that
+// processors built upon the percentile processor can customize their tasks.
+func (p *Processor) RegisterWithTaskFactory(processConfig *processortypes.ProcessConfig, newTask TaskFactory) (cErr *errortypes.CustomError) {
 	defer func() {
 		if cErr != nil {
 			klog.ErrorS(cErr, "Percentile task register failed", "ResourceRecommend", processConfig.ResourceRecommendNamespacedName)
@@ -112,7 +123,7 @@ func (p *Processor) Register(processConfig *processortypes.ProcessConfig) (cErr
 
 	metric := *processConfig.Metric
 
-	t, err := task.NewTask(metric, processConfig.Config)
+	t, err := newTask(processConfig)
 	if err != nil {
 		cErr := errortypes.NewProcessTaskError(err)
 		return cErr
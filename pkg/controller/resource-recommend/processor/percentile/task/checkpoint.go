@@ -0,0 +1,82 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package task
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	vpatypes "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+)
+
+// HistogramTaskCheckpoint is the persistent state of HistogramTask, so that
+// the history of samples is not lost after controller restarts.
+type HistogramTaskCheckpoint struct {
+	Histogram         *vpatypes.HistogramCheckpoint `json:"histogram"`
+	FirstSampleTime   time.Time                     `json:"firstSampleTime"`
+	LastSampleTime    time.Time                     `json:"lastSampleTime"`
+	TotalSamplesCount int                           `json:"totalSamplesCount"`
+	LastRunTime       time.Time                     `json:"lastRunTime"`
+}
+
+// SaveToCheckpoint returns the checkpoint of the task, and nil is returned if
+// no sample has been added yet.
+func (t *HistogramTask) SaveToCheckpoint() (*HistogramTaskCheckpoint, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.firstSampleTime.IsZero() {
+		return nil, nil
+	}
+
+	histogram, err := t.histogram.SaveToChekpoint()
+	if err != nil {
+		return nil, errors.Wrap(err, "save histogram to checkpoint failed")
+	}
+	return &HistogramTaskCheckpoint{
+		Histogram:         histogram,
+		FirstSampleTime:   t.firstSampleTime,
+		LastSampleTime:    t.lastSampleTime,
+		TotalSamplesCount: t.totalSamplesCount,
+		LastRunTime:       t.lastRunTime,
+	}, nil
+}
+
+// LoadFromCheckpoint restores the task from checkpoint, and the next run will
+// continue from the last run recorded in checkpoint, but no earlier than
+// DefaultInitDataLength to avoid querying too many samples at once.
+func (t *HistogramTask) LoadFromCheckpoint(checkpoint *HistogramTaskCheckpoint, now time.Time) error {
+	if checkpoint == nil || checkpoint.Histogram == nil {
+		return errors.New("checkpoint is empty")
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if err := t.histogram.LoadFromCheckpoint(checkpoint.Histogram); err != nil {
+		return errors.Wrap(err, "load histogram from checkpoint failed")
+	}
+	t.firstSampleTime = checkpoint.FirstSampleTime
+	t.lastSampleTime = checkpoint.LastSampleTime
+	t.totalSamplesCount = checkpoint.TotalSamplesCount
+
+	t.lastRunTime = checkpoint.LastRunTime
+	if earliest := now.Add(-DefaultInitDataLength); t.lastRunTime.Before(earliest) {
+		t.lastRunTime = earliest
+	}
+	return nil
+}
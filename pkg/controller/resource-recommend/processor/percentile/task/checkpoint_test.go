@@ -0,0 +1,73 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package task
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+
+	datasourcetypes "github.com/kubewharf/katalyst-core/pkg/util/resource-recommend/types/datasource"
+)
+
+func TestHistogramTask_Checkpoint(t *testing.T) {
+	t.Parallel()
+
+	metric := datasourcetypes.Metric{Resource: v1.ResourceCPU}
+	task, err := NewTask(metric, "")
+	assert.NoError(t, err)
+
+	checkpoint, err := task.SaveToCheckpoint()
+	assert.NoError(t, err)
+	assert.Nil(t, checkpoint, "empty task should not be checkpointed")
+
+	now := time.Now()
+	for i := 0; i < 48; i++ {
+		task.AddSample(now.Add(-time.Duration(48-i)*time.Hour), float64(i%4+1), DefaultSampleWeight)
+	}
+	task.lastRunTime = now.Add(-time.Hour)
+
+	checkpoint, err = task.SaveToCheckpoint()
+	assert.NoError(t, err)
+	assert.NotNil(t, checkpoint)
+
+	// checkpoints are persisted in json
+	data, err := json.Marshal(checkpoint)
+	assert.NoError(t, err)
+	restoredCheckpoint := &HistogramTaskCheckpoint{}
+	assert.NoError(t, json.Unmarshal(data, restoredCheckpoint))
+
+	restored, err := NewTask(metric, "")
+	assert.NoError(t, err)
+	assert.Error(t, restored.LoadFromCheckpoint(nil, now))
+	assert.NoError(t, restored.LoadFromCheckpoint(restoredCheckpoint, now))
+
+	assert.Equal(t, task.totalSamplesCount, restored.totalSamplesCount)
+	assert.True(t, task.firstSampleTime.Equal(restored.firstSampleTime))
+	assert.True(t, task.lastSampleTime.Equal(restored.lastSampleTime))
+	assert.True(t, task.lastRunTime.Equal(restored.lastRunTime))
+	assert.InDelta(t, task.histogram.Percentile(0.9), restored.histogram.Percentile(0.9), 0.01)
+
+	// the next run shouldn't query samples earlier than DefaultInitDataLength
+	outdated, _ := NewTask(metric, "")
+	restoredCheckpoint.LastRunTime = now.Add(-10 * DefaultInitDataLength)
+	assert.NoError(t, outdated.LoadFromCheckpoint(restoredCheckpoint, now))
+	assert.True(t, outdated.lastRunTime.Equal(now.Add(-DefaultInitDataLength)))
+}
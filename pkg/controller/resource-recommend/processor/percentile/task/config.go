@@ -112,8 +112,14 @@ const (
 )
 
 func GetTaskConfig(extensions processortypes.TaskConfigStr) (*ProcessConfig, error) {
+	return GetTaskConfigWithDefault(extensions, DefaultHistogramDecayHalfLife)
+}
+
+// GetTaskConfigWithDefault parses the process config from extensions, and the given
+// half-life is used if it is not specified in extensions.
+func GetTaskConfigWithDefault(extensions processortypes.TaskConfigStr, defaultHalfLife time.Duration) (*ProcessConfig, error) {
 	processConfig := &ProcessConfig{
-		DecayHalfLife: DefaultHistogramDecayHalfLife,
+		DecayHalfLife: defaultHalfLife,
 	}
 	if extensions == "" {
 		return processConfig, nil
@@ -187,3 +187,15 @@ func TestGetDefaultTaskProcessInterval(t *testing.T) {
 		})
 	}
 }
+
+func TestGetTaskConfigWithDefault(t *testing.T) {
+	got, err := GetTaskConfigWithDefault("", time.Hour*48)
+	if err != nil || got.DecayHalfLife != time.Hour*48 {
+		t.Errorf("GetTaskConfigWithDefault() = %v, %v, want default half-life", got, err)
+	}
+
+	got, err = GetTaskConfigWithDefault(`{"decayHalfLife":12}`, time.Hour*48)
+	if err != nil || got.DecayHalfLife != time.Hour*12 {
+		t.Errorf("GetTaskConfigWithDefault() = %v, %v, want half-life from extensions", got, err)
+	}
+}
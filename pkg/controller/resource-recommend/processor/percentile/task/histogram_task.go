@@ -63,6 +63,10 @@ func NewTask(metric datasourcetypes.Metric, config processortypes.TaskConfigStr)
 	if err != nil {
 		return nil, errors.Wrapf(err, "New histogram task error, get process config failed, config: %s", config)
 	}
+	return NewTaskWithConfig(metric, processConfig)
+}
+
+func NewTaskWithConfig(metric datasourcetypes.Metric, processConfig *ProcessConfig) (*HistogramTask, error) {
 	histogramOptions, err := HistogramOptionsFactory(metric.Resource)
 	if err != nil {
 		return nil, errors.Wrap(err, "get histogram options failed")
@@ -124,7 +128,7 @@ func (t *HistogramTask) Run(ctx context.Context, datasourceProxy *datasource.Pro
 	}
 	ctx = log.SetKeysAndValues(ctx, "runSectionBegin", runSectionBegin.String(), "runSectionEnd", runSectionEnd.String())
 
-	timeSeries, err := datasourceProxy.QueryTimeSeries(datasource.PrometheusDatasource, t.metric, runSectionBegin, runSectionEnd, time.Minute)
+	timeSeries, err := datasourceProxy.QueryTimeSeries(datasourceProxy.DefaultDatasource(), t.metric, runSectionBegin, runSectionEnd, time.Minute)
 	if err != nil {
 		log.ErrorS(ctx, err, "task handler error, query samples failed")
 		return 0, err
@@ -21,6 +21,7 @@ import (
 
 	"github.com/kubewharf/katalyst-api/pkg/apis/recommendation/v1alpha1"
 	"github.com/kubewharf/katalyst-core/pkg/controller/resource-recommend/oom"
+	"github.com/kubewharf/katalyst-core/pkg/controller/resource-recommend/processor/decaying"
 	processormanager "github.com/kubewharf/katalyst-core/pkg/controller/resource-recommend/processor/manager"
 	"github.com/kubewharf/katalyst-core/pkg/controller/resource-recommend/recommender"
 	"github.com/kubewharf/katalyst-core/pkg/controller/resource-recommend/recommender/recommenders"
@@ -29,20 +30,32 @@ import (
 type Manager struct {
 	ProcessorManager processormanager.Manager
 	OomRecorder      oom.Recorder
+	OOMForecaster    *recommenders.OOMForecaster
 }
 
-func NewManager(ProcessorManager processormanager.Manager, OomRecorder oom.Recorder) *Manager {
+func NewManager(ProcessorManager processormanager.Manager, OomRecorder oom.Recorder, OOMForecaster *recommenders.OOMForecaster) *Manager {
 	return &Manager{
 		ProcessorManager: ProcessorManager,
 		OomRecorder:      OomRecorder,
+		OOMForecaster:    OOMForecaster,
 	}
 }
 
 func (m *Manager) NewRecommender(algorithm v1alpha1.Algorithm) recommender.Recommender {
 	switch algorithm {
 	case v1alpha1.AlgorithmPercentile:
-		return recommenders.NewPercentileRecommender(m.ProcessorManager.GetProcessor(v1alpha1.AlgorithmPercentile), m.OomRecorder)
+		return m.newPercentileRecommender()
+	case decaying.AlgorithmDecaying:
+		decayingRecommender := recommenders.NewDecayingRecommender(m.ProcessorManager.GetProcessor(decaying.AlgorithmDecaying), m.OomRecorder)
+		decayingRecommender.OOMForecaster = m.OOMForecaster
+		return decayingRecommender
 	}
 	klog.InfoS("no recommender matched. fall through to default percentile recommender")
-	return recommenders.NewPercentileRecommender(m.ProcessorManager.GetProcessor(v1alpha1.AlgorithmPercentile), m.OomRecorder)
+	return m.newPercentileRecommender()
+}
+
+func (m *Manager) newPercentileRecommender() recommender.Recommender {
+	percentileRecommender := recommenders.NewPercentileRecommender(m.ProcessorManager.GetProcessor(v1alpha1.AlgorithmPercentile), m.OomRecorder)
+	percentileRecommender.OOMForecaster = m.OOMForecaster
+	return percentileRecommender
 }
@@ -0,0 +1,35 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recommenders
+
+import (
+	"github.com/kubewharf/katalyst-core/pkg/controller/resource-recommend/oom"
+	"github.com/kubewharf/katalyst-core/pkg/controller/resource-recommend/processor"
+)
+
+// DecayingRecommender recommends resources from exponentially decaying histograms;
+// buffers and OOM handling are the same as PercentileRecommender, and only the
+// processor providing percentile values differs.
+type DecayingRecommender struct {
+	*PercentileRecommender
+}
+
+func NewDecayingRecommender(DataProcessor processor.Processor, OomRecorder oom.Recorder) *DecayingRecommender {
+	return &DecayingRecommender{
+		PercentileRecommender: NewPercentileRecommender(DataProcessor, OomRecorder),
+	}
+}
@@ -0,0 +1,214 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recommenders
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/kubewharf/katalyst-core/pkg/controller/resource-recommend/datasource"
+	"github.com/kubewharf/katalyst-core/pkg/controller/resource-recommend/oom"
+	datasourcetypes "github.com/kubewharf/katalyst-core/pkg/util/resource-recommend/types/datasource"
+	processortypes "github.com/kubewharf/katalyst-core/pkg/util/resource-recommend/types/processor"
+)
+
+const (
+	// OOMRecordExpiration specifies how long an oom record is taken into account.
+	OOMRecordExpiration = time.Hour * 24 * 7
+)
+
+// OOMForecastConfig holds the parameters used to predict memory exhaustion.
+type OOMForecastConfig struct {
+	// Lookback is the length of memory usage history used to fit the growth trend
+	Lookback time.Duration
+	// Horizon is how far into the future the growth trend is extrapolated
+	Horizon time.Duration
+	// Step is the resolution of memory usage samples queried from datasource
+	Step time.Duration
+	// MinSamples is the minimal number of samples required to fit the trend
+	MinSamples int
+}
+
+// OOMRisk describes a container that is predicted to run out of memory within the horizon.
+type OOMRisk struct {
+	ContainerName string
+	// GrowthRate is the fitted memory growth in bytes per second
+	GrowthRate float64
+	// PredictedPeak is the memory usage expected at the end of the horizon
+	PredictedPeak float64
+	// MemoryThreshold is the memory usage at which the container is expected to be OOM-killed,
+	// it is taken from the memory limit in pod spec if any, otherwise the latest oom record
+	MemoryThreshold float64
+	// TimeToOOM is the estimated time until memory usage reaches the threshold
+	TimeToOOM time.Duration
+}
+
+func (r *OOMRisk) String() string {
+	return fmt.Sprintf("container %s is predicted to reach %.0f bytes of memory in %v (threshold %.0f bytes, growth %.2f bytes/s)",
+		r.ContainerName, r.PredictedPeak, r.TimeToOOM.Round(time.Minute), r.MemoryThreshold, r.GrowthRate)
+}
+
+// OOMForecaster combines oom history with memory growth trends from datasource to
+// predict containers that are likely to be OOM-killed within the horizon.
+type OOMForecaster struct {
+	DatasourceProxy *datasource.Proxy
+	PodLister       corelisters.PodLister
+	Config          OOMForecastConfig
+
+	now func() time.Time
+}
+
+func NewOOMForecaster(datasourceProxy *datasource.Proxy, podLister corelisters.PodLister, config OOMForecastConfig) *OOMForecaster {
+	return &OOMForecaster{
+		DatasourceProxy: datasourceProxy,
+		PodLister:       podLister,
+		Config:          config,
+		now:             time.Now,
+	}
+}
+
+// Forecast returns the predicted oom risk of the container identified by taskKey, and nil
+// is returned if the memory usage is not expected to reach the threshold within the horizon,
+// or the memory limit of the container is unknown.
+func (f *OOMForecaster) Forecast(taskKey *processortypes.ProcessKey, oomRecords []oom.OOMRecord) (*OOMRisk, error) {
+	now := f.now()
+	threshold, err := f.getMemoryThreshold(taskKey, oomRecords, now)
+	if err != nil {
+		return nil, err
+	}
+	if threshold <= 0 {
+		return nil, nil
+	}
+
+	timeSeries, err := f.DatasourceProxy.QueryTimeSeries(f.DatasourceProxy.DefaultDatasource(), *taskKey.Metric,
+		now.Add(-f.Config.Lookback), now, f.Config.Step)
+	if err != nil {
+		return nil, fmt.Errorf("query memory usage failed: %v", err)
+	}
+	if timeSeries == nil || len(timeSeries.Samples) < f.Config.MinSamples || len(timeSeries.Samples) < 2 {
+		return nil, nil
+	}
+
+	slope, latest := fitMemoryGrowthTrend(timeSeries.Samples)
+	predicted := latest + slope*f.Config.Horizon.Seconds()
+	klog.V(4).InfoS("oom forecast for container", "namespace", taskKey.Namespace, "workload", taskKey.WorkloadName,
+		"container", taskKey.ContainerName, "growthRate", slope, "latest", latest, "predicted", predicted, "threshold", threshold)
+	if predicted < threshold {
+		return nil, nil
+	}
+
+	timeToOOM := time.Duration(0)
+	if latest < threshold && slope > 0 {
+		timeToOOM = time.Duration((threshold - latest) / slope * float64(time.Second))
+	}
+	return &OOMRisk{
+		ContainerName:   taskKey.ContainerName,
+		GrowthRate:      slope,
+		PredictedPeak:   predicted,
+		MemoryThreshold: threshold,
+		TimeToOOM:       timeToOOM,
+	}, nil
+}
+
+// getMemoryThreshold returns the memory limit of the container, i.e. the minimal limit among
+// pods of the workload, and the memory of the latest oom record is used if no limit is set.
+func (f *OOMForecaster) getMemoryThreshold(taskKey *processortypes.ProcessKey, oomRecords []oom.OOMRecord, now time.Time) (float64, error) {
+	threshold := 0.0
+	if f.PodLister != nil {
+		pods, err := f.PodLister.Pods(taskKey.Namespace).List(labels.Everything())
+		if err != nil {
+			return 0, fmt.Errorf("list pods failed: %v", err)
+		}
+		for _, pod := range pods {
+			// use limits of all pods in workload, the same as oomRecord
+			if !strings.HasPrefix(pod.Name, taskKey.WorkloadName) {
+				continue
+			}
+			for _, container := range pod.Spec.Containers {
+				if container.Name != taskKey.ContainerName {
+					continue
+				}
+				if limit, ok := container.Resources.Limits[v1.ResourceMemory]; ok && limit.Value() > 0 {
+					if threshold == 0 || float64(limit.Value()) < threshold {
+						threshold = float64(limit.Value())
+					}
+				}
+			}
+		}
+	}
+	if threshold > 0 {
+		return threshold, nil
+	}
+
+	if record := findOOMRecord(oomRecords, taskKey.Namespace, taskKey.WorkloadName, taskKey.ContainerName, now); record != nil {
+		threshold = float64(record.Memory.Value())
+	}
+	return threshold, nil
+}
+
+// fitMemoryGrowthTrend fits memory usage samples with least squares linear regression, and
+// returns the growth rate in bytes per second and the fitted latest usage.
+func fitMemoryGrowthTrend(samples []datasourcetypes.Sample) (slope, latest float64) {
+	sorted := make([]datasourcetypes.Sample, len(samples))
+	copy(sorted, samples)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp < sorted[j].Timestamp
+	})
+
+	origin := sorted[0].Timestamp
+	var sumX, sumY, sumXY, sumXX float64
+	for _, sample := range sorted {
+		x := float64(sample.Timestamp - origin)
+		sumX += x
+		sumY += sample.Value
+		sumXY += x * sample.Value
+		sumXX += x * x
+	}
+
+	n := float64(len(sorted))
+	lastX := float64(sorted[len(sorted)-1].Timestamp - origin)
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0, sumY / n
+	}
+	slope = (n*sumXY - sumX*sumY) / denominator
+	intercept := (sumY - slope*sumX) / n
+	return slope, intercept + slope*lastX
+}
+
+// findOOMRecord returns the oom record of any pod in the workload for the given container,
+// and records older than OOMRecordExpiration are ignored.
+func findOOMRecord(oomRecords []oom.OOMRecord, namespace, workloadName, containerName string, now time.Time) *oom.OOMRecord {
+	for i := range oomRecords {
+		record := &oomRecords[i]
+		// use oomRecord for all pods in workload
+		if strings.HasPrefix(record.Pod, workloadName) && containerName == record.Container && namespace == record.Namespace {
+			if now.Sub(record.OOMAt) <= OOMRecordExpiration {
+				return record
+			}
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,281 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recommenders
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/recommendation/v1alpha1"
+	"github.com/kubewharf/katalyst-core/pkg/controller/resource-recommend/datasource"
+	"github.com/kubewharf/katalyst-core/pkg/controller/resource-recommend/oom"
+	conditionstypes "github.com/kubewharf/katalyst-core/pkg/util/resource-recommend/types/conditions"
+	datasourcetypes "github.com/kubewharf/katalyst-core/pkg/util/resource-recommend/types/datasource"
+	processortypes "github.com/kubewharf/katalyst-core/pkg/util/resource-recommend/types/processor"
+	recommendationtypes "github.com/kubewharf/katalyst-core/pkg/util/resource-recommend/types/recommendation"
+)
+
+type fakeMemoryDatasource struct {
+	timeSeries *datasourcetypes.TimeSeries
+}
+
+func (f *fakeMemoryDatasource) ConvertMetricToQuery(_ datasourcetypes.Metric) (*datasourcetypes.Query, error) {
+	return &datasourcetypes.Query{}, nil
+}
+
+func (f *fakeMemoryDatasource) QueryTimeSeries(_ *datasourcetypes.Query, _, _ time.Time, _ time.Duration) (*datasourcetypes.TimeSeries, error) {
+	return f.timeSeries, nil
+}
+
+// makeLinearTimeSeries generates samples per minute in the hour before now, starting from base and growing by rate bytes per second
+func makeLinearTimeSeries(now time.Time, base, rate float64) *datasourcetypes.TimeSeries {
+	ts := datasourcetypes.NewTimeSeries()
+	start := now.Add(-time.Hour)
+	for i := 0; i <= 60; i++ {
+		ts.AppendSample(start.Add(time.Duration(i)*time.Minute).Unix(), base+rate*float64(i*60))
+	}
+	return ts
+}
+
+func makePodWithMemoryLimit(namespace, name, containerName string, limit int64) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Name: containerName,
+					Resources: v1.ResourceRequirements{
+						Limits: v1.ResourceList{v1.ResourceMemory: *resource.NewQuantity(limit, resource.BinarySI)},
+					},
+				},
+			},
+		},
+	}
+}
+
+func newTestOOMForecaster(now time.Time, ts *datasourcetypes.TimeSeries, pods ...*v1.Pod) *OOMForecaster {
+	proxy := datasource.NewProxy()
+	proxy.RegisterDatasource(datasource.PrometheusDatasource, &fakeMemoryDatasource{timeSeries: ts})
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, pod := range pods {
+		_ = indexer.Add(pod)
+	}
+	f := NewOOMForecaster(proxy, corelisters.NewPodLister(indexer), OOMForecastConfig{
+		Lookback:   time.Hour,
+		Horizon:    2 * time.Hour,
+		Step:       time.Minute,
+		MinSamples: 10,
+	})
+	f.now = func() time.Time { return now }
+	return f
+}
+
+func TestFitMemoryGrowthTrend(t *testing.T) {
+	t.Parallel()
+
+	samples := []datasourcetypes.Sample{
+		{Timestamp: 120, Value: 300},
+		{Timestamp: 0, Value: 100},
+		{Timestamp: 60, Value: 200},
+	}
+	slope, latest := fitMemoryGrowthTrend(samples)
+	assert.InDelta(t, 100.0/60, slope, 1e-9)
+	assert.InDelta(t, 300, latest, 1e-9)
+	// the original samples should not be reordered
+	assert.Equal(t, int64(120), samples[0].Timestamp)
+
+	slope, latest = fitMemoryGrowthTrend([]datasourcetypes.Sample{{Timestamp: 10, Value: 50}, {Timestamp: 10, Value: 70}})
+	assert.Equal(t, 0.0, slope)
+	assert.Equal(t, 60.0, latest)
+}
+
+func TestOOMForecaster_Forecast(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	const mb = 1024 * 1024
+	taskKey := &processortypes.ProcessKey{
+		Metric: &datasourcetypes.Metric{
+			Namespace:     "ns",
+			WorkloadName:  "workload",
+			ContainerName: "c1",
+			Resource:      v1.ResourceMemory,
+		},
+	}
+
+	limitedPod := makePodWithMemoryLimit("ns", "workload-pod-1", "c1", 200*mb)
+	// usage spikes at the beginning while the trend is flat
+	spikingTimeSeries := makeLinearTimeSeries(now, 100*mb, 0)
+	spikingTimeSeries.Samples[0].Value = 250 * mb
+
+	for _, tc := range []struct {
+		name            string
+		timeSeries      *datasourcetypes.TimeSeries
+		oomRecords      []oom.OOMRecord
+		pods            []*v1.Pod
+		expectRisk      bool
+		expectThreshold float64
+		expectTimeToOOM time.Duration
+	}{
+		{
+			name:       "stable usage below limit",
+			timeSeries: makeLinearTimeSeries(now, 100*mb, 0),
+			pods:       []*v1.Pod{limitedPod},
+		},
+		{
+			name:            "growing usage exceeds limit within horizon",
+			timeSeries:      makeLinearTimeSeries(now, 100*mb, mb/60.0),
+			pods:            []*v1.Pod{limitedPod},
+			expectRisk:      true,
+			expectThreshold: 200 * mb,
+			expectTimeToOOM: 40 * time.Minute,
+		},
+		{
+			name:       "growing usage not reaching limit within horizon",
+			timeSeries: makeLinearTimeSeries(now, 100*mb, mb/600.0),
+			pods:       []*v1.Pod{limitedPod},
+		},
+		{
+			name:       "usage peak above limit is ignored if the trend is flat",
+			timeSeries: spikingTimeSeries,
+			pods:       []*v1.Pod{limitedPod},
+		},
+		{
+			name:       "limits of pods in other workloads are ignored",
+			timeSeries: makeLinearTimeSeries(now, 100*mb, mb/600.0),
+			pods: []*v1.Pod{
+				limitedPod,
+				makePodWithMemoryLimit("ns", "other-pod-1", "c1", 110*mb),
+				makePodWithMemoryLimit("other-ns", "workload-pod-1", "c1", 110*mb),
+			},
+		},
+		{
+			name:       "minimal limit among pods of the workload is used",
+			timeSeries: makeLinearTimeSeries(now, 100*mb, mb/600.0),
+			pods: []*v1.Pod{
+				limitedPod,
+				makePodWithMemoryLimit("ns", "workload-pod-2", "c1", 110*mb),
+			},
+			expectRisk:      true,
+			expectThreshold: 110 * mb,
+			expectTimeToOOM: 40 * time.Minute,
+		},
+		{
+			name:       "limit in pod spec takes precedence over oom history",
+			timeSeries: makeLinearTimeSeries(now, 100*mb, mb/600.0),
+			oomRecords: []oom.OOMRecord{
+				{Namespace: "ns", Pod: "workload-pod-1", Container: "c1", Memory: *resource.NewQuantity(110*mb, resource.BinarySI), OOMAt: now.Add(-time.Hour)},
+			},
+			pods: []*v1.Pod{limitedPod},
+		},
+		{
+			name:       "oom history is used if limit is not set",
+			timeSeries: makeLinearTimeSeries(now, 100*mb, mb/600.0),
+			oomRecords: []oom.OOMRecord{
+				{Namespace: "ns", Pod: "workload-pod-1", Container: "c1", Memory: *resource.NewQuantity(110*mb, resource.BinarySI), OOMAt: now.Add(-time.Hour)},
+			},
+			expectRisk:      true,
+			expectThreshold: 110 * mb,
+			expectTimeToOOM: 40 * time.Minute,
+		},
+		{
+			name:       "expired oom history is ignored",
+			timeSeries: makeLinearTimeSeries(now, 100*mb, mb/600.0),
+			oomRecords: []oom.OOMRecord{
+				{Namespace: "ns", Pod: "workload-pod-1", Container: "c1", Memory: *resource.NewQuantity(110*mb, resource.BinarySI), OOMAt: now.Add(-8 * 24 * time.Hour)},
+			},
+		},
+		{
+			name:       "no limit nor oom history",
+			timeSeries: makeLinearTimeSeries(now, 100*mb, mb/60.0),
+		},
+		{
+			name:       "insufficient samples",
+			timeSeries: &datasourcetypes.TimeSeries{Samples: []datasourcetypes.Sample{{Timestamp: now.Unix(), Value: 300 * mb}}},
+			pods:       []*v1.Pod{limitedPod},
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			f := newTestOOMForecaster(now, tc.timeSeries, tc.pods...)
+			risk, err := f.Forecast(taskKey, tc.oomRecords)
+			assert.NoError(t, err)
+			if !tc.expectRisk {
+				assert.Nil(t, risk)
+				return
+			}
+			assert.NotNil(t, risk)
+			assert.Equal(t, "c1", risk.ContainerName)
+			assert.InDelta(t, tc.expectThreshold, risk.MemoryThreshold, 1)
+			assert.InDelta(t, float64(tc.expectTimeToOOM), float64(risk.TimeToOOM), float64(time.Second))
+		})
+	}
+}
+
+func TestRecommendWithOOMForecast(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	newRecommendation := func() *recommendationtypes.Recommendation {
+		return &recommendationtypes.Recommendation{
+			NamespacedName: types.NamespacedName{Name: "name1", Namespace: "namespace1"},
+			Config: recommendationtypes.Config{
+				Containers: []recommendationtypes.Container{
+					{
+						ContainerName: "container1",
+						ContainerConfigs: []recommendationtypes.ContainerConfig{
+							{ControlledResource: v1.ResourceMemory, ResourceBufferPercent: 10},
+						},
+					},
+				},
+				TargetRef: v1alpha1.CrossVersionObjectReference{Kind: "deployment", Name: "workload1"},
+			},
+			Conditions: conditionstypes.NewResourceRecommendConditionsMap(),
+		}
+	}
+
+	// processed value is 1000 bytes, memory limit is 8Ki, and usage is growing from 1000 bytes by 1 byte per second
+	pod := makePodWithMemoryLimit("namespace1", "workload1-pod-1", "container1", 8*1024)
+	r := &PercentileRecommender{
+		DataProcessor: dummyDataProcessor{},
+		OomRecorder:   dummyOomRecorder{},
+		OOMForecaster: newTestOOMForecaster(now, makeLinearTimeSeries(now, 1000, 1), pod),
+	}
+	recommendation := newRecommendation()
+	assert.Nil(t, r.Recommend(recommendation))
+	// predicted peak is 1000+3600+7200 bytes, scaled by 10% buffer
+	assert.Equal(t, "13Ki", recommendation.Recommendations[0].Requests.Target.Memory().String())
+	assert.True(t, recommendation.Conditions.ConditionActive(conditionstypes.OOMRiskPredicted))
+
+	r.OOMForecaster = newTestOOMForecaster(now, makeLinearTimeSeries(now, 500, 0), pod)
+	recommendation = newRecommendation()
+	assert.Nil(t, r.Recommend(recommendation))
+	assert.Equal(t, "2Ki", recommendation.Recommendations[0].Requests.Target.Memory().String())
+	assert.False(t, recommendation.Conditions.ConditionActive(conditionstypes.OOMRiskPredicted))
+	_, found := (*recommendation.Conditions)[conditionstypes.OOMRiskPredicted]
+	assert.True(t, found)
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/kubewharf/katalyst-core/pkg/controller/resource-recommend/processor"
 	"github.com/kubewharf/katalyst-core/pkg/controller/resource-recommend/recommender"
 	"github.com/kubewharf/katalyst-core/pkg/util/general"
+	conditionstypes "github.com/kubewharf/katalyst-core/pkg/util/resource-recommend/types/conditions"
 	errortypes "github.com/kubewharf/katalyst-core/pkg/util/resource-recommend/types/error"
 	processortypes "github.com/kubewharf/katalyst-core/pkg/util/resource-recommend/types/processor"
 	recommendationtype "github.com/kubewharf/katalyst-core/pkg/util/resource-recommend/types/recommendation"
@@ -39,6 +40,9 @@ type PercentileRecommender struct {
 	recommender.Recommender
 	DataProcessor processor.Processor
 	OomRecorder   oom.Recorder
+	// OOMForecaster is optional, memory recommendation is proactively raised
+	// for containers predicted to OOM within the horizon if it is set.
+	OOMForecaster *OOMForecaster
 }
 
 const (
@@ -58,6 +62,7 @@ func NewPercentileRecommender(DataProcessor processor.Processor, OomRecorder oom
 
 func (r *PercentileRecommender) Recommend(recommendation *recommendationtype.Recommendation) *errortypes.CustomError {
 	klog.InfoS("starting recommenders process", "recommendationConfig", recommendation.Config)
+	var oomRisks []*OOMRisk
 	for _, container := range recommendation.Config.Containers {
 		containerRecommendation := v1alpha1.ContainerResources{
 			ContainerName: container.ContainerName,
@@ -80,29 +85,30 @@ func (r *PercentileRecommender) Recommend(recommendation *recommendationtype.Rec
 				if err != nil {
 					return errortypes.RecommendationNotReadyError(err.Error())
 				}
+				if risk := r.forecastOOMRisk(&taskKey); risk != nil {
+					oomRisks = append(oomRisks, risk)
+					if forecastMem := r.getMemQuantity(risk.PredictedPeak * (1 + float64(containerConfig.ResourceBufferPercent)/100)); forecastMem.Cmp(*memQuantity) > 0 {
+						klog.InfoS("container using oomForecast Memory", "container", container.ContainerName, "oomForecastMem", forecastMem.String())
+						memQuantity = forecastMem
+					}
+				}
 				requests.Target[v1.ResourceMemory] = *memQuantity
 			}
 		}
 		containerRecommendation.Requests = &requests
 		recommendation.Recommendations = append(recommendation.Recommendations, containerRecommendation)
 	}
+	if r.OOMForecaster != nil && recommendation.Conditions != nil {
+		recommendation.Conditions.Set(*conditionstypes.OOMRiskCondition(oomRisksMessage(oomRisks)))
+	}
 	klog.InfoS("recommenders process done", "recommendation", general.StructToString(recommendation.Recommendations))
 	return nil
 }
 
 func (r *PercentileRecommender) ScaleOnOOM(oomRecords []oom.OOMRecord, namespace string, workloadName string, containerName string) *resource.Quantity {
 	klog.InfoS("scaling on oom for namespace, workload, container", "namespace", namespace, "workload", workloadName, "container", containerName)
-	var oomRecord *oom.OOMRecord
-	for _, record := range oomRecords {
-		// use oomRecord for all pods in workload
-		if strings.HasPrefix(record.Pod, workloadName) && containerName == record.Container && namespace == record.Namespace {
-			oomRecord = &record
-			break
-		}
-	}
-
 	// ignore too old oom events
-	if oomRecord != nil && time.Since(oomRecord.OOMAt) <= (time.Hour*24*7) {
+	if oomRecord := findOOMRecord(oomRecords, namespace, workloadName, containerName, time.Now()); oomRecord != nil {
 		memoryOOM := oomRecord.Memory.Value()
 		var memoryNeeded vpamodel.ResourceAmount
 		memoryNeeded = vpamodel.ResourceAmountMax(vpamodel.ResourceAmount(memoryOOM)+vpamodel.MemoryAmountFromBytes(OOMMinBumpUp),
@@ -114,6 +120,29 @@ func (r *PercentileRecommender) ScaleOnOOM(oomRecords []oom.OOMRecord, namespace
 	return nil
 }
 
+// forecastOOMRisk predicts whether the container will run out of memory within the horizon,
+// and forecast failures are ignored since they shouldn't block the percentile recommendation.
+func (r *PercentileRecommender) forecastOOMRisk(taskKey *processortypes.ProcessKey) *OOMRisk {
+	if r.OOMForecaster == nil {
+		return nil
+	}
+
+	risk, err := r.OOMForecaster.Forecast(taskKey, r.OomRecorder.ListOOMRecords())
+	if err != nil {
+		klog.ErrorS(err, "failed to forecast oom risk", "namespace", taskKey.Namespace, "workload", taskKey.WorkloadName, "container", taskKey.ContainerName)
+		return nil
+	}
+	return risk
+}
+
+func oomRisksMessage(oomRisks []*OOMRisk) string {
+	messages := make([]string, 0, len(oomRisks))
+	for _, risk := range oomRisks {
+		messages = append(messages, risk.String())
+	}
+	return strings.Join(messages, "; ")
+}
+
 func (r *PercentileRecommender) getCpuTargetPercentileEstimationWithUsageBuffer(taskKey *processortypes.ProcessKey, resourceBufferPercentage float64) (quantity *resource.Quantity, err error) {
 	klog.InfoS("getting cpu estimation for namespace, workload, container, with resource buffer", "namespace", taskKey.Namespace, "workload", taskKey.WorkloadName, "container", taskKey.ContainerName, "resourceBuffer", resourceBufferPercentage)
 	cpuRecommendedValue, err := r.DataProcessor.QueryProcessedValues(taskKey)
@@ -0,0 +1,81 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recommenders
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/klog/v2"
+
+	apis "github.com/kubewharf/katalyst-api/pkg/apis/autoscaling/v1alpha1"
+	workload "github.com/kubewharf/katalyst-api/pkg/apis/workload/v1alpha1"
+	"github.com/kubewharf/katalyst-core/pkg/controller/vpa/algorithm"
+)
+
+var allResourcesRecommenderName = "AllResourcesRequest"
+
+// AllResourcesRecommender merges results of several recommenders, so that a single
+// vpa can manage all resources of a container; results of recommenders which fail
+// are skipped, and former recommenders take precedence for the same resource.
+type AllResourcesRecommender struct {
+	recommenders []algorithm.ResourceRecommender
+}
+
+// NewAllResourcesRecommender construct AllResourcesRecommender
+func NewAllResourcesRecommender(recommenders ...algorithm.ResourceRecommender) algorithm.ResourceRecommender {
+	return &AllResourcesRecommender{recommenders: recommenders}
+}
+
+func (r *AllResourcesRecommender) Name() string {
+	return allResourcesRecommenderName
+}
+
+func (r *AllResourcesRecommender) GetRecommendedPodResources(
+	spd *workload.ServiceProfileDescriptor, pods []*corev1.Pod,
+) ([]apis.RecommendedPodResources, []apis.RecommendedContainerResources, error) {
+	containerResources := make(map[string]map[corev1.ResourceName]resource.Quantity)
+	for _, recommender := range r.recommenders {
+		_, containerRecommendResources, err := recommender.GetRecommendedPodResources(spd, pods)
+		if err != nil {
+			klog.Warningf("[resource-rec] recommender %v failed: %v", recommender.Name(), err)
+			continue
+		}
+
+		for _, containerRecommendResource := range containerRecommendResources {
+			if containerRecommendResource.ContainerName == nil || containerRecommendResource.Requests == nil {
+				continue
+			}
+
+			container := *containerRecommendResource.ContainerName
+			if _, ok := containerResources[container]; !ok {
+				containerResources[container] = make(map[corev1.ResourceName]resource.Quantity)
+			}
+			for resourceName, quantity := range containerRecommendResource.Requests.Resources {
+				if _, ok := containerResources[container][resourceName]; !ok {
+					containerResources[container][resourceName] = quantity
+				}
+			}
+		}
+	}
+	if len(containerResources) == 0 {
+		return nil, nil, fmt.Errorf("no resources recommended")
+	}
+
+	return nil, makeContainerRecommendations(containerResources), nil
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recommenders
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+
+	apimetricpod "github.com/kubewharf/katalyst-api/pkg/metric/pod"
+)
+
+func TestAllResourcesRecommender(t *testing.T) {
+	t.Parallel()
+
+	r := NewAllResourcesRecommender(NewMemoryRecommender(), NewEphemeralStorageRecommender(), NewCPURecommender())
+	_, _, err := r.GetRecommendedPodResources(nil, nil)
+	assert.Error(t, err)
+
+	spd := makeMaxAggSPD(apimetricpod.CustomMetricPodMemoryUsage, map[string]string{"c1": "1000", "c2": "2000"})
+	ephemeralStorageSPD := makeMaxAggSPD(v1.ResourceEphemeralStorage, map[string]string{"c1": "100"})
+	spd.Status.AggMetrics[0].Items = append(spd.Status.AggMetrics[0].Items, ephemeralStorageSPD.Status.AggMetrics[0].Items...)
+
+	// cpu recommender fails without avg metrics, and it should be skipped
+	_, containerResources, err := r.GetRecommendedPodResources(spd, nil)
+	assert.NoError(t, err)
+	assert.Len(t, containerResources, 2)
+	assert.Equal(t, map[string]int64{"c1": 1150, "c2": 2300}, getRecommendedQuantities(containerResources, v1.ResourceMemory))
+	assert.Equal(t, map[string]int64{"c1": 115}, getRecommendedQuantities(containerResources, v1.ResourceEphemeralStorage))
+}
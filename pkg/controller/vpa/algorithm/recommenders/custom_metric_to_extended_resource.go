@@ -0,0 +1,161 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recommenders
+
+import (
+	"fmt"
+	"math"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	customclient "k8s.io/metrics/pkg/client/custom_metrics"
+
+	apis "github.com/kubewharf/katalyst-api/pkg/apis/autoscaling/v1alpha1"
+	workload "github.com/kubewharf/katalyst-api/pkg/apis/workload/v1alpha1"
+	"github.com/kubewharf/katalyst-core/pkg/controller/vpa/algorithm"
+)
+
+var extendedResourceRecommenderName = "CustomMetricToExtendedResourceRequest"
+
+// containerSelectorKey is the metric label of container name in custom metrics API
+const containerSelectorKey = "container"
+
+var podGroupKind = schema.GroupKind{Kind: "Pod"}
+
+// ExtendedResourceRecommender recommends requests of extended resources (e.g. gpu)
+// according to the peak usage exposed through custom metrics API; only containers
+// that have already claimed the extended resource will be recommended. Since extended
+// resources can't be overcommitted, limits are always recommended the same as requests.
+type ExtendedResourceRecommender struct {
+	client customclient.CustomMetricsClient
+	// resourceMetrics maps extended resources to names of their usage metrics
+	resourceMetrics   map[corev1.ResourceName]string
+	safetyMarginRatio float64
+}
+
+// NewExtendedResourceRecommender construct ExtendedResourceRecommender
+func NewExtendedResourceRecommender(client customclient.CustomMetricsClient, resourceMetrics map[string]string) algorithm.ResourceRecommender {
+	r := &ExtendedResourceRecommender{
+		client:            client,
+		resourceMetrics:   make(map[corev1.ResourceName]string, len(resourceMetrics)),
+		safetyMarginRatio: defaultSafetyMarginRatio,
+	}
+	for resourceName, metricName := range resourceMetrics {
+		r.resourceMetrics[corev1.ResourceName(resourceName)] = metricName
+	}
+	return r
+}
+
+func (r *ExtendedResourceRecommender) Name() string {
+	return extendedResourceRecommenderName
+}
+
+func (r *ExtendedResourceRecommender) GetRecommendedPodResources(
+	_ *workload.ServiceProfileDescriptor, pods []*corev1.Pod,
+) ([]apis.RecommendedPodResources, []apis.RecommendedContainerResources, error) {
+	if r.client == nil {
+		return nil, nil, fmt.Errorf("custom metrics client is nil")
+	}
+	if len(pods) == 0 {
+		return nil, nil, fmt.Errorf("no pods found")
+	}
+
+	containerResources := make(map[string]map[corev1.ResourceName]resource.Quantity)
+	for resourceName, metricName := range r.resourceMetrics {
+		for container, peak := range r.getContainerPeaks(pods, resourceName, metricName) {
+			if _, ok := containerResources[container]; !ok {
+				containerResources[container] = make(map[corev1.ResourceName]resource.Quantity)
+			}
+			containerResources[container][resourceName] = peak
+		}
+	}
+	if len(containerResources) == 0 {
+		return nil, nil, fmt.Errorf("cannot find metrics for any extended resource")
+	}
+
+	containerRecommendResources := makeContainerRecommendations(containerResources)
+	for i := range containerRecommendResources {
+		limits := make(map[corev1.ResourceName]resource.Quantity, len(containerRecommendResources[i].Requests.Resources))
+		for resourceName, quantity := range containerRecommendResources[i].Requests.Resources {
+			limits[resourceName] = quantity.DeepCopy()
+		}
+		containerRecommendResources[i].Limits = &apis.RecommendedRequestResources{Resources: limits}
+	}
+	return nil, containerRecommendResources, nil
+}
+
+// getContainerPeaks returns the peak usage of the given extended resource for each
+// container claiming it, and all metric values of pods in the list are considered.
+// Peaks are rounded up to integers with safety margin, since extended resources can
+// only be requested in integers.
+func (r *ExtendedResourceRecommender) getContainerPeaks(pods []*corev1.Pod,
+	resourceName corev1.ResourceName, metricName string,
+) map[string]resource.Quantity {
+	// containers claiming the resource in each namespace, and the pods they belong to
+	claimedContainers := make(map[string]map[string]sets.String)
+	for _, pod := range pods {
+		if pod == nil {
+			continue
+		}
+		for _, container := range pod.Spec.Containers {
+			_, requested := container.Resources.Requests[resourceName]
+			_, limited := container.Resources.Limits[resourceName]
+			if !requested && !limited {
+				continue
+			}
+
+			if _, ok := claimedContainers[pod.Namespace]; !ok {
+				claimedContainers[pod.Namespace] = make(map[string]sets.String)
+			}
+			if _, ok := claimedContainers[pod.Namespace][container.Name]; !ok {
+				claimedContainers[pod.Namespace][container.Name] = sets.NewString()
+			}
+			claimedContainers[pod.Namespace][container.Name].Insert(pod.Name)
+		}
+	}
+
+	peaks := make(map[string]resource.Quantity)
+	for namespace, containers := range claimedContainers {
+		for container, podNames := range containers {
+			metricSelector := labels.SelectorFromSet(labels.Set{containerSelectorKey: container})
+			metricValueList, err := r.client.NamespacedMetrics(namespace).GetForObjects(podGroupKind,
+				labels.Everything(), metricName, metricSelector)
+			if err != nil {
+				klog.Errorf("[resource-rec] get metric %v for container %v/%v failed: %v", metricName, namespace, container, err)
+				continue
+			}
+
+			for _, metricValue := range metricValueList.Items {
+				if !podNames.Has(metricValue.DescribedObject.Name) {
+					continue
+				}
+				if peak, ok := peaks[container]; !ok || metricValue.Value.Cmp(peak) > 0 {
+					peaks[container] = metricValue.Value.DeepCopy()
+				}
+			}
+		}
+	}
+
+	for container, peak := range peaks {
+		peaks[container] = *resource.NewQuantity(int64(math.Ceil(peak.AsApproximateFloat64()*(1+r.safetyMarginRatio))), resource.DecimalSI)
+	}
+	return peaks
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recommenders
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/metrics/pkg/apis/custom_metrics/v1beta2"
+	cmfake "k8s.io/metrics/pkg/client/custom_metrics/fake"
+
+	workload "github.com/kubewharf/katalyst-api/pkg/apis/workload/v1alpha1"
+)
+
+const testGPUResource = v1.ResourceName("nvidia.com/gpu")
+
+func makeGPUPod(name string, gpuContainers ...string) *v1.Pod {
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: name}}
+	for _, container := range []string{"c1", "c2"} {
+		c := v1.Container{Name: container}
+		for _, gpuContainer := range gpuContainers {
+			if gpuContainer == container {
+				c.Resources.Limits = v1.ResourceList{testGPUResource: resource.MustParse("1")}
+			}
+		}
+		pod.Spec.Containers = append(pod.Spec.Containers, c)
+	}
+	return pod
+}
+
+func TestExtendedResourceRecommender(t *testing.T) {
+	t.Parallel()
+
+	client := &cmfake.FakeCustomMetricsClient{}
+	var requestedMetrics []string
+	client.AddReactor("get", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		requestedMetrics = append(requestedMetrics, action.(cmfake.GetForAction).GetMetricName())
+		makeMetricValue := func(pod, value string) v1beta2.MetricValue {
+			return v1beta2.MetricValue{
+				DescribedObject: v1.ObjectReference{Kind: "Pod", Namespace: "ns1", Name: pod},
+				Value:           resource.MustParse(value),
+			}
+		}
+		return true, &v1beta2.MetricValueList{Items: []v1beta2.MetricValue{
+			makeMetricValue("pod1", "300m"),
+			makeMetricValue("pod2", "1800m"),
+			// pods not belonging to the workload should be ignored
+			makeMetricValue("pod3", "2"),
+		}}, nil
+	})
+
+	r := NewExtendedResourceRecommender(client, map[string]string{string(testGPUResource): "container_gpu_usage"})
+	_, _, err := r.GetRecommendedPodResources(&workload.ServiceProfileDescriptor{}, nil)
+	assert.Error(t, err)
+
+	_, containerResources, err := r.GetRecommendedPodResources(nil, []*v1.Pod{
+		makeGPUPod("pod1", "c1"),
+		makeGPUPod("pod2", "c1"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"container_gpu_usage"}, requestedMetrics, "only containers claiming gpu should be queried")
+	assert.Len(t, containerResources, 1)
+	assert.Equal(t, "c1", *containerResources[0].ContainerName)
+	// peak is rounded up to integer with safety margin, and limit is the same as request
+	gpu := containerResources[0].Requests.Resources[testGPUResource]
+	assert.Equal(t, int64(3000), gpu.MilliValue())
+	assert.NotNil(t, containerResources[0].Limits)
+	gpuLimit := containerResources[0].Limits.Resources[testGPUResource]
+	assert.True(t, gpu.Equal(gpuLimit))
+
+	_, _, err = r.GetRecommendedPodResources(nil, []*v1.Pod{makeGPUPod("pod1")})
+	assert.Error(t, err, "no container claims gpu")
+}
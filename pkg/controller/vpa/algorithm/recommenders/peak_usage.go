@@ -0,0 +1,148 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recommenders
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	apis "github.com/kubewharf/katalyst-api/pkg/apis/autoscaling/v1alpha1"
+	workload "github.com/kubewharf/katalyst-api/pkg/apis/workload/v1alpha1"
+	"github.com/kubewharf/katalyst-core/pkg/controller/vpa/algorithm"
+)
+
+var ephemeralStorageRecommenderName = "PeakUsageToEphemeralStorageRequest"
+
+const (
+	// defaultSafetyMarginRatio is the ratio of extra resources added upon the peak usage
+	defaultSafetyMarginRatio = 0.15
+)
+
+// PeakUsageRecommender recommends requests of the given resource according to
+// the peak usage in max-aggregated metrics of spd, with a safety margin added.
+type PeakUsageRecommender struct {
+	name              string
+	metricName        corev1.ResourceName
+	resourceName      corev1.ResourceName
+	safetyMarginRatio float64
+}
+
+// NewEphemeralStorageRecommender construct a recommender for ephemeral storage
+func NewEphemeralStorageRecommender() algorithm.ResourceRecommender {
+	return &PeakUsageRecommender{
+		name:              ephemeralStorageRecommenderName,
+		metricName:        corev1.ResourceEphemeralStorage,
+		resourceName:      corev1.ResourceEphemeralStorage,
+		safetyMarginRatio: defaultSafetyMarginRatio,
+	}
+}
+
+func (r *PeakUsageRecommender) Name() string {
+	return r.name
+}
+
+func (r *PeakUsageRecommender) GetRecommendedPodResources(
+	spd *workload.ServiceProfileDescriptor, _ []*corev1.Pod,
+) ([]apis.RecommendedPodResources, []apis.RecommendedContainerResources, error) {
+	containerPeaks, err := r.getContainerPeaks(spd)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	containerRecommendResources := make([]apis.RecommendedContainerResources, 0, len(containerPeaks))
+	for container, peak := range containerPeaks {
+		containerRecommendResources = append(containerRecommendResources,
+			makeContainerRecommendation(container, r.resourceName, r.withSafetyMargin(peak)))
+	}
+	return nil, containerRecommendResources, nil
+}
+
+func (r *PeakUsageRecommender) getContainerPeaks(spd *workload.ServiceProfileDescriptor) (map[string]*resource.Quantity, error) {
+	if spd == nil {
+		return nil, fmt.Errorf("invalid spd")
+	}
+
+	for _, aggPodMetrics := range spd.Status.AggMetrics {
+		if aggPodMetrics.Aggregator == workload.Max {
+			containerPeaks := computeMaxPodMetrics(aggPodMetrics.Items, r.metricName)
+			if len(containerPeaks) == 0 {
+				return nil, fmt.Errorf("failed to compute peak of %v", r.metricName)
+			}
+			return containerPeaks, nil
+		}
+	}
+	return nil, fmt.Errorf("cannot find max metrics")
+}
+
+func (r *PeakUsageRecommender) withSafetyMargin(peak *resource.Quantity) resource.Quantity {
+	return *resource.NewQuantity(int64(math.Ceil(peak.AsApproximateFloat64()*(1+r.safetyMarginRatio))), peak.Format)
+}
+
+// computeMaxPodMetrics returns the max usage of each container among all pod metrics
+func computeMaxPodMetrics(podMetrics []workload.PodMetrics, resourceName corev1.ResourceName) map[string]*resource.Quantity {
+	containerResources := make(map[string]*resource.Quantity)
+	for _, podMetric := range podMetrics {
+		for _, container := range podMetric.Containers {
+			usage, ok := container.Usage[resourceName]
+			if !ok {
+				continue
+			}
+
+			if peak, ok := containerResources[container.Name]; !ok || usage.Cmp(*peak) > 0 {
+				usageCopy := usage.DeepCopy()
+				containerResources[container.Name] = &usageCopy
+			}
+		}
+	}
+	return containerResources
+}
+
+func makeContainerRecommendation(container string, resourceName corev1.ResourceName, quantity resource.Quantity) apis.RecommendedContainerResources {
+	return apis.RecommendedContainerResources{
+		ContainerName: &container,
+		Requests: &apis.RecommendedRequestResources{
+			Resources: map[corev1.ResourceName]resource.Quantity{
+				resourceName: quantity,
+			},
+		},
+	}
+}
+
+// makeContainerRecommendations converts recommended resources of each container to
+// recommendations, and they are sorted by container names to keep results stable.
+func makeContainerRecommendations(containerResources map[string]map[corev1.ResourceName]resource.Quantity) []apis.RecommendedContainerResources {
+	containers := make([]string, 0, len(containerResources))
+	for container := range containerResources {
+		containers = append(containers, container)
+	}
+	sort.Strings(containers)
+
+	containerRecommendResources := make([]apis.RecommendedContainerResources, 0, len(containers))
+	for i := range containers {
+		containerRecommendResources = append(containerRecommendResources, apis.RecommendedContainerResources{
+			ContainerName: &containers[i],
+			Requests: &apis.RecommendedRequestResources{
+				Resources: containerResources[containers[i]],
+			},
+		})
+	}
+	return containerRecommendResources
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recommenders
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metrics "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+
+	apis "github.com/kubewharf/katalyst-api/pkg/apis/autoscaling/v1alpha1"
+	workload "github.com/kubewharf/katalyst-api/pkg/apis/workload/v1alpha1"
+	apimetricpod "github.com/kubewharf/katalyst-api/pkg/metric/pod"
+)
+
+func makeMaxAggSPD(metricName v1.ResourceName, containerUsages ...map[string]string) *workload.ServiceProfileDescriptor {
+	spd := &workload.ServiceProfileDescriptor{
+		Status: workload.ServiceProfileDescriptorStatus{
+			AggMetrics: []workload.AggPodMetrics{{Aggregator: workload.Max}},
+		},
+	}
+	for i, usages := range containerUsages {
+		podMetrics := workload.PodMetrics{
+			Timestamp: metav1.NewTime(time.Date(2022, 1, 1, i, 0, 0, 0, time.UTC)),
+			Window:    metav1.Duration{Duration: time.Hour},
+		}
+		for container, usage := range usages {
+			podMetrics.Containers = append(podMetrics.Containers, metrics.ContainerMetrics{
+				Name:  container,
+				Usage: map[v1.ResourceName]resource.Quantity{metricName: resource.MustParse(usage)},
+			})
+		}
+		spd.Status.AggMetrics[0].Items = append(spd.Status.AggMetrics[0].Items, podMetrics)
+	}
+	return spd
+}
+
+func getRecommendedQuantities(recommendations []apis.RecommendedContainerResources, resourceName v1.ResourceName) map[string]int64 {
+	result := make(map[string]int64)
+	for _, r := range recommendations {
+		if quantity, ok := r.Requests.Resources[resourceName]; ok {
+			result[*r.ContainerName] = quantity.Value()
+		}
+	}
+	return result
+}
+
+func TestEphemeralStorageRecommender(t *testing.T) {
+	t.Parallel()
+
+	r := NewEphemeralStorageRecommender()
+	_, _, err := r.GetRecommendedPodResources(nil, nil)
+	assert.Error(t, err)
+	_, _, err = r.GetRecommendedPodResources(&workload.ServiceProfileDescriptor{}, nil)
+	assert.Error(t, err)
+
+	spd := makeMaxAggSPD(v1.ResourceEphemeralStorage,
+		map[string]string{"c1": "1000", "c2": "2000"},
+		map[string]string{"c1": "3000", "c2": "1000"},
+	)
+	_, containerResources, err := r.GetRecommendedPodResources(spd, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int64{"c1": 3450, "c2": 2300}, getRecommendedQuantities(containerResources, v1.ResourceEphemeralStorage))
+}
+
+func TestMemoryRecommender(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	r := NewMemoryRecommender().(*MemoryRecommender)
+	r.now = func() time.Time { return now }
+
+	spd := makeMaxAggSPD(apimetricpod.CustomMetricPodMemoryUsage,
+		map[string]string{"c1": "1Gi", "c2": "1Gi", "c3": "1Gi"},
+		map[string]string{"c1": "2Gi"},
+	)
+	makePod := func(container, limit string, oomAt time.Time) *v1.Pod {
+		return &v1.Pod{
+			Spec: v1.PodSpec{Containers: []v1.Container{{
+				Name:      container,
+				Resources: v1.ResourceRequirements{Limits: v1.ResourceList{v1.ResourceMemory: resource.MustParse(limit)}},
+			}}},
+			Status: v1.PodStatus{ContainerStatuses: []v1.ContainerStatus{{
+				Name: container,
+				LastTerminationState: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{
+					Reason:     oomKilledReason,
+					FinishedAt: metav1.NewTime(oomAt),
+				}},
+			}}},
+		}
+	}
+	pods := []*v1.Pod{
+		// c1 is oom-killed with a lower limit than its peak, so it shouldn't be changed
+		makePod("c1", "1Gi", now.Add(-time.Hour)),
+		makePod("c2", "2Gi", now.Add(-time.Hour)),
+		// oom kills too long ago should be ignored
+		makePod("c3", "4Gi", now.Add(-2*oomRecordExpiration)),
+	}
+
+	_, containerResources, err := r.GetRecommendedPodResources(spd, pods)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int64{
+		"c1": 2469606196, // 2Gi * 1.15
+		"c2": 2576980378, // 2Gi * 1.2
+		"c3": 1234803098, // 1Gi * 1.15
+	}, getRecommendedQuantities(containerResources, v1.ResourceMemory))
+}
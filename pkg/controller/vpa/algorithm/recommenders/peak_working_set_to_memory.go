@@ -0,0 +1,122 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recommenders
+
+import (
+	"math"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	apis "github.com/kubewharf/katalyst-api/pkg/apis/autoscaling/v1alpha1"
+	workload "github.com/kubewharf/katalyst-api/pkg/apis/workload/v1alpha1"
+	apimetricpod "github.com/kubewharf/katalyst-api/pkg/metric/pod"
+	"github.com/kubewharf/katalyst-core/pkg/controller/vpa/algorithm"
+)
+
+var memoryRecommenderName = "PeakWorkingSetToMemoryRequest"
+
+const (
+	// oomBumpUpRatio specifies how much memory will be added after observing OOM.
+	oomBumpUpRatio float64 = 1.2
+	// oomMinBumpUp specifies minimal increase of memory after observing OOM.
+	oomMinBumpUp float64 = 100 * 1024 * 1024 // 100MB
+	// oomRecordExpiration is the time after which OOM kills are no longer considered
+	oomRecordExpiration = 24 * time.Hour
+
+	oomKilledReason = "OOMKilled"
+)
+
+// MemoryRecommender recommends memory requests according to peak working set;
+// and if any container is OOM-killed recently, the recommendation is bumped up
+// from the memory it is killed at, so that it won't be OOM-killed again.
+type MemoryRecommender struct {
+	PeakUsageRecommender
+
+	now func() time.Time
+}
+
+// NewMemoryRecommender construct MemoryRecommender
+func NewMemoryRecommender() algorithm.ResourceRecommender {
+	return &MemoryRecommender{
+		PeakUsageRecommender: PeakUsageRecommender{
+			name:              memoryRecommenderName,
+			metricName:        apimetricpod.CustomMetricPodMemoryUsage,
+			resourceName:      corev1.ResourceMemory,
+			safetyMarginRatio: defaultSafetyMarginRatio,
+		},
+		now: time.Now,
+	}
+}
+
+func (r *MemoryRecommender) GetRecommendedPodResources(
+	spd *workload.ServiceProfileDescriptor, pods []*corev1.Pod,
+) ([]apis.RecommendedPodResources, []apis.RecommendedContainerResources, error) {
+	containerPeaks, err := r.getContainerPeaks(spd)
+	if err != nil {
+		return nil, nil, err
+	}
+	oomMemory := r.getOOMKilledMemory(pods)
+
+	containerRecommendResources := make([]apis.RecommendedContainerResources, 0, len(containerPeaks))
+	for container, peak := range containerPeaks {
+		recommended := r.withSafetyMargin(peak)
+		if memory, ok := oomMemory[container]; ok {
+			bumpUp := math.Max(memory+oomMinBumpUp, memory*oomBumpUpRatio)
+			if bumpUp > recommended.AsApproximateFloat64() {
+				recommended = *resource.NewQuantity(int64(math.Ceil(bumpUp)), resource.BinarySI)
+			}
+		}
+		containerRecommendResources = append(containerRecommendResources,
+			makeContainerRecommendation(container, r.resourceName, recommended))
+	}
+	return nil, containerRecommendResources, nil
+}
+
+// getOOMKilledMemory returns the max memory limit (or request if no limit) of each
+// container that is OOM-killed within oomRecordExpiration.
+func (r *MemoryRecommender) getOOMKilledMemory(pods []*corev1.Pod) map[string]float64 {
+	oomMemory := make(map[string]float64)
+	for _, pod := range pods {
+		if pod == nil {
+			continue
+		}
+
+		containerMemory := make(map[string]float64, len(pod.Spec.Containers))
+		for _, container := range pod.Spec.Containers {
+			if limit, ok := container.Resources.Limits[corev1.ResourceMemory]; ok {
+				containerMemory[container.Name] = limit.AsApproximateFloat64()
+			} else if request, ok := container.Resources.Requests[corev1.ResourceMemory]; ok {
+				containerMemory[container.Name] = request.AsApproximateFloat64()
+			}
+		}
+
+		for _, status := range pod.Status.ContainerStatuses {
+			terminated := status.LastTerminationState.Terminated
+			if terminated == nil || terminated.Reason != oomKilledReason ||
+				r.now().Sub(terminated.FinishedAt.Time) > oomRecordExpiration {
+				continue
+			}
+
+			if memory, ok := containerMemory[status.Name]; ok && memory > oomMemory[status.Name] {
+				oomMemory[status.Name] = memory
+			}
+		}
+	}
+	return oomMemory
+}
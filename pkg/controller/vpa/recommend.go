@@ -56,6 +56,8 @@ const metricNameRecommendControlVPASyncCosts = "res_rec_vpa_sync_costs"
 // rs stores all the in-tree recommendation algorithm implementations
 var rs = []algorithm.ResourceRecommender{
 	recommenders.NewCPURecommender(),
+	recommenders.NewMemoryRecommender(),
+	recommenders.NewEphemeralStorageRecommender(),
 }
 
 func init() {
@@ -180,6 +182,15 @@ func NewResourceRecommendController(ctx context.Context, controlCtx *katalystbas
 		}
 	}
 
+	// recommenders relying on clients or configurations can only be registered here
+	allResourcesRecommenders := append([]algorithm.ResourceRecommender{}, rs...)
+	if len(config.ExtendedResourceMetrics) > 0 {
+		extendedResourceRecommender := recommenders.NewExtendedResourceRecommender(genericClient.CustomClient, config.ExtendedResourceMetrics)
+		algorithm.RegisterRecommender(extendedResourceRecommender)
+		allResourcesRecommenders = append(allResourcesRecommenders, extendedResourceRecommender)
+	}
+	algorithm.RegisterRecommender(recommenders.NewAllResourcesRecommender(allResourcesRecommenders...))
+
 	recController.metricsEmitter = controlCtx.EmitterPool.GetDefaultMetricsEmitter()
 	if recController.metricsEmitter == nil {
 		recController.metricsEmitter = metrics.DummyMetrics{}
@@ -0,0 +1,760 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package local
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	"github.com/kubewharf/katalyst-core/pkg/custom-metric/store/data/types"
+)
+
+const (
+	diskHeadFileName      = "head.wal"
+	diskRawDirName        = "raw"
+	diskDownsampleDirName = "downsampled"
+	diskSegmentSuffix     = ".seg"
+	diskIndexSuffix       = ".idx"
+)
+
+// diskSeriesSelector selects series by metric and object, and an empty objectName
+// matches all objects with the given kind and namespace; nil selector matches all series.
+type diskSeriesSelector struct {
+	name            string
+	objectKind      string
+	objectNamespace string
+	objectName      string
+}
+
+func (sel *diskSeriesSelector) matches(s *types.SeriesMetric) bool {
+	if sel == nil {
+		return true
+	}
+	return s.GetName() == sel.name && s.GetObjectKind() == sel.objectKind &&
+		s.GetObjectNamespace() == sel.objectNamespace &&
+		(sel.objectName == "" || s.GetObjectName() == sel.objectName)
+}
+
+// diskIndex maps metric names to objects that have series of the metric in a segment,
+// so that queries can skip segments without any selected series instead of scanning them.
+type diskIndex map[string]sets.String
+
+func diskIndexObjectKey(objectKind, objectNamespace, objectName string) string {
+	return strings.Join([]string{objectKind, objectNamespace, objectName}, "/")
+}
+
+func (i diskIndex) add(s *types.SeriesMetric) {
+	objects, ok := i[s.GetName()]
+	if !ok {
+		objects = sets.NewString()
+		i[s.GetName()] = objects
+	}
+	objects.Insert(diskIndexObjectKey(s.GetObjectKind(), s.GetObjectNamespace(), s.GetObjectName()))
+}
+
+// matches returns false only if none of the series indexed can be selected
+func (i diskIndex) matches(sel *diskSeriesSelector) bool {
+	if sel == nil {
+		return true
+	}
+
+	objects, ok := i[sel.name]
+	if !ok {
+		return false
+	}
+	if sel.objectName != "" {
+		return objects.Has(diskIndexObjectKey(sel.objectKind, sel.objectNamespace, sel.objectName))
+	}
+
+	prefix := diskIndexObjectKey(sel.objectKind, sel.objectNamespace, "")
+	for key := range objects {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// diskSegment is a sealed and immutable segment file, and all series items
+// in it are with timestamps in [minTimestamp, maxTimestamp].
+type diskSegment struct {
+	path         string
+	minTimestamp int64
+	maxTimestamp int64
+	index        diskIndex
+}
+
+func (s *diskSegment) overlaps(since, until int64) bool {
+	return s.maxTimestamp >= since && s.minTimestamp < until
+}
+
+// diskMetricStore persists series metrics in local disk with an append-only format.
+// series metrics are appended into the head file in json lines, and the head will be
+// sealed into a raw segment periodically; raw segments older than downsampleAfter
+// will be downsampled by averaging, and all segments older than retention will be removed.
+// each segment is persisted along with its index, and the lock order is segmentMutex
+// before headMutex.
+type diskMetricStore struct {
+	dir             string
+	segmentDuration time.Duration
+	downsampleAfter time.Duration
+	resolution      time.Duration
+	retention       time.Duration
+
+	// headMutex protects the writable head file
+	headMutex   sync.Mutex
+	head        *os.File
+	headCreated time.Time
+	headMin     int64
+	headMax     int64
+	headIndex   diskIndex
+
+	// segmentMutex protects the sealed segments, and it's held by queries while
+	// reading segment files, so that no segment file is removed during the read
+	segmentMutex       sync.RWMutex
+	rawSegments        []*diskSegment
+	downsampleSegments []*diskSegment
+}
+
+func newDiskMetricStore(dir string, segmentDuration, downsampleAfter, resolution,
+	retention time.Duration,
+) (*diskMetricStore, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("data dir for disk metric store is empty")
+	}
+
+	d := &diskMetricStore{
+		dir:             dir,
+		segmentDuration: segmentDuration,
+		downsampleAfter: downsampleAfter,
+		resolution:      resolution,
+		retention:       retention,
+		headIndex:       diskIndex{},
+	}
+
+	var err error
+	for _, sub := range []string{diskRawDirName, diskDownsampleDirName} {
+		if err = os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create dir %v: %v", sub, err)
+		}
+	}
+
+	if d.rawSegments, err = loadDiskSegments(filepath.Join(dir, diskRawDirName)); err != nil {
+		return nil, err
+	}
+	if d.downsampleSegments, err = loadDiskSegments(filepath.Join(dir, diskDownsampleDirName)); err != nil {
+		return nil, err
+	}
+
+	// the head file left by the previous process should be sealed before
+	// any new metric is appended, since we don't know its time range
+	headPath := filepath.Join(dir, diskHeadFileName)
+	if _, err = os.Stat(headPath); err == nil {
+		if err = readSegmentFile(headPath, func(s *types.SeriesMetric) {
+			d.headIndex.add(s)
+			for _, item := range s.Values {
+				d.updateHeadRange(item.Timestamp)
+			}
+		}); err != nil {
+			return nil, err
+		}
+		d.segmentMutex.Lock()
+		d.headMutex.Lock()
+		err = d.sealHead()
+		d.headMutex.Unlock()
+		d.segmentMutex.Unlock()
+		if err != nil {
+			return nil, err
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if err = d.openHead(time.Now()); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// append persists the given series metrics into the head file
+func (d *diskMetricStore) append(metricList ...types.Metric) error {
+	var buf []byte
+	var timestamps []int64
+	for _, m := range metricList {
+		s, ok := m.(*types.SeriesMetric)
+		if !ok || s == nil || s.Len() == 0 {
+			continue
+		}
+
+		line, err := json.Marshal(s)
+		if err != nil {
+			return err
+		}
+		buf = append(buf, line...)
+		buf = append(buf, '\n')
+		for _, item := range s.Values {
+			timestamps = append(timestamps, item.Timestamp)
+		}
+	}
+	if len(buf) == 0 {
+		return nil
+	}
+
+	d.headMutex.Lock()
+	defer d.headMutex.Unlock()
+
+	if d.head == nil {
+		return fmt.Errorf("disk metric store has been closed")
+	}
+	if _, err := d.head.Write(buf); err != nil {
+		return err
+	}
+	for _, ts := range timestamps {
+		d.updateHeadRange(ts)
+	}
+	for _, m := range metricList {
+		if s, ok := m.(*types.SeriesMetric); ok && s != nil && s.Len() > 0 {
+			d.headIndex.add(s)
+		}
+	}
+	return nil
+}
+
+// query returns all series metrics that are selected by the selector and match with
+// the filter, and only items with timestamps in [since, until) will be returned; segments
+// are skipped by their time range and index before being read. the same series may appear
+// multiple times in the result since it's persisted in different segments, and callers
+// should merge them by mergeDiskSeries if needed.
+func (d *diskMetricStore) query(since, until int64, sel *diskSeriesSelector,
+	filter func(s *types.SeriesMetric) bool,
+) ([]types.Metric, error) {
+	var res []types.Metric
+	handler := func(s *types.SeriesMetric) {
+		if !sel.matches(s) || (filter != nil && !filter(s)) {
+			return
+		}
+
+		values := make([]*types.SeriesItem, 0, len(s.Values))
+		for _, item := range s.Values {
+			if item.Timestamp >= since && item.Timestamp < until {
+				values = append(values, item)
+			}
+		}
+		if len(values) > 0 {
+			s.Values = values
+			res = append(res, s)
+		}
+	}
+
+	d.segmentMutex.RLock()
+	defer d.segmentMutex.RUnlock()
+
+	for _, segments := range [][]*diskSegment{d.downsampleSegments, d.rawSegments} {
+		for _, segment := range segments {
+			if !segment.overlaps(since, until) || !segment.index.matches(sel) {
+				continue
+			}
+			if err := readSegmentFile(segment.path, handler); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	d.headMutex.Lock()
+	defer d.headMutex.Unlock()
+
+	if d.head != nil && d.headMin != 0 && d.headMax >= since && d.headMin < until && d.headIndex.matches(sel) {
+		if err := readSegmentFile(d.head.Name(), handler); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// maintain seals the head file if it has been written for long enough, and then
+// downsamples and removes the sealed segments according to their ages.
+func (d *diskMetricStore) maintain(now time.Time) error {
+	if err := d.rotateHead(now); err != nil {
+		return err
+	}
+
+	var errList []error
+	if d.resolution > 0 {
+		downsampleBefore := now.Add(-d.downsampleAfter).UnixMilli()
+
+		d.segmentMutex.RLock()
+		var toDownsample []*diskSegment
+		for _, segment := range d.rawSegments {
+			if segment.maxTimestamp < downsampleBefore {
+				toDownsample = append(toDownsample, segment)
+			}
+		}
+		d.segmentMutex.RUnlock()
+
+		for _, segment := range toDownsample {
+			if err := d.downsample(segment); err != nil {
+				errList = append(errList, fmt.Errorf("downsample %v failed: %v", segment.path, err))
+			}
+		}
+	}
+
+	if d.retention > 0 {
+		if err := d.removeExpired(now.Add(-d.retention).UnixMilli()); err != nil {
+			errList = append(errList, err)
+		}
+	}
+	return utilerrors.NewAggregate(errList)
+}
+
+// rotateHead seals the head file and opens a new one if the head has been written for long enough
+func (d *diskMetricStore) rotateHead(now time.Time) error {
+	d.segmentMutex.Lock()
+	defer d.segmentMutex.Unlock()
+	d.headMutex.Lock()
+	defer d.headMutex.Unlock()
+
+	if d.head == nil || now.Sub(d.headCreated) < d.segmentDuration {
+		return nil
+	}
+	if err := d.sealHead(); err != nil {
+		return err
+	}
+	return d.openHead(now)
+}
+
+// close seals the head file, and no metric can be appended after that
+func (d *diskMetricStore) close() error {
+	d.segmentMutex.Lock()
+	defer d.segmentMutex.Unlock()
+	d.headMutex.Lock()
+	defer d.headMutex.Unlock()
+
+	return d.sealHead()
+}
+
+// downsample averages series items in the given raw segment into buckets
+// with the configured resolution, and replaces it with the downsampled one.
+func (d *diskMetricStore) downsample(segment *diskSegment) error {
+	type bucket struct {
+		sum   float64
+		count int
+	}
+	type downsampledSeries struct {
+		series  *types.SeriesMetric
+		buckets map[int64]*bucket
+	}
+
+	resolution := d.resolution.Milliseconds()
+	seriesMap := make(map[string]*downsampledSeries)
+	// the raw segment is only removed by the maintaining routine itself,
+	// so it's safe to be read without segmentMutex
+	if err := readSegmentFile(segment.path, func(s *types.SeriesMetric) {
+		key := diskSeriesKey(s)
+		ds, ok := seriesMap[key]
+		if !ok {
+			ds = &downsampledSeries{
+				series: &types.SeriesMetric{
+					MetricMetaImp: s.MetricMetaImp,
+					ObjectMetaImp: s.ObjectMetaImp,
+					BasicMetric:   s.BasicMetric,
+				},
+				buckets: make(map[int64]*bucket),
+			}
+			seriesMap[key] = ds
+		}
+
+		for _, item := range s.Values {
+			ts := item.Timestamp - item.Timestamp%resolution
+			b, ok := ds.buckets[ts]
+			if !ok {
+				b = &bucket{}
+				ds.buckets[ts] = b
+			}
+			b.sum += item.Value
+			b.count++
+		}
+	}); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(seriesMap))
+	for key := range seriesMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var buf []byte
+	var minTimestamp, maxTimestamp int64
+	index := diskIndex{}
+	for _, key := range keys {
+		ds := seriesMap[key]
+		index.add(ds.series)
+		for ts, b := range ds.buckets {
+			ds.series.AddMetric(&types.SeriesItem{Value: b.sum / float64(b.count), Timestamp: ts})
+			if minTimestamp == 0 || ts < minTimestamp {
+				minTimestamp = ts
+			}
+			if ts > maxTimestamp {
+				maxTimestamp = ts
+			}
+		}
+		sort.Slice(ds.series.Values, func(i, j int) bool {
+			return ds.series.Values[i].Timestamp < ds.series.Values[j].Timestamp
+		})
+
+		line, err := json.Marshal(ds.series)
+		if err != nil {
+			return err
+		}
+		buf = append(buf, line...)
+		buf = append(buf, '\n')
+	}
+
+	var downsampled *diskSegment
+	if len(buf) > 0 {
+		downsampled = &diskSegment{
+			path: filepath.Join(d.dir, diskDownsampleDirName,
+				diskSegmentFileName(minTimestamp, maxTimestamp, time.Now())),
+			minTimestamp: minTimestamp,
+			maxTimestamp: maxTimestamp,
+			index:        index,
+		}
+		if err := writeFileAtomic(downsampled.path, buf); err != nil {
+			return err
+		}
+		if err := writeDiskIndex(downsampled); err != nil {
+			klog.Warningf("[diskMetricStore] failed to write index of %v: %v", downsampled.path, err)
+		}
+	}
+
+	// the raw segment is replaced and removed with segmentMutex held,
+	// so that it's never removed while any query is reading it
+	d.segmentMutex.Lock()
+	defer d.segmentMutex.Unlock()
+
+	if downsampled != nil {
+		d.downsampleSegments = append(d.downsampleSegments, downsampled)
+	}
+	d.rawSegments = removeDiskSegment(d.rawSegments, segment)
+	return removeSegmentFiles(segment)
+}
+
+// removeExpired removes all segments whose items are all older than expiredTimestamp
+func (d *diskMetricStore) removeExpired(expiredTimestamp int64) error {
+	d.segmentMutex.Lock()
+	defer d.segmentMutex.Unlock()
+
+	var errList []error
+	filter := func(segments []*diskSegment) []*diskSegment {
+		res := make([]*diskSegment, 0, len(segments))
+		for _, segment := range segments {
+			if segment.maxTimestamp >= expiredTimestamp {
+				res = append(res, segment)
+				continue
+			}
+
+			klog.Infof("[diskMetricStore] remove expired segment %v", segment.path)
+			if err := removeSegmentFiles(segment); err != nil {
+				errList = append(errList, err)
+				res = append(res, segment)
+			}
+		}
+		return res
+	}
+
+	d.rawSegments = filter(d.rawSegments)
+	d.downsampleSegments = filter(d.downsampleSegments)
+	return utilerrors.NewAggregate(errList)
+}
+
+// openHead creates a new head file, and headMutex must be held by the caller.
+func (d *diskMetricStore) openHead(now time.Time) error {
+	head, err := os.OpenFile(filepath.Join(d.dir, diskHeadFileName), os.O_CREATE|os.O_WRONLY|os.O_APPEND|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+
+	d.head = head
+	d.headCreated = now
+	d.headMin, d.headMax = 0, 0
+	d.headIndex = diskIndex{}
+	return nil
+}
+
+// sealHead turns the head file into a raw segment, and both segmentMutex
+// and headMutex must be held by the caller.
+func (d *diskMetricStore) sealHead() error {
+	headPath := filepath.Join(d.dir, diskHeadFileName)
+	if d.head != nil {
+		if err := d.head.Sync(); err != nil {
+			klog.Warningf("[diskMetricStore] failed to sync head: %v", err)
+		}
+		if err := d.head.Close(); err != nil {
+			return err
+		}
+		d.head = nil
+	}
+
+	// the head doesn't contain any valid item
+	if d.headMin == 0 {
+		return os.Remove(headPath)
+	}
+
+	segment := &diskSegment{
+		path:         filepath.Join(d.dir, diskRawDirName, diskSegmentFileName(d.headMin, d.headMax, time.Now())),
+		minTimestamp: d.headMin,
+		maxTimestamp: d.headMax,
+		index:        d.headIndex,
+	}
+	if err := os.Rename(headPath, segment.path); err != nil {
+		return err
+	}
+	// the index will be rebuilt from the segment when loading if it's not written
+	if err := writeDiskIndex(segment); err != nil {
+		klog.Warningf("[diskMetricStore] failed to write index of %v: %v", segment.path, err)
+	}
+	d.headMin, d.headMax = 0, 0
+	d.headIndex = diskIndex{}
+
+	d.rawSegments = append(d.rawSegments, segment)
+	return nil
+}
+
+func (d *diskMetricStore) updateHeadRange(ts int64) {
+	if ts <= 0 {
+		return
+	}
+	if d.headMin == 0 || ts < d.headMin {
+		d.headMin = ts
+	}
+	if ts > d.headMax {
+		d.headMax = ts
+	}
+}
+
+// diskSegmentFileName generates segment file name with its time range, and the
+// creation time is used to avoid conflicts among segments with the same range.
+func diskSegmentFileName(minTimestamp, maxTimestamp int64, now time.Time) string {
+	return fmt.Sprintf("%d-%d-%d%s", minTimestamp, maxTimestamp, now.UnixNano(), diskSegmentSuffix)
+}
+
+// loadDiskSegments lists all sealed segments along with their indexes in the given dir,
+// and indexes missing or broken are rebuilt from segments; temporary files left by
+// interrupted writes and indexes without segments will be cleaned up.
+func loadDiskSegments(dir string) ([]*diskSegment, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var segments []*diskSegment
+	indexPaths := sets.NewString()
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		if strings.HasSuffix(entry.Name(), diskIndexSuffix) {
+			indexPaths.Insert(path)
+			continue
+		}
+		if !strings.HasSuffix(entry.Name(), diskSegmentSuffix) {
+			klog.Warningf("[diskMetricStore] remove unknown file %v", path)
+			_ = os.Remove(path)
+			continue
+		}
+
+		var minTimestamp, maxTimestamp, created int64
+		if _, err := fmt.Sscanf(strings.TrimSuffix(entry.Name(), diskSegmentSuffix), "%d-%d-%d",
+			&minTimestamp, &maxTimestamp, &created); err != nil {
+			klog.Warningf("[diskMetricStore] skip segment %v with invalid name: %v", path, err)
+			continue
+		}
+		segment := &diskSegment{path: path, minTimestamp: minTimestamp, maxTimestamp: maxTimestamp}
+		if segment.index, err = readDiskIndex(diskIndexPath(path)); err != nil {
+			klog.Warningf("[diskMetricStore] rebuild index of segment %v: %v", path, err)
+			segment.index = diskIndex{}
+			if err := readSegmentFile(path, segment.index.add); err != nil {
+				return nil, err
+			}
+			if err := writeDiskIndex(segment); err != nil {
+				klog.Warningf("[diskMetricStore] failed to write index of %v: %v", path, err)
+			}
+		}
+		indexPaths.Delete(diskIndexPath(path))
+		segments = append(segments, segment)
+	}
+
+	for _, path := range indexPaths.List() {
+		klog.Warningf("[diskMetricStore] remove index %v without segment", path)
+		_ = os.Remove(path)
+	}
+	return segments, nil
+}
+
+func diskIndexPath(segmentPath string) string {
+	return strings.TrimSuffix(segmentPath, diskSegmentSuffix) + diskIndexSuffix
+}
+
+// writeDiskIndex persists the index of the segment with metric names mapped to sorted object keys
+func writeDiskIndex(segment *diskSegment) error {
+	content := make(map[string][]string, len(segment.index))
+	for name, objects := range segment.index {
+		content[name] = objects.List()
+	}
+
+	buf, err := json.Marshal(content)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(diskIndexPath(segment.path), buf)
+}
+
+func readDiskIndex(path string) (diskIndex, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	content := make(map[string][]string)
+	if err := json.Unmarshal(buf, &content); err != nil {
+		return nil, err
+	}
+
+	index := make(diskIndex, len(content))
+	for name, objects := range content {
+		index[name] = sets.NewString(objects...)
+	}
+	return index, nil
+}
+
+// removeSegmentFiles removes the segment file and its index
+func removeSegmentFiles(segment *diskSegment) error {
+	var errList []error
+	for _, path := range []string{segment.path, diskIndexPath(segment.path)} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			errList = append(errList, err)
+		}
+	}
+	return utilerrors.NewAggregate(errList)
+}
+
+// readSegmentFile decodes series metrics from the given file line by line;
+// broken lines (e.g. a torn write when the process crashes) will be skipped.
+func readSegmentFile(path string, handler func(s *types.SeriesMetric)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			s := &types.SeriesMetric{}
+			if jsonErr := json.Unmarshal(line, s); jsonErr != nil {
+				klog.Warningf("[diskMetricStore] skip broken line in %v: %v", path, jsonErr)
+			} else {
+				handler(s)
+			}
+		}
+
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+func writeFileAtomic(path string, content []byte) error {
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, content, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+func removeDiskSegment(segments []*diskSegment, target *diskSegment) []*diskSegment {
+	res := make([]*diskSegment, 0, len(segments))
+	for _, segment := range segments {
+		if segment != target {
+			res = append(res, segment)
+		}
+	}
+	return res
+}
+
+// mergeDiskSeries merges items of the same series persisted in different segments,
+// and items are sorted by timestamp with duplicated ones dropped.
+func mergeDiskSeries(metricList []types.Metric) []types.Metric {
+	var keys []string
+	seriesMap := make(map[string]*types.SeriesMetric)
+	for _, m := range metricList {
+		s, ok := m.(*types.SeriesMetric)
+		if !ok {
+			continue
+		}
+
+		key := diskSeriesKey(s)
+		merged, ok := seriesMap[key]
+		if !ok {
+			seriesMap[key] = s
+			keys = append(keys, key)
+			continue
+		}
+		merged.Values = append(merged.Values, s.Values...)
+	}
+
+	res := make([]types.Metric, 0, len(keys))
+	for _, key := range keys {
+		s := seriesMap[key]
+		sort.SliceStable(s.Values, func(i, j int) bool {
+			return s.Values[i].Timestamp < s.Values[j].Timestamp
+		})
+
+		values := make([]*types.SeriesItem, 0, len(s.Values))
+		for _, item := range s.Values {
+			if len(values) > 0 && values[len(values)-1].Timestamp == item.Timestamp {
+				continue
+			}
+			values = append(values, item)
+		}
+		s.Values = values
+		res = append(res, s)
+	}
+	return res
+}
+
+func diskSeriesKey(s *types.SeriesMetric) string {
+	return strings.Join([]string{s.GetObjectNamespace(), s.GetName(), s.GetObjectKind(),
+		s.GetObjectName(), s.BasicMetric.String()}, ":")
+}
@@ -0,0 +1,215 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package local
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubewharf/katalyst-core/pkg/custom-metric/store/data/types"
+)
+
+func makeDiskTestSeries(name, objName string, labels map[string]string, items ...*types.SeriesItem) *types.SeriesMetric {
+	return &types.SeriesMetric{
+		MetricMetaImp: types.MetricMetaImp{Name: name, Namespaced: true, ObjectKind: "pods"},
+		ObjectMetaImp: types.ObjectMetaImp{ObjectNamespace: "default", ObjectName: objName},
+		BasicMetric:   types.BasicMetric{Labels: labels},
+		Values:        items,
+	}
+}
+
+func countDiskItems(metricList []types.Metric) int {
+	count := 0
+	for _, m := range metricList {
+		count += m.Len()
+	}
+	return count
+}
+
+func TestDiskMetricStoreRestart(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	d, err := newDiskMetricStore(dir, time.Hour, time.Hour, time.Minute, 24*time.Hour)
+	require.NoError(t, err)
+
+	now := time.Now().UnixMilli()
+	require.NoError(t, d.append(
+		makeDiskTestSeries("cpu", "pod-1", map[string]string{"container": "c1"},
+			types.NewInternalItem(1, now-2000), types.NewInternalItem(2, now-1000)),
+		makeDiskTestSeries("mem", "pod-1", nil, types.NewInternalItem(3, now-1000)),
+	))
+
+	res, err := d.query(0, now, nil, func(s *types.SeriesMetric) bool { return s.GetName() == "cpu" })
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(res))
+	assert.Equal(t, 2, countDiskItems(res))
+	assert.Equal(t, map[string]string{"container": "c1"}, res[0].(*types.SeriesMetric).Labels)
+
+	// simulate a crash with a torn write, and the head should be sealed into raw segment after restart
+	f, err := os.OpenFile(filepath.Join(dir, diskHeadFileName), os.O_WRONLY|os.O_APPEND, 0o644)
+	require.NoError(t, err)
+	_, err = f.WriteString("{\"name\":\"cpu\",\"val")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	d, err = newDiskMetricStore(dir, time.Hour, time.Hour, time.Minute, 24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(d.rawSegments))
+	assert.Equal(t, now-2000, d.rawSegments[0].minTimestamp)
+	assert.Equal(t, now-1000, d.rawSegments[0].maxTimestamp)
+
+	res, err = d.query(now-1500, now, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, countDiskItems(res))
+
+	require.NoError(t, d.append(makeDiskTestSeries("cpu", "pod-2", nil, types.NewInternalItem(4, now))))
+	res, err = d.query(now, now+1, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(res))
+	assert.Equal(t, "pod-2", res[0].GetObjectName())
+
+	require.NoError(t, d.close())
+	assert.Error(t, d.append(makeDiskTestSeries("cpu", "pod-2", nil, types.NewInternalItem(5, now+1))))
+
+	d, err = newDiskMetricStore(dir, time.Hour, time.Hour, time.Minute, 24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(d.rawSegments))
+	res, err = d.query(0, now+1, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 4, countDiskItems(res))
+}
+
+func TestDiskMetricStoreMaintain(t *testing.T) {
+	t.Parallel()
+
+	d, err := newDiskMetricStore(t.TempDir(), time.Hour, 6*time.Hour, time.Minute, 24*time.Hour)
+	require.NoError(t, err)
+
+	now := time.Now()
+	base := now.Add(-10 * time.Hour).Truncate(time.Minute).UnixMilli()
+	expired := now.Add(-30 * time.Hour).UnixMilli()
+	require.NoError(t, d.append(
+		makeDiskTestSeries("cpu", "pod-1", nil,
+			types.NewInternalItem(1, base), types.NewInternalItem(3, base+10000),
+			types.NewInternalItem(5, base+60000)),
+		makeDiskTestSeries("cpu", "pod-2", nil, types.NewInternalItem(7, base+20000)),
+	))
+
+	// the head is not old enough to be sealed
+	require.NoError(t, d.maintain(now))
+	assert.Equal(t, 0, len(d.rawSegments))
+
+	// the sealed segment should be downsampled immediately since it's older than downsampleAfter
+	require.NoError(t, d.maintain(now.Add(time.Hour)))
+	assert.Equal(t, 0, len(d.rawSegments))
+	assert.Equal(t, 1, len(d.downsampleSegments))
+
+	res, err := d.query(0, now.UnixMilli(), nil, func(s *types.SeriesMetric) bool { return s.GetObjectName() == "pod-1" })
+	require.NoError(t, err)
+	require.Equal(t, 1, len(res))
+	assert.Equal(t, []*types.SeriesItem{
+		types.NewInternalItem(2, base),
+		types.NewInternalItem(5, base+60000),
+	}, res[0].(*types.SeriesMetric).Values)
+
+	res, err = d.query(0, now.UnixMilli(), nil, func(s *types.SeriesMetric) bool { return s.GetObjectName() == "pod-2" })
+	require.NoError(t, err)
+	require.Equal(t, 1, len(res))
+	assert.Equal(t, []*types.SeriesItem{types.NewInternalItem(7, base)}, res[0].(*types.SeriesMetric).Values)
+
+	// segments out of retention should be removed
+	require.NoError(t, d.append(makeDiskTestSeries("cpu", "pod-3", nil, types.NewInternalItem(1, expired))))
+	require.NoError(t, d.maintain(now.Add(2*time.Hour)))
+	assert.Equal(t, 0, len(d.rawSegments))
+	assert.Equal(t, 1, len(d.downsampleSegments))
+
+	res, err = d.query(0, now.UnixMilli(), nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 3, countDiskItems(res))
+}
+
+func TestDiskMetricStoreIndex(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	d, err := newDiskMetricStore(dir, time.Hour, 24*time.Hour, time.Minute, 24*time.Hour)
+	require.NoError(t, err)
+
+	now := time.Now()
+	ts := now.UnixMilli()
+	require.NoError(t, d.append(
+		makeDiskTestSeries("cpu", "pod-1", nil, types.NewInternalItem(1, ts-2000)),
+		makeDiskTestSeries("mem", "pod-2", nil, types.NewInternalItem(2, ts-2000)),
+	))
+	require.NoError(t, d.maintain(now.Add(time.Hour)))
+	require.NoError(t, d.append(makeDiskTestSeries("cpu", "pod-1", nil, types.NewInternalItem(3, ts-1000))))
+	require.Equal(t, 1, len(d.rawSegments))
+
+	cpuOfPod1 := &diskSeriesSelector{name: "cpu", objectKind: "pods", objectNamespace: "default", objectName: "pod-1"}
+	for _, tc := range []struct {
+		sel     *diskSeriesSelector
+		matched bool
+	}{
+		{sel: nil, matched: true},
+		{sel: cpuOfPod1, matched: true},
+		{sel: &diskSeriesSelector{name: "cpu", objectKind: "pods", objectNamespace: "default"}, matched: true},
+		{sel: &diskSeriesSelector{name: "cpu", objectKind: "pods", objectNamespace: "default", objectName: "pod-2"}, matched: false},
+		{sel: &diskSeriesSelector{name: "cpu", objectKind: "pods", objectNamespace: "other"}, matched: false},
+		{sel: &diskSeriesSelector{name: "disk", objectKind: "pods", objectNamespace: "default"}, matched: false},
+	} {
+		assert.Equal(t, tc.matched, d.rawSegments[0].index.matches(tc.sel), "%+v", tc.sel)
+	}
+
+	// series of the same object in the segment and the head are merged
+	res, err := d.query(0, ts, cpuOfPod1, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(res))
+	res = mergeDiskSeries(res)
+	require.Equal(t, 1, len(res))
+	assert.Equal(t, []*types.SeriesItem{
+		types.NewInternalItem(1, ts-2000),
+		types.NewInternalItem(3, ts-1000),
+	}, res[0].(*types.SeriesMetric).Values)
+
+	// segments not matched by the index are not read at all
+	require.NoError(t, os.WriteFile(d.rawSegments[0].path, []byte("broken\n"), 0o644))
+	res, err = d.query(0, ts, &diskSeriesSelector{name: "cpu", objectKind: "pods", objectNamespace: "default", objectName: "pod-3"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, len(res))
+
+	// indexes are persisted with segments, and rebuilt if missing
+	require.NoError(t, d.close())
+	indexPath := diskIndexPath(d.rawSegments[1].path)
+	_, err = os.Stat(indexPath)
+	require.NoError(t, err)
+	require.NoError(t, os.Remove(indexPath))
+
+	d, err = newDiskMetricStore(dir, time.Hour, 24*time.Hour, time.Minute, 24*time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(d.rawSegments))
+	for _, segment := range d.rawSegments {
+		_, err = os.Stat(diskIndexPath(segment.path))
+		assert.NoError(t, err)
+		assert.True(t, segment.index.matches(cpuOfPod1))
+	}
+}
@@ -152,11 +152,19 @@ func (l *LocalMemoryMetricStore) Stop() error {
 }
 
 func (l *LocalMemoryMetricStore) InsertMetric(seriesList []*data.MetricSeries) error {
+	_, err := l.insertMetric(seriesList)
+	return err
+}
+
+// insertMetric parses and adds the given series into cache, and returns
+// the parsed internalMetric that have been inserted successfully.
+func (l *LocalMemoryMetricStore) insertMetric(seriesList []*data.MetricSeries) ([]types.Metric, error) {
 	begin := time.Now()
 	defer func() {
 		klog.V(5).Infof("[LocalMemoryMetricStore] InsertMetric costs %s", time.Since(begin).String())
 	}()
 
+	inserted := make([]types.Metric, 0, len(seriesList))
 	for _, series := range seriesList {
 		begin := time.Now()
 		seriesData, ok := l.parseMetricSeries(series)
@@ -171,11 +179,12 @@ func (l *LocalMemoryMetricStore) InsertMetric(seriesList []*data.MetricSeries) e
 				metrics.MetricTag{Key: "metric_name", Val: seriesData.GetName()},
 				metrics.MetricTag{Key: "object_kind", Val: seriesData.GetObjectKind()},
 			)
-			return err
+			return inserted, err
 		}
+		inserted = append(inserted, seriesData)
 		klog.V(6).Infof("LocalMemoryMetricStore] insert with %v, costs %s", seriesData.String(), time.Since(begin).String())
 	}
-	return nil
+	return inserted, nil
 }
 
 func (l *LocalMemoryMetricStore) getObjectMetaByIndex(gr *schema.GroupResource, objSelector labels.Selector) (bool, []types.ObjectMetaImp, error) {
@@ -236,7 +245,6 @@ func (l *LocalMemoryMetricStore) GetMetric(_ context.Context, namespace, metricN
 	}()
 
 	var (
-		res               []types.Metric
 		metricList        []types.Metric
 		err               error
 		hitIndex          bool
@@ -267,6 +275,15 @@ func (l *LocalMemoryMetricStore) GetMetric(_ context.Context, namespace, metricN
 		return metricList, err
 	}
 
+	return l.filterMetricList(metricList, gr, namespace, objName, objSelector), nil
+}
+
+// filterMetricList filters out those internalMetric that don't match with the
+// kubernetes objects referred by objName or objSelector
+func (l *LocalMemoryMetricStore) filterMetricList(metricList []types.Metric, gr *schema.GroupResource,
+	namespace, objName string, objSelector labels.Selector,
+) []types.Metric {
+	var res []types.Metric
 	for _, metricItem := range metricList {
 		if objName != "" {
 			if valid, err := l.checkInternalMetricMatchedWithObject(metricItem, gr, namespace, objName); err != nil {
@@ -288,7 +305,7 @@ func (l *LocalMemoryMetricStore) GetMetric(_ context.Context, namespace, metricN
 
 		res = append(res, metricItem)
 	}
-	return res, nil
+	return res
 }
 
 func (l *LocalMemoryMetricStore) ListMetricMeta(_ context.Context, withObject bool) ([]types.MetricMeta, error) {
@@ -0,0 +1,315 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package local
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/selection"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+
+	katalystbase "github.com/kubewharf/katalyst-core/cmd/base"
+	metricconf "github.com/kubewharf/katalyst-core/pkg/config/metric"
+	"github.com/kubewharf/katalyst-core/pkg/custom-metric/store"
+	"github.com/kubewharf/katalyst-core/pkg/custom-metric/store/data"
+	"github.com/kubewharf/katalyst-core/pkg/custom-metric/store/data/types"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+)
+
+const (
+	MetricStoreNameMultiTier = "multi-tier-store"
+
+	MetricNamePersistFailed = "kcmas_multi_tier_store_persist_failed"
+
+	multiTierMaintainPeriod = time.Minute
+)
+
+// MultiTierMetricStore implements MetricStore with two tiers: the hot tier is
+// a LocalMemoryMetricStore which keeps recent metrics in memory, and the cold tier
+// persists all metrics in local disk with downsampling; so that metrics can survive
+// restarts, and queries can be performed in a time window longer than memory holds.
+type MultiTierMetricStore struct {
+	*LocalMemoryMetricStore
+
+	disk *diskMetricStore
+}
+
+var _ store.MetricStore = &MultiTierMetricStore{}
+
+func NewMultiTierMetricStore(ctx context.Context, baseCtx *katalystbase.GenericContext,
+	genericConf *metricconf.GenericMetricConfiguration, storeConf *metricconf.StoreConfiguration,
+) (store.MetricStore, error) {
+	s, err := NewLocalMemoryMetricStore(ctx, baseCtx, genericConf, storeConf)
+	if err != nil {
+		return nil, err
+	}
+
+	l, ok := s.(*LocalMemoryMetricStore)
+	if !ok {
+		return nil, fmt.Errorf("error to transform metric store into local memory store")
+	}
+
+	disk, err := newDiskMetricStore(storeConf.MultiTierDataDir, storeConf.MultiTierSegmentDuration,
+		storeConf.MultiTierDownsampleAfter, storeConf.MultiTierDownsampleResolution, storeConf.MultiTierRetention)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init disk metric store: %v", err)
+	}
+
+	return &MultiTierMetricStore{
+		LocalMemoryMetricStore: l,
+		disk:                   disk,
+	}, nil
+}
+
+func (m *MultiTierMetricStore) Name() string { return MetricStoreNameMultiTier }
+
+func (m *MultiTierMetricStore) Start() error {
+	if err := m.LocalMemoryMetricStore.Start(); err != nil {
+		return err
+	}
+
+	m.restore()
+	go wait.Until(m.maintain, multiTierMaintainPeriod, m.ctx.Done())
+	return nil
+}
+
+func (m *MultiTierMetricStore) Stop() error {
+	return utilerrors.NewAggregate([]error{m.LocalMemoryMetricStore.Stop(), m.disk.close()})
+}
+
+func (m *MultiTierMetricStore) InsertMetric(seriesList []*data.MetricSeries) error {
+	inserted, err := m.insertMetric(seriesList)
+	if len(inserted) > 0 {
+		if persistErr := m.disk.append(inserted...); persistErr != nil {
+			klog.Errorf("[MultiTierMetricStore] persist %v metrics failed: %v", len(inserted), persistErr)
+			_ = m.emitter.StoreInt64(MetricNamePersistFailed, int64(len(inserted)), metrics.MetricTypeNameCount)
+		}
+	}
+	return err
+}
+
+// GetMetric serves queries from the hot tier by default; and if a time window is given
+// by requirements on the timestamp label in metricSelector, e.g. "timestamp>1700000000000",
+// windows beyond the hot tier are served from the cold tier, which holds all metrics with
+// older ones downsampled. Since only raw series are persisted, aggregated metrics are not
+// supported for such windows, and the window must be no longer than MultiTierMaxQueryRange.
+func (m *MultiTierMetricStore) GetMetric(ctx context.Context, namespace, metricName, objName string,
+	gr *schema.GroupResource, objSelector, metricSelector labels.Selector, latest bool,
+) ([]types.Metric, error) {
+	since, until, metricSelector, err := parseTimeWindow(metricSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if since == 0 && until == math.MaxInt64 {
+		return m.LocalMemoryMetricStore.GetMetric(ctx, namespace, metricName, objName, gr, objSelector, metricSelector, latest)
+	}
+	if since >= m.hotTimestamp(now) {
+		metricList, err := m.LocalMemoryMetricStore.GetMetric(ctx, namespace, metricName, objName, gr, objSelector, metricSelector, latest)
+		if err != nil {
+			return nil, err
+		}
+		return filterSeriesInWindow(metricList, since, until), nil
+	}
+
+	metricList, err := m.getColdMetric(namespace, metricName, objName, gr, objSelector, metricSelector, since, until, now)
+	if err != nil {
+		return nil, err
+	}
+	if latest {
+		for _, metric := range metricList {
+			if s, ok := metric.(*types.SeriesMetric); ok && len(s.Values) > 0 {
+				s.Values = s.Values[len(s.Values)-1:]
+			}
+		}
+	}
+	return metricList, nil
+}
+
+// getColdMetric returns raw series of the given metric with items in [since, until) from the cold tier
+func (m *MultiTierMetricStore) getColdMetric(namespace, metricName, objName string, gr *schema.GroupResource,
+	objSelector, metricSelector labels.Selector, since, until int64, now time.Time,
+) ([]types.Metric, error) {
+	defer func() {
+		klog.V(5).Infof("[MultiTierMetricStore] getColdMetric costs %s", time.Since(now).String())
+	}()
+
+	if metricName == "" || metricName == "*" {
+		return nil, fmt.Errorf("metric name must be specified for time windows beyond memory")
+	}
+	if _, aggName := types.ParseAggregator(metricName); aggName != "" {
+		return nil, fmt.Errorf("aggregated metric %v is not supported for time windows beyond memory", metricName)
+	}
+	if until == math.MaxInt64 {
+		until = now.UnixMilli() + 1
+	}
+	if until <= since {
+		return nil, fmt.Errorf("invalid time window [%v, %v)", since, until)
+	}
+	if maxRange := m.storeConf.MultiTierMaxQueryRange; maxRange > 0 && time.Duration(until-since)*time.Millisecond > maxRange {
+		return nil, fmt.Errorf("time window %v exceeds the max query range %v", time.Duration(until-since)*time.Millisecond, maxRange)
+	}
+
+	sel := &diskSeriesSelector{name: metricName, objectNamespace: namespace}
+	if gr != nil {
+		sel.objectKind = gr.String()
+	}
+	if objName != "" && objName != "*" {
+		sel.objectName = objName
+	}
+
+	if retentionTimestamp := m.retentionTimestamp(now); since < retentionTimestamp {
+		since = retentionTimestamp
+	}
+	metricList, err := m.disk.query(since, until, sel, func(s *types.SeriesMetric) bool {
+		return s.GetNamespaced() == (namespace != "") &&
+			(metricSelector == nil || metricSelector.Matches(labels.Set(s.Labels)))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("query %v from disk failed: %v", metricName, err)
+	}
+	return m.filterMetricList(mergeDiskSeries(metricList), gr, namespace, objName, objSelector), nil
+}
+
+// restore loads metrics persisted in disk back into the hot tier
+func (m *MultiTierMetricStore) restore() {
+	begin := time.Now()
+	defer func() {
+		klog.Infof("[MultiTierMetricStore] restore costs %s", time.Since(begin).String())
+	}()
+
+	metricList, err := m.disk.query(m.hotTimestamp(begin), math.MaxInt64, nil, nil)
+	if err != nil {
+		klog.Errorf("[MultiTierMetricStore] failed to restore metrics from disk: %v", err)
+		return
+	}
+
+	if err := m.cache.AddSeriesMetric(metricList...); err != nil {
+		klog.Errorf("[MultiTierMetricStore] failed to add restored metrics: %v", err)
+		return
+	}
+	klog.Infof("[MultiTierMetricStore] restored %v series from disk", len(metricList))
+}
+
+func (m *MultiTierMetricStore) maintain() {
+	begin := time.Now()
+	defer func() {
+		klog.Infof("[MultiTierMetricStore] maintain costs %s", time.Since(begin).String())
+	}()
+
+	if err := m.disk.maintain(begin); err != nil {
+		klog.Errorf("[MultiTierMetricStore] maintain disk failed: %v", err)
+	}
+}
+
+// hotTimestamp returns the timestamp since when metrics are kept in the hot tier
+func (m *MultiTierMetricStore) hotTimestamp(now time.Time) int64 {
+	return now.Add(-1 * m.genericConf.OutOfDataPeriod).UnixMilli()
+}
+
+// retentionTimestamp returns the timestamp since when metrics are kept in the cold tier
+func (m *MultiTierMetricStore) retentionTimestamp(now time.Time) int64 {
+	if m.storeConf.MultiTierRetention <= 0 {
+		return 0
+	}
+	return now.Add(-1 * m.storeConf.MultiTierRetention).UnixMilli()
+}
+
+// parseTimeWindow extracts the time window [since, until) in milliseconds from requirements
+// on the timestamp label, and returns the selector with the other requirements; the window
+// is [0, math.MaxInt64) if no such requirement is given.
+func parseTimeWindow(metricSelector labels.Selector) (int64, int64, labels.Selector, error) {
+	since, until := int64(0), int64(math.MaxInt64)
+	if metricSelector == nil {
+		return since, until, metricSelector, nil
+	}
+
+	requirements, selectable := metricSelector.Requirements()
+	if !selectable {
+		return since, until, metricSelector, nil
+	}
+
+	found := false
+	rest := labels.NewSelector()
+	for _, requirement := range requirements {
+		if requirement.Key() != string(data.CustomMetricLabelKeyTimestamp) {
+			rest = rest.Add(requirement)
+			continue
+		}
+
+		values := requirement.Values().List()
+		if len(values) != 1 {
+			return 0, 0, nil, fmt.Errorf("invalid time window requirement %v", requirement.String())
+		}
+		timestamp, err := strconv.ParseInt(values[0], 10, 64)
+		if err != nil {
+			return 0, 0, nil, fmt.Errorf("invalid time window requirement %v: %v", requirement.String(), err)
+		}
+
+		switch requirement.Operator() {
+		case selection.GreaterThan:
+			if timestamp+1 > since {
+				since = timestamp + 1
+			}
+		case selection.LessThan:
+			if timestamp < until {
+				until = timestamp
+			}
+		default:
+			return 0, 0, nil, fmt.Errorf("unsupported time window requirement %v", requirement.String())
+		}
+		found = true
+	}
+
+	if !found {
+		return since, until, metricSelector, nil
+	}
+	return since, until, rest, nil
+}
+
+// filterSeriesInWindow drops items out of [since, until) in series metrics
+func filterSeriesInWindow(metricList []types.Metric, since, until int64) []types.Metric {
+	res := make([]types.Metric, 0, len(metricList))
+	for _, metric := range metricList {
+		s, ok := metric.(*types.SeriesMetric)
+		if !ok {
+			res = append(res, metric)
+			continue
+		}
+
+		values := make([]*types.SeriesItem, 0, len(s.Values))
+		for _, item := range s.Values {
+			if item.Timestamp >= since && item.Timestamp < until {
+				values = append(values, item)
+			}
+		}
+		if len(values) > 0 {
+			s.Values = values
+			res = append(res, s)
+		}
+	}
+	return res
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package local
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	katalystbase "github.com/kubewharf/katalyst-core/cmd/base"
+	metricconf "github.com/kubewharf/katalyst-core/pkg/config/metric"
+	"github.com/kubewharf/katalyst-core/pkg/custom-metric/store"
+	"github.com/kubewharf/katalyst-core/pkg/custom-metric/store/data"
+	"github.com/kubewharf/katalyst-core/pkg/custom-metric/store/data/types"
+)
+
+func TestMultiTierMetricStore_GetMetric(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pod := &metav1.PartialObjectMetadata{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod-1"},
+	}
+	baseCtx, err := katalystbase.GenerateFakeGenericContext(nil, nil, nil, []runtime.Object{pod})
+	require.NoError(t, err)
+
+	storeConf := metricconf.NewStoreConfiguration()
+	storeConf.GCPeriod = time.Minute
+	storeConf.PurgePeriod = time.Minute
+	storeConf.MultiTierDataDir = t.TempDir()
+	var s store.MetricStore
+	s, err = NewMultiTierMetricStore(ctx, baseCtx, &metricconf.GenericMetricConfiguration{OutOfDataPeriod: 10 * time.Minute}, storeConf)
+	require.NoError(t, err)
+	baseCtx.StartInformer(ctx)
+	require.NoError(t, s.Start())
+	defer func() { _ = s.Stop() }()
+
+	// metrics older than the hot tier only exist in disk
+	now := time.Now()
+	require.NoError(t, s.(*MultiTierMetricStore).disk.append(makeDiskTestSeries("cpu", "pod-1", map[string]string{"container": "c1"},
+		types.NewInternalItem(1, now.Add(-2*time.Hour).UnixMilli()), types.NewInternalItem(2, now.Add(-time.Hour).UnixMilli()))))
+	require.NoError(t, s.InsertMetric([]*data.MetricSeries{
+		{
+			Name: "cpu",
+			Labels: map[string]string{
+				string(data.CustomMetricLabelKeyNamespace):  "default",
+				string(data.CustomMetricLabelKeyObject):     "pods",
+				string(data.CustomMetricLabelKeyObjectName): "pod-1",
+				"selector_container":                        "c1",
+			},
+			Series: []*data.MetricData{{Data: 3, Timestamp: now.UnixMilli()}},
+		},
+	}))
+
+	podGR := &schema.GroupResource{Resource: "pods"}
+	getMetric := func(selector string) ([]types.Metric, error) {
+		metricSelector, err := labels.Parse(selector)
+		require.NoError(t, err)
+		return s.GetMetric(ctx, "default", "cpu", "pod-1", podGR, nil, metricSelector, false)
+	}
+	windowSince := func(d time.Duration) string {
+		return fmt.Sprintf("%v>%v", data.CustomMetricLabelKeyTimestamp, now.Add(-d).UnixMilli())
+	}
+
+	// only the hot tier is read without time window
+	metricList, err := getMetric("container=c1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, countDiskItems(metricList))
+
+	// time window within the hot tier
+	metricList, err = getMetric(windowSince(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, countDiskItems(metricList))
+
+	// time window beyond the hot tier is served by disk
+	metricList, err = getMetric("container=c1," + windowSince(3*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, countDiskItems(metricList))
+	assert.Equal(t, map[string]string{"container": "c1"}, metricList[0].(*types.SeriesMetric).Labels)
+
+	metricList, err = getMetric(fmt.Sprintf("%v,%v<%v", windowSince(3*time.Hour),
+		data.CustomMetricLabelKeyTimestamp, now.Add(-90*time.Minute).UnixMilli()))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, countDiskItems(metricList))
+
+	metricList, err = getMetric("container=c2," + windowSince(3*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, countDiskItems(metricList))
+
+	metricSelector, err := labels.Parse(windowSince(3 * time.Hour))
+	require.NoError(t, err)
+	metricList, err = s.GetMetric(ctx, "default", "cpu", "pod-1", podGR, nil, metricSelector, true)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, countDiskItems(metricList))
+	assert.Equal(t, 3.0, metricList[0].(*types.SeriesMetric).Values[0].Value)
+
+	// time window longer than the max query range is rejected
+	_, err = getMetric(windowSince(48 * time.Hour))
+	assert.Error(t, err)
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+
+	apiconsts "github.com/kubewharf/katalyst-api/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+)
+
+// UsageStatistic locates one usage statistic in the node-usage scope of npd.
+type UsageStatistic struct {
+	Window     metav1.Duration `json:"window"`
+	Aggregator string          `json:"aggregator"`
+}
+
+// LoadAwareArgs holds arguments used to configure the LoadAware plugin;
+// the plugin args are decoded from raw json/yaml since they are not
+// registered in the scheduler config scheme.
+type LoadAwareArgs struct {
+	// UsageThresholds maps qos level to the max usage percentage of each resource,
+	// nodes with usage exceeding the threshold are filtered out for pods in the qos level.
+	UsageThresholds map[string]map[v1.ResourceName]int64 `json:"usageThresholds,omitempty"`
+	// FilterUsage is the usage statistic compared with thresholds in filtering.
+	FilterUsage UsageStatistic `json:"filterUsage"`
+	// ScoreUsage is the usage statistic used to predict post-placement usage in scoring.
+	ScoreUsage UsageStatistic `json:"scoreUsage"`
+	// ResourceWeights are weights of resources in scoring.
+	ResourceWeights map[v1.ResourceName]int64 `json:"resourceWeights,omitempty"`
+	// EstimatedScalingFactors are percentages of pod requests regarded as its usage,
+	// which is used for the incoming pod and pods not reflected in npd yet.
+	EstimatedScalingFactors map[v1.ResourceName]int64 `json:"estimatedScalingFactors,omitempty"`
+	// AssumedPodDuration is the duration for pods to be reflected in npd usage after
+	// being placed, and pods placed within it before the usage timestamp are estimated.
+	AssumedPodDuration metav1.Duration `json:"assumedPodDuration"`
+	// UsageExpiration is the duration after which usage in npd is regarded as stale;
+	// npd usage is stamped with its newest sample, so usage expires once no sample is
+	// collected for this duration, and nodes with stale usage are not filtered and get
+	// the lowest score.
+	UsageExpiration metav1.Duration `json:"usageExpiration"`
+}
+
+func newDefaultLoadAwareArgs() *LoadAwareArgs {
+	return &LoadAwareArgs{
+		UsageThresholds: map[string]map[v1.ResourceName]int64{
+			apiconsts.PodAnnotationQoSLevelSharedCores: {
+				v1.ResourceCPU:    80,
+				v1.ResourceMemory: 90,
+			},
+			apiconsts.PodAnnotationQoSLevelReclaimedCores: {
+				v1.ResourceCPU:    65,
+				v1.ResourceMemory: 85,
+			},
+		},
+		FilterUsage: UsageStatistic{
+			Window:     metav1.Duration{Duration: 5 * time.Minute},
+			Aggregator: consts.NPDAggregatorAvg,
+		},
+		ScoreUsage: UsageStatistic{
+			Window:     metav1.Duration{Duration: time.Hour},
+			Aggregator: consts.NPDAggregatorP95,
+		},
+		ResourceWeights: map[v1.ResourceName]int64{
+			v1.ResourceCPU:    1,
+			v1.ResourceMemory: 1,
+		},
+		EstimatedScalingFactors: map[v1.ResourceName]int64{
+			v1.ResourceCPU:    85,
+			v1.ResourceMemory: 70,
+		},
+		AssumedPodDuration: metav1.Duration{Duration: 5 * time.Minute},
+		UsageExpiration:    metav1.Duration{Duration: 5 * time.Minute},
+	}
+}
+
+// getLoadAwareArgs decodes args on top of default values, and nil args
+// means that all default values are used.
+func getLoadAwareArgs(obj runtime.Object) (*LoadAwareArgs, error) {
+	args := newDefaultLoadAwareArgs()
+	if err := frameworkruntime.DecodeInto(obj, args); err != nil {
+		return nil, err
+	}
+
+	if err := validateLoadAwareArgs(args); err != nil {
+		return nil, err
+	}
+	return args, nil
+}
+
+func validateLoadAwareArgs(args *LoadAwareArgs) error {
+	for qosLevel, thresholds := range args.UsageThresholds {
+		for resourceName, threshold := range thresholds {
+			if threshold < 0 || threshold > 100 {
+				return fmt.Errorf("usage threshold of %v for %v should be in [0, 100], got %v", resourceName, qosLevel, threshold)
+			}
+		}
+	}
+
+	for _, statistic := range []UsageStatistic{args.FilterUsage, args.ScoreUsage} {
+		if statistic.Window.Duration <= 0 || statistic.Aggregator == "" {
+			return fmt.Errorf("invalid usage statistic %+v", statistic)
+		}
+	}
+
+	totalWeight := int64(0)
+	for resourceName, weight := range args.ResourceWeights {
+		if weight < 0 {
+			return fmt.Errorf("weight of %v should be non-negative, got %v", resourceName, weight)
+		}
+		totalWeight += weight
+	}
+	if totalWeight == 0 {
+		return fmt.Errorf("at least one resource should have positive weight")
+	}
+
+	for resourceName, factor := range args.EstimatedScalingFactors {
+		if factor < 0 || factor > 100 {
+			return fmt.Errorf("estimated scaling factor of %v should be in [0, 100], got %v", resourceName, factor)
+		}
+	}
+
+	if args.AssumedPodDuration.Duration < 0 {
+		return fmt.Errorf("assumed pod duration should be non-negative, got %v", args.AssumedPodDuration.Duration)
+	}
+	if args.UsageExpiration.Duration <= 0 {
+		return fmt.Errorf("usage expiration should be positive, got %v", args.UsageExpiration.Duration)
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/util"
+)
+
+var cache *loadAwareCache
+
+func init() {
+	cache = &loadAwareCache{
+		nodeCaches: map[string]*NodeCache{},
+	}
+}
+
+// loadAwareCache stores node usage from npd, and pods assumed to nodes
+// recently whose usage may not be reflected in npd yet.
+type loadAwareCache struct {
+	sync.RWMutex
+	nodeCaches map[string]*NodeCache
+}
+
+func GetCache() *loadAwareCache {
+	return cache
+}
+
+type assumedPod struct {
+	pod         *v1.Pod
+	assumedTime time.Time
+}
+
+type NodeCache struct {
+	// usage metrics in node-usage scope of npd
+	usage []v1alpha1.MetricValue
+	// assumedPods maps pod key to pods assumed to this node
+	assumedPods map[string]*assumedPod
+}
+
+func newNodeCache() *NodeCache {
+	return &NodeCache{
+		assumedPods: map[string]*assumedPod{},
+	}
+}
+
+func (c *loadAwareCache) getOrCreateNodeCache(nodeName string) *NodeCache {
+	n, ok := c.nodeCaches[nodeName]
+	if !ok {
+		n = newNodeCache()
+		c.nodeCaches[nodeName] = n
+	}
+	return n
+}
+
+func (c *loadAwareCache) AddOrUpdateNPD(npd *v1alpha1.NodeProfileDescriptor) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.getOrCreateNodeCache(npd.Name).usage = util.ExtractNPDScopedNodeMetrics(&npd.Status, consts.NPDScopeNodeUsage)
+}
+
+func (c *loadAwareCache) RemoveNPD(npd *v1alpha1.NodeProfileDescriptor) {
+	c.Lock()
+	defer c.Unlock()
+
+	delete(c.nodeCaches, npd.Name)
+}
+
+// GetNodeUsage returns usage metrics of the node with the given window and
+// aggregator, indexed by resource name.
+func (c *loadAwareCache) GetNodeUsage(nodeName string, window time.Duration, aggregator string) map[v1.ResourceName]v1alpha1.MetricValue {
+	c.RLock()
+	defer c.RUnlock()
+
+	n, ok := c.nodeCaches[nodeName]
+	if !ok {
+		return nil
+	}
+
+	usage := make(map[v1.ResourceName]v1alpha1.MetricValue)
+	for _, metric := range n.usage {
+		if metric.Window == nil || metric.Window.Duration != window ||
+			metric.Aggregator == nil || string(*metric.Aggregator) != aggregator {
+			continue
+		}
+		usage[v1.ResourceName(metric.MetricName)] = metric
+	}
+	return usage
+}
+
+// GetAssumedPods returns pods assumed to the node after the given time.
+func (c *loadAwareCache) GetAssumedPods(nodeName string, since time.Time) []*v1.Pod {
+	c.RLock()
+	defer c.RUnlock()
+
+	n, ok := c.nodeCaches[nodeName]
+	if !ok {
+		return nil
+	}
+
+	var pods []*v1.Pod
+	for _, p := range n.assumedPods {
+		if p.assumedTime.After(since) {
+			pods = append(pods, p.pod)
+		}
+	}
+	return pods
+}
+
+func (c *loadAwareCache) AssumePod(nodeName string, pod *v1.Pod, now time.Time) error {
+	key, err := framework.GetPodKey(pod)
+	if err != nil {
+		return err
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	c.getOrCreateNodeCache(nodeName).assumedPods[key] = &assumedPod{pod: pod, assumedTime: now}
+	return nil
+}
+
+func (c *loadAwareCache) ForgetPod(nodeName string, pod *v1.Pod) error {
+	key, err := framework.GetPodKey(pod)
+	if err != nil {
+		return err
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	if n, ok := c.nodeCaches[nodeName]; ok {
+		delete(n.assumedPods, key)
+	}
+	return nil
+}
+
+// PruneAssumedPods drops pods assumed before the deadline, since their usage
+// must have been reflected in npd (or npd has been stale and won't be used).
+func (c *loadAwareCache) PruneAssumedPods(nodeName string, deadline time.Time) {
+	c.Lock()
+	defer c.Unlock()
+
+	n, ok := c.nodeCaches[nodeName]
+	if !ok {
+		return
+	}
+	for key, p := range n.assumedPods {
+		if p.assumedTime.Before(deadline) {
+			delete(n.assumedPods, key)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	schedutil "k8s.io/kubernetes/pkg/scheduler/util"
+
+	apiconsts "github.com/kubewharf/katalyst-api/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/scheduler/util"
+)
+
+// getResourceValue returns milli-value for cpu and value for other resources,
+// which is the unit of resources in usage calculation.
+func getResourceValue(resourceName v1.ResourceName, quantity resource.Quantity) int64 {
+	if resourceName == v1.ResourceCPU {
+		return quantity.MilliValue()
+	}
+	return quantity.Value()
+}
+
+// estimatePodUsage estimates usage of the pod by scaling its requests, and
+// reclaimed resources are regarded as their native counterparts since they
+// share the same physical resources.
+func (la *LoadAware) estimatePodUsage(pod *v1.Pod) map[v1.ResourceName]int64 {
+	requests := util.GetPodEffectiveRequest(pod)
+
+	estimated := make(map[v1.ResourceName]int64)
+	for resourceName, factor := range la.args.EstimatedScalingFactors {
+		var request int64
+		switch resourceName {
+		case v1.ResourceCPU:
+			request = getResourceValue(v1.ResourceCPU, requests[v1.ResourceCPU])
+			if reclaimed, ok := requests[apiconsts.ReclaimedResourceMilliCPU]; ok {
+				// reclaimed milli-cpu is already in milli unit
+				request += reclaimed.Value()
+			}
+			if request == 0 {
+				request = schedutil.DefaultMilliCPURequest
+			}
+		case v1.ResourceMemory:
+			request = getResourceValue(v1.ResourceMemory, requests[v1.ResourceMemory])
+			if reclaimed, ok := requests[apiconsts.ReclaimedResourceMemory]; ok {
+				request += reclaimed.Value()
+			}
+			if request == 0 {
+				request = schedutil.DefaultMemoryRequest
+			}
+		default:
+			quantity := requests[resourceName]
+			request = getResourceValue(resourceName, quantity)
+		}
+		estimated[resourceName] = request * factor / 100
+	}
+	return estimated
+}
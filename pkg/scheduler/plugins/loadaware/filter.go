@@ -0,0 +1,68 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"github.com/kubewharf/katalyst-core/pkg/scheduler/util"
+)
+
+// Filter rejects nodes whose usage exceeds the thresholds of the qos level
+// of the pod; nodes without fresh usage are not filtered, since usage-based
+// scheduling is a best-effort enhancement over request-based plugins.
+func (la *LoadAware) Filter(_ context.Context, _ *framework.CycleState, pod *v1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
+	node := nodeInfo.Node()
+	if node == nil {
+		return framework.NewStatus(framework.Error, "node not found")
+	}
+
+	thresholds := la.args.UsageThresholds[util.GetQoSLevel(pod)]
+	if len(thresholds) == 0 {
+		return nil
+	}
+
+	usage, ok := la.getNodeUsage(node.Name, la.args.FilterUsage, la.now())
+	if !ok {
+		return nil
+	}
+
+	resourceNames := make([]string, 0, len(thresholds))
+	for resourceName := range thresholds {
+		resourceNames = append(resourceNames, string(resourceName))
+	}
+	sort.Strings(resourceNames)
+
+	for _, name := range resourceNames {
+		resourceName := v1.ResourceName(name)
+		allocatable, ok := node.Status.Allocatable[resourceName]
+		if !ok || allocatable.IsZero() {
+			continue
+		}
+
+		if usage[resourceName]*100 > thresholds[resourceName]*getResourceValue(resourceName, allocatable) {
+			return framework.NewStatus(framework.Unschedulable,
+				fmt.Sprintf("node(s) %v usage exceeds threshold", resourceName))
+		}
+	}
+	return nil
+}
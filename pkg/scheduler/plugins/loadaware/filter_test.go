@@ -0,0 +1,77 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	apiconsts "github.com/kubewharf/katalyst-api/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/config/generic"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/scheduler/plugins/loadaware/cache"
+	"github.com/kubewharf/katalyst-core/pkg/scheduler/util"
+)
+
+func TestFilter(t *testing.T) {
+	t.Parallel()
+	util.SetQoSConfig(generic.NewQoSConfiguration())
+
+	now := time.Now()
+	c := cache.GetCache()
+	c.AddOrUpdateNPD(makeTestNPD("filter-node-idle", now, 5*time.Minute, consts.NPDAggregatorAvg, "2", "4Gi"))
+	c.AddOrUpdateNPD(makeTestNPD("filter-node-busy", now, 5*time.Minute, consts.NPDAggregatorAvg, "14", "4Gi"))
+	c.AddOrUpdateNPD(makeTestNPD("filter-node-medium", now, 5*time.Minute, consts.NPDAggregatorAvg, "12", "4Gi"))
+	c.AddOrUpdateNPD(makeTestNPD("filter-node-stale", now.Add(-time.Hour), 5*time.Minute, consts.NPDAggregatorAvg, "15", "15Gi"))
+	// usage with other statistics should be ignored
+	c.AddOrUpdateNPD(makeTestNPD("filter-node-other", now, time.Hour, consts.NPDAggregatorAvg, "15", "15Gi"))
+	c.AddOrUpdateNPD(makeTestNPD("filter-node-assumed", now, 5*time.Minute, consts.NPDAggregatorAvg, "10", "4Gi"))
+	assert.NoError(t, c.AssumePod("filter-node-assumed", makeTestPod("assumed-1", apiconsts.PodAnnotationQoSLevelSharedCores, "4", "1Gi"), now.Add(-time.Minute)))
+	// pods assumed long before the usage timestamp have been reflected in usage
+	assert.NoError(t, c.AssumePod("filter-node-idle", makeTestPod("assumed-2", apiconsts.PodAnnotationQoSLevelSharedCores, "16", "1Gi"), now.Add(-time.Hour)))
+
+	p, err := New(nil, nil)
+	assert.NoError(t, err)
+	la := p.(*LoadAware)
+	la.now = func() time.Time { return now }
+
+	for _, tc := range []struct {
+		name     string
+		nodeName string
+		qosLevel string
+		wantCode framework.Code
+	}{
+		{name: "idle node", nodeName: "filter-node-idle", qosLevel: apiconsts.PodAnnotationQoSLevelSharedCores, wantCode: framework.Success},
+		{name: "busy node", nodeName: "filter-node-busy", qosLevel: apiconsts.PodAnnotationQoSLevelSharedCores, wantCode: framework.Unschedulable},
+		{name: "qos level without thresholds", nodeName: "filter-node-busy", qosLevel: apiconsts.PodAnnotationQoSLevelDedicatedCores, wantCode: framework.Success},
+		{name: "stale usage", nodeName: "filter-node-stale", qosLevel: apiconsts.PodAnnotationQoSLevelSharedCores, wantCode: framework.Success},
+		{name: "usage with other statistics", nodeName: "filter-node-other", qosLevel: apiconsts.PodAnnotationQoSLevelSharedCores, wantCode: framework.Success},
+		{name: "node without usage", nodeName: "filter-node-unknown", qosLevel: apiconsts.PodAnnotationQoSLevelSharedCores, wantCode: framework.Success},
+		{name: "assumed pods exceed threshold", nodeName: "filter-node-assumed", qosLevel: apiconsts.PodAnnotationQoSLevelSharedCores, wantCode: framework.Unschedulable},
+		{name: "usage below shared threshold", nodeName: "filter-node-medium", qosLevel: apiconsts.PodAnnotationQoSLevelSharedCores, wantCode: framework.Success},
+		{name: "usage above reclaimed threshold", nodeName: "filter-node-medium", qosLevel: apiconsts.PodAnnotationQoSLevelReclaimedCores, wantCode: framework.Unschedulable},
+	} {
+		nodeInfo := framework.NewNodeInfo()
+		nodeInfo.SetNode(makeTestNode(tc.nodeName, "16", "16Gi"))
+		status := la.Filter(context.TODO(), nil, makeTestPod("pod", tc.qosLevel, "1", "1Gi"), nodeInfo)
+		assert.Equal(t, tc.wantCode, status.Code(), tc.name)
+	}
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
+	"github.com/kubewharf/katalyst-core/pkg/scheduler/plugins/loadaware/cache"
+)
+
+const (
+	// Name is the name of the plugin used in the plugin registry and configurations.
+	Name = "LoadAware"
+)
+
+var (
+	_ framework.FilterPlugin      = &LoadAware{}
+	_ framework.ScorePlugin       = &LoadAware{}
+	_ framework.ReservePlugin     = &LoadAware{}
+	_ framework.EnqueueExtensions = &LoadAware{}
+)
+
+// LoadAware filters and scores nodes by their real usage reported in npd,
+// rather than the requests of pods placed on them.
+type LoadAware struct {
+	args   *LoadAwareArgs
+	handle framework.Handle
+	now    func() time.Time
+}
+
+func (la *LoadAware) Name() string {
+	return Name
+}
+
+func New(args runtime.Object, h framework.Handle) (framework.Plugin, error) {
+	klog.Info("Creating new LoadAware plugin")
+	laArgs, err := getLoadAwareArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	klog.Infof("args: %+v", laArgs)
+
+	cache.RegisterNPDHandler()
+	return &LoadAware{
+		args:   laArgs,
+		handle: h,
+		now:    time.Now,
+	}, nil
+}
+
+// EventsToRegister returns the possible events that may make a Pod
+// failed by this plugin schedulable.
+func (la *LoadAware) EventsToRegister() []framework.ClusterEvent {
+	npdGVK := fmt.Sprintf("nodeprofiledescriptors.v1alpha1.%v", v1alpha1.GroupName)
+	return []framework.ClusterEvent{
+		{Resource: framework.Node, ActionType: framework.Add | framework.UpdateNodeAllocatable},
+		{Resource: framework.GVK(npdGVK), ActionType: framework.Add | framework.Update},
+	}
+}
+
+// getNodeUsage returns the predicted usage of the node with the given usage statistic,
+// which consists of the usage in npd and estimated usage of pods not reflected in npd;
+// false is returned if the usage is missing or stale.
+func (la *LoadAware) getNodeUsage(nodeName string, statistic UsageStatistic, now time.Time) (map[v1.ResourceName]int64, bool) {
+	metrics := cache.GetCache().GetNodeUsage(nodeName, statistic.Window.Duration, statistic.Aggregator)
+	if len(metrics) == 0 {
+		return nil, false
+	}
+
+	var latest time.Time
+	usage := make(map[v1.ResourceName]int64)
+	for resourceName, metric := range metrics {
+		if now.Sub(metric.Timestamp.Time) > la.args.UsageExpiration.Duration {
+			klog.V(4).Infof("[%v] usage of %v for node %v is stale, updated at %v", Name, resourceName, nodeName, metric.Timestamp)
+			return nil, false
+		}
+		if metric.Timestamp.After(latest) {
+			latest = metric.Timestamp.Time
+		}
+		usage[resourceName] = getResourceValue(resourceName, metric.Value)
+	}
+
+	for _, pod := range cache.GetCache().GetAssumedPods(nodeName, latest.Add(-la.args.AssumedPodDuration.Duration)) {
+		for resourceName, value := range la.estimatePodUsage(pod) {
+			usage[resourceName] += value
+		}
+	}
+	return usage, true
+}
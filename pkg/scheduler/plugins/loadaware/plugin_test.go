@@ -0,0 +1,209 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
+	apiconsts "github.com/kubewharf/katalyst-api/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/scheduler/plugins/loadaware/cache"
+)
+
+var _ framework.SharedLister = &testSharedLister{}
+
+type testSharedLister struct {
+	nodeInfoMap map[string]*framework.NodeInfo
+}
+
+func newTestSharedLister(nodes []*v1.Node) *testSharedLister {
+	nodeInfoMap := make(map[string]*framework.NodeInfo)
+	for _, node := range nodes {
+		nodeInfoMap[node.Name] = framework.NewNodeInfo()
+		nodeInfoMap[node.Name].SetNode(node)
+	}
+	return &testSharedLister{nodeInfoMap: nodeInfoMap}
+}
+
+func (f *testSharedLister) NodeInfos() framework.NodeInfoLister {
+	return f
+}
+
+func (f *testSharedLister) List() ([]*framework.NodeInfo, error) {
+	nodeInfos := make([]*framework.NodeInfo, 0, len(f.nodeInfoMap))
+	for _, nodeInfo := range f.nodeInfoMap {
+		nodeInfos = append(nodeInfos, nodeInfo)
+	}
+	return nodeInfos, nil
+}
+
+func (f *testSharedLister) HavePodsWithAffinityList() ([]*framework.NodeInfo, error) {
+	return nil, nil
+}
+
+func (f *testSharedLister) HavePodsWithRequiredAntiAffinityList() ([]*framework.NodeInfo, error) {
+	return nil, nil
+}
+
+func (f *testSharedLister) Get(nodeName string) (*framework.NodeInfo, error) {
+	return f.nodeInfoMap[nodeName], nil
+}
+
+func makeTestNode(name, cpu, memory string) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: v1.NodeStatus{
+			Allocatable: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse(cpu),
+				v1.ResourceMemory: resource.MustParse(memory),
+			},
+		},
+	}
+}
+
+func makeTestNPD(name string, timestamp time.Time, window time.Duration, aggregator, cpu, memory string) *v1alpha1.NodeProfileDescriptor {
+	agg := v1alpha1.Aggregator(aggregator)
+	makeMetric := func(resourceName v1.ResourceName, value string) v1alpha1.MetricValue {
+		return v1alpha1.MetricValue{
+			MetricName: string(resourceName),
+			Value:      resource.MustParse(value),
+			Timestamp:  metav1.NewTime(timestamp),
+			Window:     &metav1.Duration{Duration: window},
+			Aggregator: &agg,
+		}
+	}
+
+	return &v1alpha1.NodeProfileDescriptor{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: v1alpha1.NodeProfileDescriptorStatus{
+			NodeMetrics: []v1alpha1.ScopedNodeMetrics{
+				{
+					Scope: consts.NPDScopeNodeUsage,
+					Metrics: []v1alpha1.MetricValue{
+						makeMetric(v1.ResourceCPU, cpu),
+						makeMetric(v1.ResourceMemory, memory),
+					},
+				},
+			},
+		},
+	}
+}
+
+func makeTestPod(name, qosLevel, cpu, memory string) *v1.Pod {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      name,
+			UID:       types.UID(name),
+			Annotations: map[string]string{
+				apiconsts.PodAnnotationQoSLevelKey: qosLevel,
+			},
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Name: "c1",
+					Resources: v1.ResourceRequirements{
+						Requests: v1.ResourceList{},
+					},
+				},
+			},
+		},
+	}
+	if cpu != "" {
+		pod.Spec.Containers[0].Resources.Requests[v1.ResourceCPU] = resource.MustParse(cpu)
+	}
+	if memory != "" {
+		pod.Spec.Containers[0].Resources.Requests[v1.ResourceMemory] = resource.MustParse(memory)
+	}
+	return pod
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	p, err := New(nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, Name, p.Name())
+	assert.Equal(t, newDefaultLoadAwareArgs(), p.(*LoadAware).args)
+
+	p, err = New(&runtime.Unknown{
+		Raw: []byte(`{"usageThresholds":{"shared_cores":{"cpu":50}},"scoreUsage":{"window":"5m","aggregator":"max"}}`),
+	}, nil)
+	assert.NoError(t, err)
+	args := p.(*LoadAware).args
+	assert.Equal(t, map[v1.ResourceName]int64{v1.ResourceCPU: 50}, args.UsageThresholds[apiconsts.PodAnnotationQoSLevelSharedCores])
+	assert.Equal(t, UsageStatistic{Window: metav1.Duration{Duration: 5 * time.Minute}, Aggregator: consts.NPDAggregatorMax}, args.ScoreUsage)
+	assert.Equal(t, newDefaultLoadAwareArgs().FilterUsage, args.FilterUsage, "unspecified args should keep default values")
+
+	_, err = New(&runtime.Unknown{Raw: []byte(`{"usageThresholds":{"shared_cores":{"cpu":120}}}`)}, nil)
+	assert.Error(t, err)
+
+	_, err = New(&runtime.Unknown{Raw: []byte(`{"resourceWeights":{"cpu":0,"memory":0}}`)}, nil)
+	assert.Error(t, err)
+
+	_, err = New(&v1.Pod{}, nil)
+	assert.Error(t, err)
+}
+
+func TestGetNodeUsage(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	c := cache.GetCache()
+	c.AddOrUpdateNPD(makeTestNPD("usage-node-fresh", now.Add(-time.Minute), 5*time.Minute, consts.NPDAggregatorAvg, "2", "4Gi"))
+	c.AddOrUpdateNPD(makeTestNPD("usage-node-expired", now.Add(-6*time.Minute), 5*time.Minute, consts.NPDAggregatorAvg, "2", "4Gi"))
+	partial := makeTestNPD("usage-node-partial", now, 5*time.Minute, consts.NPDAggregatorAvg, "2", "4Gi")
+	partial.Status.NodeMetrics[0].Metrics[1].Timestamp = metav1.NewTime(now.Add(-time.Hour))
+	c.AddOrUpdateNPD(partial)
+
+	p, err := New(nil, nil)
+	assert.NoError(t, err)
+	la := p.(*LoadAware)
+	statistic := UsageStatistic{Window: metav1.Duration{Duration: 5 * time.Minute}, Aggregator: consts.NPDAggregatorAvg}
+
+	for _, tc := range []struct {
+		name      string
+		nodeName  string
+		wantOK    bool
+		wantUsage map[v1.ResourceName]int64
+	}{
+		{
+			name:      "usage within expiration",
+			nodeName:  "usage-node-fresh",
+			wantOK:    true,
+			wantUsage: map[v1.ResourceName]int64{v1.ResourceCPU: 2000, v1.ResourceMemory: 4 << 30},
+		},
+		{name: "usage older than expiration", nodeName: "usage-node-expired"},
+		{name: "usage of one resource older than expiration", nodeName: "usage-node-partial"},
+		{name: "node without usage", nodeName: "usage-node-unknown"},
+	} {
+		usage, ok := la.getNodeUsage(tc.nodeName, statistic, now)
+		assert.Equal(t, tc.wantOK, ok, tc.name)
+		assert.Equal(t, tc.wantUsage, usage, tc.name)
+	}
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"github.com/kubewharf/katalyst-core/pkg/scheduler/plugins/loadaware/cache"
+)
+
+// Reserve records the pod as assumed, so that its estimated usage is counted
+// until it's reflected in npd.
+func (la *LoadAware) Reserve(_ context.Context, _ *framework.CycleState, pod *v1.Pod, nodeName string) *framework.Status {
+	now := la.now()
+	cache.GetCache().PruneAssumedPods(nodeName, now.Add(-la.args.AssumedPodDuration.Duration-la.args.UsageExpiration.Duration))
+	if err := cache.GetCache().AssumePod(nodeName, pod, now); err != nil {
+		klog.Errorf("[%v] failed to assume pod %v to node %v: %v", Name, klog.KObj(pod), nodeName, err)
+	}
+
+	return framework.NewStatus(framework.Success, "")
+}
+
+func (la *LoadAware) Unreserve(_ context.Context, _ *framework.CycleState, pod *v1.Pod, nodeName string) {
+	if err := cache.GetCache().ForgetPod(nodeName, pod); err != nil {
+		klog.Errorf("[%v] failed to forget pod %v from node %v: %v", Name, klog.KObj(pod), nodeName, err)
+	}
+}
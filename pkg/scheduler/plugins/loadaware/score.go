@@ -0,0 +1,75 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// Score prefers nodes with lower usage after the pod is placed, and nodes
+// without fresh usage get the lowest score.
+func (la *LoadAware) Score(_ context.Context, _ *framework.CycleState, pod *v1.Pod, nodeName string) (int64, *framework.Status) {
+	nodeInfo, err := la.handle.SnapshotSharedLister().NodeInfos().Get(nodeName)
+	if err != nil {
+		return 0, framework.AsStatus(fmt.Errorf("getting node %q from Snapshot: %w", nodeName, err))
+	}
+	node := nodeInfo.Node()
+	if node == nil {
+		return 0, framework.NewStatus(framework.Error, "node not found")
+	}
+
+	usage, ok := la.getNodeUsage(nodeName, la.args.ScoreUsage, la.now())
+	if !ok {
+		return framework.MinNodeScore, nil
+	}
+
+	estimated := la.estimatePodUsage(pod)
+	var score, weightSum int64
+	for resourceName, weight := range la.args.ResourceWeights {
+		allocatable, ok := node.Status.Allocatable[resourceName]
+		if !ok || allocatable.IsZero() || weight == 0 {
+			continue
+		}
+
+		predicted := usage[resourceName] + estimated[resourceName]
+		score += leastUsedScore(predicted, getResourceValue(resourceName, allocatable)) * weight
+		weightSum += weight
+	}
+
+	if weightSum == 0 {
+		return framework.MinNodeScore, nil
+	}
+	return score / weightSum, nil
+}
+
+func (la *LoadAware) ScoreExtensions() framework.ScoreExtensions {
+	return nil
+}
+
+func leastUsedScore(used, capacity int64) int64 {
+	if capacity <= 0 || used >= capacity {
+		return framework.MinNodeScore
+	}
+	if used < 0 {
+		used = 0
+	}
+	return (capacity - used) * framework.MaxNodeScore / capacity
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadaware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+
+	apiconsts "github.com/kubewharf/katalyst-api/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/config/generic"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/scheduler/plugins/loadaware/cache"
+	"github.com/kubewharf/katalyst-core/pkg/scheduler/util"
+)
+
+func TestScore(t *testing.T) {
+	t.Parallel()
+	util.SetQoSConfig(generic.NewQoSConfiguration())
+
+	now := time.Now()
+	c := cache.GetCache()
+	c.AddOrUpdateNPD(makeTestNPD("score-node-a", now, time.Hour, consts.NPDAggregatorP95, "4", "4Gi"))
+	c.AddOrUpdateNPD(makeTestNPD("score-node-b", now, time.Hour, consts.NPDAggregatorP95, "12", "4Gi"))
+	c.AddOrUpdateNPD(makeTestNPD("score-node-stale", now.Add(-time.Hour), time.Hour, consts.NPDAggregatorP95, "1", "1Gi"))
+
+	nodes := []*v1.Node{
+		makeTestNode("score-node-a", "16", "16Gi"),
+		makeTestNode("score-node-b", "16", "16Gi"),
+		makeTestNode("score-node-stale", "16", "16Gi"),
+	}
+	f, err := runtime.NewFramework(nil, nil,
+		runtime.WithSnapshotSharedLister(newTestSharedLister(nodes)))
+	assert.NoError(t, err)
+
+	p, err := New(nil, f)
+	assert.NoError(t, err)
+	la := p.(*LoadAware)
+	la.now = func() time.Time { return now }
+
+	pod := makeTestPod("pod", apiconsts.PodAnnotationQoSLevelSharedCores, "2", "2Gi")
+	for nodeName, wantScore := range map[string]int64{
+		"score-node-a":     65,
+		"score-node-b":     40,
+		"score-node-stale": framework.MinNodeScore,
+	} {
+		score, status := la.Score(context.TODO(), nil, pod, nodeName)
+		assert.True(t, status.IsSuccess())
+		assert.Equal(t, wantScore, score, nodeName)
+	}
+
+	// usage of reserved pods should be estimated before being reflected in npd
+	assumed := makeTestPod("assumed", apiconsts.PodAnnotationQoSLevelSharedCores, "4", "4Gi")
+	assert.True(t, la.Reserve(context.TODO(), nil, assumed, "score-node-a").IsSuccess())
+	score, status := la.Score(context.TODO(), nil, pod, "score-node-a")
+	assert.True(t, status.IsSuccess())
+	assert.Equal(t, int64(45), score)
+
+	la.Unreserve(context.TODO(), nil, assumed, "score-node-a")
+	score, status = la.Score(context.TODO(), nil, pod, "score-node-a")
+	assert.True(t, status.IsSuccess())
+	assert.Equal(t, int64(65), score)
+}
+
+func TestEstimatePodUsage(t *testing.T) {
+	t.Parallel()
+
+	la := &LoadAware{args: newDefaultLoadAwareArgs()}
+	assert.Equal(t, map[v1.ResourceName]int64{
+		v1.ResourceCPU:    1700,
+		v1.ResourceMemory: 1503238553,
+	}, la.estimatePodUsage(makeTestPod("pod", apiconsts.PodAnnotationQoSLevelSharedCores, "2", "2Gi")))
+
+	// pods without requests are estimated by default requests
+	assert.Equal(t, map[v1.ResourceName]int64{
+		v1.ResourceCPU:    85,
+		v1.ResourceMemory: 146800640,
+	}, la.estimatePodUsage(makeTestPod("pod", apiconsts.PodAnnotationQoSLevelSharedCores, "", "")))
+}
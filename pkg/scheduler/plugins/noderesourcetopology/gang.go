@@ -0,0 +1,149 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+const (
+	// PodGroupLabelKey and PodGroupMinAvailableLabelKey declare the pod group a pod belongs to,
+	// and they are compatible with the lightweight coscheduling plugin in scheduler-plugins.
+	PodGroupLabelKey             = "pod-group.scheduling.sigs.k8s.io"
+	PodGroupMinAvailableLabelKey = "pod-group.scheduling.sigs.k8s.io/min-available"
+
+	// DefaultGangWaitingTime is the max duration for members of a pod group to hold
+	// their NUMA reservations while waiting for others in permit stage, and it's only
+	// used if GangWaitingTime is not set in plugin args.
+	DefaultGangWaitingTime = 60 * time.Second
+)
+
+type podGroup struct {
+	namespace    string
+	name         string
+	minAvailable int
+}
+
+// getPodGroup returns the pod group of the given pod, and nil is returned if the
+// pod doesn't belong to any pod group which needs all-or-nothing placement.
+func getPodGroup(pod *v1.Pod) *podGroup {
+	name, ok := pod.Labels[PodGroupLabelKey]
+	if !ok || name == "" {
+		return nil
+	}
+
+	minAvailable, err := strconv.Atoi(pod.Labels[PodGroupMinAvailableLabelKey])
+	if err != nil {
+		klog.Errorf("[TopologyMatch] pod %v has invalid min-available of pod group %v: %v", klog.KObj(pod), name, err)
+		return nil
+	}
+	if minAvailable <= 1 {
+		return nil
+	}
+
+	return &podGroup{namespace: pod.Namespace, name: name, minAvailable: minAvailable}
+}
+
+func (pg *podGroup) has(pod *v1.Pod) bool {
+	return pod.Namespace == pg.namespace && pod.Labels[PodGroupLabelKey] == pg.name
+}
+
+func (pg *podGroup) String() string {
+	return pg.namespace + "/" + pg.name
+}
+
+// Permit holds the pod (along with its NUMA reservation) until enough members
+// of its pod group have been reserved, and then allows all waiting members;
+// if members can't be gathered in time, the framework rejects the pod and
+// Unreserve releases reservations of the whole pod group.
+func (tm *TopologyMatch) Permit(_ context.Context, _ *framework.CycleState, pod *v1.Pod, nodeName string) (*framework.Status, time.Duration) {
+	if !tm.topologyMatchSupport(pod) {
+		return framework.NewStatus(framework.Success, ""), 0
+	}
+
+	pg := getPodGroup(pod)
+	if pg == nil {
+		return framework.NewStatus(framework.Success, ""), 0
+	}
+
+	members := tm.countReservedMembers(pg, pod)
+	if members < pg.minAvailable {
+		klog.V(4).Infof("[TopologyMatch] pod %v waits on node %v for pod group %v, %v/%v members reserved",
+			klog.KObj(pod), nodeName, pg, members, pg.minAvailable)
+		return framework.NewStatus(framework.Wait, ""), tm.gangWaitingTime
+	}
+
+	klog.V(4).Infof("[TopologyMatch] pod group %v has %v/%v members reserved, allow all waiting members",
+		pg, members, pg.minAvailable)
+	tm.handle.IterateOverWaitingPods(func(wp framework.WaitingPod) {
+		if pg.has(wp.GetPod()) {
+			wp.Allow(tm.Name())
+		}
+	})
+	return framework.NewStatus(framework.Success, ""), 0
+}
+
+// countReservedMembers counts the given pod, members waiting in permit stage and
+// members already placed in the snapshot; waiting members are also assumed in
+// the snapshot, so they are deduplicated by uid.
+func (tm *TopologyMatch) countReservedMembers(pg *podGroup, pod *v1.Pod) int {
+	members := map[types.UID]struct{}{pod.UID: {}}
+	tm.handle.IterateOverWaitingPods(func(wp framework.WaitingPod) {
+		if pg.has(wp.GetPod()) {
+			members[wp.GetPod().UID] = struct{}{}
+		}
+	})
+
+	nodeInfos, err := tm.sharedLister.NodeInfos().List()
+	if err != nil {
+		klog.Errorf("[TopologyMatch] failed to list node infos: %v", err)
+		return len(members)
+	}
+	for _, nodeInfo := range nodeInfos {
+		for _, podInfo := range nodeInfo.Pods {
+			if pg.has(podInfo.Pod) {
+				members[podInfo.Pod.UID] = struct{}{}
+			}
+		}
+	}
+	return len(members)
+}
+
+// rejectWaitingMembers rejects all waiting members of the pod group once
+// any member is unreserved, so that reservations are released all together.
+func (tm *TopologyMatch) rejectWaitingMembers(pod *v1.Pod) {
+	pg := getPodGroup(pod)
+	if pg == nil {
+		return
+	}
+
+	tm.handle.IterateOverWaitingPods(func(wp framework.WaitingPod) {
+		if wp.GetPod().UID != pod.UID && pg.has(wp.GetPod()) {
+			klog.V(4).Infof("[TopologyMatch] reject waiting pod %v since member %v of pod group %v is unreserved",
+				klog.KObj(wp.GetPod()), klog.KObj(pod), pg)
+			wp.Reject(tm.Name(), fmt.Sprintf("member %v of pod group %v is unreserved", pod.Name, pg))
+		}
+	})
+}
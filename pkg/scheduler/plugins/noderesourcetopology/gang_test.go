@@ -0,0 +1,140 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/kubernetes/pkg/scheduler/apis/config"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	st "k8s.io/kubernetes/pkg/scheduler/testing"
+
+	"github.com/kubewharf/katalyst-api/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/config/generic"
+	"github.com/kubewharf/katalyst-core/pkg/scheduler/util"
+)
+
+func makeTestGangPod(name, group, minAvailable string) *v1.Pod {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      name,
+			UID:       types.UID(name),
+			Annotations: map[string]string{
+				consts.PodAnnotationQoSLevelKey:          consts.PodAnnotationQoSLevelDedicatedCores,
+				consts.PodAnnotationMemoryEnhancementKey: `{"numa_binding":"true"}`,
+			},
+			Labels: map[string]string{},
+		},
+	}
+	if group != "" {
+		pod.Labels[PodGroupLabelKey] = group
+		pod.Labels[PodGroupMinAvailableLabelKey] = minAvailable
+	}
+	return pod
+}
+
+func TestGetPodGroup(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, getPodGroup(makeTestGangPod("p1", "", "")))
+	assert.Nil(t, getPodGroup(makeTestGangPod("p1", "g1", "invalid")))
+	assert.Nil(t, getPodGroup(makeTestGangPod("p1", "g1", "1")), "pod group with single member doesn't need gang scheduling")
+
+	pg := getPodGroup(makeTestGangPod("p1", "g1", "3"))
+	assert.Equal(t, &podGroup{namespace: "default", name: "g1", minAvailable: 3}, pg)
+	assert.True(t, pg.has(makeTestGangPod("p2", "g1", "3")))
+	assert.False(t, pg.has(makeTestGangPod("p3", "g2", "3")))
+}
+
+func TestPermit(t *testing.T) {
+	t.Parallel()
+	util.SetQoSConfig(generic.NewQoSConfiguration())
+
+	args := MakeTestArgs(config.MostAllocated, []string{"cpu", "memory"}, consts.ResourcePluginPolicyNameDynamic)
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-gang"}}
+	f, err := util.NewFramework([]st.RegisterPluginFunc{
+		st.RegisterPermitPlugin(TopologyMatchName, New),
+	}, []config.PluginConfig{
+		{Name: TopologyMatchName, Args: args},
+	}, "test", runtime.WithSnapshotSharedLister(newTestSharedLister(nil, []*v1.Node{node})))
+	assert.NoError(t, err)
+
+	tm, err := MakeTestTm(args, f)
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultGangWaitingTime, tm.(*TopologyMatch).gangWaitingTime)
+
+	ctx := context.TODO()
+	state := framework.NewCycleState()
+
+	// pods without pod group are permitted directly
+	assert.True(t, f.RunPermitPlugins(ctx, state, makeTestGangPod("single", "", ""), node.Name).IsSuccess())
+
+	// all waiting members are allowed once min-available members are reserved
+	p1, p2 := makeTestGangPod("p1", "g1", "2"), makeTestGangPod("p2", "g1", "2")
+	assert.Equal(t, framework.Wait, f.RunPermitPlugins(ctx, state, p1, node.Name).Code())
+	assert.True(t, f.RunPermitPlugins(ctx, state, p2, node.Name).IsSuccess())
+	assert.True(t, f.WaitOnPermit(ctx, p1).IsSuccess())
+
+	// waiting members are rejected once any member is unreserved
+	p3, p4 := makeTestGangPod("p3", "g2", "3"), makeTestGangPod("p4", "g2", "3")
+	p5 := makeTestGangPod("p5", "g3", "2")
+	assert.Equal(t, framework.Wait, f.RunPermitPlugins(ctx, state, p3, node.Name).Code())
+	assert.Equal(t, framework.Wait, f.RunPermitPlugins(ctx, state, p4, node.Name).Code())
+	assert.Equal(t, framework.Wait, f.RunPermitPlugins(ctx, state, p5, node.Name).Code())
+
+	// mock the timeout of p3
+	assert.True(t, f.RejectWaitingPod(p3.UID))
+	assert.False(t, f.WaitOnPermit(ctx, p3).IsSuccess())
+	tm.(*TopologyMatch).Unreserve(ctx, state, p3, node.Name)
+
+	status := f.WaitOnPermit(ctx, p4)
+	assert.True(t, status.IsUnschedulable())
+	assert.Equal(t, TopologyMatchName, status.FailedPlugin())
+	assert.NotNil(t, f.GetWaitingPod(p5.UID), "members of other pod groups should keep waiting")
+	f.RejectWaitingPod(p5.UID)
+}
+
+func TestPermitWithGangWaitingTime(t *testing.T) {
+	t.Parallel()
+	util.SetQoSConfig(generic.NewQoSConfiguration())
+
+	args := MakeTestArgs(config.MostAllocated, []string{"cpu", "memory"}, consts.ResourcePluginPolicyNameDynamic)
+	args.GangWaitingTime = metav1.Duration{Duration: 30 * time.Second}
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-gang"}}
+	f, err := util.NewFramework([]st.RegisterPluginFunc{
+		st.RegisterPermitPlugin(TopologyMatchName, New),
+	}, []config.PluginConfig{
+		{Name: TopologyMatchName, Args: args},
+	}, "test", runtime.WithSnapshotSharedLister(newTestSharedLister(nil, []*v1.Node{node})))
+	assert.NoError(t, err)
+
+	tm, err := MakeTestTm(args, f)
+	assert.NoError(t, err)
+
+	// members wait for others as long as the waiting time in plugin args
+	status, timeout := tm.(*TopologyMatch).Permit(context.TODO(), framework.NewCycleState(), makeTestGangPod("p1", "g1", "2"), node.Name)
+	assert.Equal(t, framework.Wait, status.Code())
+	assert.Equal(t, 30*time.Second, timeout)
+}
@@ -19,12 +19,14 @@ package noderesourcetopology
 import (
 	"fmt"
 	"strconv"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
 	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+	policylisters "k8s.io/client-go/listers/policy/v1"
 	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/apis/core/v1/helper/qos"
 	"k8s.io/kubernetes/pkg/scheduler/apis/config"
@@ -68,12 +70,17 @@ type TopologyMatch struct {
 	alignedResources    sets.String
 	resourcePolicy      consts.ResourcePluginPolicyName
 	sharedLister        framework.SharedLister
+	handle              framework.Handle
+	pdbLister           policylisters.PodDisruptionBudgetLister
+	gangWaitingTime     time.Duration
 }
 
 var (
 	_ framework.FilterPlugin      = &TopologyMatch{}
 	_ framework.ScorePlugin       = &TopologyMatch{}
 	_ framework.ReservePlugin     = &TopologyMatch{}
+	_ framework.PermitPlugin      = &TopologyMatch{}
+	_ framework.PostFilterPlugin  = &TopologyMatch{}
 	_ framework.EnqueueExtensions = &TopologyMatch{}
 )
 
@@ -106,9 +113,21 @@ func New(args runtime.Object, h framework.Handle) (framework.Plugin, error) {
 		return nil, err
 	}
 
+	gangWaitingTime := tcfg.GangWaitingTime.Duration
+	if gangWaitingTime <= 0 {
+		gangWaitingTime = DefaultGangWaitingTime
+	}
+
 	eventhandlers.RegisterCommonPodHandler()
 	eventhandlers.RegisterCommonCNRHandler()
 
+	// pdbs are respected in NUMA preemption as the default preemption does,
+	// and they are ignored if the informer factory is not available.
+	var pdbLister policylisters.PodDisruptionBudgetLister
+	if informerFactory := h.SharedInformerFactory(); informerFactory != nil {
+		pdbLister = informerFactory.Policy().V1().PodDisruptionBudgets().Lister()
+	}
+
 	return &TopologyMatch{
 		scoreStrategyType:   tcfg.ScoringStrategy.Type,
 		alignedResources:    alignedResources,
@@ -116,6 +135,9 @@ func New(args runtime.Object, h framework.Handle) (framework.Plugin, error) {
 		scoreStrategyFunc:   strategy,
 		resourcePolicy:      tcfg.ResourcePluginPolicy,
 		sharedLister:        h.SnapshotSharedLister(),
+		handle:              h,
+		pdbLister:           pdbLister,
+		gangWaitingTime:     gangWaitingTime,
 	}, nil
 }
 
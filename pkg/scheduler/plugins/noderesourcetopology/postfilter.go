@@ -0,0 +1,445 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	schedutil "k8s.io/kubernetes/pkg/scheduler/util"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
+	"github.com/kubewharf/katalyst-api/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/scheduler/cache"
+	"github.com/kubewharf/katalyst-core/pkg/scheduler/util"
+	"github.com/kubewharf/katalyst-core/pkg/util/native"
+)
+
+// numaPreemptionCandidate records the victims that should be preempted
+// to free NUMA resources for the preemptor on the given node.
+type numaPreemptionCandidate struct {
+	nodeName string
+	victims  []*v1.Pod
+	// numPDBViolations is the number of victims whose pdbs are violated by the preemption
+	numPDBViolations int
+}
+
+// highestVictimPriority returns the highest priority among all victims.
+func (c *numaPreemptionCandidate) highestVictimPriority() int32 {
+	var highest int32
+	for i, victim := range c.victims {
+		if p := corev1helpers.PodPriority(victim); i == 0 || p > highest {
+			highest = p
+		}
+	}
+	return highest
+}
+
+// betterThan prefers candidates with fewer pdb violations, and then candidates
+// whose highest victim priority is lower, and then candidates with fewer victims.
+func (c *numaPreemptionCandidate) betterThan(other *numaPreemptionCandidate) bool {
+	if other == nil {
+		return true
+	}
+	if c.numPDBViolations != other.numPDBViolations {
+		return c.numPDBViolations < other.numPDBViolations
+	}
+	if p1, p2 := c.highestVictimPriority(), other.highestVictimPriority(); p1 != p2 {
+		return p1 < p2
+	}
+	if len(c.victims) != len(other.victims) {
+		return len(c.victims) < len(other.victims)
+	}
+	return c.nodeName < other.nodeName
+}
+
+// PostFilter preempts pods holding NUMA resources for numa_binding pods rejected by TopologyMatch;
+// the default preemption only checks node-level resources, so it may either nominate nodes without
+// any fitting NUMA or fail to find victims at all. Victims are chosen based on the NUMA allocations
+// of CNR topology zones, and the node that violates the fewest pdbs and needs the least important
+// victims is nominated.
+func (tm *TopologyMatch) PostFilter(ctx context.Context, _ *framework.CycleState, pod *v1.Pod,
+	filteredNodeStatusMap framework.NodeToStatusMap,
+) (*framework.PostFilterResult, *framework.Status) {
+	if !tm.topologyMatchSupport(pod) || !util.IsNumaBinding(pod) {
+		return nil, framework.NewStatus(framework.Unschedulable, "pod doesn't need NUMA preemption")
+	}
+
+	if ok, msg := tm.podEligibleToPreemptOthers(pod); !ok {
+		klog.V(5).Infof("[TopologyMatch] pod %v/%v is not eligible for NUMA preemption: %v", pod.Namespace, pod.Name, msg)
+		return nil, framework.NewStatus(framework.Unschedulable, msg)
+	}
+
+	pdbs, err := tm.getPodDisruptionBudgets()
+	if err != nil {
+		return nil, framework.AsStatus(err)
+	}
+
+	var best *numaPreemptionCandidate
+	for nodeName, status := range filteredNodeStatusMap {
+		// only nodes rejected by TopologyMatch can be helped by NUMA preemption,
+		// since removing victims never makes failures of other plugins resolved.
+		if status.Code() != framework.Unschedulable || status.FailedPlugin() != TopologyMatchName {
+			continue
+		}
+
+		nodeInfo, err := tm.sharedLister.NodeInfos().Get(nodeName)
+		if err != nil || nodeInfo.Node() == nil {
+			klog.Warningf("[TopologyMatch] failed to get node %v from snapshot: %v", nodeName, err)
+			continue
+		}
+
+		candidate := tm.selectVictimsOnNode(pod, nodeInfo, pdbs)
+		if candidate != nil && candidate.betterThan(best) {
+			best = candidate
+		}
+	}
+
+	if best == nil {
+		return nil, framework.NewStatus(framework.Unschedulable, "no NUMA can be freed by preemption")
+	}
+
+	if err := tm.preemptVictims(ctx, pod, best); err != nil {
+		return nil, framework.AsStatus(err)
+	}
+	return framework.NewPostFilterResultWithNominatedNode(best.nodeName), framework.NewStatus(framework.Success)
+}
+
+// podEligibleToPreemptOthers returns false if the pod is not allowed to preempt, or there are
+// still victims terminating on its nominated node, which means that the previous preemption
+// hasn't finished yet.
+func (tm *TopologyMatch) podEligibleToPreemptOthers(pod *v1.Pod) (bool, string) {
+	if pod.Spec.PreemptionPolicy != nil && *pod.Spec.PreemptionPolicy == v1.PreemptNever {
+		return false, "not eligible due to preemptionPolicy=Never"
+	}
+
+	nominatedNodeName := pod.Status.NominatedNodeName
+	if nominatedNodeName == "" {
+		return true, ""
+	}
+
+	nodeInfo, err := tm.sharedLister.NodeInfos().Get(nominatedNodeName)
+	if err != nil {
+		return true, ""
+	}
+
+	podPriority := corev1helpers.PodPriority(pod)
+	for _, p := range nodeInfo.Pods {
+		if p.Pod.DeletionTimestamp != nil && corev1helpers.PodPriority(p.Pod) < podPriority {
+			return false, "not eligible due to a terminating pod on the nominated node"
+		}
+	}
+	return true, ""
+}
+
+// getPodDisruptionBudgets returns all pdbs, and nil is returned if pdbs are not watched.
+func (tm *TopologyMatch) getPodDisruptionBudgets() ([]*policy.PodDisruptionBudget, error) {
+	if tm.pdbLister == nil {
+		return nil, nil
+	}
+	return tm.pdbLister.List(labels.Everything())
+}
+
+// selectVictimsOnNode finds the least important victims whose removal makes the pod fit on the node.
+// For each NUMA, all preemptible pods holding resources on it are tried as victims, and then as many
+// of them as possible are reprieved, starting from pods whose pdbs would be violated, in the order of
+// importance; all preemptible pods on the node are tried at last for pods that need multiple NUMAs.
+func (tm *TopologyMatch) selectVictimsOnNode(pod *v1.Pod, nodeInfo *framework.NodeInfo,
+	pdbs []*policy.PodDisruptionBudget,
+) *numaPreemptionCandidate {
+	nodeName := nodeInfo.Node().Name
+
+	var rt *cache.ResourceTopology
+	if consts.ResourcePluginPolicyNameDynamic == tm.resourcePolicy {
+		rt = cache.GetCache().GetNodeResourceTopology(nodeName, tm.dedicatedPodsFilter(nodeInfo))
+	} else {
+		rt = cache.GetCache().GetNodeResourceTopology(nodeName, nil)
+	}
+	if rt == nil {
+		return nil
+	}
+
+	handler := tm.filterHandler(pod, rt)
+	if handler == nil {
+		return nil
+	}
+
+	preemptiblePods := make(map[string]*v1.Pod)
+	for _, podInfo := range nodeInfo.Pods {
+		if podPreemptible(pod, podInfo.Pod) {
+			preemptiblePods[native.GenerateNamespaceNameKey(podInfo.Pod.Namespace, podInfo.Pod.Name)] = podInfo.Pod
+		}
+	}
+	if len(preemptiblePods) == 0 {
+		return nil
+	}
+
+	fits := func(victims sets.String) bool {
+		return handler(pod, removeConsumers(rt, victims).TopologyZone, removePods(nodeInfo, victims, preemptiblePods)).IsSuccess()
+	}
+
+	var best *numaPreemptionCandidate
+	for _, potentialVictims := range numaPotentialVictims(rt, preemptiblePods) {
+		if potentialVictims.Len() == 0 || !fits(potentialVictims) {
+			continue
+		}
+
+		victims, numPDBViolations := reprieveVictims(potentialVictims, preemptiblePods, pdbs, fits)
+		candidate := &numaPreemptionCandidate{nodeName: nodeName, numPDBViolations: numPDBViolations}
+		for _, key := range victims.List() {
+			candidate.victims = append(candidate.victims, preemptiblePods[key])
+		}
+		if candidate.betterThan(best) {
+			best = candidate
+		}
+	}
+	return best
+}
+
+// numaPotentialVictims returns keys of preemptible pods holding resources on each NUMA,
+// and all preemptible pods are appended as the last potential victims.
+func numaPotentialVictims(rt *cache.ResourceTopology, preemptiblePods map[string]*v1.Pod) []sets.String {
+	numaVictims := make(map[int]sets.String)
+	for _, topologyZone := range rt.TopologyZone {
+		if topologyZone.Type != v1alpha1.TopologyTypeSocket {
+			continue
+		}
+		for _, child := range topologyZone.Children {
+			if child.Type != v1alpha1.TopologyTypeNuma {
+				continue
+			}
+			numaID, err := getID(child.Name)
+			if err != nil {
+				klog.Error(err)
+				continue
+			}
+
+			victims := sets.NewString()
+			for _, alloc := range child.Allocations {
+				if key, ok := consumerPodKey(alloc.Consumer); ok {
+					if _, ok := preemptiblePods[key]; ok {
+						victims.Insert(key)
+					}
+				}
+			}
+			numaVictims[numaID] = victims
+		}
+	}
+
+	numaIDs := make([]int, 0, len(numaVictims))
+	for numaID := range numaVictims {
+		numaIDs = append(numaIDs, numaID)
+	}
+	sort.Ints(numaIDs)
+
+	result := make([]sets.String, 0, len(numaIDs)+1)
+	for _, numaID := range numaIDs {
+		result = append(result, numaVictims[numaID])
+	}
+
+	all := sets.NewString()
+	for key := range preemptiblePods {
+		all.Insert(key)
+	}
+	return append(result, all)
+}
+
+// reprieveVictims tries to add victims back from the most important one, and keeps the victim
+// away from preemption if the pod still fits; same as the default preemption, victims whose pdbs
+// would be violated are reprieved first, and the number of violating victims left is returned.
+func reprieveVictims(potentialVictims sets.String, pods map[string]*v1.Pod, pdbs []*policy.PodDisruptionBudget,
+	fits func(sets.String) bool,
+) (sets.String, int) {
+	keys := potentialVictims.List()
+	sort.SliceStable(keys, func(i, j int) bool {
+		return schedutil.MoreImportantPod(pods[keys[i]], pods[keys[j]])
+	})
+	violatingKeys, nonViolatingKeys := filterPodsWithPDBViolation(keys, pods, pdbs)
+
+	victims := sets.NewString(keys...)
+	reprieve := func(key string) bool {
+		victims.Delete(key)
+		if !fits(victims) {
+			victims.Insert(key)
+			return false
+		}
+		return true
+	}
+
+	numViolations := 0
+	for _, key := range violatingKeys {
+		if !reprieve(key) {
+			numViolations++
+		}
+	}
+	for _, key := range nonViolatingKeys {
+		reprieve(key)
+	}
+	return victims, numViolations
+}
+
+// filterPodsWithPDBViolation groups the given pods into those whose pdbs would be violated
+// if they are preempted and the others, keeping the order of the given keys; it follows
+// filterPodsWithPDBViolation of the default preemption.
+func filterPodsWithPDBViolation(keys []string, pods map[string]*v1.Pod,
+	pdbs []*policy.PodDisruptionBudget,
+) (violatingKeys, nonViolatingKeys []string) {
+	pdbsAllowed := make([]int32, len(pdbs))
+	for i, pdb := range pdbs {
+		pdbsAllowed[i] = pdb.Status.DisruptionsAllowed
+	}
+
+	for _, key := range keys {
+		pod := pods[key]
+		violated := false
+		// a pod without labels never matches any pdb
+		if len(pod.Labels) != 0 {
+			for i, pdb := range pdbs {
+				if pdb.Namespace != pod.Namespace {
+					continue
+				}
+				selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+				// pdbs with invalid, nil or empty selector match nothing
+				if err != nil || selector.Empty() || !selector.Matches(labels.Set(pod.Labels)) {
+					continue
+				}
+				// pods in DisruptedPods have been processed by apiserver, and they
+				// shouldn't be counted again.
+				if _, ok := pdb.Status.DisruptedPods[pod.Name]; ok {
+					continue
+				}
+
+				pdbsAllowed[i]--
+				if pdbsAllowed[i] < 0 {
+					violated = true
+				}
+			}
+		}
+
+		if violated {
+			violatingKeys = append(violatingKeys, key)
+		} else {
+			nonViolatingKeys = append(nonViolatingKeys, key)
+		}
+	}
+	return violatingKeys, nonViolatingKeys
+}
+
+// podPreemptible returns true if the pod can be preempted by the preemptor, i.e. pods with
+// lower priority, or reclaimed/shared pods whose priority is not higher than the preemptor.
+func podPreemptible(preemptor, pod *v1.Pod) bool {
+	if pod.DeletionTimestamp != nil {
+		return false
+	}
+
+	preemptorPriority, podPriority := corev1helpers.PodPriority(preemptor), corev1helpers.PodPriority(pod)
+	if podPriority < preemptorPriority {
+		return true
+	}
+	return podPriority == preemptorPriority && !util.IsDedicatedPod(pod)
+}
+
+// removeConsumers returns a copy of the ResourceTopology without allocations of the given pods.
+func removeConsumers(rt *cache.ResourceTopology, podKeys sets.String) *cache.ResourceTopology {
+	cp := rt.DeepCopy()
+	for _, topologyZone := range cp.TopologyZone {
+		if topologyZone.Type != v1alpha1.TopologyTypeSocket {
+			continue
+		}
+		for _, child := range topologyZone.Children {
+			if child.Type != v1alpha1.TopologyTypeNuma {
+				continue
+			}
+
+			allocations := make([]*v1alpha1.Allocation, 0, len(child.Allocations))
+			for _, alloc := range child.Allocations {
+				if key, ok := consumerPodKey(alloc.Consumer); ok && podKeys.Has(key) {
+					continue
+				}
+				allocations = append(allocations, alloc)
+			}
+			child.Allocations = allocations
+		}
+	}
+	return cp
+}
+
+// removePods returns a copy of the NodeInfo without the given pods.
+func removePods(nodeInfo *framework.NodeInfo, podKeys sets.String, pods map[string]*v1.Pod) *framework.NodeInfo {
+	cp := nodeInfo.Clone()
+	for key := range podKeys {
+		if err := cp.RemovePod(pods[key]); err != nil {
+			klog.Errorf("[TopologyMatch] failed to remove pod %v from node %v: %v", key, cp.Node().Name, err)
+		}
+	}
+	return cp
+}
+
+func consumerPodKey(consumer string) (string, bool) {
+	namespace, name, _, err := native.ParseNamespaceNameUIDKey(consumer)
+	if err != nil {
+		return "", false
+	}
+	return native.GenerateNamespaceNameKey(namespace, name), true
+}
+
+// preemptVictims deletes all victims and records preemption events for them; deletion goes on
+// for the other victims if one of them fails, and the failure is reported with the victims that
+// have been preempted, so that the partial preemption is not mistaken for no preemption at all.
+func (tm *TopologyMatch) preemptVictims(ctx context.Context, pod *v1.Pod, candidate *numaPreemptionCandidate) error {
+	cs := tm.handle.ClientSet()
+	if cs == nil {
+		return fmt.Errorf("clientset is nil")
+	}
+
+	var (
+		errList   []error
+		preempted []string
+	)
+	for _, victim := range candidate.victims {
+		if err := cs.CoreV1().Pods(victim.Namespace).Delete(ctx, victim.Name, metav1.DeleteOptions{}); err != nil {
+			klog.Errorf("[TopologyMatch] failed to preempt pod %v/%v for %v/%v: %v",
+				victim.Namespace, victim.Name, pod.Namespace, pod.Name, err)
+			errList = append(errList, fmt.Errorf("preempt pod %v/%v: %w", victim.Namespace, victim.Name, err))
+			continue
+		}
+		preempted = append(preempted, native.GenerateNamespaceNameKey(victim.Namespace, victim.Name))
+
+		if recorder := tm.handle.EventRecorder(); recorder != nil {
+			recorder.Eventf(victim, pod, v1.EventTypeNormal, "Preempted", "Preempting",
+				"Preempted by %v/%v on node %v to free NUMA resources", pod.Namespace, pod.Name, candidate.nodeName)
+		}
+		klog.Infof("[TopologyMatch] pod %v/%v is preempted by %v/%v on node %v",
+			victim.Namespace, victim.Name, pod.Namespace, pod.Name, candidate.nodeName)
+	}
+
+	if len(errList) > 0 {
+		return fmt.Errorf("NUMA preemption on node %v partially failed, %d/%d victims preempted %v: %v",
+			candidate.nodeName, len(preempted), len(candidate.victims), preempted, utilerrors.NewAggregate(errList))
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apiruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/kubernetes/pkg/scheduler/apis/config"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
+	"github.com/kubewharf/katalyst-api/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/config/generic"
+	"github.com/kubewharf/katalyst-core/pkg/scheduler/cache"
+	"github.com/kubewharf/katalyst-core/pkg/scheduler/util"
+)
+
+func makeTestPreemptionPod(name, nodeName string, priority int32, cpu, memory string) *v1.Pod {
+	resources := v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse(cpu),
+		v1.ResourceMemory: resource.MustParse(memory),
+	}
+	pod := makePodByResourceList(&resources, map[string]string{
+		consts.PodAnnotationQoSLevelKey:          consts.PodAnnotationQoSLevelDedicatedCores,
+		consts.PodAnnotationMemoryEnhancementKey: `{"numa_binding":"true"}`,
+	})
+	pod.Namespace, pod.Name, pod.UID = "default", name, types.UID(name)
+	pod.Spec.NodeName = nodeName
+	pod.Spec.Priority = &priority
+	return pod
+}
+
+func makeTestPreemptionNUMA(name string, consumers ...*v1.Pod) *v1alpha1.TopologyZone {
+	numa := &v1alpha1.TopologyZone{
+		Name: name,
+		Type: v1alpha1.TopologyTypeNuma,
+		Resources: v1alpha1.Resources{
+			Capacity: &v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("4"),
+				v1.ResourceMemory: resource.MustParse("8Gi"),
+			},
+			Allocatable: &v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("4"),
+				v1.ResourceMemory: resource.MustParse("8Gi"),
+			},
+		},
+	}
+	for _, pod := range consumers {
+		requests := pod.Spec.Containers[0].Resources.Requests.DeepCopy()
+		numa.Allocations = append(numa.Allocations, &v1alpha1.Allocation{
+			Consumer: fmt.Sprintf("%s/%s/%s", pod.Namespace, pod.Name, pod.UID),
+			Requests: &requests,
+		})
+	}
+	return numa
+}
+
+func TestPostFilter(t *testing.T) {
+	t.Parallel()
+	util.SetQoSConfig(generic.NewQoSConfiguration())
+
+	nodeName := "node-numa-preemption"
+	low1 := makeTestPreemptionPod("low-1", nodeName, 10, "2", "4Gi")
+	low1.Labels = map[string]string{"app": "low-1"}
+	high1 := makeTestPreemptionPod("high-1", nodeName, 1000, "2", "4Gi")
+	low2 := makeTestPreemptionPod("low-2", nodeName, 10, "2", "4Gi")
+	low3 := makeTestPreemptionPod("low-3", nodeName, 20, "2", "4Gi")
+	pods := []*v1.Pod{low1, high1, low2, low3}
+
+	cache.GetCache().AddOrUpdateCNR(&v1alpha1.CustomNodeResource{
+		ObjectMeta: metav1.ObjectMeta{Name: nodeName},
+		Status: v1alpha1.CustomNodeResourceStatus{
+			TopologyPolicy: v1alpha1.TopologyPolicySingleNUMANodeContainerLevel,
+			TopologyZone: []*v1alpha1.TopologyZone{
+				{
+					Name: "0",
+					Type: v1alpha1.TopologyTypeSocket,
+					Children: []*v1alpha1.TopologyZone{
+						makeTestPreemptionNUMA("0", low1, high1),
+						makeTestPreemptionNUMA("1", low2, low3),
+					},
+				},
+			},
+		},
+	})
+
+	for _, tc := range []struct {
+		comment          string
+		pod              *v1.Pod
+		failedPlugin     string
+		pdbs             []*policyv1.PodDisruptionBudget
+		failedDeletion   string
+		expectedError    bool
+		expectedNode     string
+		expectedVictims  []string
+		expectedSurvived []string
+	}{
+		{
+			comment:          "pods with higher priority can't be preempted",
+			pod:              makeTestPreemptionPod("preemptor", "", 5, "4", "8Gi"),
+			failedPlugin:     TopologyMatchName,
+			expectedSurvived: []string{"low-1", "high-1", "low-2", "low-3"},
+		},
+		{
+			comment:          "nodes rejected by other plugins should be skipped",
+			pod:              makeTestPreemptionPod("preemptor", "", 100, "4", "8Gi"),
+			failedPlugin:     "NodeResourcesFit",
+			expectedSurvived: []string{"low-1", "high-1", "low-2", "low-3"},
+		},
+		{
+			comment:          "the whole NUMA without pods of higher priority should be freed",
+			pod:              makeTestPreemptionPod("preemptor", "", 100, "4", "8Gi"),
+			failedPlugin:     TopologyMatchName,
+			expectedNode:     nodeName,
+			expectedVictims:  []string{"low-2", "low-3"},
+			expectedSurvived: []string{"low-1", "high-1"},
+		},
+		{
+			comment:          "victims should be reprieved if the pod still fits",
+			pod:              makeTestPreemptionPod("preemptor", "", 100, "2", "4Gi"),
+			failedPlugin:     TopologyMatchName,
+			expectedNode:     nodeName,
+			expectedVictims:  []string{"low-1"},
+			expectedSurvived: []string{"high-1", "low-2", "low-3"},
+		},
+		{
+			comment:      "victims whose pdbs would be violated should be avoided",
+			pod:          makeTestPreemptionPod("preemptor", "", 100, "2", "4Gi"),
+			failedPlugin: TopologyMatchName,
+			pdbs: []*policyv1.PodDisruptionBudget{
+				{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pdb-low-1"},
+					Spec: policyv1.PodDisruptionBudgetSpec{
+						Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "low-1"}},
+					},
+					Status: policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: 0},
+				},
+			},
+			expectedNode:     nodeName,
+			expectedVictims:  []string{"low-2"},
+			expectedSurvived: []string{"low-1", "high-1", "low-3"},
+		},
+		{
+			comment:          "failed deletion should be reported without stopping other victims",
+			pod:              makeTestPreemptionPod("preemptor", "", 100, "4", "8Gi"),
+			failedPlugin:     TopologyMatchName,
+			failedDeletion:   "low-2",
+			expectedError:    true,
+			expectedVictims:  []string{"low-3"},
+			expectedSurvived: []string{"low-1", "high-1", "low-2"},
+		},
+	} {
+		objects := make([]apiruntime.Object, 0, len(pods)+len(tc.pdbs))
+		for _, pod := range pods {
+			objects = append(objects, pod.DeepCopy())
+		}
+		for _, pdb := range tc.pdbs {
+			objects = append(objects, pdb)
+		}
+		cs := fake.NewSimpleClientset(objects...)
+		failedDeletion := tc.failedDeletion
+		cs.PrependReactor("delete", "pods", func(action clienttesting.Action) (bool, apiruntime.Object, error) {
+			if action.(clienttesting.DeleteAction).GetName() == failedDeletion {
+				return true, nil, fmt.Errorf("injected deletion failure")
+			}
+			return false, nil, nil
+		})
+		informerFactory := informers.NewSharedInformerFactory(cs, 0)
+
+		node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: nodeName}}
+		f, err := runtime.NewFramework(nil, nil,
+			runtime.WithClientSet(cs),
+			runtime.WithInformerFactory(informerFactory),
+			runtime.WithSnapshotSharedLister(newTestSharedLister(pods, []*v1.Node{node})))
+		assert.NoError(t, err)
+
+		tm, err := MakeTestTm(MakeTestArgs(config.MostAllocated, []string{"cpu", "memory"}, consts.ResourcePluginPolicyNameDynamic), f)
+		assert.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.TODO())
+		informerFactory.Start(ctx.Done())
+		informerFactory.WaitForCacheSync(ctx.Done())
+
+		result, status := tm.(*TopologyMatch).PostFilter(ctx, framework.NewCycleState(), tc.pod, framework.NodeToStatusMap{
+			nodeName: framework.NewStatus(framework.Unschedulable, "cannot align container").WithFailedPlugin(tc.failedPlugin),
+		})
+		cancel()
+		if tc.expectedError {
+			assert.Nil(t, result, tc.comment)
+			assert.Equal(t, framework.Error, status.Code(), tc.comment)
+			assert.Contains(t, status.Message(), "partially failed", tc.comment)
+		} else if tc.expectedNode == "" {
+			assert.Nil(t, result, tc.comment)
+			assert.Equal(t, framework.Unschedulable, status.Code(), tc.comment)
+		} else {
+			assert.True(t, status.IsSuccess(), tc.comment)
+			assert.Equal(t, tc.expectedNode, result.NominatedNodeName, tc.comment)
+		}
+
+		for _, name := range tc.expectedVictims {
+			_, err := cs.CoreV1().Pods("default").Get(context.TODO(), name, metav1.GetOptions{})
+			assert.True(t, errors.IsNotFound(err), "%v: %v should be preempted", tc.comment, name)
+		}
+		for _, name := range tc.expectedSurvived {
+			_, err := cs.CoreV1().Pods("default").Get(context.TODO(), name, metav1.GetOptions{})
+			assert.NoError(t, err, "%v: %v should survive", tc.comment, name)
+		}
+	}
+}
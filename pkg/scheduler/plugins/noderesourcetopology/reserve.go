@@ -65,6 +65,7 @@ func (tm *TopologyMatch) Reserve(ctx context.Context, state *framework.CycleStat
 func (tm *TopologyMatch) Unreserve(ctx context.Context, state *framework.CycleState, pod *corev1.Pod, nodeName string) {
 	if tm.topologyMatchSupport(pod) {
 		cache.GetCache().UnreserveNodeResource(nodeName, pod)
+		tm.rejectWaitingMembers(pod)
 	}
 }
 
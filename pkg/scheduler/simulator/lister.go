@@ -0,0 +1,103 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// nodeInfoLister implements framework.SharedLister for the simulated cluster,
+// and it keeps nodes in the order of the snapshot.
+type nodeInfoLister struct {
+	nodeInfoList []*framework.NodeInfo
+	nodeInfoMap  map[string]*framework.NodeInfo
+}
+
+var _ framework.SharedLister = &nodeInfoLister{}
+
+func newNodeInfoLister(nodes []*v1.Node, pods []*v1.Pod) *nodeInfoLister {
+	l := &nodeInfoLister{
+		nodeInfoMap: make(map[string]*framework.NodeInfo, len(nodes)),
+	}
+	for _, node := range nodes {
+		if _, ok := l.nodeInfoMap[node.Name]; ok {
+			klog.Warningf("skip duplicated node %v in snapshot", node.Name)
+			continue
+		}
+
+		nodeInfo := framework.NewNodeInfo()
+		nodeInfo.SetNode(node)
+		l.nodeInfoMap[node.Name] = nodeInfo
+		l.nodeInfoList = append(l.nodeInfoList, nodeInfo)
+	}
+
+	for _, pod := range pods {
+		if err := l.addPod(pod); err != nil {
+			klog.Warningf("skip running pod %v/%v: %v", pod.Namespace, pod.Name, err)
+		}
+	}
+	return l
+}
+
+func (l *nodeInfoLister) addPod(pod *v1.Pod) error {
+	nodeInfo, ok := l.nodeInfoMap[pod.Spec.NodeName]
+	if !ok {
+		return fmt.Errorf("node %v not found", pod.Spec.NodeName)
+	}
+	nodeInfo.AddPod(pod)
+	return nil
+}
+
+func (l *nodeInfoLister) NodeInfos() framework.NodeInfoLister {
+	return l
+}
+
+func (l *nodeInfoLister) List() ([]*framework.NodeInfo, error) {
+	return l.nodeInfoList, nil
+}
+
+func (l *nodeInfoLister) HavePodsWithAffinityList() ([]*framework.NodeInfo, error) {
+	var result []*framework.NodeInfo
+	for _, nodeInfo := range l.nodeInfoList {
+		if len(nodeInfo.PodsWithAffinity) > 0 {
+			result = append(result, nodeInfo)
+		}
+	}
+	return result, nil
+}
+
+func (l *nodeInfoLister) HavePodsWithRequiredAntiAffinityList() ([]*framework.NodeInfo, error) {
+	var result []*framework.NodeInfo
+	for _, nodeInfo := range l.nodeInfoList {
+		if len(nodeInfo.PodsWithRequiredAntiAffinity) > 0 {
+			result = append(result, nodeInfo)
+		}
+	}
+	return result, nil
+}
+
+func (l *nodeInfoLister) Get(nodeName string) (*framework.NodeInfo, error) {
+	nodeInfo, ok := l.nodeInfoMap[nodeName]
+	if !ok {
+		return nil, fmt.Errorf("nodeinfo not found for node name %q", nodeName)
+	}
+	return nodeInfo, nil
+}
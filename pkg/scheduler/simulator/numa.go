@@ -0,0 +1,156 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	v1 "k8s.io/api/core/v1"
+	quotav1 "k8s.io/apiserver/pkg/quota/v1"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
+	"github.com/kubewharf/katalyst-core/pkg/scheduler/util"
+	"github.com/kubewharf/katalyst-core/pkg/util/native"
+)
+
+type numaZone struct {
+	id          int
+	zone        *v1alpha1.TopologyZone
+	allocatable v1.ResourceList
+	available   v1.ResourceList
+}
+
+// needNUMAAssignment returns true for pods whose resources are bound to NUMA nodes by katalyst agent.
+func needNUMAAssignment(pod *v1.Pod) bool {
+	return util.IsDedicatedPod(pod) && util.IsNumaBinding(pod)
+}
+
+// assignNUMANodes picks NUMA nodes for the pod in place of katalyst agent, i.e. the lowest NUMA
+// id that fits is preferred, and numa_exclusive pods may occupy multiple idle NUMA nodes. The
+// allocations are recorded in the returned copy of CNR, so that later pods see them as occupied.
+func assignNUMANodes(pod *v1.Pod, cnr *v1alpha1.CustomNodeResource) ([]int, *v1alpha1.CustomNodeResource, error) {
+	cp := cnr.DeepCopy()
+	zones := getNUMAZones(cp)
+	if len(zones) == 0 {
+		return nil, nil, fmt.Errorf("no NUMA found in CNR %v", cnr.Name)
+	}
+
+	request := util.GetPodEffectiveRequest(pod)
+	exclusive := util.IsExclusive(pod)
+	fits := func(available v1.ResourceList) bool {
+		for resourceName, quantity := range request {
+			// resources without NUMA affinity are skipped
+			if availableQuantity, ok := available[resourceName]; ok && availableQuantity.Cmp(quantity) < 0 {
+				return false
+			}
+		}
+		return true
+	}
+	idle := func(z numaZone) bool {
+		return quotav1.Equals(z.available, z.allocatable)
+	}
+
+	var selected []numaZone
+	for _, z := range zones {
+		if (!exclusive || idle(z)) && fits(z.available) {
+			selected = []numaZone{z}
+			break
+		}
+	}
+
+	if selected == nil && exclusive {
+		var (
+			candidates []numaZone
+			total      = v1.ResourceList{}
+		)
+		for _, z := range zones {
+			if !idle(z) {
+				continue
+			}
+			candidates = append(candidates, z)
+			total = quotav1.Add(total, z.available)
+			if fits(total) {
+				selected = candidates
+				break
+			}
+		}
+	}
+
+	if selected == nil {
+		return nil, nil, fmt.Errorf("no NUMA in node %v fits the pod", cnr.Name)
+	}
+
+	consumer := native.GenerateNamespaceNameUIDKey(pod.Namespace, pod.Name, string(pod.UID))
+	numaIDs := make([]int, 0, len(selected))
+	for _, z := range selected {
+		// numa_exclusive pods occupy the whole NUMA
+		requests := quotav1.Mask(request, quotav1.ResourceNames(z.allocatable))
+		if exclusive {
+			requests = z.allocatable.DeepCopy()
+		}
+		z.zone.Allocations = append(z.zone.Allocations, &v1alpha1.Allocation{
+			Consumer: consumer,
+			Requests: &requests,
+		})
+		numaIDs = append(numaIDs, z.id)
+	}
+	return numaIDs, cp, nil
+}
+
+// getNUMAZones returns all NUMA zones in CNR with their available resources, sorted by NUMA id.
+func getNUMAZones(cnr *v1alpha1.CustomNodeResource) []numaZone {
+	var zones []numaZone
+	for _, topologyZone := range cnr.Status.TopologyZone {
+		if topologyZone.Type != v1alpha1.TopologyTypeSocket {
+			continue
+		}
+		for _, child := range topologyZone.Children {
+			if child.Type != v1alpha1.TopologyTypeNuma {
+				continue
+			}
+			numaID, err := strconv.Atoi(child.Name)
+			if err != nil {
+				continue
+			}
+
+			allocatable := v1.ResourceList{}
+			if child.Resources.Allocatable != nil {
+				allocatable = child.Resources.Allocatable.DeepCopy()
+			}
+			used := v1.ResourceList{}
+			for _, alloc := range child.Allocations {
+				if alloc.Requests != nil {
+					used = quotav1.Add(used, *alloc.Requests)
+				}
+			}
+
+			zones = append(zones, numaZone{
+				id:          numaID,
+				zone:        child,
+				allocatable: allocatable,
+				available:   quotav1.SubtractWithNonNegativeResult(allocatable, used),
+			})
+		}
+	}
+
+	sort.Slice(zones, func(i, j int) bool {
+		return zones[i].id < zones[j].id
+	})
+	return zones
+}
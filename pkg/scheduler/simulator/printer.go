@@ -0,0 +1,82 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	OutputFormatTable = "table"
+	OutputFormatJSON  = "json"
+)
+
+// PrintResults writes the results in the given format; for table format, scores
+// of each plugin are only printed for the selected node.
+func PrintResults(w io.Writer, results []*Result, format string) error {
+	switch format {
+	case OutputFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(results)
+	case OutputFormatTable:
+		return printTable(w, results)
+	default:
+		return fmt.Errorf("unsupported output format %v", format)
+	}
+}
+
+func printTable(w io.Writer, results []*Result) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "NAMESPACE\tNAME\tNODE\tNUMA\tSCORE\tDETAILS")
+	for _, r := range results {
+		if r.NodeName == "" {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t<none>\t<none>\t<none>\t%s\n", r.Namespace, r.Name, r.Message)
+			continue
+		}
+
+		numa := "<none>"
+		if len(r.NUMANodes) > 0 {
+			ids := make([]string, 0, len(r.NUMANodes))
+			for _, id := range r.NUMANodes {
+				ids = append(ids, fmt.Sprint(id))
+			}
+			numa = strings.Join(ids, ",")
+		}
+
+		scores := r.Scores[r.NodeName]
+		plugins := make([]string, 0, len(scores))
+		for plugin := range scores {
+			plugins = append(plugins, plugin)
+		}
+		sort.Strings(plugins)
+
+		details := make([]string, 0, len(plugins))
+		for _, plugin := range plugins {
+			details = append(details, fmt.Sprintf("%s=%d", plugin, scores[plugin]))
+		}
+
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%s\n", r.Namespace, r.Name, r.NodeName, numa,
+			r.TotalScore(r.NodeName), strings.Join(details, " "))
+	}
+	return tw.Flush()
+}
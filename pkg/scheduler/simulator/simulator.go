@@ -0,0 +1,307 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/events"
+	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
+	"k8s.io/klog/v2"
+	kubeschedulerconfig "k8s.io/kubernetes/pkg/scheduler/apis/config"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
+	"github.com/kubewharf/katalyst-api/pkg/client/clientset/versioned"
+	internalfake "github.com/kubewharf/katalyst-api/pkg/client/clientset/versioned/fake"
+	"github.com/kubewharf/katalyst-api/pkg/client/informers/externalversions"
+	"github.com/kubewharf/katalyst-core/pkg/scheduler/cache"
+	"github.com/kubewharf/katalyst-core/pkg/scheduler/eventhandlers"
+	"github.com/kubewharf/katalyst-core/pkg/scheduler/util"
+)
+
+// Result is the scheduling decision for a pending pod.
+type Result struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// NodeName is empty if the pod can't be scheduled, and Message explains why.
+	NodeName  string `json:"nodeName,omitempty"`
+	NUMANodes []int  `json:"numaNodes,omitempty"`
+	// Scores maps each feasible node to the weighted scores of all score plugins.
+	Scores  map[string]map[string]int64 `json:"scores,omitempty"`
+	Message string                      `json:"message,omitempty"`
+}
+
+// TotalScore returns the sum of weighted scores of the given node.
+func (r *Result) TotalScore(nodeName string) int64 {
+	var total int64
+	for _, score := range r.Scores[nodeName] {
+		total += score
+	}
+	return total
+}
+
+// Simulator schedules pending pods of a snapshot offline with the scheduler framework built
+// from the given profiles. Scheduled pods are assumed to be running on the selected nodes,
+// and NUMA resources are allocated in CNR in place of katalyst agent, so that later pods
+// see the cluster as if all previous pods have been bound.
+type Simulator struct {
+	frameworks  map[string]framework.Framework
+	lister      *nodeInfoLister
+	cnrs        map[string]*v1alpha1.CustomNodeResource
+	runningPods []*v1.Pod
+	pendingPods []*v1.Pod
+
+	kubeClient              kubernetes.Interface
+	internalClient          versioned.Interface
+	informerFactory         informers.SharedInformerFactory
+	internalInformerFactory externalversions.SharedInformerFactory
+}
+
+// NewSimulator builds frameworks for all profiles in the config, and pods are scheduled
+// by the profile of the same scheduler name.
+func NewSimulator(cfg *kubeschedulerconfig.KubeSchedulerConfiguration, registry frameworkruntime.Registry, snapshot *Snapshot) (*Simulator, error) {
+	kubeObjects := make([]runtime.Object, 0, len(snapshot.Nodes)+len(snapshot.RunningPods))
+	for _, node := range snapshot.Nodes {
+		kubeObjects = append(kubeObjects, node)
+	}
+	for _, pod := range snapshot.RunningPods {
+		kubeObjects = append(kubeObjects, pod)
+	}
+
+	cnrs := make(map[string]*v1alpha1.CustomNodeResource, len(snapshot.CNRs))
+	internalObjects := make([]runtime.Object, 0, len(snapshot.CNRs))
+	for _, cnr := range snapshot.CNRs {
+		cnrs[cnr.Name] = cnr
+		internalObjects = append(internalObjects, cnr)
+	}
+
+	pendingPods := append([]*v1.Pod{}, snapshot.PendingPods...)
+	// the same order as the default scheduling queue sort plugin
+	sort.SliceStable(pendingPods, func(i, j int) bool {
+		return corev1helpers.PodPriority(pendingPods[i]) > corev1helpers.PodPriority(pendingPods[j])
+	})
+
+	kubeClient := fake.NewSimpleClientset(kubeObjects...)
+	internalClient := internalfake.NewSimpleClientset(internalObjects...)
+	s := &Simulator{
+		frameworks:              make(map[string]framework.Framework, len(cfg.Profiles)),
+		lister:                  newNodeInfoLister(snapshot.Nodes, snapshot.RunningPods),
+		cnrs:                    cnrs,
+		runningPods:             snapshot.RunningPods,
+		pendingPods:             pendingPods,
+		kubeClient:              kubeClient,
+		internalClient:          internalClient,
+		informerFactory:         informers.NewSharedInformerFactory(kubeClient, 0),
+		internalInformerFactory: externalversions.NewSharedInformerFactory(internalClient, 0),
+	}
+
+	for i := range cfg.Profiles {
+		profile := &cfg.Profiles[i]
+		fwk, err := frameworkruntime.NewFramework(registry, profile,
+			frameworkruntime.WithClientSet(s.kubeClient),
+			frameworkruntime.WithInformerFactory(s.informerFactory),
+			frameworkruntime.WithSnapshotSharedLister(s.lister),
+			frameworkruntime.WithEventRecorder(&events.FakeRecorder{}),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build framework for profile %v: %v", profile.SchedulerName, err)
+		}
+		s.frameworks[profile.SchedulerName] = fwk
+	}
+	return s, nil
+}
+
+// Run schedules all pending pods one by one, and returns the decisions in the scheduling order.
+func (s *Simulator) Run(ctx context.Context) ([]*Result, error) {
+	// event handlers are registered by plugins when frameworks are built
+	for _, handlerFunc := range eventhandlers.ListEventHandlerFunc() {
+		handlerFunc(s.informerFactory, s.internalInformerFactory)
+	}
+
+	s.informerFactory.Start(ctx.Done())
+	s.internalInformerFactory.Start(ctx.Done())
+	s.informerFactory.WaitForCacheSync(ctx.Done())
+	s.internalInformerFactory.WaitForCacheSync(ctx.Done())
+
+	for _, pod := range s.runningPods {
+		if util.IsReclaimedPod(pod) {
+			if err := cache.GetCache().AddPod(pod); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	results := make([]*Result, 0, len(s.pendingPods))
+	for _, pod := range s.pendingPods {
+		result := s.schedulePod(ctx, pod)
+		if result.NodeName != "" {
+			if err := s.assumePod(ctx, pod, result); err != nil {
+				return nil, err
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (s *Simulator) schedulePod(ctx context.Context, pod *v1.Pod) *Result {
+	result := &Result{Namespace: pod.Namespace, Name: pod.Name}
+
+	schedulerName := pod.Spec.SchedulerName
+	if schedulerName == "" {
+		schedulerName = v1.DefaultSchedulerName
+	}
+	fwk, ok := s.frameworks[schedulerName]
+	if !ok {
+		result.Message = fmt.Sprintf("profile not found for scheduler name %q", schedulerName)
+		return result
+	}
+
+	// CNRs in the extended cache are refreshed directly in each cycle, since
+	// the informer may deliver events later than the simulated bindings.
+	for _, cnr := range s.cnrs {
+		cache.GetCache().AddOrUpdateCNR(cnr)
+	}
+
+	state := framework.NewCycleState()
+	nodeInfos, err := s.lister.List()
+	if err != nil {
+		result.Message = err.Error()
+		return result
+	}
+
+	preFilterResult, status := fwk.RunPreFilterPlugins(ctx, state, pod)
+	if !status.IsSuccess() {
+		result.Message = status.Message()
+		return result
+	}
+
+	diagnosis := framework.Diagnosis{
+		NodeToStatusMap:      make(framework.NodeToStatusMap),
+		UnschedulablePlugins: sets.NewString(),
+	}
+	feasibleNodes := make([]*v1.Node, 0, len(nodeInfos))
+	for _, nodeInfo := range nodeInfos {
+		nodeName := nodeInfo.Node().Name
+		if !preFilterResult.AllNodes() && !preFilterResult.NodeNames.Has(nodeName) {
+			diagnosis.NodeToStatusMap[nodeName] = framework.NewStatus(framework.UnschedulableAndUnresolvable,
+				"node is filtered out by the prefilter result")
+			continue
+		}
+
+		if status := fwk.RunFilterPlugins(ctx, state, pod, nodeInfo).Merge(); !status.IsSuccess() {
+			diagnosis.NodeToStatusMap[nodeName] = status
+			diagnosis.UnschedulablePlugins.Insert(status.FailedPlugin())
+			continue
+		}
+		feasibleNodes = append(feasibleNodes, nodeInfo.Node())
+	}
+
+	if len(feasibleNodes) == 0 {
+		result.Message = (&framework.FitError{Pod: pod, NumAllNodes: len(nodeInfos), Diagnosis: diagnosis}).Error()
+		return result
+	}
+
+	if status := fwk.RunPreScorePlugins(ctx, state, pod, feasibleNodes); !status.IsSuccess() {
+		result.Message = status.Message()
+		return result
+	}
+	pluginToNodeScores, status := fwk.RunScorePlugins(ctx, state, pod, feasibleNodes)
+	if !status.IsSuccess() {
+		result.Message = status.Message()
+		return result
+	}
+
+	result.Scores = make(map[string]map[string]int64, len(feasibleNodes))
+	for _, node := range feasibleNodes {
+		result.Scores[node.Name] = make(map[string]int64)
+	}
+	for pluginName, nodeScores := range pluginToNodeScores {
+		for _, nodeScore := range nodeScores {
+			result.Scores[nodeScore.Name][pluginName] = nodeScore.Score
+		}
+	}
+
+	nodeName := selectHost(result)
+	if status := fwk.RunReservePluginsReserve(ctx, state, pod, nodeName); !status.IsSuccess() {
+		fwk.RunReservePluginsUnreserve(ctx, state, pod, nodeName)
+		result.Message = status.Message()
+		return result
+	}
+	result.NodeName = nodeName
+	return result
+}
+
+// selectHost returns the node with the highest total score, and ties are broken by node
+// name instead of randomly as the scheduler does, to make the simulation reproducible.
+func selectHost(result *Result) string {
+	var (
+		selected  string
+		bestScore int64
+	)
+	for nodeName := range result.Scores {
+		score := result.TotalScore(nodeName)
+		if selected == "" || score > bestScore || (score == bestScore && nodeName < selected) {
+			selected, bestScore = nodeName, score
+		}
+	}
+	return selected
+}
+
+// assumePod binds the pod to the selected node in the simulated cluster, and
+// allocates NUMA resources for the pod if needed.
+func (s *Simulator) assumePod(ctx context.Context, pod *v1.Pod, result *Result) error {
+	assumed := pod.DeepCopy()
+	assumed.Spec.NodeName = result.NodeName
+	assumed.Status.Phase = v1.PodRunning
+
+	if cnr, ok := s.cnrs[result.NodeName]; ok && needNUMAAssignment(assumed) {
+		numaIDs, updated, err := assignNUMANodes(assumed, cnr)
+		if err != nil {
+			klog.Warningf("failed to assign NUMA for pod %v/%v: %v", pod.Namespace, pod.Name, err)
+		} else {
+			result.NUMANodes = numaIDs
+			s.cnrs[result.NodeName] = updated
+			cache.GetCache().AddOrUpdateCNR(updated)
+			if _, err := s.internalClient.NodeV1alpha1().CustomNodeResources().Update(ctx, updated, metav1.UpdateOptions{}); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := s.lister.addPod(assumed); err != nil {
+		return err
+	}
+	if util.IsReclaimedPod(assumed) {
+		if err := cache.GetCache().AddPod(assumed); err != nil {
+			return err
+		}
+	}
+	_, err := s.kubeClient.CoreV1().Pods(assumed.Namespace).Create(ctx, assumed, metav1.CreateOptions{})
+	return err
+}
@@ -0,0 +1,222 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/kubernetes/pkg/scheduler/apis/config/latest"
+	frameworkplugins "k8s.io/kubernetes/pkg/scheduler/framework/plugins"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
+	"github.com/kubewharf/katalyst-api/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/config/generic"
+	"github.com/kubewharf/katalyst-core/pkg/scheduler/util"
+)
+
+func makeTestSimulatorNode(name, cpu, memory string) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: v1.NodeStatus{
+			Allocatable: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse(cpu),
+				v1.ResourceMemory: resource.MustParse(memory),
+				v1.ResourcePods:   resource.MustParse("110"),
+			},
+		},
+	}
+}
+
+func makeTestSimulatorNUMA(name, cpu, memory string) *v1alpha1.TopologyZone {
+	return &v1alpha1.TopologyZone{
+		Name: name,
+		Type: v1alpha1.TopologyTypeNuma,
+		Resources: v1alpha1.Resources{
+			Capacity: &v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse(cpu),
+				v1.ResourceMemory: resource.MustParse(memory),
+			},
+			Allocatable: &v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse(cpu),
+				v1.ResourceMemory: resource.MustParse(memory),
+			},
+		},
+	}
+}
+
+func makeTestSimulatorPod(name, nodeName, cpu, memory string, annotations map[string]string) *v1.Pod {
+	resources := v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse(cpu),
+		v1.ResourceMemory: resource.MustParse(memory),
+	}
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        name,
+			UID:         types.UID(name),
+			Annotations: annotations,
+		},
+		Spec: v1.PodSpec{
+			NodeName: nodeName,
+			Containers: []v1.Container{
+				{
+					Name:      "c",
+					Resources: v1.ResourceRequirements{Requests: resources, Limits: resources},
+				},
+			},
+		},
+	}
+}
+
+func TestAssignNUMANodes(t *testing.T) {
+	t.Parallel()
+	util.SetQoSConfig(generic.NewQoSConfiguration())
+
+	cnr := &v1alpha1.CustomNodeResource{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-numa"},
+		Status: v1alpha1.CustomNodeResourceStatus{
+			TopologyZone: []*v1alpha1.TopologyZone{
+				{
+					Name: "0",
+					Type: v1alpha1.TopologyTypeSocket,
+					Children: []*v1alpha1.TopologyZone{
+						makeTestSimulatorNUMA("0", "4", "8Gi"),
+						makeTestSimulatorNUMA("1", "4", "8Gi"),
+						makeTestSimulatorNUMA("2", "4", "8Gi"),
+					},
+				},
+			},
+		},
+	}
+
+	binding := map[string]string{
+		consts.PodAnnotationQoSLevelKey:          consts.PodAnnotationQoSLevelDedicatedCores,
+		consts.PodAnnotationMemoryEnhancementKey: `{"numa_binding":"true"}`,
+	}
+	exclusive := map[string]string{
+		consts.PodAnnotationQoSLevelKey:          consts.PodAnnotationQoSLevelDedicatedCores,
+		consts.PodAnnotationMemoryEnhancementKey: `{"numa_binding":"true","numa_exclusive":"true"}`,
+	}
+
+	numaIDs, cnr, err := assignNUMANodes(makeTestSimulatorPod("p1", "", "2", "4Gi", binding), cnr)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0}, numaIDs)
+
+	numaIDs, cnr, err = assignNUMANodes(makeTestSimulatorPod("p2", "", "2", "4Gi", binding), cnr)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0}, numaIDs, "the lowest NUMA that fits should be preferred")
+
+	numaIDs, cnr, err = assignNUMANodes(makeTestSimulatorPod("p3", "", "1", "1Gi", exclusive), cnr)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1}, numaIDs, "numa_exclusive pods should occupy idle NUMA")
+
+	numaIDs, cnr, err = assignNUMANodes(makeTestSimulatorPod("p4", "", "1", "1Gi", binding), cnr)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2}, numaIDs)
+
+	_, _, err = assignNUMANodes(makeTestSimulatorPod("p5", "", "6", "12Gi", exclusive), cnr)
+	assert.Error(t, err, "no idle NUMA left")
+
+	zones := getNUMAZones(cnr)
+	assert.Len(t, zones[0].zone.Allocations, 2)
+	assert.Equal(t, "default/p3/p3", zones[1].zone.Allocations[0].Consumer)
+	assert.True(t, zones[1].available.Cpu().IsZero(), "numa_exclusive pods should consume the whole NUMA")
+}
+
+func TestSimulator(t *testing.T) {
+	t.Parallel()
+	util.SetQoSConfig(generic.NewQoSConfiguration())
+
+	binding := map[string]string{
+		consts.PodAnnotationQoSLevelKey:          consts.PodAnnotationQoSLevelDedicatedCores,
+		consts.PodAnnotationMemoryEnhancementKey: `{"numa_binding":"true"}`,
+	}
+	highPriority := int32(100)
+	highPriorityPod := makeTestSimulatorPod("high-priority", "", "1", "1Gi", nil)
+	highPriorityPod.Spec.Priority = &highPriority
+
+	snapshot := &Snapshot{
+		Nodes: []*v1.Node{
+			makeTestSimulatorNode("node-simulator-1", "8", "16Gi"),
+			makeTestSimulatorNode("node-simulator-2", "8", "16Gi"),
+		},
+		CNRs: []*v1alpha1.CustomNodeResource{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "node-simulator-2"},
+				Status: v1alpha1.CustomNodeResourceStatus{
+					TopologyZone: []*v1alpha1.TopologyZone{
+						{
+							Name: "0",
+							Type: v1alpha1.TopologyTypeSocket,
+							Children: []*v1alpha1.TopologyZone{
+								makeTestSimulatorNUMA("0", "4", "8Gi"),
+								makeTestSimulatorNUMA("1", "4", "8Gi"),
+							},
+						},
+					},
+				},
+			},
+		},
+		RunningPods: []*v1.Pod{
+			makeTestSimulatorPod("running", "node-simulator-1", "6", "12Gi", nil),
+		},
+		PendingPods: []*v1.Pod{
+			makeTestSimulatorPod("numa-binding", "", "4", "8Gi", binding),
+			makeTestSimulatorPod("too-large", "", "16", "1Gi", nil),
+			highPriorityPod,
+		},
+	}
+
+	cfg, err := latest.Default()
+	assert.NoError(t, err)
+
+	s, err := NewSimulator(cfg, frameworkplugins.NewInTreeRegistry(), snapshot)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	results, err := s.Run(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, results, 3)
+
+	// pods with higher priority are scheduled first
+	assert.Equal(t, "high-priority", results[0].Name)
+	assert.Equal(t, "node-simulator-2", results[0].NodeName)
+	assert.Len(t, results[0].Scores, 2)
+
+	assert.Equal(t, "numa-binding", results[1].Name)
+	assert.Equal(t, "node-simulator-2", results[1].NodeName)
+	assert.Equal(t, []int{0}, results[1].NUMANodes)
+	assert.Len(t, results[1].Scores, 1, "node-simulator-1 doesn't have enough cpu")
+
+	assert.Equal(t, "too-large", results[2].Name)
+	assert.Empty(t, results[2].NodeName)
+	assert.Contains(t, results[2].Message, "Insufficient cpu")
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, PrintResults(buf, results, OutputFormatTable))
+	assert.Contains(t, buf.String(), "numa-binding")
+	assert.Error(t, PrintResults(buf, results, "yaml"))
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/klog/v2"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
+	katalystscheme "github.com/kubewharf/katalyst-api/pkg/client/clientset/versioned/scheme"
+)
+
+var snapshotDecoder runtime.Decoder
+
+func init() {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(katalystscheme.AddToScheme(scheme))
+	snapshotDecoder = serializer.NewCodecFactory(scheme).UniversalDeserializer()
+}
+
+// Snapshot is the cluster state loaded from YAML or JSON files, pods that have been
+// assigned to nodes are regarded as running pods, and the others are pending pods
+// that will be scheduled by the simulator.
+type Snapshot struct {
+	Nodes       []*v1.Node
+	CNRs        []*v1alpha1.CustomNodeResource
+	RunningPods []*v1.Pod
+	PendingPods []*v1.Pod
+}
+
+// LoadSnapshot loads Nodes, CNRs and Pods from the given files or directories; each file
+// may contain multiple documents, and lists (e.g. output of kubectl get -o yaml) are supported.
+func LoadSnapshot(paths ...string) (*Snapshot, error) {
+	s := &Snapshot{}
+	for _, path := range paths {
+		files, err := listSnapshotFiles(path)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range files {
+			if err := s.loadFile(file); err != nil {
+				return nil, fmt.Errorf("failed to load %v: %v", file, err)
+			}
+		}
+	}
+	return s, nil
+}
+
+// listSnapshotFiles returns the path itself for files, and all YAML or JSON
+// files in lexical order for directories.
+func listSnapshotFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	} else if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".yaml", ".yml", ".json":
+			if !entry.IsDir() {
+				files = append(files, filepath.Join(path, entry.Name()))
+			}
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func (s *Snapshot) loadFile(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	reader := yaml.NewYAMLReader(bufio.NewReader(f))
+	for {
+		doc, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+		if err := s.loadDocument(doc); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Snapshot) loadDocument(doc []byte) error {
+	jsonDoc, err := yaml.ToJSON(doc)
+	if err != nil {
+		return err
+	}
+
+	obj, _, err := snapshotDecoder.Decode(jsonDoc, nil, nil)
+	if err != nil {
+		return err
+	}
+	return s.addObject(obj)
+}
+
+func (s *Snapshot) addObject(obj runtime.Object) error {
+	switch t := obj.(type) {
+	case *v1.List:
+		for _, item := range t.Items {
+			if err := s.loadDocument(item.Raw); err != nil {
+				return err
+			}
+		}
+	case *v1.NodeList:
+		for i := range t.Items {
+			s.Nodes = append(s.Nodes, &t.Items[i])
+		}
+	case *v1.PodList:
+		for i := range t.Items {
+			s.addPod(&t.Items[i])
+		}
+	case *v1alpha1.CustomNodeResourceList:
+		for i := range t.Items {
+			s.CNRs = append(s.CNRs, &t.Items[i])
+		}
+	case *v1.Node:
+		s.Nodes = append(s.Nodes, t)
+	case *v1.Pod:
+		s.addPod(t)
+	case *v1alpha1.CustomNodeResource:
+		s.CNRs = append(s.CNRs, t)
+	default:
+		klog.Warningf("skip unsupported object %v in snapshot", obj.GetObjectKind().GroupVersionKind())
+	}
+	return nil
+}
+
+func (s *Snapshot) addPod(pod *v1.Pod) {
+	if pod.Namespace == "" {
+		pod.Namespace = v1.NamespaceDefault
+	}
+	if pod.UID == "" {
+		pod.UID = types.UID(fmt.Sprintf("%v-%v", pod.Namespace, pod.Name))
+	}
+
+	// pods finished already don't occupy any resource
+	if pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+		return
+	}
+
+	if pod.Spec.NodeName != "" {
+		s.RunningPods = append(s.RunningPods, pod)
+	} else {
+		s.PendingPods = append(s.PendingPods, pod)
+	}
+}
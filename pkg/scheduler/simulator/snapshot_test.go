@@ -0,0 +1,110 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubewharf/katalyst-api/pkg/apis/node/v1alpha1"
+)
+
+const testSnapshotNodes = `
+apiVersion: v1
+kind: Node
+metadata:
+  name: node-1
+status:
+  allocatable:
+    cpu: "8"
+    memory: 16Gi
+    pods: "110"
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: running
+  spec:
+    nodeName: node-1
+    containers:
+    - name: c
+      image: test
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: pending
+    namespace: test
+  spec:
+    containers:
+    - name: c
+      image: test
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: succeeded
+  spec:
+    containers:
+    - name: c
+      image: test
+  status:
+    phase: Succeeded
+- apiVersion: v1
+  kind: Service
+  metadata:
+    name: unsupported
+`
+
+func TestLoadSnapshot(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "nodes.yaml"), []byte(testSnapshotNodes), 0o644))
+
+	cnr, err := json.Marshal(&v1alpha1.CustomNodeResource{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1alpha1.SchemeGroupVersion.String(),
+			Kind:       "CustomNodeResource",
+		},
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "cnrs.json"), cnr, 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("ignored"), 0o644))
+
+	s, err := LoadSnapshot(dir)
+	assert.NoError(t, err)
+	assert.Len(t, s.Nodes, 1)
+	assert.Len(t, s.CNRs, 1)
+	assert.Equal(t, "node-1", s.CNRs[0].Name)
+
+	assert.Len(t, s.RunningPods, 1)
+	assert.Equal(t, "default", s.RunningPods[0].Namespace)
+	assert.Equal(t, "default-running", string(s.RunningPods[0].UID))
+	assert.Len(t, s.PendingPods, 1)
+	assert.Equal(t, "test", s.PendingPods[0].Namespace)
+
+	_, err = LoadSnapshot(filepath.Join(dir, "not-exist.yaml"))
+	assert.Error(t, err)
+}
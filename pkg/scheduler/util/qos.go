@@ -48,6 +48,17 @@ func IsDedicatedPod(pod *v1.Pod) bool {
 	return ok
 }
 
+// GetQoSLevel returns the qos level of the given pod, and empty string is
+// returned if the qos level can't be determined.
+func GetQoSLevel(pod *v1.Pod) string {
+	qosLevel, err := qosConfig.GetQoSLevelForPod(pod)
+	if err != nil {
+		klog.Errorf("failed to get qos level for pod %v/%v: %v", pod.Namespace, pod.Name, err)
+		return ""
+	}
+	return qosLevel
+}
+
 func IsNumaBinding(pod *v1.Pod) bool {
 	if pod == nil {
 		return false
@@ -36,6 +36,8 @@ const (
 	CgroupSubsysIO     = "io"
 	// CgroupSubsysNetCls is the net_cls sub-system
 	CgroupSubsysNetCls = "net_cls"
+	// CgroupSubsysFreezer is the freezer sub-system, and it only exists in cgroupv1
+	CgroupSubsysFreezer = "freezer"
 
 	PodCgroupPathPrefix        = "pod"
 	CgroupFsRootPath           = "/kubepods"
@@ -187,6 +189,8 @@ type MemoryStats struct {
 type CPUStats struct {
 	CpuPeriod uint64
 	CpuQuota  int64
+	// CpuBurst is 0 if cpu burst is not supported by the kernel
+	CpuBurst uint64
 }
 
 // CPUSetStats get cgroup cpuset data
@@ -195,6 +195,21 @@ func ApplyUnifiedDataForContainer(podUID, containerId, subsys, cgroupFileName, d
 	return ApplyUnifiedDataWithAbsolutePath(absCgroupPath, cgroupFileName, data)
 }
 
+func ApplyFreezerWithAbsolutePath(absCgroupPath string, frozen bool) error {
+	return GetManager().ApplyFreezer(absCgroupPath, frozen)
+}
+
+// ApplyFreezerForPod freezes or thaws all processes of the given pod; for cgroupv1
+// it's done by freezer sub-system, and for cgroupv2 it's done by cgroup.freeze.
+func ApplyFreezerForPod(podUID string, frozen bool) error {
+	absCgroupPath, err := common.GetPodAbsCgroupPath(common.CgroupSubsysFreezer, podUID)
+	if err != nil {
+		return fmt.Errorf("GetPodAbsCgroupPath failed with error: %v", err)
+	}
+
+	return ApplyFreezerWithAbsolutePath(absCgroupPath, frozen)
+}
+
 func GetMemoryWithRelativePath(relCgroupPath string) (*common.MemoryStats, error) {
 	absCgroupPath := common.GetAbsCgroupPath("memory", relCgroupPath)
 	return GetManager().GetMemory(absCgroupPath)
@@ -303,6 +318,36 @@ func GetTasksWithAbsolutePath(absCgroupPath string) ([]string, error) {
 	return GetManager().GetTasks(absCgroupPath)
 }
 
+// GetTasksForContainer returns thread ids in the cpu cgroup of the container, and
+// nothing is returned if the container cgroup doesn't exist yet.
+func GetTasksForContainer(podUID, containerId string) ([]int, error) {
+	if exist, err := common.IsContainerCgroupExist(podUID, containerId); err != nil {
+		return nil, err
+	} else if !exist {
+		return nil, nil
+	}
+
+	absCgroupPath, err := common.GetContainerAbsCgroupPath(common.CgroupSubsysCPU, podUID, containerId)
+	if err != nil {
+		return nil, fmt.Errorf("GetContainerAbsCgroupPath failed with error: %v", err)
+	}
+
+	taskList, err := GetTasksWithAbsolutePath(absCgroupPath)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]int, 0, len(taskList))
+	for _, task := range taskList {
+		pid, err := strconv.Atoi(task)
+		if err != nil {
+			continue
+		}
+		tasks = append(tasks, pid)
+	}
+	return tasks, nil
+}
+
 func GetCPUSetForContainer(podUID, containerId string) (*common.CPUSetStats, error) {
 	cpusetAbsCGPath, err := common.GetContainerAbsCgroupPath(common.CgroupSubsysCPUSet, podUID, containerId)
 	if err != nil {
@@ -52,6 +52,10 @@ func (f *FakeCgroupManager) ApplyUnifiedData(absCgroupPath, cgroupFileName, data
 	return nil
 }
 
+func (f *FakeCgroupManager) ApplyFreezer(absCgroupPath string, frozen bool) error {
+	return nil
+}
+
 func (f *FakeCgroupManager) GetMemory(absCgroupPath string) (*common.MemoryStats, error) {
 	return nil, nil
 }
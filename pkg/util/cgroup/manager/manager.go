@@ -42,6 +42,7 @@ type Manager interface {
 	ApplyIOCostModel(absCgroupPath string, devID string, data *common.IOCostModelData) error
 	ApplyIOWeight(absCgroupPath string, devID string, weight uint64) error
 	ApplyUnifiedData(absCgroupPath, cgroupFileName, data string) error
+	ApplyFreezer(absCgroupPath string, frozen bool) error
 
 	GetMemory(absCgroupPath string) (*common.MemoryStats, error)
 	GetNumaMemory(absCgroupPath string) (map[int]*common.MemoryNumaMetrics, error)
@@ -22,6 +22,7 @@ package v1
 import (
 	"errors"
 	"fmt"
+	"os"
 	"path"
 	"path/filepath"
 	"strconv"
@@ -225,6 +226,21 @@ func (m *manager) ApplyUnifiedData(absCgroupPath, cgroupFileName, data string) e
 	return nil
 }
 
+func (m *manager) ApplyFreezer(absCgroupPath string, frozen bool) error {
+	state := "THAWED"
+	if frozen {
+		state = "FROZEN"
+	}
+
+	if err, applied, oldData := common.InstrumentedWriteFileIfChange(absCgroupPath, "freezer.state", state); err != nil {
+		return err
+	} else if applied {
+		klog.Infof("[CgroupV1] apply freezer state successfully, cgroupPath: %s, data: %v, old data: %v\n", absCgroupPath, state, oldData)
+	}
+
+	return nil
+}
+
 func (m *manager) GetMemory(absCgroupPath string) (*common.MemoryStats, error) {
 	memoryStats := &common.MemoryStats{}
 	moduleName := "memory"
@@ -311,6 +327,14 @@ func (m *manager) GetCPU(absCgroupPath string) (*common.CPUStats, error) {
 		return nil, fmt.Errorf("get cfs quota %s err, %v", absCgroupPath, err)
 	}
 
+	// cpu burst is only supported by newer kernels, so its absence is not an error
+	burst, err := common.GetCgroupParamInt(absCgroupPath, "cpu.cfs_burst_us")
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("get cfs burst %s err, %v", absCgroupPath, err)
+	} else if err == nil && burst > 0 {
+		cpuStats.CpuBurst = uint64(burst)
+	}
+
 	cpuStats.CpuPeriod = period
 	cpuStats.CpuQuota = quota
 	return cpuStats, nil
@@ -280,6 +280,21 @@ func (m *manager) ApplyUnifiedData(absCgroupPath, cgroupFileName, data string) e
 	return nil
 }
 
+func (m *manager) ApplyFreezer(absCgroupPath string, frozen bool) error {
+	state := "0"
+	if frozen {
+		state = "1"
+	}
+
+	if err, applied, oldData := common.InstrumentedWriteFileIfChange(absCgroupPath, "cgroup.freeze", state); err != nil {
+		return err
+	} else if applied {
+		klog.Infof("[CgroupV2] apply cgroup freeze successfully, cgroupPath: %s, data: %v, old data: %v\n", absCgroupPath, state, oldData)
+	}
+
+	return nil
+}
+
 func (m *manager) GetMemory(absCgroupPath string) (*common.MemoryStats, error) {
 	memoryStats := &common.MemoryStats{}
 	moduleName := "memory"
@@ -504,6 +519,14 @@ func (m *manager) GetCPU(absCgroupPath string) (*common.CPUStats, error) {
 		return nil, fmt.Errorf("parse uint %s err, err %v", parts[1], err)
 	}
 
+	// cpu burst is only supported by newer kernels, so its absence is not an error
+	burst, err := common.GetCgroupParamInt(absCgroupPath, "cpu.max.burst")
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("get cpu burst %s err, %v", absCgroupPath, err)
+	} else if err == nil && burst > 0 {
+		cpuStats.CpuBurst = uint64(burst)
+	}
+
 	cpuStats.CpuPeriod = period
 	cpuStats.CpuQuota = quota
 	return cpuStats, nil
@@ -64,6 +64,10 @@ func (m *unsupportedManager) ApplyUnifiedData(absCgroupPath, cgroupFileName, dat
 	return fmt.Errorf("unsupported manager v1")
 }
 
+func (m *unsupportedManager) ApplyFreezer(absCgroupPath string, frozen bool) error {
+	return fmt.Errorf("unsupported manager v2")
+}
+
 func (m *unsupportedManager) GetMemory(_ string) (*common.MemoryStats, error) {
 	return nil, fmt.Errorf("unsupported manager v2")
 }
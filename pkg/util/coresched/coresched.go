@@ -0,0 +1,38 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package coresched wraps linux core scheduling (prctl PR_SCHED_CORE), which
+// guarantees that only tasks sharing the same cookie can run simultaneously
+// on SMT siblings of a physical core.
+package coresched
+
+// CookieOperator manages core scheduling cookies of tasks; all pids here
+// are thread ids, since cookies are maintained per task in kernel.
+type CookieOperator interface {
+	// Supported returns whether core scheduling is supported by the kernel
+	Supported() bool
+	// Get returns the cookie of the given task, and zero means no cookie
+	Get(pid int) (uint64, error)
+	// Create assigns a new unique cookie to the given task
+	Create(pid int) error
+	// ShareTo copies the cookie of task from to all the tasks in to
+	ShareTo(from int, to []int) error
+}
+
+// NewCookieOperator returns the CookieOperator based on prctl
+func NewCookieOperator() CookieOperator {
+	return &prctlCookieOperator{}
+}
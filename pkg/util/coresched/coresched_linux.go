@@ -0,0 +1,90 @@
+//go:build linux
+// +build linux
+
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coresched
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+type prctlCookieOperator struct{}
+
+func (p *prctlCookieOperator) Supported() bool {
+	_, err := p.Get(os.Getpid())
+	return err == nil
+}
+
+func (p *prctlCookieOperator) Get(pid int) (uint64, error) {
+	var cookie uint64
+	err := unix.Prctl(unix.PR_SCHED_CORE, unix.PR_SCHED_CORE_GET, uintptr(pid),
+		unix.PR_SCHED_CORE_SCOPE_THREAD, uintptr(unsafe.Pointer(&cookie)))
+	if err != nil {
+		return 0, fmt.Errorf("get core sched cookie of %d failed: %w", pid, err)
+	}
+	return cookie, nil
+}
+
+func (p *prctlCookieOperator) Create(pid int) error {
+	err := unix.Prctl(unix.PR_SCHED_CORE, unix.PR_SCHED_CORE_CREATE, uintptr(pid),
+		unix.PR_SCHED_CORE_SCOPE_THREAD, 0)
+	if err != nil {
+		return fmt.Errorf("create core sched cookie for %d failed: %w", pid, err)
+	}
+	return nil
+}
+
+// ShareTo pulls the cookie into the current thread and pushes it to the targets,
+// since kernel doesn't support sharing cookies between two other tasks directly.
+func (p *prctlCookieOperator) ShareTo(from int, to []int) error {
+	if len(to) == 0 {
+		return nil
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		// the thread holds the cookie after SHARE_FROM, so we never unlock it
+		// to make go runtime terminate the thread when this goroutine exits,
+		// otherwise the cookie will leak to other goroutines of the agent.
+		runtime.LockOSThread()
+
+		err := unix.Prctl(unix.PR_SCHED_CORE, unix.PR_SCHED_CORE_SHARE_FROM, uintptr(from),
+			unix.PR_SCHED_CORE_SCOPE_THREAD, 0)
+		if err != nil {
+			errCh <- fmt.Errorf("share core sched cookie from %d failed: %w", from, err)
+			return
+		}
+
+		var errList []error
+		for _, pid := range to {
+			err = unix.Prctl(unix.PR_SCHED_CORE, unix.PR_SCHED_CORE_SHARE_TO, uintptr(pid),
+				unix.PR_SCHED_CORE_SCOPE_THREAD, 0)
+			if err != nil {
+				errList = append(errList, fmt.Errorf("share core sched cookie to %d failed: %w", pid, err))
+			}
+		}
+		errCh <- utilerrors.NewAggregate(errList)
+	}()
+	return <-errCh
+}
@@ -68,6 +68,42 @@ type SyscallEvent struct {
 	Logs        []SyscallLog
 }
 
+// EvictionEvent records a pod eviction performed (or only simulated
+// in dry-run mode) by eviction manager.
+type EvictionEvent struct {
+	BaseEventImpl
+	PodUID             string
+	PodNamespace       string
+	PodName            string
+	Plugin             string
+	Killer             string
+	Reason             string
+	GracePeriodSeconds int64
+	DryRun             bool
+}
+
+// AllocationEvent records the result of allocating resources for a container
+// by QRM plugins, and Error is set if the allocation fails.
+type AllocationEvent struct {
+	BaseEventImpl
+	PodUID        string
+	PodNamespace  string
+	PodName       string
+	ContainerName string
+	ResourceName  string
+	QoSLevel      string
+	Results       []AllocationResult
+	Error         string
+}
+
+// AllocationResult is the allocated quantity and result (e.g. cpuset or NUMA
+// nodes) of a resource.
+type AllocationResult struct {
+	ResourceName      string
+	AllocatedQuantity float64
+	AllocationResult  string
+}
+
 type SyscallLog struct {
 	Time     time.Time
 	KeyValue map[string]string
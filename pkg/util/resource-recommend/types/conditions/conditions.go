@@ -26,6 +26,16 @@ import (
 	errortypes "github.com/kubewharf/katalyst-core/pkg/util/resource-recommend/types/error"
 )
 
+const (
+	// OOMRiskPredicted indicates whether any container is predicted to OOM within the forecast horizon.
+	OOMRiskPredicted v1alpha1.ResourceRecommendConditionType = "OOMRiskPredicted"
+)
+
+const (
+	OOMRiskReasonMemoryGrowth = "PredictedMemoryExhaustion"
+	OOMRiskReasonNoRisk       = "NoRiskPredicted"
+)
+
 // ResourceRecommendConditionsMap is map from recommend condition type to condition.
 type ResourceRecommendConditionsMap map[v1alpha1.ResourceRecommendConditionType]v1alpha1.ResourceRecommendCondition
 
@@ -87,6 +97,24 @@ func RecommendationReadyCondition() *v1alpha1.ResourceRecommendCondition {
 	}
 }
 
+// OOMRiskCondition returns the OOMRiskPredicted condition, it is true if the message describing
+// the predicted risks is not empty.
+func OOMRiskCondition(message string) *v1alpha1.ResourceRecommendCondition {
+	if message == "" {
+		return &v1alpha1.ResourceRecommendCondition{
+			Type:   OOMRiskPredicted,
+			Status: v1.ConditionFalse,
+			Reason: OOMRiskReasonNoRisk,
+		}
+	}
+	return &v1alpha1.ResourceRecommendCondition{
+		Type:    OOMRiskPredicted,
+		Status:  v1.ConditionTrue,
+		Reason:  OOMRiskReasonMemoryGrowth,
+		Message: message,
+	}
+}
+
 func ConvertCustomErrorToCondition(err errortypes.CustomError) *v1alpha1.ResourceRecommendCondition {
 	var conditionType v1alpha1.ResourceRecommendConditionType
 	switch err.Phase {
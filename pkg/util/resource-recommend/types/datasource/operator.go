@@ -18,6 +18,7 @@ package datasource
 
 import (
 	"sort"
+	"time"
 )
 
 type SamplesOverview struct {
@@ -82,3 +83,29 @@ func calculateSamplesPercentile(samples []Sample, percentile float64) float64 {
 	upper := samples[int(index)+1].Value
 	return (lower + upper) / 2
 }
+
+// DownsampleSamples keeps the first sample in each step-aligned interval, so that
+// datasources returning raw samples are consistent with range queries; samples
+// are sorted by timestamp in place.
+func DownsampleSamples(samples []Sample, step time.Duration) []Sample {
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i].Timestamp < samples[j].Timestamp
+	})
+
+	stepSeconds := int64(step.Seconds())
+	if stepSeconds <= 0 {
+		return samples
+	}
+
+	result := make([]Sample, 0, len(samples))
+	lastBucket := int64(-1)
+	for _, sample := range samples {
+		bucket := sample.Timestamp / stepSeconds
+		if len(result) > 0 && bucket == lastBucket {
+			continue
+		}
+		lastBucket = bucket
+		result = append(result, sample)
+	}
+	return result
+}
@@ -19,6 +19,7 @@ package datasource
 import (
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestGetSamplesOverview(t *testing.T) {
@@ -68,3 +69,23 @@ func TestGetSamplesOverview(t *testing.T) {
 		})
 	}
 }
+
+func TestDownsampleSamples(t *testing.T) {
+	samples := []Sample{
+		{Value: 3, Timestamp: 125},
+		{Value: 1, Timestamp: 60},
+		{Value: 2, Timestamp: 90},
+		{Value: 4, Timestamp: 180},
+	}
+	want := []Sample{
+		{Value: 1, Timestamp: 60},
+		{Value: 3, Timestamp: 125},
+		{Value: 4, Timestamp: 180},
+	}
+	if got := DownsampleSamples(samples, time.Minute); !reflect.DeepEqual(got, want) {
+		t.Errorf("DownsampleSamples() = %v, want %v", got, want)
+	}
+	if got := DownsampleSamples(samples, 0); len(got) != len(samples) {
+		t.Errorf("DownsampleSamples() with zero step should keep all samples, got %v", got)
+	}
+}
@@ -46,6 +46,10 @@ const (
 	WorkloadDeployment WorkloadKind = "Deployment"
 )
 
+const (
+	WorkloadSuffixRuleForDeployment = `[a-z0-9]+-[a-z0-9]{5}$`
+)
+
 type Metric struct {
 	// to be extended when new datasource is added
 	Namespace     string
@@ -75,14 +79,41 @@ func (m *Metric) SetSelector(selectors map[string]string) {
 	klog.V(4).InfoS("Setting selectors", "selectors", m.Selectors)
 }
 
+// GetWorkloadPodNamePattern returns the regular expression matching names of pods belonging to the workload
+func GetWorkloadPodNamePattern(workloadName string, workloadKind string) string {
+	switch workloadKind {
+	case string(WorkloadDeployment):
+		return fmt.Sprintf("^%s-%s", workloadName, WorkloadSuffixRuleForDeployment)
+	}
+	return fmt.Sprintf("^%s-%s", workloadName, `.*`)
+}
+
 type Query struct {
 	// to be extended when new datasource is added
-	Prometheus *PrometheusQuery
+	Prometheus     *PrometheusQuery
+	KatalystMetric *KatalystMetricQuery
+	Offline        *OfflineQuery
 }
 type PrometheusQuery struct {
 	Query string
 }
 
+// KatalystMetricQuery queries pod metrics stored in katalyst custom metric store
+type KatalystMetricQuery struct {
+	Namespace      string
+	MetricName     string
+	PodNamePattern string
+	MetricSelector string
+}
+
+// OfflineQuery queries container metrics from on-disk snapshots
+type OfflineQuery struct {
+	MetricName     string
+	Namespace      string
+	PodNamePattern string
+	ContainerName  string
+}
+
 func NewTimeSeries() *TimeSeries {
 	return &TimeSeries{
 		Labels:  make(map[string]string),
@@ -44,11 +44,14 @@ const (
 
 const (
 	PercentileAlgorithmType = "percentile"
+	// DecayingAlgorithmType aggregates samples into exponentially decaying histograms
+	// with configurable half-life, and histograms are checkpointed in ConfigMaps
+	DecayingAlgorithmType = "decaying"
 	// DefaultAlgorithmType use percentile as the default algorithm
 	DefaultAlgorithmType = PercentileAlgorithmType
 )
 
-var AlgorithmTypes = []string{PercentileAlgorithmType}
+var AlgorithmTypes = []string{PercentileAlgorithmType, DecayingAlgorithmType}
 
 var ResourceNames = []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory}
 